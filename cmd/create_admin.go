@@ -3,14 +3,18 @@ package cmd
 import (
 	"bufio"
 	domainuser "carowebapp/core/internal/domain/user"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
 
 	"carowebapp/core/internal/features/auth"
+	"carowebapp/core/internal/features/onetimetoken"
 	db "carowebapp/core/internal/infrastructure/db"
 	"carowebapp/core/internal/infrastructure/email"
 	"carowebapp/core/internal/infrastructure/logger"
+	"carowebapp/core/internal/pkg/passwordhash"
+	"carowebapp/core/internal/pkg/passwordpolicy"
 
 	"github.com/spf13/cobra"
 )
@@ -24,7 +28,18 @@ var CreateAdminCmd = &cobra.Command{
 		dbConn := db.InitDB()
 		repo := auth.NewSQLXRepository(dbConn)
 		sender := email.NewMailer(logger.Log)
-		service := auth.NewService(repo, sender)
+		policy, err := passwordpolicy.FromEnv()
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		hasher, err := passwordhash.FromEnv()
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		tokens := onetimetoken.NewService(onetimetoken.NewSQLXRepository(dbConn))
+		service := auth.NewService(repo, sender, nil, policy, hasher, tokens)
 
 		reader := bufio.NewReader(os.Stdin)
 
@@ -36,9 +51,15 @@ var CreateAdminCmd = &cobra.Command{
 		passwordInput, _ := reader.ReadString('\n')
 		password := strings.TrimSpace(passwordInput)
 
-		user, err := service.RegisterUser(email, password, domainuser.RoleAdmin, true)
+		user, err := service.RegisterUser(email, password, domainuser.RoleAdmin, "", true)
 		if err != nil {
 			fmt.Println("Error:", err)
+			var policyErr *passwordpolicy.ViolationError
+			if errors.As(err, &policyErr) {
+				for _, v := range policyErr.Violations {
+					fmt.Printf("  - %s (%s)\n", v.Message, v.Code)
+				}
+			}
 			os.Exit(1)
 		}
 
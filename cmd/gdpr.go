@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"carowebapp/core/internal/features/documents"
+	"carowebapp/core/internal/features/onetimetoken"
+	"carowebapp/core/internal/features/privacy"
+	db "carowebapp/core/internal/infrastructure/db"
+	"carowebapp/core/internal/infrastructure/email"
+	"carowebapp/core/internal/infrastructure/logger"
+	"carowebapp/core/internal/infrastructure/storage"
+
+	"github.com/spf13/cobra"
+)
+
+// GDPRCmd groups the commands for data subject requests that arrive offline.
+var GDPRCmd = &cobra.Command{
+	Use:   "gdpr",
+	Short: "Answer data export and deletion requests",
+}
+
+var (
+	gdprEmail  string
+	gdprFormat string
+	gdprOutput string
+	gdprNow    bool
+)
+
+var gdprExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export all data held about a user",
+	Run: func(_ *cobra.Command, _ []string) {
+		service := newPrivacyService()
+		userID := findUserID(service)
+
+		if gdprFormat != "json" && gdprFormat != "zip" {
+			exitWithError(fmt.Errorf("unknown format %q", gdprFormat))
+		}
+
+		export, err := service.Export(userID, privacy.RequestedByCLI, "", "")
+		if err != nil {
+			exitWithError(err)
+		}
+
+		var out io.Writer = os.Stdout
+		if gdprOutput != "" {
+			file, err := os.Create(gdprOutput)
+			if err != nil {
+				exitWithError(err)
+			}
+			defer file.Close()
+			out = file
+		}
+
+		if gdprFormat == "zip" {
+			err = privacy.WriteZip(out, export)
+		} else {
+			err = privacy.WriteJSON(out, export)
+		}
+		if err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+var gdprDeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Schedule the deletion of a user's account, or delete it with --now",
+	Run: func(_ *cobra.Command, _ []string) {
+		service := newPrivacyService()
+		userID := findUserID(service)
+
+		if gdprNow {
+			if err := service.DeleteNow(userID, privacy.RequestedByCLI, "", ""); err != nil {
+				exitWithError(err)
+			}
+			fmt.Println("Account deleted:", gdprEmail)
+			return
+		}
+
+		deletion, err := service.ScheduleDeletion(userID, privacy.RequestedByCLI, "", "")
+		if err != nil {
+			exitWithError(err)
+		}
+		fmt.Printf("Account deletion scheduled for %s: %s\n", deletion.ScheduledFor.UTC().Format("2006-01-02 15:04 MST"), gdprEmail)
+	},
+}
+
+var gdprCancelCmd = &cobra.Command{
+	Use:   "cancel",
+	Short: "Cancel a scheduled account deletion",
+	Run: func(_ *cobra.Command, _ []string) {
+		service := newPrivacyService()
+		userID := findUserID(service)
+
+		if err := service.CancelDeletion(userID, privacy.RequestedByCLI, "", ""); err != nil {
+			exitWithError(err)
+		}
+		fmt.Println("Account deletion cancelled:", gdprEmail)
+	},
+}
+
+var gdprPurgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Delete all accounts whose grace period has ended",
+	Run: func(_ *cobra.Command, _ []string) {
+		service := newPrivacyService()
+
+		deleted, err := service.PurgeDue()
+		if err != nil {
+			exitWithError(err)
+		}
+		fmt.Println("Accounts deleted:", deleted)
+	},
+}
+
+func init() {
+	for _, c := range []*cobra.Command{gdprExportCmd, gdprDeleteCmd, gdprCancelCmd} {
+		c.Flags().StringVar(&gdprEmail, "email", "", "email address of the user")
+		_ = c.MarkFlagRequired("email")
+	}
+	gdprExportCmd.Flags().StringVar(&gdprFormat, "format", "json", "json or zip")
+	gdprExportCmd.Flags().StringVarP(&gdprOutput, "output", "o", "", "file to write to instead of stdout")
+	gdprDeleteCmd.Flags().BoolVar(&gdprNow, "now", false, "delete right away instead of after the grace period")
+
+	GDPRCmd.AddCommand(gdprExportCmd, gdprDeleteCmd, gdprCancelCmd, gdprPurgeCmd)
+}
+
+// newPrivacyService builds the privacy service for CLI use. Self-service deletions
+// are not handled here, so no password checker is needed. Uploaded documents live in
+// the same storage the server uses, so deleted accounts lose their files too.
+func newPrivacyService() *privacy.Service {
+	logger.Init(false)
+	dbConn := db.InitDB()
+
+	gracePeriod, err := privacy.GracePeriodFromEnv()
+	if err != nil {
+		exitWithError(err)
+	}
+
+	store, err := storage.LocalFromEnv()
+	if err != nil {
+		exitWithError(err)
+	}
+	docs := documents.NewService(documents.NewSQLXRepository(dbConn), store, documents.DefaultRetention, logger.Log)
+
+	tokens := onetimetoken.NewService(onetimetoken.NewSQLXRepository(dbConn))
+	return privacy.NewService(privacy.NewSQLXRepository(dbConn), email.NewMailer(logger.Log), tokens, nil, docs, gracePeriod, logger.Log)
+}
+
+// findUserID resolves the --email flag or exits.
+func findUserID(service *privacy.Service) string {
+	userID, err := service.FindUserID(strings.TrimSpace(gdprEmail))
+	if err != nil {
+		exitWithError(err)
+	}
+	return userID
+}
+
+func exitWithError(err error) {
+	fmt.Fprintln(os.Stderr, "Error:", err)
+	os.Exit(1)
+}
@@ -48,7 +48,7 @@ require (
 	github.com/mattn/go-runewidth v0.0.16 // indirect
 	github.com/rivo/uniseg v0.4.7 // indirect
 	github.com/valyala/bytebufferpool v1.0.0 // indirect
-	github.com/valyala/fasthttp v1.60.0 // indirect
+	github.com/valyala/fasthttp v1.60.0
 	go.uber.org/atomic v1.7.0 // indirect
 	golang.org/x/crypto v0.37.0
 	golang.org/x/sys v0.32.0 // indirect
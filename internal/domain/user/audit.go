@@ -0,0 +1,144 @@
+package user
+
+import (
+	"database/sql/driver"
+
+	"encoding/json"
+
+	"errors"
+
+	"time"
+)
+
+// Admin actions recorded in the audit log.
+const (
+	AuditUserApproved           = "user.approved"
+	AuditUserRejected           = "user.rejected"
+	AuditUserRoleChanged        = "user.role_changed"
+	AuditUserStatusOverridden   = "user.status_overridden"
+	AuditUserSuspended          = "user.suspended"
+	AuditUserBanned             = "user.banned"
+	AuditUserReactivated        = "user.reactivated"
+	AuditUserUnlocked           = "user.unlocked"
+	AuditUserNoteAdded          = "user.note_added"
+	AuditUserNoteDeleted        = "user.note_deleted"
+	AuditUserTagsChanged        = "user.tags_changed"
+	AuditUserDocumentReviewed   = "user.document_reviewed"
+	AuditUserDataExported       = "user.data_exported"
+	AuditRolePermissionsChanged = "role.permissions_changed"
+	AuditRejectionReasonSaved   = "rejection_reason.saved"
+	AuditDeletionScheduled      = "deletion.scheduled"
+	AuditDeletionCancelled      = "deletion.cancelled"
+	AuditDeletionCompleted      = "deletion.completed"
+)
+
+// AuditActions lists every action written to the audit log.
+var AuditActions = []string{
+	AuditUserApproved,
+	AuditUserRejected,
+	AuditUserRoleChanged,
+	AuditUserStatusOverridden,
+	AuditUserSuspended,
+	AuditUserBanned,
+	AuditUserReactivated,
+	AuditUserUnlocked,
+	AuditUserNoteAdded,
+	AuditUserNoteDeleted,
+	AuditUserTagsChanged,
+	AuditUserDocumentReviewed,
+	AuditUserDataExported,
+	AuditRolePermissionsChanged,
+	AuditRejectionReasonSaved,
+	AuditDeletionScheduled,
+	AuditDeletionCancelled,
+	AuditDeletionCompleted,
+}
+
+// Kinds of audit log targets. Account deletions are logged under the deletion, so the
+// entries do not name the erased user.
+const (
+	AuditTargetUser            = "user"
+	AuditTargetRole            = "role"
+	AuditTargetRejectionReason = "rejection_reason"
+	AuditTargetDeletion        = "deletion"
+)
+
+// Actor is the admin performing an action, with the request it came from.
+type Actor struct {
+	ID      string
+	IP      string
+	TraceID string
+}
+
+// AuditState is the part of a target an action changed. It is stored as JSONB.
+// It holds IDs, codes, statuses and counts only, never free text such as reasons,
+// notes or tags: the log is append-only and outlives erased accounts, so nothing
+// written to it could be deleted with the person it is about.
+type AuditState map[string]any
+
+func (s AuditState) Value() (driver.Value, error) {
+	if s == nil {
+		return nil, nil
+	}
+	return json.Marshal(s)
+}
+
+func (s *AuditState) Scan(src any) error {
+	if src == nil {
+		*s = nil
+		return nil
+	}
+	b, ok := src.([]byte)
+	if !ok {
+		return errors.New("audit state: expected []byte")
+	}
+	return json.Unmarshal(b, s)
+}
+
+// AuditEntry is one admin action in the append-only audit log. ActorID is nil for
+// changes the application made on its own, such as the end of a suspension.
+type AuditEntry struct {
+	ID         string     `db:"id" json:"id"`
+	ActorID    *string    `db:"actor_id" json:"actor_id"`
+	Action     string     `db:"action" json:"action"`
+	TargetType string     `db:"target_type" json:"target_type"`
+	TargetID   string     `db:"target_id" json:"target_id"`
+	Before     AuditState `db:"before_state" json:"before"`
+	After      AuditState `db:"after_state" json:"after"`
+	IP         *string    `db:"ip" json:"ip"`
+	TraceID    *string    `db:"trace_id" json:"trace_id"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+}
+
+// NewAuditEntry builds the entry for an action of the actor on a target. An empty actor ID
+// records a change the application made on its own.
+func NewAuditEntry(actor Actor, action, targetType, targetID string, before, after AuditState) AuditEntry {
+	entry := AuditEntry{
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Before:     before,
+		After:      after,
+		CreatedAt:  time.Now(),
+	}
+	if actor.ID != "" {
+		entry.ActorID = &actor.ID
+	}
+	if actor.IP != "" {
+		entry.IP = &actor.IP
+	}
+	if actor.TraceID != "" {
+		entry.TraceID = &actor.TraceID
+	}
+	return entry
+}
+
+// IsAuditAction reports whether the action is written to the audit log.
+func IsAuditAction(action string) bool {
+	for _, a := range AuditActions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
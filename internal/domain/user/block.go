@@ -0,0 +1,29 @@
+package user
+
+import "time"
+
+// Kinds of account blocks.
+const (
+	BlockSuspension = "suspension"
+	BlockBan        = "ban"
+)
+
+// AccountBlock is a suspension or ban of an account. A suspension ends at EndsAt, a ban
+// has no end. Lifting a block restores PreviousStatus.
+type AccountBlock struct {
+	ID             string     `db:"id" json:"id"`
+	UserID         string     `db:"user_id" json:"-"`
+	Kind           string     `db:"kind" json:"kind"`
+	Reason         string     `db:"reason" json:"reason"`
+	PreviousStatus string     `db:"previous_status" json:"previous_status"`
+	EndsAt         *time.Time `db:"ends_at" json:"ends_at"`
+	CreatedBy      *string    `db:"created_by" json:"created_by"`
+	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
+	LiftedAt       *time.Time `db:"lifted_at" json:"lifted_at,omitempty"`
+	LiftedBy       *string    `db:"lifted_by" json:"lifted_by,omitempty"`
+}
+
+// IsActive reports whether the block still keeps the user out at the given time.
+func (b *AccountBlock) IsActive(now time.Time) bool {
+	return b.LiftedAt == nil && (b.EndsAt == nil || now.Before(*b.EndsAt))
+}
@@ -0,0 +1,47 @@
+package user
+
+import "time"
+
+// Kinds of verification documents applicants upload during onboarding.
+const (
+	DocumentProofOfOwnership     = "proof_of_ownership"
+	DocumentIdentity             = "identity"
+	DocumentTradeRegisterExtract = "trade_register_extract"
+)
+
+// DocumentTypes lists every kind of verification document.
+var DocumentTypes = []string{DocumentProofOfOwnership, DocumentIdentity, DocumentTradeRegisterExtract}
+
+// Review states of a verification document.
+const (
+	DocumentPending  = "pending"
+	DocumentAccepted = "accepted"
+	DocumentRejected = "rejected"
+)
+
+// Document is a file an applicant uploaded to support their registration. The file itself
+// lives in the document store under StorageKey.
+type Document struct {
+	ID          string     `db:"id" json:"id"`
+	UserID      string     `db:"user_id" json:"-"`
+	Type        string     `db:"type" json:"type"`
+	FileName    string     `db:"file_name" json:"file_name"`
+	ContentType string     `db:"content_type" json:"content_type"`
+	Size        int64      `db:"size_bytes" json:"size"`
+	StorageKey  string     `db:"storage_key" json:"-"`
+	Status      string     `db:"status" json:"status"`
+	ReviewNote  *string    `db:"review_note" json:"review_note"`
+	ReviewedBy  *string    `db:"reviewed_by" json:"reviewed_by,omitempty"`
+	ReviewedAt  *time.Time `db:"reviewed_at" json:"reviewed_at"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+}
+
+// IsDocumentType reports whether the type names a kind of verification document.
+func IsDocumentType(docType string) bool {
+	for _, t := range DocumentTypes {
+		if t == docType {
+			return true
+		}
+	}
+	return false
+}
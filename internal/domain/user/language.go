@@ -0,0 +1,23 @@
+package user
+
+// Languages users can receive emails in.
+const (
+	LanguageDE = "de"
+	LanguageEN = "en"
+
+	// DefaultLanguage is used for users who did not choose a language.
+	DefaultLanguage = LanguageEN
+)
+
+// Languages lists every supported language.
+var Languages = []string{LanguageDE, LanguageEN}
+
+// IsSupportedLanguage checks if emails can be rendered in the language.
+func IsSupportedLanguage(language string) bool {
+	for _, l := range Languages {
+		if l == language {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package user
+
+// Permissions that can be granted to roles.
+const (
+	PermissionUsersModerate  = "users.moderate"
+	PermissionUsersRead      = "users.read"
+	PermissionTicketsAssign  = "tickets.assign"
+	PermissionTicketsReadAll = "tickets.read_all"
+	PermissionRolesManage    = "roles.manage"
+	PermissionUsersPrivacy   = "users.privacy"
+	PermissionUsersManage    = "users.manage"
+	PermissionAuditRead      = "audit.read"
+	PermissionReasonsManage  = "rejection_reasons.manage"
+	PermissionStatsRead      = "stats.read"
+	PermissionUsersNotes     = "users.notes"
+)
+
+// Permissions lists every permission known to the application.
+var Permissions = []string{
+	PermissionUsersModerate,
+	PermissionUsersRead,
+	PermissionTicketsAssign,
+	PermissionTicketsReadAll,
+	PermissionRolesManage,
+	PermissionUsersPrivacy,
+	PermissionUsersManage,
+	PermissionAuditRead,
+	PermissionReasonsManage,
+	PermissionStatsRead,
+	PermissionUsersNotes,
+}
+
+// IsKnownPermission checks if the permission is defined by the application.
+func IsKnownPermission(permission string) bool {
+	for _, p := range Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
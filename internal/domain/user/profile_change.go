@@ -0,0 +1,44 @@
+package user
+
+import (
+	"database/sql/driver"
+
+	"encoding/json"
+
+	"errors"
+
+	"time"
+)
+
+// FieldChange is the old and new value of one edited profile field.
+type FieldChange struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// ProfileDiff maps profile fields to their changes. It is stored as JSONB.
+type ProfileDiff map[string]FieldChange
+
+func (d ProfileDiff) Value() (driver.Value, error) {
+	return json.Marshal(d)
+}
+
+func (d *ProfileDiff) Scan(src any) error {
+	b, ok := src.([]byte)
+	if !ok {
+		return errors.New("profile diff: expected []byte")
+	}
+	return json.Unmarshal(b, d)
+}
+
+// ProfileChange records an edit of a profile so moderators can see what changed
+// since they last reviewed it. A resubmission after a rejection references that rejection.
+type ProfileChange struct {
+	ID             string      `db:"id" json:"id"`
+	UserID         string      `db:"user_id" json:"-"`
+	RejectionID    *string     `db:"rejection_id" json:"rejection_id,omitempty"`
+	Changes        ProfileDiff `db:"changes" json:"changes"`
+	PreviousStatus string      `db:"previous_status" json:"previous_status"`
+	NewStatus      string      `db:"new_status" json:"new_status"`
+	CreatedAt      time.Time   `db:"created_at" json:"created_at"`
+}
@@ -1,7 +0,0 @@
-package user
-
-import "context"
-
-type Provider interface {
-	GetByID(ctx context.Context, id string) (*User, error)
-}
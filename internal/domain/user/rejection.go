@@ -0,0 +1,87 @@
+package user
+
+import (
+	"database/sql/driver"
+
+	"encoding/json"
+
+	"errors"
+
+	"strings"
+
+	"time"
+)
+
+// ProfileFields lists the profile fields a moderator can reject, in form order.
+var ProfileFields = []string{
+	"salutation",
+	"title",
+	"first_name",
+	"last_name",
+	"street",
+	"house_number",
+	"postal_code",
+	"city",
+}
+
+// ProfileFieldName returns the canonical profile field for a key in snake_case or
+// camelCase, as used by the profile forms.
+func ProfileFieldName(key string) (string, bool) {
+	normalized := strings.ToLower(strings.ReplaceAll(key, "_", ""))
+	for _, field := range ProfileFields {
+		if strings.ReplaceAll(field, "_", "") == normalized {
+			return field, true
+		}
+	}
+	return "", false
+}
+
+// RejectionReasons maps profile fields to the reason a moderator rejected them. It is stored as JSONB.
+type RejectionReasons map[string]string
+
+func (r RejectionReasons) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
+
+func (r *RejectionReasons) Scan(src any) error {
+	if src == nil {
+		*r = RejectionReasons{}
+		return nil
+	}
+	b, ok := src.([]byte)
+	if !ok {
+		return errors.New("rejection reasons: expected []byte")
+	}
+	return json.Unmarshal(b, r)
+}
+
+// RejectionReason is a catalog entry moderators pick when they reject a profile field.
+// Inactive reasons can no longer be picked but stay for the statistics.
+type RejectionReason struct {
+	Code      string    `db:"code" json:"code"`
+	Field     string    `db:"field" json:"field"`
+	TextDE    string    `db:"text_de" json:"text_de"`
+	TextEN    string    `db:"text_en" json:"text_en"`
+	Active    bool      `db:"active" json:"active"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// Text returns the reason in the language, or in English if the language is not supported.
+func (r RejectionReason) Text(language string) string {
+	if language == LanguageDE {
+		return r.TextDE
+	}
+	return r.TextEN
+}
+
+// Rejection is one moderation decision against a registration. Reasons holds the texts in
+// the user's language and Codes the catalog codes they came from, both keyed by profile field.
+// Rejections from before the catalog have no codes.
+type Rejection struct {
+	ID         string           `db:"id" json:"id"`
+	UserID     string           `db:"user_id" json:"-"`
+	Reasons    RejectionReasons `db:"errors" json:"reasons"`
+	Codes      RejectionReasons `db:"reason_codes" json:"codes,omitempty"`
+	RejectedBy *string          `db:"rejected_by" json:"rejected_by,omitempty"`
+	RejectedAt time.Time        `db:"rejected_at" json:"rejected_at"`
+}
@@ -6,6 +6,19 @@ const (
 	RoleHomeowner = "ROLE_HOMEOWNER"
 )
 
+// Roles lists every role known to the application.
+var Roles = []string{RoleAdmin, RoleManager, RoleHomeowner}
+
+// IsKnownRole checks if the role is defined by the application.
+func IsKnownRole(role string) bool {
+	for _, r := range Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
 func (u *User) IsAdmin() bool {
 	return u.Role == RoleAdmin
 }
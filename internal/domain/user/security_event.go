@@ -0,0 +1,23 @@
+package user
+
+// Event types written to the security log.
+const (
+	SecurityEventAccountLocked      = "account_locked"
+	SecurityEventAccountUnlocked    = "account_unlocked"
+	SecurityEventDataExported       = "data_exported"
+	SecurityEventDeletionScheduled  = "deletion_scheduled"
+	SecurityEventDeletionCancelled  = "deletion_cancelled"
+	SecurityEventAccountDeleted     = "account_deleted"
+	SecurityEventRoleChanged        = "role_changed"
+	SecurityEventAccountSuspended   = "account_suspended"
+	SecurityEventAccountBanned      = "account_banned"
+	SecurityEventAccountReactivated = "account_reactivated"
+)
+
+// SecurityEvent is a single entry of the security log.
+type SecurityEvent struct {
+	UserID  string
+	Type    string
+	IP      string
+	Details map[string]any
+}
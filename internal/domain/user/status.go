@@ -1,8 +1,224 @@
 package user
 
+import (
+	"errors"
+
+	"fmt"
+
+	"time"
+)
+
 const (
-	StatusCreated  = "created"
-	StatusPending  = "pending"
-	StatusApproved = "approved"
-	StatusRejected = "rejected"
+	StatusCreated   = "created"
+	StatusPending   = "pending"
+	StatusApproved  = "approved"
+	StatusRejected  = "rejected"
+	StatusSuspended = "suspended"
+	StatusBanned    = "banned"
 )
+
+// Statuses lists every valid user status.
+var Statuses = []string{StatusCreated, StatusPending, StatusApproved, StatusRejected, StatusSuspended, StatusBanned}
+
+// Transition is a named step of the user lifecycle.
+type Transition string
+
+const (
+	// TransitionSubmit sends a new registration to moderation once the profile exists.
+	TransitionSubmit Transition = "submit"
+	// TransitionApprove accepts a registration.
+	TransitionApprove Transition = "approve"
+	// TransitionReject refuses a registration with field-level reasons.
+	TransitionReject Transition = "reject"
+	// TransitionResubmit sends a corrected registration back to moderation.
+	TransitionResubmit Transition = "resubmit"
+	// TransitionProfileEdited sends an approved account back to moderation after
+	// the user changed reviewed profile fields.
+	TransitionProfileEdited Transition = "profile_edited"
+	// TransitionOverride is an admin correction to any other status. It still
+	// passes the guards of the target status.
+	TransitionOverride Transition = "override"
+	// TransitionSuspend blocks an account until a given time.
+	TransitionSuspend Transition = "suspend"
+	// TransitionBan blocks an account permanently.
+	TransitionBan Transition = "ban"
+	// TransitionReactivate lifts a suspension or ban and restores the status from before.
+	TransitionReactivate Transition = "reactivate"
+)
+
+var (
+	ErrUnknownTransition   = errors.New("unknown status transition")
+	ErrTransitionForbidden = errors.New("status transition not allowed")
+	ErrEmailNotConfirmed   = errors.New("email address is not confirmed")
+	ErrProfileRequired     = errors.New("user has no profile")
+	ErrStatusConflict      = errors.New("user status was changed concurrently")
+	ErrUnknownStatus       = errors.New("unknown user status")
+)
+
+// StatusFacts are the parts of the account the transition guards look at.
+type StatusFacts struct {
+	EmailConfirmed bool
+	HasProfile     bool
+}
+
+type lifecycleStep struct {
+	from  []string
+	to    string
+	guard func(StatusFacts) error
+}
+
+var lifecycle = map[Transition]lifecycleStep{
+	TransitionSubmit:        {from: []string{StatusCreated}, to: StatusPending, guard: requireProfile},
+	TransitionApprove:       {from: []string{StatusPending}, to: StatusApproved, guard: requireConfirmedProfile},
+	TransitionReject:        {from: []string{StatusPending}, to: StatusRejected, guard: requireProfile},
+	TransitionResubmit:      {from: []string{StatusRejected}, to: StatusPending, guard: requireProfile},
+	TransitionProfileEdited: {from: []string{StatusApproved}, to: StatusPending, guard: requireProfile},
+	TransitionSuspend:       {from: []string{StatusCreated, StatusPending, StatusApproved, StatusRejected}, to: StatusSuspended, guard: noGuard},
+	TransitionBan:           {from: []string{StatusCreated, StatusPending, StatusApproved, StatusRejected, StatusSuspended}, to: StatusBanned, guard: noGuard},
+}
+
+func noGuard(StatusFacts) error {
+	return nil
+}
+
+func requireProfile(facts StatusFacts) error {
+	if !facts.HasProfile {
+		return ErrProfileRequired
+	}
+	return nil
+}
+
+func requireConfirmedProfile(facts StatusFacts) error {
+	if !facts.EmailConfirmed {
+		return ErrEmailNotConfirmed
+	}
+	return requireProfile(facts)
+}
+
+// StatusChange is one applied transition. It is stored in user_status_transitions.
+type StatusChange struct {
+	ID         string     `db:"id" json:"id"`
+	UserID     string     `db:"user_id" json:"-"`
+	From       string     `db:"from_status" json:"from"`
+	To         string     `db:"to_status" json:"to"`
+	Transition Transition `db:"transition" json:"transition"`
+	ActorID    *string    `db:"actor_id" json:"actor_id,omitempty"`
+	Reason     *string    `db:"reason" json:"reason,omitempty"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+}
+
+// ChangeStatus checks that the transition is allowed from the current status and that its
+// guard passes, and returns the change to persist. An empty actorID means the user acted themselves.
+func ChangeStatus(userID, current string, transition Transition, facts StatusFacts, actorID string) (*StatusChange, error) {
+	step, ok := lifecycle[transition]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTransition, transition)
+	}
+
+	allowed := false
+	for _, from := range step.from {
+		if from == current {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return nil, fmt.Errorf("%w: cannot %s a %s user", ErrTransitionForbidden, transition, current)
+	}
+
+	if err := step.guard(facts); err != nil {
+		return nil, err
+	}
+
+	change := &StatusChange{
+		UserID:     userID,
+		From:       current,
+		To:         step.to,
+		Transition: transition,
+		CreatedAt:  time.Now(),
+	}
+	if actorID != "" {
+		change.ActorID = &actorID
+	}
+	return change, nil
+}
+
+// targetGuards are the preconditions an account must meet to be in a status.
+var targetGuards = map[string]func(StatusFacts) error{
+	StatusCreated:  noGuard,
+	StatusPending:  requireProfile,
+	StatusApproved: requireConfirmedProfile,
+	StatusRejected: requireProfile,
+}
+
+// OverrideStatus moves the user to any other status on behalf of an admin. The reason is
+// kept in the transition history.
+func OverrideStatus(userID, current, target string, facts StatusFacts, actorID, reason string) (*StatusChange, error) {
+	if IsBlockedStatus(target) {
+		return nil, fmt.Errorf("%w: suspend or ban the user instead", ErrTransitionForbidden)
+	}
+	guard, ok := targetGuards[target]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownStatus, target)
+	}
+	if target == current {
+		return nil, fmt.Errorf("%w: user is already %s", ErrTransitionForbidden, current)
+	}
+	if IsBlockedStatus(current) {
+		return nil, fmt.Errorf("%w: reactivate the %s user instead", ErrTransitionForbidden, current)
+	}
+	if err := guard(facts); err != nil {
+		return nil, err
+	}
+
+	change := &StatusChange{
+		UserID:     userID,
+		From:       current,
+		To:         target,
+		Transition: TransitionOverride,
+		ActorID:    &actorID,
+		CreatedAt:  time.Now(),
+	}
+	if reason != "" {
+		change.Reason = &reason
+	}
+	return change, nil
+}
+
+// Reactivate lifts a suspension or ban and returns the user to the status they had before
+// the block. An empty actorID means the suspension ended on its own.
+func Reactivate(userID, current, previous, actorID string) (*StatusChange, error) {
+	if !IsBlockedStatus(current) {
+		return nil, fmt.Errorf("%w: cannot %s a %s user", ErrTransitionForbidden, TransitionReactivate, current)
+	}
+	if _, ok := targetGuards[previous]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownStatus, previous)
+	}
+
+	change := &StatusChange{
+		UserID:     userID,
+		From:       current,
+		To:         previous,
+		Transition: TransitionReactivate,
+		CreatedAt:  time.Now(),
+	}
+	if actorID != "" {
+		change.ActorID = &actorID
+	}
+	return change, nil
+}
+
+// IsBlockedStatus reports whether the status keeps the user from using the application.
+func IsBlockedStatus(status string) bool {
+	return status == StatusSuspended || status == StatusBanned
+}
+
+// IsValidStatus reports whether status is part of the lifecycle.
+func IsValidStatus(status string) bool {
+	for _, s := range Statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
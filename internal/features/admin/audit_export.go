@@ -0,0 +1,72 @@
+package admin
+
+import (
+	domainuser "carowebapp/core/internal/domain/user"
+
+	"encoding/csv"
+
+	"encoding/json"
+
+	"io"
+
+	"time"
+)
+
+// WriteAuditJSON writes the audit entries as an indented JSON array.
+func WriteAuditJSON(w io.Writer, entries []domainuser.AuditEntry) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(entries)
+}
+
+// WriteAuditCSV writes the audit entries as CSV with a header row. The before and after
+// states are written as JSON.
+func WriteAuditCSV(w io.Writer, entries []domainuser.AuditEntry) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"id", "created_at", "actor_id", "action", "target_type", "target_id", "before", "after", "ip", "trace_id"}); err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		before, err := stateJSON(e.Before)
+		if err != nil {
+			return err
+		}
+		after, err := stateJSON(e.After)
+		if err != nil {
+			return err
+		}
+		if err := writer.Write([]string{
+			e.ID,
+			e.CreatedAt.UTC().Format(time.RFC3339),
+			stringValue(e.ActorID),
+			e.Action,
+			e.TargetType,
+			e.TargetID,
+			before,
+			after,
+			stringValue(e.IP),
+			stringValue(e.TraceID),
+		}); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func stateJSON(state domainuser.AuditState) (string, error) {
+	if state == nil {
+		return "", nil
+	}
+	b, err := json.Marshal(state)
+	return string(b), err
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -2,12 +2,62 @@
 package admin
 
 const (
-	ErrMsgInvalidApproveBody = "invalid approve request body"
-	ErrMsgInvalidRejectBody  = "invalid reject request body"
+	ErrMsgInvalidApproveBody    = "invalid approve request body"
+	ErrMsgInvalidRejectBody     = "invalid reject request body"
+	ErrMsgInvalidRolesBody      = "invalid role permissions request body"
+	ErrMsgInvalidUserRoleBody   = "invalid user role request body"
+	ErrMsgInvalidUserStatusBody = "invalid user status request body"
+	ErrMsgInvalidSuspendBody    = "invalid suspend request body"
+	ErrMsgInvalidBanBody        = "invalid ban request body"
+	ErrMsgInvalidFilter         = "invalid user filter"
+	ErrMsgInvalidSort           = "sort must be one of created_at, email, status, last_name and order asc or desc"
+	ErrMsgInvalidPageSize       = "limit must be between 1 and 100"
+	ErrMsgInvalidBulkBody       = "invalid bulk moderation request body"
+	ErrMsgInvalidReasonBody     = "invalid rejection reason request body"
+	ErrMsgInvalidStatsFormat    = "format must be json or csv"
+	ErrMsgInvalidNoteBody       = "invalid note request body"
+	ErrMsgInvalidTagsBody       = "invalid tags request body"
 
-	ErrMsgApproveFailed = "failed to approve user"
-	ErrMsgRejectFailed  = "failed to reject user"
+	ErrMsgApproveFailed       = "failed to approve user"
+	ErrMsgRejectFailed        = "failed to reject user"
+	ErrMsgListRolesFailed     = "failed to list role permissions"
+	ErrMsgSetRoleFailed       = "failed to update role permissions"
+	ErrMsgUnlockFailed        = "failed to unlock user"
+	ErrMsgListUsersFailed     = "failed to list users"
+	ErrMsgGetUserFailed       = "failed to get user"
+	ErrMsgSetUserRoleFailed   = "failed to change user role"
+	ErrMsgSetUserStatusFailed = "failed to change user status"
+	ErrMsgSuspendFailed       = "failed to suspend user"
+	ErrMsgBanFailed           = "failed to ban user"
+	ErrMsgReactivateFailed    = "failed to reactivate user"
+	ErrMsgListAuditFailed     = "failed to list audit log"
+	ErrMsgClaimFailed         = "failed to claim user"
+	ErrMsgReleaseClaimFailed  = "failed to release claim"
+	ErrMsgBulkModerateFailed  = "failed to moderate users"
+	ErrMsgListReasonsFailed   = "failed to list rejection reasons"
+	ErrMsgSaveReasonFailed    = "failed to save rejection reason"
+	ErrMsgReasonStatsFailed   = "failed to count rejection reasons"
+	ErrMsgStatsFailed         = "failed to compute statistics"
+	ErrMsgListNotesFailed     = "failed to list notes"
+	ErrMsgAddNoteFailed       = "failed to add note"
+	ErrMsgDeleteNoteFailed    = "failed to delete note"
+	ErrMsgSetTagsFailed       = "failed to change tags"
+	ErrMsgListTagsFailed      = "failed to list tags"
 
-	SuccessMsgApproved = "user approval completed successfully"
-	SuccessMsgRejected = "user reject completed successfully"
+	SuccessMsgApproved      = "user approval completed successfully"
+	SuccessMsgRejected      = "user reject completed successfully"
+	SuccessMsgRoleSet       = "role permissions updated successfully"
+	SuccessMsgUnlocked      = "user unlocked successfully"
+	SuccessMsgUserRoleSet   = "user role changed successfully"
+	SuccessMsgUserStatusSet = "user status changed successfully"
+	SuccessMsgSuspended     = "user suspended successfully"
+	SuccessMsgBanned        = "user banned successfully"
+	SuccessMsgReactivated   = "user reactivated successfully"
+	SuccessMsgClaimed       = "user claimed for moderation"
+	SuccessMsgClaimReleased = "moderation claim released"
+	SuccessMsgBulkModerated = "bulk moderation completed"
+	SuccessMsgReasonSaved   = "rejection reason saved successfully"
+	SuccessMsgNoteAdded     = "note added successfully"
+	SuccessMsgNoteDeleted   = "note deleted successfully"
+	SuccessMsgTagsSet       = "user tags changed successfully"
 )
@@ -7,17 +7,20 @@ import (
 
 	"carowebapp/core/internal/pkg/contextutils"
 
+	"errors"
+
 	"github.com/gofiber/fiber/v2"
 
+	"github.com/google/uuid"
+
 	"go.uber.org/zap"
 )
 
 // Handler provides HTTP handlers for user moderation endpoints.
 // Assumes admin access is already enforced via middleware.
 type Handler struct {
-	Service      *Service
-	Logger       *zap.Logger
-	UserProvider domainuser.Provider
+	Service *Service
+	Logger  *zap.Logger
 }
 
 // ApproveUserRequest represents the payload for approving a user.
@@ -25,10 +28,16 @@ type ApproveUserRequest struct {
 	UserID string `json:"user_id" validate:"required,uuid4"`
 }
 
-// RejectUserRequest represents the payload for rejecting a user with specific field errors.
+// RejectUserRequest represents the payload for rejecting a user. Errors maps profile fields
+// to rejection reason codes from the catalog.
 type RejectUserRequest struct {
 	UserID string            `json:"user_id" validate:"required,uuid4"`
-	Errors map[string]string `json:"errors" validate:"required"`
+	Errors map[string]string `json:"errors" validate:"required,min=1"`
+}
+
+// SetRolePermissionsRequest represents the payload for replacing the permissions of a role.
+type SetRolePermissionsRequest struct {
+	Permissions []string `json:"permissions" validate:"required,dive,required"`
 }
 
 // ApproveUser allows an admin to approve a pending user by their user ID.
@@ -39,14 +48,14 @@ func (h *Handler) ApproveUser(c *fiber.Ctx) error {
 		return response.JSONError(c, fiber.StatusBadRequest, fiber.ErrBadRequest)
 	}
 
-	if err := h.Service.ApproveUser(req.UserID); err != nil {
-		return response.JSONErrorWithLog(c, h.Logger, fiber.StatusInternalServerError, ErrMsgApproveFailed,
-			zap.String("target_user_id", req.UserID),
-			zap.Error(err),
-		)
+	actor := h.actor(c)
+
+	if err := h.Service.ApproveUser(actor, req.UserID); err != nil {
+		return h.statusError(c, req.UserID, err, ErrMsgApproveFailed)
 	}
 
 	h.Logger.Info(SuccessMsgApproved,
+		zap.String("admin_id", actor.ID),
 		zap.String("target_user_id", req.UserID),
 	)
 
@@ -64,15 +73,16 @@ func (h *Handler) RejectUser(c *fiber.Ctx) error {
 		return response.JSONError(c, fiber.StatusBadRequest, fiber.ErrBadRequest)
 	}
 
-	if err := h.Service.RejectUser(req.UserID, req.Errors); err != nil {
-		return response.JSONErrorWithLog(c, h.Logger, fiber.StatusInternalServerError, ErrMsgRejectFailed,
-			zap.String("target_user_id", req.UserID),
-			zap.Error(err),
+	actor := h.actor(c)
+
+	if err := h.Service.RejectUser(actor, req.UserID, req.Errors); err != nil {
+		return h.statusError(c, req.UserID, err, ErrMsgRejectFailed,
 			zap.Any("rejection_errors", req.Errors),
 		)
 	}
 
 	h.Logger.Info(SuccessMsgRejected,
+		zap.String("admin_id", actor.ID),
 		zap.String("target_user_id", req.UserID),
 		zap.Any("rejection_errors", req.Errors),
 	)
@@ -83,6 +93,49 @@ func (h *Handler) RejectUser(c *fiber.Ctx) error {
 	})
 }
 
+// actor identifies the admin making the request for the audit log.
+func (h *Handler) actor(c *fiber.Ctx) domainuser.Actor {
+	adminID, _ := contextutils.GetUserID(c)
+	traceID, _ := contextutils.GetTraceID(c)
+	return domainuser.Actor{ID: adminID, IP: c.IP(), TraceID: traceID}
+}
+
+// statusError maps errors of status transitions to HTTP responses.
+func (h *Handler) statusError(c *fiber.Ctx, userID string, err error, fallback string, fields ...zap.Field) error {
+	fields = append([]zap.Field{zap.String("target_user_id", userID)}, fields...)
+
+	switch {
+	case errors.Is(err, ErrUnknownProfileField),
+		errors.Is(err, ErrUnknownRejectionReason),
+		errors.Is(err, ErrReasonFieldMismatch),
+		errors.Is(err, ErrNoRejectionReasons),
+		errors.Is(err, ErrUnknownRole),
+		errors.Is(err, ErrInvalidSuspensionEnd),
+		errors.Is(err, domainuser.ErrUnknownStatus):
+		return response.JSONErrorInfoLog(c, h.Logger, fiber.StatusBadRequest, err.Error(), fields...)
+
+	case errors.Is(err, ErrSelfManagement):
+		return response.JSONErrorInfoLog(c, h.Logger, fiber.StatusForbidden, err.Error(), fields...)
+
+	case errors.Is(err, ErrUserNotFound):
+		return response.JSONErrorInfoLog(c, h.Logger, fiber.StatusNotFound, err.Error(), fields...)
+
+	case errors.Is(err, domainuser.ErrTransitionForbidden),
+		errors.Is(err, domainuser.ErrEmailNotConfirmed),
+		errors.Is(err, domainuser.ErrProfileRequired),
+		errors.Is(err, domainuser.ErrStatusConflict),
+		errors.Is(err, ErrNotBlocked),
+		errors.Is(err, ErrNotPending),
+		errors.Is(err, ErrClaimedByOther),
+		errors.Is(err, ErrNotClaimed):
+		return response.JSONErrorInfoLog(c, h.Logger, fiber.StatusConflict, err.Error(), fields...)
+	}
+
+	return response.JSONErrorWithLog(c, h.Logger, fiber.StatusInternalServerError, fallback,
+		append(fields, zap.Error(err))...,
+	)
+}
+
 // ListPendingUsers returns a paginated list of users with 'pending' status.
 // Supports optional search by first or last name via ?search=... query param.
 func (h *Handler) ListPendingUsers(c *fiber.Ctx) error {
@@ -91,10 +144,10 @@ func (h *Handler) ListPendingUsers(c *fiber.Ctx) error {
 	if page < 1 {
 		page = 1
 	}
-	limit := 25
+	limit := DefaultPageSize
 	offset := (page - 1) * limit
 
-	users, err := h.Service.ListPendingUsers(search, limit, offset)
+	result, err := h.Service.ListPendingUsers(h.actor(c).ID, search, limit, offset)
 	if err != nil {
 		return response.JSONErrorWithLog(c, h.Logger, fiber.StatusInternalServerError, "failed to list pending users",
 			zap.String("search", search),
@@ -104,7 +157,7 @@ func (h *Handler) ListPendingUsers(c *fiber.Ctx) error {
 	}
 
 	h.Logger.Info("Pending users fetched",
-		zap.Int("count", len(users)),
+		zap.Int("count", len(result.Users)),
 		zap.String("search", search),
 		zap.Int("page", page),
 	)
@@ -112,35 +165,111 @@ func (h *Handler) ListPendingUsers(c *fiber.Ctx) error {
 	return response.JSONSuccess(c, fiber.StatusOK, fiber.Map{
 		"page":  page,
 		"limit": limit,
-		"total": len(users),
-		"users": users,
+		"total": result.Total,
+		"users": result.Users,
 	})
 }
 
-// GetUserProfile returns the profile of a specific user by their ID.
+// GetUserProfile returns the profile of a specific user by their ID, together with the
+// internal notes and tags. Users without a profile are returned with has_profile false.
 func (h *Handler) GetUserProfile(c *fiber.Ctx) error {
-	userID := c.Params("id")
-	if userID == "" {
-		h.Logger.Debug("missing user ID in path", zap.String("handler", "GetUserProfile"))
+	userID, ok := h.pathUserID(c, "GetUserProfile")
+	if !ok {
 		return response.JSONError(c, fiber.StatusBadRequest, fiber.ErrBadRequest)
 	}
 
 	profile, err := h.Service.GetUserProfile(userID)
 	if err != nil {
-		return response.JSONErrorWithLog(c, h.Logger, fiber.StatusInternalServerError, "failed to get user profile",
-			zap.String("user_id", userID),
+		return h.statusError(c, userID, err, "failed to get user profile")
+	}
+
+	h.Logger.Info("User profile fetched",
+		zap.String("user_id", userID),
+	)
+
+	return response.JSONSuccess(c, fiber.StatusOK, profile)
+}
+
+// ListRolePermissions returns every role with its granted permissions and the list of known permissions.
+func (h *Handler) ListRolePermissions(c *fiber.Ctx) error {
+	roles, err := h.Service.ListRolePermissions()
+	if err != nil {
+		return response.JSONErrorWithLog(c, h.Logger, fiber.StatusInternalServerError, ErrMsgListRolesFailed,
 			zap.Error(err),
 		)
 	}
-	if profile == nil {
-		return response.JSONErrorInfoLog(c, h.Logger, fiber.StatusNotFound, "user profile not found",
-			zap.String("user_id", userID),
+
+	return response.JSONSuccess(c, fiber.StatusOK, fiber.Map{
+		"roles":       roles,
+		"permissions": domainuser.Permissions,
+	})
+}
+
+// SetRolePermissions replaces the permissions granted to the role given in the path.
+func (h *Handler) SetRolePermissions(c *fiber.Ctx) error {
+	req, ok := contextutils.GetValidatedBody[SetRolePermissionsRequest](c)
+	if !ok {
+		h.Logger.Debug(ErrMsgInvalidRolesBody, zap.String("handler", "SetRolePermissions"))
+		return response.JSONError(c, fiber.StatusBadRequest, fiber.ErrBadRequest)
+	}
+	role := c.Params("role")
+	actor := h.actor(c)
+
+	if err := h.Service.SetRolePermissions(actor, role, req.Permissions); err != nil {
+		switch {
+		case errors.Is(err, ErrUnknownRole), errors.Is(err, ErrUnknownPermission), errors.Is(err, ErrAdminLockout):
+			return response.JSONErrorInfoLog(c, h.Logger, fiber.StatusBadRequest, err.Error(),
+				zap.String("role", role),
+				zap.Strings("permissions", req.Permissions),
+			)
+
+		default:
+			return response.JSONErrorWithLog(c, h.Logger, fiber.StatusInternalServerError, ErrMsgSetRoleFailed,
+				zap.String("role", role),
+				zap.Error(err),
+			)
+		}
+	}
+
+	h.Logger.Info(SuccessMsgRoleSet,
+		zap.String("admin_id", actor.ID),
+		zap.String("role", role),
+		zap.Strings("permissions", req.Permissions),
+	)
+
+	return response.JSONSuccess(c, fiber.StatusOK, fiber.Map{
+		"role":        role,
+		"permissions": req.Permissions,
+	})
+}
+
+// UnlockUser lifts the login lockout of the user given in the path.
+func (h *Handler) UnlockUser(c *fiber.Ctx) error {
+	userID := c.Params("id")
+	if _, err := uuid.Parse(userID); err != nil {
+		h.Logger.Debug("invalid user ID in path", zap.String("handler", "UnlockUser"))
+		return response.JSONError(c, fiber.StatusBadRequest, fiber.ErrBadRequest)
+	}
+	actor := h.actor(c)
+
+	if err := h.Service.UnlockUser(actor, userID); err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return response.JSONErrorInfoLog(c, h.Logger, fiber.StatusNotFound, err.Error(),
+				zap.String("target_user_id", userID),
+			)
+		}
+		return response.JSONErrorWithLog(c, h.Logger, fiber.StatusInternalServerError, ErrMsgUnlockFailed,
+			zap.String("target_user_id", userID),
+			zap.Error(err),
 		)
 	}
 
-	h.Logger.Info("User profile fetched",
-		zap.String("user_id", userID),
+	h.Logger.Info(SuccessMsgUnlocked,
+		zap.String("admin_id", actor.ID),
+		zap.String("target_user_id", userID),
 	)
 
-	return response.JSONSuccess(c, fiber.StatusOK, profile)
+	return response.JSONSuccess(c, fiber.StatusOK, fiber.Map{
+		"message": SuccessMsgUnlocked,
+	})
 }
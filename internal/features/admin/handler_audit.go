@@ -0,0 +1,84 @@
+package admin
+
+import (
+	domainuser "carowebapp/core/internal/domain/user"
+
+	"carowebapp/core/internal/infrastructure/response"
+
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/google/uuid"
+
+	"go.uber.org/zap"
+)
+
+// ListAuditLog returns a page of the admin audit log, newest first.
+// Query params: actor_id, action, target_type (user, role, rejection_reason, deletion), target_id,
+// from, to (RFC 3339 or YYYY-MM-DD), page, limit.
+func (h *Handler) ListAuditLog(c *fiber.Ctx) error {
+	filter, page, err := parseAuditFilter(c)
+	if err != nil {
+		return response.JSONErrorInfoLog(c, h.Logger, fiber.StatusBadRequest, err.Error(),
+			zap.String("query", c.Context().QueryArgs().String()),
+		)
+	}
+
+	result, err := h.Service.ListAuditEntries(filter)
+	if err != nil {
+		if errors.Is(err, ErrUnknownAuditAction) || errors.Is(err, ErrUnknownAuditTarget) {
+			return response.JSONErrorInfoLog(c, h.Logger, fiber.StatusBadRequest, err.Error())
+		}
+		return response.JSONErrorWithLog(c, h.Logger, fiber.StatusInternalServerError, ErrMsgListAuditFailed,
+			zap.Error(err),
+		)
+	}
+
+	return response.JSONSuccess(c, fiber.StatusOK, fiber.Map{
+		"page":    page,
+		"limit":   filter.Limit,
+		"total":   result.Total,
+		"entries": result.Entries,
+	})
+}
+
+// parseAuditFilter reads the audit log filter and the page number from the query.
+func parseAuditFilter(c *fiber.Ctx) (AuditFilter, int, error) {
+	filter := AuditFilter{
+		ActorID:    c.Query("actor_id"),
+		Action:     c.Query("action"),
+		TargetType: c.Query("target_type"),
+		TargetID:   c.Query("target_id"),
+		Descending: true,
+	}
+
+	if filter.ActorID != "" {
+		if _, err := uuid.Parse(filter.ActorID); err != nil {
+			return filter, 0, errors.New(ErrMsgInvalidFilter)
+		}
+	}
+	if filter.TargetID != "" && filter.TargetType == "" {
+		filter.TargetType = domainuser.AuditTargetUser
+	}
+
+	var err error
+	if filter.From, err = parseDateParam(c.Query("from"), false); err != nil {
+		return filter, 0, err
+	}
+	if filter.To, err = parseDateParam(c.Query("to"), true); err != nil {
+		return filter, 0, err
+	}
+
+	page := c.QueryInt("page", 1)
+	if page < 1 {
+		page = 1
+	}
+	filter.Limit = c.QueryInt("limit", DefaultPageSize)
+	if filter.Limit < 1 || filter.Limit > MaxPageSize {
+		return filter, 0, errors.New(ErrMsgInvalidPageSize)
+	}
+	filter.Offset = (page - 1) * filter.Limit
+
+	return filter, page, nil
+}
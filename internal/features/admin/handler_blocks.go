@@ -0,0 +1,99 @@
+package admin
+
+import (
+	"carowebapp/core/internal/infrastructure/response"
+
+	"carowebapp/core/internal/pkg/contextutils"
+
+	"github.com/gofiber/fiber/v2"
+
+	"go.uber.org/zap"
+
+	"time"
+)
+
+// SuspendUserRequest represents the payload for suspending a user until a point in time.
+type SuspendUserRequest struct {
+	Reason string    `json:"reason" validate:"required,max=500"`
+	Until  time.Time `json:"until" validate:"required"`
+}
+
+// BanUserRequest represents the payload for banning a user.
+type BanUserRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+// SuspendUser suspends the user given in the path until the time from the body.
+func (h *Handler) SuspendUser(c *fiber.Ctx) error {
+	userID, ok := h.pathUserID(c, "SuspendUser")
+	if !ok {
+		return response.JSONError(c, fiber.StatusBadRequest, fiber.ErrBadRequest)
+	}
+	req, ok := contextutils.GetValidatedBody[SuspendUserRequest](c)
+	if !ok {
+		h.Logger.Debug(ErrMsgInvalidSuspendBody, zap.String("handler", "SuspendUser"))
+		return response.JSONError(c, fiber.StatusBadRequest, fiber.ErrBadRequest)
+	}
+	actor := h.actor(c)
+
+	block, err := h.Service.SuspendUser(actor, userID, req.Reason, req.Until)
+	if err != nil {
+		return h.statusError(c, userID, err, ErrMsgSuspendFailed)
+	}
+
+	h.Logger.Info(SuccessMsgSuspended,
+		zap.String("admin_id", actor.ID),
+		zap.String("target_user_id", userID),
+		zap.Time("until", req.Until),
+	)
+
+	return response.JSONSuccess(c, fiber.StatusOK, block)
+}
+
+// BanUser permanently bans the user given in the path.
+func (h *Handler) BanUser(c *fiber.Ctx) error {
+	userID, ok := h.pathUserID(c, "BanUser")
+	if !ok {
+		return response.JSONError(c, fiber.StatusBadRequest, fiber.ErrBadRequest)
+	}
+	req, ok := contextutils.GetValidatedBody[BanUserRequest](c)
+	if !ok {
+		h.Logger.Debug(ErrMsgInvalidBanBody, zap.String("handler", "BanUser"))
+		return response.JSONError(c, fiber.StatusBadRequest, fiber.ErrBadRequest)
+	}
+	actor := h.actor(c)
+
+	block, err := h.Service.BanUser(actor, userID, req.Reason)
+	if err != nil {
+		return h.statusError(c, userID, err, ErrMsgBanFailed)
+	}
+
+	h.Logger.Info(SuccessMsgBanned,
+		zap.String("admin_id", actor.ID),
+		zap.String("target_user_id", userID),
+	)
+
+	return response.JSONSuccess(c, fiber.StatusOK, block)
+}
+
+// ReactivateUser lifts the suspension or ban of the user given in the path.
+func (h *Handler) ReactivateUser(c *fiber.Ctx) error {
+	userID, ok := h.pathUserID(c, "ReactivateUser")
+	if !ok {
+		return response.JSONError(c, fiber.StatusBadRequest, fiber.ErrBadRequest)
+	}
+	actor := h.actor(c)
+
+	change, err := h.Service.ReactivateUser(actor, userID)
+	if err != nil {
+		return h.statusError(c, userID, err, ErrMsgReactivateFailed)
+	}
+
+	h.Logger.Info(SuccessMsgReactivated,
+		zap.String("admin_id", actor.ID),
+		zap.String("target_user_id", userID),
+		zap.String("status", change.To),
+	)
+
+	return response.JSONSuccess(c, fiber.StatusOK, change)
+}
@@ -0,0 +1,92 @@
+package admin
+
+import (
+	"carowebapp/core/internal/infrastructure/response"
+
+	"carowebapp/core/internal/pkg/contextutils"
+
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"go.uber.org/zap"
+)
+
+// BulkApproveRequest represents the payload for approving many pending users at once.
+type BulkApproveRequest struct {
+	UserIDs []string `json:"user_ids" validate:"required,min=1,max=500,dive,uuid4"`
+	DryRun  bool     `json:"dry_run"`
+}
+
+// BulkRejectRequest represents the payload for rejecting many pending users with the same
+// rejection reason codes, keyed by profile field.
+type BulkRejectRequest struct {
+	UserIDs []string          `json:"user_ids" validate:"required,min=1,max=500,dive,uuid4"`
+	Errors  map[string]string `json:"errors" validate:"required,min=1"`
+	DryRun  bool              `json:"dry_run"`
+}
+
+// BulkApprove approves the listed users and reports the outcome for each of them.
+// With dry_run set, nothing is changed and the response shows which users would fail.
+func (h *Handler) BulkApprove(c *fiber.Ctx) error {
+	req, ok := contextutils.GetValidatedBody[BulkApproveRequest](c)
+	if !ok {
+		h.Logger.Debug(ErrMsgInvalidBulkBody, zap.String("handler", "BulkApprove"))
+		return response.JSONError(c, fiber.StatusBadRequest, fiber.ErrBadRequest)
+	}
+	actor := h.actor(c)
+
+	results, err := h.Service.BulkApprove(actor, req.UserIDs, req.DryRun)
+	return h.bulkResponse(c, actor.ID, "approve", req.DryRun, results, err)
+}
+
+// BulkReject rejects the listed users with the same field errors and reports the outcome for each of them.
+func (h *Handler) BulkReject(c *fiber.Ctx) error {
+	req, ok := contextutils.GetValidatedBody[BulkRejectRequest](c)
+	if !ok {
+		h.Logger.Debug(ErrMsgInvalidBulkBody, zap.String("handler", "BulkReject"))
+		return response.JSONError(c, fiber.StatusBadRequest, fiber.ErrBadRequest)
+	}
+	actor := h.actor(c)
+
+	results, err := h.Service.BulkReject(actor, req.UserIDs, req.Errors, req.DryRun)
+	return h.bulkResponse(c, actor.ID, "reject", req.DryRun, results, err)
+}
+
+// bulkResponse maps the outcome of a bulk moderation to the HTTP response.
+func (h *Handler) bulkResponse(c *fiber.Ctx, adminID, operation string, dryRun bool, results []BulkResult, err error) error {
+	fields := []zap.Field{
+		zap.String("admin_id", adminID),
+		zap.String("operation", operation),
+		zap.Bool("dry_run", dryRun),
+	}
+
+	if err != nil {
+		if errors.Is(err, ErrTooManyUsers) || errors.Is(err, ErrUnknownProfileField) ||
+			errors.Is(err, ErrUnknownRejectionReason) || errors.Is(err, ErrReasonFieldMismatch) ||
+			errors.Is(err, ErrNoRejectionReasons) {
+			return response.JSONErrorInfoLog(c, h.Logger, fiber.StatusBadRequest, err.Error(), fields...)
+		}
+		return response.JSONErrorWithLog(c, h.Logger, fiber.StatusInternalServerError, ErrMsgBulkModerateFailed,
+			append(fields, zap.Error(err))...,
+		)
+	}
+
+	succeeded := 0
+	for _, result := range results {
+		if result.OK {
+			succeeded++
+		}
+	}
+
+	h.Logger.Info(SuccessMsgBulkModerated,
+		append(fields, zap.Int("succeeded", succeeded), zap.Int("failed", len(results)-succeeded))...,
+	)
+
+	return response.JSONSuccess(c, fiber.StatusOK, fiber.Map{
+		"dry_run":   dryRun,
+		"succeeded": succeeded,
+		"failed":    len(results) - succeeded,
+		"results":   results,
+	})
+}
@@ -0,0 +1,78 @@
+package admin
+
+import (
+	"carowebapp/core/internal/infrastructure/response"
+
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"go.uber.org/zap"
+)
+
+// ClaimNext claims the longest waiting pending user for the calling admin. It answers
+// 204 No Content when the queue is empty.
+func (h *Handler) ClaimNext(c *fiber.Ctx) error {
+	actor := h.actor(c)
+
+	moderationCase, err := h.Service.ClaimNext(actor)
+	if errors.Is(err, ErrQueueEmpty) {
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+	if err != nil {
+		return response.JSONErrorWithLog(c, h.Logger, fiber.StatusInternalServerError, ErrMsgClaimFailed,
+			zap.String("admin_id", actor.ID),
+			zap.Error(err),
+		)
+	}
+
+	h.Logger.Info(SuccessMsgClaimed,
+		zap.String("admin_id", actor.ID),
+		zap.String("target_user_id", moderationCase.Claim.UserID),
+		zap.Time("expires_at", moderationCase.Claim.ExpiresAt),
+	)
+
+	return response.JSONSuccess(c, fiber.StatusOK, moderationCase)
+}
+
+// ClaimUser claims the pending user given in the path for the calling admin.
+func (h *Handler) ClaimUser(c *fiber.Ctx) error {
+	userID, ok := h.pathUserID(c, "ClaimUser")
+	if !ok {
+		return response.JSONError(c, fiber.StatusBadRequest, fiber.ErrBadRequest)
+	}
+	actor := h.actor(c)
+
+	claim, err := h.Service.ClaimUser(actor, userID)
+	if err != nil {
+		return h.statusError(c, userID, err, ErrMsgClaimFailed)
+	}
+
+	h.Logger.Info(SuccessMsgClaimed,
+		zap.String("admin_id", actor.ID),
+		zap.String("target_user_id", userID),
+		zap.Time("expires_at", claim.ExpiresAt),
+	)
+
+	return response.JSONSuccess(c, fiber.StatusOK, claim)
+}
+
+// ReleaseClaim hands the user given in the path back to the moderation queue.
+func (h *Handler) ReleaseClaim(c *fiber.Ctx) error {
+	userID, ok := h.pathUserID(c, "ReleaseClaim")
+	if !ok {
+		return response.JSONError(c, fiber.StatusBadRequest, fiber.ErrBadRequest)
+	}
+	actor := h.actor(c)
+
+	if err := h.Service.ReleaseClaim(actor, userID); err != nil {
+		return h.statusError(c, userID, err, ErrMsgReleaseClaimFailed)
+	}
+
+	h.Logger.Info(SuccessMsgClaimReleased,
+		zap.String("admin_id", actor.ID),
+		zap.String("target_user_id", userID),
+	)
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,150 @@
+package admin
+
+import (
+	"carowebapp/core/internal/infrastructure/response"
+
+	"carowebapp/core/internal/pkg/contextutils"
+
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/google/uuid"
+
+	"go.uber.org/zap"
+)
+
+// AddNoteRequest represents the payload for adding an internal note about a user.
+type AddNoteRequest struct {
+	Body string `json:"body" validate:"required,max=5000"`
+}
+
+// SetTagsRequest represents the payload for replacing the tags of a user.
+// An empty list removes all tags.
+type SetTagsRequest struct {
+	Tags []string `json:"tags" validate:"max=20,dive,required,max=50"`
+}
+
+// ListNotes returns the internal notes about the user given in the path, newest first.
+func (h *Handler) ListNotes(c *fiber.Ctx) error {
+	userID, ok := h.pathUserID(c, "ListNotes")
+	if !ok {
+		return response.JSONError(c, fiber.StatusBadRequest, fiber.ErrBadRequest)
+	}
+
+	notes, err := h.Service.ListNotes(userID)
+	if err != nil {
+		return h.statusError(c, userID, err, ErrMsgListNotesFailed)
+	}
+
+	return response.JSONSuccess(c, fiber.StatusOK, notes)
+}
+
+// AddNote adds an internal note about the user given in the path.
+func (h *Handler) AddNote(c *fiber.Ctx) error {
+	userID, ok := h.pathUserID(c, "AddNote")
+	if !ok {
+		return response.JSONError(c, fiber.StatusBadRequest, fiber.ErrBadRequest)
+	}
+	req, ok := contextutils.GetValidatedBody[AddNoteRequest](c)
+	if !ok {
+		h.Logger.Debug(ErrMsgInvalidNoteBody, zap.String("handler", "AddNote"))
+		return response.JSONError(c, fiber.StatusBadRequest, fiber.ErrBadRequest)
+	}
+	actor := h.actor(c)
+
+	note, err := h.Service.AddNote(actor, userID, req.Body)
+	if err != nil {
+		return h.statusError(c, userID, err, ErrMsgAddNoteFailed)
+	}
+
+	h.Logger.Info(SuccessMsgNoteAdded,
+		zap.String("admin_id", actor.ID),
+		zap.String("target_user_id", userID),
+		zap.String("note_id", note.ID),
+	)
+
+	return response.JSONSuccess(c, fiber.StatusCreated, note)
+}
+
+// DeleteNote removes the note given in the path from the user given in the path.
+func (h *Handler) DeleteNote(c *fiber.Ctx) error {
+	userID, ok := h.pathUserID(c, "DeleteNote")
+	if !ok {
+		return response.JSONError(c, fiber.StatusBadRequest, fiber.ErrBadRequest)
+	}
+	noteID := c.Params("noteId")
+	if _, err := uuid.Parse(noteID); err != nil {
+		h.Logger.Debug("invalid note ID in path", zap.String("handler", "DeleteNote"))
+		return response.JSONError(c, fiber.StatusBadRequest, fiber.ErrBadRequest)
+	}
+	actor := h.actor(c)
+
+	if err := h.Service.DeleteNote(actor, userID, noteID); err != nil {
+		if errors.Is(err, ErrNoteNotFound) {
+			return response.JSONErrorInfoLog(c, h.Logger, fiber.StatusNotFound, err.Error(),
+				zap.String("target_user_id", userID),
+				zap.String("note_id", noteID),
+			)
+		}
+		return h.statusError(c, userID, err, ErrMsgDeleteNoteFailed, zap.String("note_id", noteID))
+	}
+
+	h.Logger.Info(SuccessMsgNoteDeleted,
+		zap.String("admin_id", actor.ID),
+		zap.String("target_user_id", userID),
+		zap.String("note_id", noteID),
+	)
+
+	return response.JSONSuccess(c, fiber.StatusOK, fiber.Map{
+		"user_id": userID,
+		"note_id": noteID,
+	})
+}
+
+// SetTags replaces the tags of the user given in the path.
+func (h *Handler) SetTags(c *fiber.Ctx) error {
+	userID, ok := h.pathUserID(c, "SetTags")
+	if !ok {
+		return response.JSONError(c, fiber.StatusBadRequest, fiber.ErrBadRequest)
+	}
+	req, ok := contextutils.GetValidatedBody[SetTagsRequest](c)
+	if !ok {
+		h.Logger.Debug(ErrMsgInvalidTagsBody, zap.String("handler", "SetTags"))
+		return response.JSONError(c, fiber.StatusBadRequest, fiber.ErrBadRequest)
+	}
+	actor := h.actor(c)
+
+	tags, err := h.Service.SetTags(actor, userID, req.Tags)
+	if err != nil {
+		if errors.Is(err, ErrInvalidTag) || errors.Is(err, ErrTooManyTags) {
+			return response.JSONErrorInfoLog(c, h.Logger, fiber.StatusBadRequest, err.Error(),
+				zap.String("target_user_id", userID),
+			)
+		}
+		return h.statusError(c, userID, err, ErrMsgSetTagsFailed)
+	}
+
+	h.Logger.Info(SuccessMsgTagsSet,
+		zap.String("admin_id", actor.ID),
+		zap.String("target_user_id", userID),
+		zap.Strings("tags", tags),
+	)
+
+	return response.JSONSuccess(c, fiber.StatusOK, fiber.Map{
+		"user_id": userID,
+		"tags":    tags,
+	})
+}
+
+// ListTags returns every tag in use with the number of users carrying it, most used first.
+func (h *Handler) ListTags(c *fiber.Ctx) error {
+	tags, err := h.Service.ListAllTags()
+	if err != nil {
+		return response.JSONErrorWithLog(c, h.Logger, fiber.StatusInternalServerError, ErrMsgListTagsFailed,
+			zap.Error(err),
+		)
+	}
+
+	return response.JSONSuccess(c, fiber.StatusOK, tags)
+}
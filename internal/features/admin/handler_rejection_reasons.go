@@ -0,0 +1,106 @@
+package admin
+
+import (
+	domainuser "carowebapp/core/internal/domain/user"
+
+	"carowebapp/core/internal/infrastructure/response"
+
+	"carowebapp/core/internal/pkg/contextutils"
+
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"go.uber.org/zap"
+)
+
+// SaveRejectionReasonRequest represents the payload for adding or changing a catalog reason.
+// Active defaults to true; saving a reason inactive retires it.
+type SaveRejectionReasonRequest struct {
+	Field  string `json:"field" validate:"required"`
+	TextDE string `json:"text_de" validate:"required,max=500"`
+	TextEN string `json:"text_en" validate:"required,max=500"`
+	Active *bool  `json:"active"`
+}
+
+// ListRejectionReasons returns the rejection reason catalog. Retired reasons are included
+// with ?include_inactive=true.
+func (h *Handler) ListRejectionReasons(c *fiber.Ctx) error {
+	reasons, err := h.Service.ListRejectionReasons(c.QueryBool("include_inactive"))
+	if err != nil {
+		return response.JSONErrorWithLog(c, h.Logger, fiber.StatusInternalServerError, ErrMsgListReasonsFailed,
+			zap.Error(err),
+		)
+	}
+
+	return response.JSONSuccess(c, fiber.StatusOK, reasons)
+}
+
+// SaveRejectionReason creates or updates the catalog reason with the code given in the path.
+func (h *Handler) SaveRejectionReason(c *fiber.Ctx) error {
+	req, ok := contextutils.GetValidatedBody[SaveRejectionReasonRequest](c)
+	if !ok {
+		h.Logger.Debug(ErrMsgInvalidReasonBody, zap.String("handler", "SaveRejectionReason"))
+		return response.JSONError(c, fiber.StatusBadRequest, fiber.ErrBadRequest)
+	}
+	actor := h.actor(c)
+	code := c.Params("code")
+
+	active := true
+	if req.Active != nil {
+		active = *req.Active
+	}
+
+	reason, err := h.Service.SaveRejectionReason(actor, domainuser.RejectionReason{
+		Code:   code,
+		Field:  req.Field,
+		TextDE: req.TextDE,
+		TextEN: req.TextEN,
+		Active: active,
+	})
+	if err != nil {
+		if errors.Is(err, ErrInvalidReasonCode) || errors.Is(err, ErrUnknownProfileField) {
+			return response.JSONErrorInfoLog(c, h.Logger, fiber.StatusBadRequest, err.Error(),
+				zap.String("code", code),
+			)
+		}
+		return response.JSONErrorWithLog(c, h.Logger, fiber.StatusInternalServerError, ErrMsgSaveReasonFailed,
+			zap.String("code", code),
+			zap.Error(err),
+		)
+	}
+
+	h.Logger.Info(SuccessMsgReasonSaved,
+		zap.String("admin_id", actor.ID),
+		zap.String("code", reason.Code),
+		zap.Bool("active", reason.Active),
+	)
+
+	return response.JSONSuccess(c, fiber.StatusOK, reason)
+}
+
+// RejectionReasonStats returns how often each catalog reason was given, most common first.
+// Query params: from, to (RFC 3339 or YYYY-MM-DD).
+func (h *Handler) RejectionReasonStats(c *fiber.Ctx) error {
+	from, err := parseDateParam(c.Query("from"), false)
+	if err != nil {
+		return response.JSONErrorInfoLog(c, h.Logger, fiber.StatusBadRequest, err.Error())
+	}
+	to, err := parseDateParam(c.Query("to"), true)
+	if err != nil {
+		return response.JSONErrorInfoLog(c, h.Logger, fiber.StatusBadRequest, err.Error())
+	}
+
+	counts, err := h.Service.RejectionReasonStats(from, to)
+	if err != nil {
+		return response.JSONErrorWithLog(c, h.Logger, fiber.StatusInternalServerError, ErrMsgReasonStatsFailed,
+			zap.Error(err),
+		)
+	}
+
+	return response.JSONSuccess(c, fiber.StatusOK, fiber.Map{
+		"from":    from,
+		"to":      to,
+		"reasons": counts,
+	})
+}
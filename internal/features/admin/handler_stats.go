@@ -0,0 +1,60 @@
+package admin
+
+import (
+	"bytes"
+
+	"carowebapp/core/internal/infrastructure/response"
+
+	"errors"
+
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+
+	"go.uber.org/zap"
+)
+
+// GetStats returns the moderation dashboard statistics.
+// Query params: from, to (RFC 3339 or YYYY-MM-DD, to is inclusive for dates), format (json, csv).
+// The CSV is sent as a download for management reports.
+func (h *Handler) GetStats(c *fiber.Ctx) error {
+	format := c.Query("format", "json")
+	if format != "json" && format != "csv" {
+		return response.JSONErrorInfoLog(c, h.Logger, fiber.StatusBadRequest, ErrMsgInvalidStatsFormat,
+			zap.String("format", format),
+		)
+	}
+	from, err := parseDateParam(c.Query("from"), false)
+	if err != nil {
+		return response.JSONErrorInfoLog(c, h.Logger, fiber.StatusBadRequest, err.Error())
+	}
+	to, err := parseDateParam(c.Query("to"), true)
+	if err != nil {
+		return response.JSONErrorInfoLog(c, h.Logger, fiber.StatusBadRequest, err.Error())
+	}
+
+	stats, err := h.Service.GetStats(from, to)
+	if err != nil {
+		if errors.Is(err, ErrInvalidStatsRange) {
+			return response.JSONErrorInfoLog(c, h.Logger, fiber.StatusBadRequest, err.Error())
+		}
+		return response.JSONErrorWithLog(c, h.Logger, fiber.StatusInternalServerError, ErrMsgStatsFailed,
+			zap.Error(err),
+		)
+	}
+
+	if format == "json" {
+		return response.JSONSuccess(c, fiber.StatusOK, stats)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteStatsCSV(&buf, stats); err != nil {
+		return response.JSONErrorWithLog(c, h.Logger, fiber.StatusInternalServerError, ErrMsgStatsFailed,
+			zap.Error(err),
+		)
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Attachment(fmt.Sprintf("moderation-stats-%s-%s.csv", stats.From.Format("20060102"), stats.To.Format("20060102")))
+	return c.Status(fiber.StatusOK).Send(buf.Bytes())
+}
@@ -0,0 +1,221 @@
+package admin
+
+import (
+	domainuser "carowebapp/core/internal/domain/user"
+
+	"carowebapp/core/internal/infrastructure/response"
+
+	"carowebapp/core/internal/pkg/contextutils"
+
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/google/uuid"
+
+	"go.uber.org/zap"
+
+	"strconv"
+
+	"time"
+)
+
+// Page sizes of the admin user lists.
+const (
+	DefaultPageSize = 25
+	MaxPageSize     = 100
+)
+
+// SetUserRoleRequest represents the payload for assigning a role to a user.
+type SetUserRoleRequest struct {
+	Role string `json:"role" validate:"required"`
+}
+
+// SetUserStatusRequest represents the payload for overriding the status of a user.
+type SetUserStatusRequest struct {
+	Status string `json:"status" validate:"required"`
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+// ListUsers returns a filtered, sorted page of all users with the total number of matches.
+// Query params: status, role, email_confirmed, created_from, created_to (RFC 3339 or YYYY-MM-DD),
+// search (email or name), tag (repeatable, users must carry all), sort (created_at, email, status,
+// last_name), order (asc, desc), page, limit.
+func (h *Handler) ListUsers(c *fiber.Ctx) error {
+	filter, page, err := parseUserFilter(c)
+	if err != nil {
+		return response.JSONErrorInfoLog(c, h.Logger, fiber.StatusBadRequest, err.Error(),
+			zap.String("query", c.Context().QueryArgs().String()),
+		)
+	}
+
+	result, err := h.Service.ListUsers(filter)
+	if err != nil {
+		if errors.Is(err, domainuser.ErrUnknownStatus) || errors.Is(err, ErrUnknownRole) || errors.Is(err, ErrInvalidTag) {
+			return response.JSONErrorInfoLog(c, h.Logger, fiber.StatusBadRequest, err.Error())
+		}
+		return response.JSONErrorWithLog(c, h.Logger, fiber.StatusInternalServerError, ErrMsgListUsersFailed,
+			zap.Error(err),
+		)
+	}
+
+	return response.JSONSuccess(c, fiber.StatusOK, fiber.Map{
+		"page":  page,
+		"limit": filter.Limit,
+		"total": result.Total,
+		"users": result.Users,
+	})
+}
+
+// GetUser returns the admin view of the user given in the path.
+func (h *Handler) GetUser(c *fiber.Ctx) error {
+	userID, ok := h.pathUserID(c, "GetUser")
+	if !ok {
+		return response.JSONError(c, fiber.StatusBadRequest, fiber.ErrBadRequest)
+	}
+
+	detail, err := h.Service.GetUserDetail(userID)
+	if err != nil {
+		return h.statusError(c, userID, err, ErrMsgGetUserFailed)
+	}
+
+	return response.JSONSuccess(c, fiber.StatusOK, detail)
+}
+
+// SetUserRole assigns the role from the body to the user given in the path.
+func (h *Handler) SetUserRole(c *fiber.Ctx) error {
+	userID, ok := h.pathUserID(c, "SetUserRole")
+	if !ok {
+		return response.JSONError(c, fiber.StatusBadRequest, fiber.ErrBadRequest)
+	}
+	req, ok := contextutils.GetValidatedBody[SetUserRoleRequest](c)
+	if !ok {
+		h.Logger.Debug(ErrMsgInvalidUserRoleBody, zap.String("handler", "SetUserRole"))
+		return response.JSONError(c, fiber.StatusBadRequest, fiber.ErrBadRequest)
+	}
+	actor := h.actor(c)
+
+	if err := h.Service.SetUserRole(actor, userID, req.Role); err != nil {
+		return h.statusError(c, userID, err, ErrMsgSetUserRoleFailed)
+	}
+
+	h.Logger.Info(SuccessMsgUserRoleSet,
+		zap.String("admin_id", actor.ID),
+		zap.String("target_user_id", userID),
+		zap.String("role", req.Role),
+	)
+
+	return response.JSONSuccess(c, fiber.StatusOK, fiber.Map{
+		"user_id": userID,
+		"role":    req.Role,
+	})
+}
+
+// SetUserStatus overrides the status of the user given in the path.
+func (h *Handler) SetUserStatus(c *fiber.Ctx) error {
+	userID, ok := h.pathUserID(c, "SetUserStatus")
+	if !ok {
+		return response.JSONError(c, fiber.StatusBadRequest, fiber.ErrBadRequest)
+	}
+	req, ok := contextutils.GetValidatedBody[SetUserStatusRequest](c)
+	if !ok {
+		h.Logger.Debug(ErrMsgInvalidUserStatusBody, zap.String("handler", "SetUserStatus"))
+		return response.JSONError(c, fiber.StatusBadRequest, fiber.ErrBadRequest)
+	}
+	actor := h.actor(c)
+
+	change, err := h.Service.OverrideStatus(actor, userID, req.Status, req.Reason)
+	if err != nil {
+		return h.statusError(c, userID, err, ErrMsgSetUserStatusFailed)
+	}
+
+	h.Logger.Info(SuccessMsgUserStatusSet,
+		zap.String("admin_id", actor.ID),
+		zap.String("target_user_id", userID),
+		zap.String("from", change.From),
+		zap.String("to", change.To),
+	)
+
+	return response.JSONSuccess(c, fiber.StatusOK, change)
+}
+
+// pathUserID returns the user ID from the path if it is a valid UUID.
+func (h *Handler) pathUserID(c *fiber.Ctx, handler string) (string, bool) {
+	userID := c.Params("id")
+	if _, err := uuid.Parse(userID); err != nil {
+		h.Logger.Debug("invalid user ID in path", zap.String("handler", handler))
+		return "", false
+	}
+	return userID, true
+}
+
+// parseUserFilter reads the user list filter and the page number from the query.
+func parseUserFilter(c *fiber.Ctx) (UserFilter, int, error) {
+	filter := UserFilter{
+		Status: c.Query("status"),
+		Role:   c.Query("role"),
+		Search: c.Query("search"),
+		Sort:   c.Query("sort", SortCreatedAt),
+	}
+	for _, tag := range c.Context().QueryArgs().PeekMulti("tag") {
+		filter.Tags = append(filter.Tags, string(tag))
+	}
+
+	if _, ok := sortColumns[filter.Sort]; !ok {
+		return filter, 0, errors.New(ErrMsgInvalidSort)
+	}
+	switch c.Query("order", "desc") {
+	case "asc":
+	case "desc":
+		filter.Descending = true
+	default:
+		return filter, 0, errors.New(ErrMsgInvalidSort)
+	}
+
+	if raw := c.Query("email_confirmed"); raw != "" {
+		confirmed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return filter, 0, errors.New(ErrMsgInvalidFilter)
+		}
+		filter.EmailConfirmed = &confirmed
+	}
+
+	var err error
+	if filter.CreatedFrom, err = parseDateParam(c.Query("created_from"), false); err != nil {
+		return filter, 0, err
+	}
+	if filter.CreatedTo, err = parseDateParam(c.Query("created_to"), true); err != nil {
+		return filter, 0, err
+	}
+
+	page := c.QueryInt("page", 1)
+	if page < 1 {
+		page = 1
+	}
+	filter.Limit = c.QueryInt("limit", DefaultPageSize)
+	if filter.Limit < 1 || filter.Limit > MaxPageSize {
+		return filter, 0, errors.New(ErrMsgInvalidPageSize)
+	}
+	filter.Offset = (page - 1) * filter.Limit
+
+	return filter, page, nil
+}
+
+// parseDateParam accepts RFC 3339 timestamps and plain dates. A plain date used as an
+// upper bound includes the whole day.
+func parseDateParam(raw string, upperBound bool) (*time.Time, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse(time.DateOnly, raw)
+	if err != nil {
+		return nil, errors.New(ErrMsgInvalidFilter)
+	}
+	if upperBound {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
@@ -1 +1,200 @@
 package admin
+
+import (
+	domainuser "carowebapp/core/internal/domain/user"
+
+	"github.com/lib/pq"
+
+	"time"
+)
+
+// ProfileDetail is a user profile together with its rejections and the edits the user
+// made to it, newest first, so moderators can see what changed since the last review.
+// Resubmissions are the changes that reference a rejection. Notes and tags are internal.
+type ProfileDetail struct {
+	*domainuser.Profile
+	// HasProfile is false for users who have not created a profile yet. The profile
+	// fields are then absent from the JSON.
+	HasProfile bool                       `json:"has_profile"`
+	Rejections []domainuser.Rejection     `json:"rejections"`
+	Changes    []domainuser.ProfileChange `json:"profile_changes"`
+	Notes      []Note                     `json:"notes"`
+	Tags       []string                   `json:"tags"`
+}
+
+// Sort columns accepted by ListUsers.
+const (
+	SortCreatedAt = "created_at"
+	SortEmail     = "email"
+	SortStatus    = "status"
+	SortLastName  = "last_name"
+)
+
+// UserFilter narrows and orders the admin user list. Empty fields do not filter.
+// VisibleTo hides users another admin has claimed for moderation. Users must carry all Tags.
+type UserFilter struct {
+	Status         string
+	Role           string
+	EmailConfirmed *bool
+	CreatedFrom    *time.Time
+	CreatedTo      *time.Time
+	Search         string
+	VisibleTo      string
+	Tags           []string
+	Sort           string
+	Descending     bool
+	Limit          int
+	Offset         int
+}
+
+// UserSummary is one row of the admin user list. Users without a profile have no name.
+type UserSummary struct {
+	ID             string         `db:"id" json:"id"`
+	Email          string         `db:"email" json:"email"`
+	Role           string         `db:"role" json:"role"`
+	Status         string         `db:"status" json:"status"`
+	EmailConfirmed bool           `db:"email_confirmed" json:"email_confirmed"`
+	FirstName      *string        `db:"first_name" json:"first_name"`
+	LastName       *string        `db:"last_name" json:"last_name"`
+	Tags           pq.StringArray `db:"tags" json:"tags"`
+	CreatedAt      time.Time      `db:"created_at" json:"created_at"`
+}
+
+// UserPage is a page of the user list together with the number of all matching users.
+type UserPage struct {
+	Users []UserSummary `json:"users"`
+	Total int           `json:"total"`
+}
+
+// UserDetail is the full admin view of an account.
+type UserDetail struct {
+	ID             string                    `db:"id" json:"id"`
+	Email          string                    `db:"email" json:"email"`
+	Role           string                    `db:"role" json:"role"`
+	Status         string                    `db:"status" json:"status"`
+	EmailConfirmed bool                      `db:"email_confirmed" json:"email_confirmed"`
+	TOTPEnabled    bool                      `db:"totp_enabled" json:"totp_enabled"`
+	LockedUntil    *time.Time                `db:"locked_until" json:"locked_until"`
+	CreatedAt      time.Time                 `db:"created_at" json:"created_at"`
+	Profile        *domainuser.Profile       `db:"-" json:"profile"`
+	StatusHistory  []domainuser.StatusChange `db:"-" json:"status_history"`
+	Block          *domainuser.AccountBlock  `db:"-" json:"block"`
+}
+
+// AuditFilter narrows the audit log. Empty fields do not filter; From is inclusive, To exclusive.
+type AuditFilter struct {
+	ActorID    string
+	Action     string
+	TargetType string
+	TargetID   string
+	From       *time.Time
+	To         *time.Time
+	Descending bool
+	Limit      int
+	Offset     int
+}
+
+// AuditPage is a page of the audit log together with the number of all matching entries.
+type AuditPage struct {
+	Entries []domainuser.AuditEntry `json:"entries"`
+	Total   int                     `json:"total"`
+}
+
+// Decision is an approval or rejection ready to be stored. Reasons and their catalog Codes
+// are only set for rejections.
+type Decision struct {
+	Change  *domainuser.StatusChange
+	Reasons map[string]string
+	Codes   map[string]string
+	Entry   domainuser.AuditEntry
+}
+
+// RejectionReasonCount is how often a catalog reason was given in rejections.
+type RejectionReasonCount struct {
+	Code  string `db:"code" json:"code"`
+	Field string `db:"field" json:"field"`
+	Count int    `db:"count" json:"count"`
+}
+
+// Claim is an admin's lease on a pending user. Until it expires, the user is hidden from
+// other admins' moderation queues and only the claiming admin can decide on them.
+type Claim struct {
+	UserID    string    `db:"user_id" json:"user_id"`
+	AdminID   string    `db:"admin_id" json:"admin_id"`
+	ClaimedAt time.Time `db:"claimed_at" json:"claimed_at"`
+	ExpiresAt time.Time `db:"expires_at" json:"expires_at"`
+}
+
+// ModerationCase is a claimed user with the details a moderator reviews.
+type ModerationCase struct {
+	Claim *Claim      `json:"claim"`
+	User  *UserDetail `json:"user"`
+}
+
+// Stats are the moderation dashboard figures for the period from From (inclusive) to To (exclusive).
+// The funnel follows the users registered in the period; decisions are those made in it.
+// The pending backlog is always the current one.
+type Stats struct {
+	From                  time.Time        `json:"from"`
+	To                    time.Time        `json:"to"`
+	Registrations         []DailyCount     `json:"registrations"`
+	Funnel                Funnel           `json:"funnel"`
+	Backlog               []BacklogBucket  `json:"backlog"`
+	MedianDecisionSeconds *float64         `json:"median_decision_seconds"`
+	Decisions             []AdminDecisions `json:"decisions"`
+}
+
+// DailyCount is the number of registrations on a day, given as YYYY-MM-DD.
+type DailyCount struct {
+	Day   string `db:"day" json:"day"`
+	Count int    `db:"count" json:"count"`
+}
+
+// Funnel counts how far the users registered in a period got.
+type Funnel struct {
+	Created          int `db:"created" json:"created"`
+	EmailConfirmed   int `db:"email_confirmed" json:"email_confirmed"`
+	ProfileSubmitted int `db:"profile_submitted" json:"profile_submitted"`
+	Approved         int `db:"approved" json:"approved"`
+	Rejected         int `db:"rejected" json:"rejected"`
+}
+
+// Ages of pending users in the backlog.
+const (
+	BacklogUnderOneDay      = "under_1d"
+	BacklogOneToThreeDays   = "1d_3d"
+	BacklogThreeToSevenDays = "3d_7d"
+	BacklogOverSevenDays    = "over_7d"
+)
+
+// BacklogBucket is the number of users that have been pending for an age range.
+type BacklogBucket struct {
+	Age   string `db:"age" json:"age"`
+	Count int    `db:"count" json:"count"`
+}
+
+// AdminDecisions counts the approvals and rejections of one admin. AdminID and Email are nil
+// for the decisions of admins whose accounts were deleted.
+type AdminDecisions struct {
+	AdminID  *string `db:"admin_id" json:"admin_id"`
+	Email    *string `db:"email" json:"email"`
+	Approved int     `db:"approved" json:"approved"`
+	Rejected int     `db:"rejected" json:"rejected"`
+}
+
+// Note is an internal remark staff keep about a user. It is only shown to admins.
+// AuthorID and AuthorEmail are nil if the author's account was deleted.
+type Note struct {
+	ID          string    `db:"id" json:"id"`
+	UserID      string    `db:"user_id" json:"user_id"`
+	AuthorID    *string   `db:"author_id" json:"author_id"`
+	AuthorEmail *string   `db:"author_email" json:"author_email"`
+	Body        string    `db:"body" json:"body"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+}
+
+// TagCount is a tag with the number of users carrying it.
+type TagCount struct {
+	Tag   string `db:"tag" json:"tag"`
+	Count int    `db:"count" json:"count"`
+}
@@ -1,12 +1,44 @@
 package admin
 
-import domainuser "carowebapp/core/internal/domain/user"
+import (
+	domainuser "carowebapp/core/internal/domain/user"
+
+	"time"
+)
 
 type Repository interface {
 	GetUserByID(userID string) (*User, error)
-	SetUserApproved(userID string) error
-	SetUserRejected(userID string) error
-	InsertUserRejection(userID string, errors map[string]string) error
-	ListPendingUsers(search string, limit, offset int) ([]*domainuser.User, error)
+	GetUsersByIDs(userIDs []string) ([]User, error)
+	ChangeStatus(change *domainuser.StatusChange, entry domainuser.AuditEntry) error
+	RejectUser(change *domainuser.StatusChange, reasons, codes map[string]string, entry domainuser.AuditEntry) error
+	ApplyDecisions(decisions []Decision) ([]error, error)
+	ListUsers(filter UserFilter) (*UserPage, error)
+	GetUserDetail(userID string) (*UserDetail, error)
+	ListStatusChanges(userID string) ([]domainuser.StatusChange, error)
+	SetUserRole(userID, role string, event domainuser.SecurityEvent, entry domainuser.AuditEntry) error
+	GetOpenBlock(userID string) (*domainuser.AccountBlock, error)
+	BlockUser(block *domainuser.AccountBlock, change *domainuser.StatusChange, event domainuser.SecurityEvent, entry domainuser.AuditEntry) error
+	LiftBlock(block *domainuser.AccountBlock, change *domainuser.StatusChange, event domainuser.SecurityEvent, entry domainuser.AuditEntry) error
+	ListExpiredSuspensions(now time.Time) ([]domainuser.AccountBlock, error)
 	GetUserProfile(userID string) (*domainuser.Profile, error)
+	ListProfileChanges(userID string) ([]domainuser.ProfileChange, error)
+	ListRejections(userID string) ([]domainuser.Rejection, error)
+	ListRolePermissions() (map[string][]string, error)
+	SetRolePermissions(role string, permissions []string, entry domainuser.AuditEntry) error
+	UnlockUser(userID string, event domainuser.SecurityEvent, entry domainuser.AuditEntry) (bool, error)
+	ListAuditEntries(filter AuditFilter) (*AuditPage, error)
+	ClaimNext(adminID string, lease time.Duration) (*Claim, error)
+	ClaimUser(userID, adminID string, lease time.Duration) (*Claim, error)
+	ReleaseClaim(userID, adminID string) (bool, error)
+	ListRejectionReasons(includeInactive bool) ([]domainuser.RejectionReason, error)
+	GetRejectionReasons(codes []string) ([]domainuser.RejectionReason, error)
+	SaveRejectionReason(reason *domainuser.RejectionReason, entry domainuser.AuditEntry) error
+	RejectionReasonStats(from, to *time.Time) ([]RejectionReasonCount, error)
+	GetStats(from, to time.Time) (*Stats, error)
+	ListNotes(userID string) ([]Note, error)
+	AddNote(note *Note, entry domainuser.AuditEntry) error
+	DeleteNote(userID, noteID string, entry domainuser.AuditEntry) (bool, error)
+	ListTags(userID string) ([]string, error)
+	SetTags(userID string, tags []string, createdBy string, entry domainuser.AuditEntry) error
+	ListAllTags() ([]TagCount, error)
 }
@@ -1,8 +1,16 @@
 package admin
 
 import (
+	"context"
+
 	domainuser "carowebapp/core/internal/domain/user"
 
+	"carowebapp/core/internal/infrastructure/auditlog"
+
+	"carowebapp/core/internal/infrastructure/securitylog"
+
+	"carowebapp/core/internal/infrastructure/userstatus"
+
 	"database/sql"
 
 	"encoding/json"
@@ -12,6 +20,12 @@ import (
 	"fmt"
 
 	"github.com/jmoiron/sqlx"
+
+	"github.com/lib/pq"
+
+	"strings"
+
+	"time"
 )
 
 // sqlxRepository provides SQL-backed implementation of the admin.Repository interface.
@@ -26,107 +40,579 @@ func NewSQLXRepository(db *sqlx.DB) Repository {
 
 // User represents a minimal user view used internally by the admin feature.
 type User struct {
-	ID     string `db:"id"`
-	Email  string `db:"email"`
-	Status string `db:"status"`
-	Role   string `db:"role"`
+	ID             string `db:"id"`
+	Email          string `db:"email"`
+	Status         string `db:"status"`
+	Role           string `db:"role"`
+	EmailConfirmed bool   `db:"email_confirmed"`
+	HasProfile     bool   `db:"has_profile"`
+	Language       string `db:"language"`
 }
 
 // GetUserByID fetches a user by their ID.
 func (r *sqlxRepository) GetUserByID(userID string) (*User, error) {
 	var user User
 	err := r.db.Get(&user, `
-		SELECT id, email, status, role
-		FROM users
-		WHERE id = $1
+		SELECT u.id, u.email, u.status, u.role, u.email_confirmed, u.language,
+		       EXISTS (SELECT 1 FROM user_profiles p WHERE p.user_id = u.id) AS has_profile
+		FROM users u
+		WHERE u.id = $1
 	`, userID)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
 		return nil, err
 	}
 	return &user, nil
 }
 
-// SetUserApproved updates a user's status to 'approved'.
-func (r *sqlxRepository) SetUserApproved(userID string) error {
-	_, err := r.db.Exec(`
-		UPDATE users
-		SET status = 'approved'
-		WHERE id = $1
-	`, userID)
+// GetUsersByIDs returns the users with the given IDs. Unknown IDs are left out.
+func (r *sqlxRepository) GetUsersByIDs(userIDs []string) ([]User, error) {
+	users := []User{}
+	err := r.db.Select(&users, `
+		SELECT u.id, u.email, u.status, u.role, u.email_confirmed, u.language,
+		       EXISTS (SELECT 1 FROM user_profiles p WHERE p.user_id = u.id) AS has_profile
+		FROM users u
+		WHERE u.id = ANY($1)
+	`, pq.Array(userIDs))
+	return users, err
+}
+
+// ChangeStatus applies a status transition and records it in the transition history and the audit log.
+// It fails with ErrClaimedByOther while another admin holds the moderation claim on the user.
+func (r *sqlxRepository) ChangeStatus(change *domainuser.StatusChange, entry domainuser.AuditEntry) error {
+	return r.applyDecision(Decision{Change: change, Entry: entry})
+}
+
+// RejectUser stores the rejection reasons and their catalog codes as JSON objects together with
+// the rejecting admin and applies the reject transition atomically. Like ChangeStatus, it respects
+// moderation claims.
+func (r *sqlxRepository) RejectUser(change *domainuser.StatusChange, reasons, codes map[string]string, entry domainuser.AuditEntry) error {
+	return r.applyDecision(Decision{Change: change, Reasons: reasons, Codes: codes, Entry: entry})
+}
+
+// ApplyDecisions stores the decisions in one transaction. A decision that fails is rolled
+// back on its own and its error is returned at the same index; the others are kept.
+// The error is only set if the transaction as a whole failed.
+func (r *sqlxRepository) ApplyDecisions(decisions []Decision) ([]error, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	errs := make([]error, len(decisions))
+	for i, decision := range decisions {
+		if _, err := tx.Exec(`SAVEPOINT decision`); err != nil {
+			return nil, err
+		}
+		if errs[i] = storeDecision(tx, decision); errs[i] != nil {
+			if _, err := tx.Exec(`ROLLBACK TO SAVEPOINT decision`); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if _, err := tx.Exec(`RELEASE SAVEPOINT decision`); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return errs, nil
+}
+
+// applyDecision stores a single decision in its own transaction.
+func (r *sqlxRepository) applyDecision(decision Decision) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := storeDecision(tx, decision); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// storeDecision settles the moderation claim, stores the rejection reasons if there are any,
+// applies the status change and writes the audit entry.
+func storeDecision(tx *sqlx.Tx, decision Decision) error {
+	change := decision.Change
+
+	if err := settleClaim(tx, change); err != nil {
+		return err
+	}
+
+	if decision.Reasons != nil {
+		jsonData, err := json.Marshal(decision.Reasons)
+		if err != nil {
+			return fmt.Errorf("failed to marshal rejection errors: %w", err)
+		}
+		codes, err := json.Marshal(decision.Codes)
+		if err != nil {
+			return fmt.Errorf("failed to marshal rejection codes: %w", err)
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO user_rejections (user_id, errors, reason_codes, rejected_by)
+			VALUES ($1, $2, $3, $4)
+		`, change.UserID, jsonData, codes, change.ActorID); err != nil {
+			return err
+		}
+	}
+
+	if err := userstatus.Apply(tx, change); err != nil {
+		return err
+	}
+
+	return auditlog.Record(tx, decision.Entry)
+}
+
+// sortColumns maps the accepted sort keys to SQL expressions.
+var sortColumns = map[string]string{
+	SortCreatedAt: "u.created_at",
+	SortEmail:     "u.email",
+	SortStatus:    "u.status",
+	SortLastName:  "p.last_name",
+}
+
+// ListUsers returns one page of users matching the filter and the total number of matches.
+// Search matches email, first and last name, so users without a profile are found by email.
+func (r *sqlxRepository) ListUsers(filter UserFilter) (*UserPage, error) {
+	var conditions []string
+	var args []any
+	add := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, strings.ReplaceAll(condition, "?", fmt.Sprintf("$%d", len(args))))
+	}
+
+	if filter.Status != "" {
+		add("u.status = ?", filter.Status)
+	}
+	if filter.Role != "" {
+		add("u.role = ?", filter.Role)
+	}
+	if filter.EmailConfirmed != nil {
+		add("u.email_confirmed = ?", *filter.EmailConfirmed)
+	}
+	if filter.CreatedFrom != nil {
+		add("u.created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		add("u.created_at < ?", *filter.CreatedTo)
+	}
+	if filter.Search != "" {
+		add("(u.email ILIKE ? OR p.first_name ILIKE ? OR p.last_name ILIKE ?)", "%"+escapeLike(filter.Search)+"%")
+	}
+	if filter.VisibleTo != "" {
+		add(`NOT EXISTS (
+			SELECT 1 FROM moderation_claims c
+			WHERE c.user_id = u.id AND c.expires_at > NOW() AND c.admin_id <> ?
+		)`, filter.VisibleTo)
+	}
+	for _, tag := range filter.Tags {
+		add("EXISTS (SELECT 1 FROM user_tags t WHERE t.user_id = u.id AND t.tag = ?)", tag)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	page := &UserPage{Users: []UserSummary{}}
+	if err := r.db.Get(&page.Total, `
+		SELECT COUNT(*)
+		FROM users u
+		LEFT JOIN user_profiles p ON u.id = p.user_id
+		`+where, args...); err != nil {
+		return nil, err
+	}
+
+	column, ok := sortColumns[filter.Sort]
+	if !ok {
+		column = sortColumns[SortCreatedAt]
+	}
+	direction := "ASC"
+	if filter.Descending {
+		direction = "DESC"
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	query := fmt.Sprintf(`
+		SELECT u.id, u.email, u.role, u.status, u.email_confirmed, u.created_at,
+		       p.first_name, p.last_name,
+		       ARRAY(SELECT t.tag FROM user_tags t WHERE t.user_id = u.id ORDER BY t.tag) AS tags
+		FROM users u
+		LEFT JOIN user_profiles p ON u.id = p.user_id
+		%s
+		ORDER BY %s %s NULLS LAST, u.id
+		LIMIT $%d OFFSET $%d
+	`, where, column, direction, len(args)-1, len(args))
+
+	if err := r.db.Select(&page.Users, query, args...); err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// claimUpsert takes over the claim on a user unless another admin holds it and it has not
+// expired yet. The selected users are given by the query it is appended to.
+const claimUpsert = `
+	ON CONFLICT (user_id) DO UPDATE
+	SET admin_id = EXCLUDED.admin_id, claimed_at = EXCLUDED.claimed_at, expires_at = EXCLUDED.expires_at
+	WHERE moderation_claims.expires_at <= NOW() OR moderation_claims.admin_id = EXCLUDED.admin_id
+	RETURNING user_id, admin_id, claimed_at, expires_at
+`
+
+// ClaimNext claims the longest waiting pending user that is not claimed by another admin.
+// Concurrent callers get different users. It returns nil if there is no such user.
+func (r *sqlxRepository) ClaimNext(adminID string, lease time.Duration) (*Claim, error) {
+	var claim Claim
+	err := r.db.Get(&claim, `
+		WITH next AS (
+			SELECT u.id
+			FROM users u
+			WHERE u.status = $3 AND NOT EXISTS (
+				SELECT 1 FROM moderation_claims c
+				WHERE c.user_id = u.id AND c.expires_at > NOW() AND c.admin_id <> $1
+			)
+			ORDER BY u.created_at, u.id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		INSERT INTO moderation_claims (user_id, admin_id, claimed_at, expires_at)
+		SELECT id, $1, NOW(), NOW() + make_interval(secs => $2) FROM next
+	`+claimUpsert, adminID, lease.Seconds(), domainuser.StatusPending)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &claim, nil
+}
+
+// ClaimUser claims a pending user or renews the caller's claim. It returns nil if the user is
+// not pending or another admin holds the claim.
+func (r *sqlxRepository) ClaimUser(userID, adminID string, lease time.Duration) (*Claim, error) {
+	var claim Claim
+	err := r.db.Get(&claim, `
+		INSERT INTO moderation_claims (user_id, admin_id, claimed_at, expires_at)
+		SELECT u.id, $2, NOW(), NOW() + make_interval(secs => $3)
+		FROM users u
+		WHERE u.id = $1 AND u.status = $4
+	`+claimUpsert, userID, adminID, lease.Seconds(), domainuser.StatusPending)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &claim, nil
+}
+
+// ReleaseClaim gives up the admin's claim on a user. It returns false if the admin held none.
+func (r *sqlxRepository) ReleaseClaim(userID, adminID string) (bool, error) {
+	res, err := r.db.Exec(`DELETE FROM moderation_claims WHERE user_id = $1 AND admin_id = $2`, userID, adminID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// settleClaim removes the moderation claim on the user of a status change. It fails with
+// ErrClaimedByOther if another admin holds a claim that has not expired. Changes the
+// application makes on its own ignore claims.
+func settleClaim(tx *sqlx.Tx, change *domainuser.StatusChange) error {
+	if change.ActorID != nil {
+		var claimed bool
+		if err := tx.Get(&claimed, `
+			SELECT EXISTS (
+				SELECT 1 FROM moderation_claims
+				WHERE user_id = $1 AND expires_at > NOW() AND admin_id <> $2
+				FOR UPDATE
+			)
+		`, change.UserID, *change.ActorID); err != nil {
+			return err
+		}
+		if claimed {
+			return ErrClaimedByOther
+		}
+	}
+
+	_, err := tx.Exec(`DELETE FROM moderation_claims WHERE user_id = $1`, change.UserID)
 	return err
 }
 
-// SetUserRejected updates a user's status to 'rejected'.
-func (r *sqlxRepository) SetUserRejected(userID string) error {
-	_, err := r.db.Exec(`
-		UPDATE users
-		SET status = 'rejected'
+// escapeLike escapes the wildcard characters of a LIKE pattern.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// ListAuditEntries returns one page of the audit log matching the filter and the total number of matches.
+func (r *sqlxRepository) ListAuditEntries(filter AuditFilter) (*AuditPage, error) {
+	var conditions []string
+	var args []any
+	add := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, strings.ReplaceAll(condition, "?", fmt.Sprintf("$%d", len(args))))
+	}
+
+	if filter.ActorID != "" {
+		add("actor_id = ?", filter.ActorID)
+	}
+	if filter.Action != "" {
+		add("action = ?", filter.Action)
+	}
+	if filter.TargetType != "" {
+		add("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		add("target_id = ?", filter.TargetID)
+	}
+	if filter.From != nil {
+		add("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		add("created_at < ?", *filter.To)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	page := &AuditPage{Entries: []domainuser.AuditEntry{}}
+	if err := r.db.Get(&page.Total, `SELECT COUNT(*) FROM admin_audit_log `+where, args...); err != nil {
+		return nil, err
+	}
+
+	direction := "ASC"
+	if filter.Descending {
+		direction = "DESC"
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	query := fmt.Sprintf(`
+		SELECT id, actor_id, action, target_type, target_id, before_state, after_state, ip, trace_id, created_at
+		FROM admin_audit_log
+		%s
+		ORDER BY created_at %s, id
+		LIMIT $%d OFFSET $%d
+	`, where, direction, len(args)-1, len(args))
+
+	if err := r.db.Select(&page.Entries, query, args...); err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// GetUserDetail returns the admin view of a user including the profile, or nil if the user does not exist.
+func (r *sqlxRepository) GetUserDetail(userID string) (*UserDetail, error) {
+	var detail UserDetail
+	err := r.db.Get(&detail, `
+		SELECT id, email, role, status, email_confirmed, totp_enabled, locked_until, created_at
+		FROM users
 		WHERE id = $1
 	`, userID)
-	return err
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	detail.Profile, err = r.GetUserProfile(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &detail, nil
 }
 
-// InsertUserRejection stores the rejection reasons for a given user as a JSON object.
-func (r *sqlxRepository) InsertUserRejection(userID string, errors map[string]string) error {
-	jsonData, err := json.Marshal(errors)
+// ListStatusChanges returns the status transitions of a user, newest first.
+func (r *sqlxRepository) ListStatusChanges(userID string) ([]domainuser.StatusChange, error) {
+	changes := []domainuser.StatusChange{}
+	err := r.db.Select(&changes, `
+		SELECT id, user_id, from_status, to_status, transition, actor_id, reason, created_at
+		FROM user_status_transitions
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`, userID)
 	if err != nil {
-		return fmt.Errorf("failed to marshal rejection errors: %w", err)
+		return nil, err
 	}
 
-	_, err = r.db.Exec(`
-		INSERT INTO user_rejections (user_id, errors)
-		VALUES ($1, $2)
-	`, userID, jsonData)
+	return changes, nil
+}
 
-	return err
+// SetUserRole changes the role of a user and signs them out everywhere, so the access
+// of the old role ends immediately. The change is logged as a security event.
+func (r *sqlxRepository) SetUserRole(userID, role string, event domainuser.SecurityEvent, entry domainuser.AuditEntry) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(`UPDATE users SET role = $1 WHERE id = $2`, role, userID); err != nil {
+		return err
+	}
+
+	if err := revokeSessions(tx, userID); err != nil {
+		return err
+	}
+
+	if err := securitylog.Record(tx, event); err != nil {
+		return err
+	}
+
+	if err := auditlog.Record(tx, entry); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-// ListPendingUsers returns a paginated list of users with 'pending' status,
-// optionally filtered by partial match on first or last name.
-func (r *sqlxRepository) ListPendingUsers(search string, limit, offset int) ([]*domainuser.User, error) {
-	query := `
-		SELECT 
-			u.id, 
-			u.email, 
-			u.status,
-			COALESCE(p.first_name, '') AS first_name,
-			COALESCE(p.last_name, '') AS last_name
-		FROM users u
-		LEFT JOIN user_profiles p ON u.id = p.user_id
-		WHERE u.status = 'pending'
-		AND (p.first_name ILIKE '%' || $1 || '%' OR p.last_name ILIKE '%' || $1 || '%')
-		ORDER BY u.created_at DESC
-		LIMIT $2 OFFSET $3
-	`
+// GetOpenBlock returns the suspension or ban that has not been lifted yet, or nil if there is none.
+func (r *sqlxRepository) GetOpenBlock(userID string) (*domainuser.AccountBlock, error) {
+	var block domainuser.AccountBlock
+	err := r.db.Get(&block, `
+		SELECT id, user_id, kind, reason, previous_status, ends_at, created_by, created_at, lifted_at, lifted_by
+		FROM account_blocks
+		WHERE user_id = $1 AND lifted_at IS NULL
+	`, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &block, nil
+}
+
+// BlockUser stores a suspension or ban, replacing a block still in force, applies the status
+// transition, if any, and signs the user out everywhere in one transaction.
+func (r *sqlxRepository) BlockUser(block *domainuser.AccountBlock, change *domainuser.StatusChange, event domainuser.SecurityEvent, entry domainuser.AuditEntry) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(`
+		UPDATE account_blocks SET lifted_at = $2, lifted_by = $3
+		WHERE user_id = $1 AND lifted_at IS NULL
+	`, block.UserID, block.CreatedAt, block.CreatedBy); err != nil {
+		return err
+	}
 
-	type UserWithProfile struct {
-		ID        string `db:"id"`
-		Email     string `db:"email"`
-		Status    string `db:"status"`
-		FirstName string `db:"first_name"`
-		LastName  string `db:"last_name"`
+	if _, err := tx.NamedExec(`
+		INSERT INTO account_blocks (id, user_id, kind, reason, previous_status, ends_at, created_by, created_at)
+		VALUES (:id, :user_id, :kind, :reason, :previous_status, :ends_at, :created_by, :created_at)
+	`, block); err != nil {
+		return err
 	}
 
-	var results []UserWithProfile
-	err := r.db.Select(&results, query, search, limit, offset)
+	if change != nil {
+		if err := userstatus.Apply(tx, change); err != nil {
+			return err
+		}
+	}
+
+	if err := revokeSessions(tx, block.UserID); err != nil {
+		return err
+	}
+
+	if err := securitylog.Record(tx, event); err != nil {
+		return err
+	}
+
+	if err := auditlog.Record(tx, entry); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// LiftBlock ends a block and restores the previous status. It returns domainuser.ErrStatusConflict
+// if the block was lifted in the meantime.
+func (r *sqlxRepository) LiftBlock(block *domainuser.AccountBlock, change *domainuser.StatusChange, event domainuser.SecurityEvent, entry domainuser.AuditEntry) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.Exec(`
+		UPDATE account_blocks SET lifted_at = $2, lifted_by = $3
+		WHERE id = $1 AND lifted_at IS NULL
+	`, block.ID, block.LiftedAt, block.LiftedBy)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return domainuser.ErrStatusConflict
+	}
+
+	if err := userstatus.Apply(tx, change); err != nil {
+		return err
+	}
+
+	if err := securitylog.Record(tx, event); err != nil {
+		return err
+	}
+
+	if err := auditlog.Record(tx, entry); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ListExpiredSuspensions returns the suspensions that ended before now but were not lifted yet.
+func (r *sqlxRepository) ListExpiredSuspensions(now time.Time) ([]domainuser.AccountBlock, error) {
+	blocks := []domainuser.AccountBlock{}
+	err := r.db.Select(&blocks, `
+		SELECT id, user_id, kind, reason, previous_status, ends_at, created_by, created_at, lifted_at, lifted_by
+		FROM account_blocks
+		WHERE lifted_at IS NULL AND ends_at <= $1
+		ORDER BY ends_at
+	`, now)
 	if err != nil {
 		return nil, err
 	}
 
-	var users []*domainuser.User
-	for _, res := range results {
-		users = append(users, &domainuser.User{
-			ID:     res.ID,
-			Email:  res.Email,
-			Status: res.Status,
-			Profile: &domainuser.Profile{
-				FirstName: res.FirstName,
-				LastName:  res.LastName,
-			},
-		})
+	return blocks, nil
+}
+
+// revokeSessions revokes every session and refresh token of a user, so their access
+// tokens are rejected by the JWT middleware and cannot be renewed.
+func revokeSessions(tx *sqlx.Tx, userID string) error {
+	if _, err := tx.Exec(`
+		UPDATE user_sessions SET revoked_at = NOW()
+		WHERE user_id = $1 AND revoked_at IS NULL
+	`, userID); err != nil {
+		return err
 	}
 
-	return users, nil
+	_, err := tx.Exec(`
+		UPDATE refresh_tokens SET revoked_at = NOW()
+		WHERE user_id = $1 AND revoked_at IS NULL
+	`, userID)
+	return err
 }
 
 // GetUserProfile retrieves a user's profile information by their user ID.
@@ -148,3 +634,407 @@ func (r *sqlxRepository) GetUserProfile(userID string) (*domainuser.Profile, err
 
 	return &profile, nil
 }
+
+// ListProfileChanges returns the recorded profile edits of a user, newest first.
+func (r *sqlxRepository) ListProfileChanges(userID string) ([]domainuser.ProfileChange, error) {
+	changes := []domainuser.ProfileChange{}
+	err := r.db.Select(&changes, `
+		SELECT id, user_id, rejection_id, changes, previous_status, new_status, created_at
+		FROM profile_changes
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+// ListRejections returns the rejections of a user, newest first.
+func (r *sqlxRepository) ListRejections(userID string) ([]domainuser.Rejection, error) {
+	rejections := []domainuser.Rejection{}
+	err := r.db.Select(&rejections, `
+		SELECT id, user_id, errors, reason_codes, rejected_by, rejected_at
+		FROM user_rejections
+		WHERE user_id = $1
+		ORDER BY rejected_at DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return rejections, nil
+}
+
+// ListRolePermissions returns the permissions granted to each role.
+func (r *sqlxRepository) ListRolePermissions() (map[string][]string, error) {
+	var rows []struct {
+		Role       string `db:"role"`
+		Permission string `db:"permission"`
+	}
+	if err := r.db.Select(&rows, `
+		SELECT role, permission
+		FROM role_permissions
+		ORDER BY role, permission
+	`); err != nil {
+		return nil, err
+	}
+
+	result := make(map[string][]string)
+	for _, row := range rows {
+		result[row.Role] = append(result[row.Role], row.Permission)
+	}
+	return result, nil
+}
+
+// SetRolePermissions replaces the permissions of a role and records the change in the
+// audit log in a single transaction.
+func (r *sqlxRepository) SetRolePermissions(role string, permissions []string, entry domainuser.AuditEntry) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(`DELETE FROM role_permissions WHERE role = $1`, role); err != nil {
+		return err
+	}
+
+	for _, permission := range permissions {
+		if _, err := tx.Exec(`
+			INSERT INTO role_permissions (role, permission)
+			VALUES ($1, $2)
+		`, role, permission); err != nil {
+			return err
+		}
+	}
+
+	if err := auditlog.Record(tx, entry); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UnlockUser clears the login lockout of a user and records the event in the security log
+// and the audit log, with the lockout as it was before. It returns false if the user does not exist.
+func (r *sqlxRepository) UnlockUser(userID string, event domainuser.SecurityEvent, entry domainuser.AuditEntry) (bool, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var before struct {
+		FailedLoginAttempts int        `db:"failed_login_attempts"`
+		LockedUntil         *time.Time `db:"locked_until"`
+	}
+	err = tx.Get(&before, `
+		UPDATE users u
+		SET failed_login_attempts = 0, locked_until = NULL
+		FROM (SELECT id, failed_login_attempts, locked_until FROM users WHERE id = $1 FOR UPDATE) old
+		WHERE u.id = old.id
+		RETURNING old.failed_login_attempts, old.locked_until
+	`, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	event.UserID = userID
+	if err := securitylog.Record(tx, event); err != nil {
+		return false, err
+	}
+
+	entry.Before = domainuser.AuditState{
+		"failed_login_attempts": before.FailedLoginAttempts,
+		"locked_until":          before.LockedUntil,
+	}
+	if err := auditlog.Record(tx, entry); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// ListRejectionReasons returns the rejection reason catalog ordered by field and code.
+func (r *sqlxRepository) ListRejectionReasons(includeInactive bool) ([]domainuser.RejectionReason, error) {
+	reasons := []domainuser.RejectionReason{}
+	err := r.db.Select(&reasons, `
+		SELECT code, field, text_de, text_en, active, updated_at
+		FROM rejection_reasons
+		WHERE active OR $1
+		ORDER BY field, code
+	`, includeInactive)
+	return reasons, err
+}
+
+// GetRejectionReasons returns the catalog entries with the given codes, active or not.
+func (r *sqlxRepository) GetRejectionReasons(codes []string) ([]domainuser.RejectionReason, error) {
+	reasons := []domainuser.RejectionReason{}
+	err := r.db.Select(&reasons, `
+		SELECT code, field, text_de, text_en, active, updated_at
+		FROM rejection_reasons
+		WHERE code = ANY($1)
+	`, pq.Array(codes))
+	return reasons, err
+}
+
+// SaveRejectionReason creates or updates a catalog entry and records the change in the audit log.
+func (r *sqlxRepository) SaveRejectionReason(reason *domainuser.RejectionReason, entry domainuser.AuditEntry) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := tx.Get(&reason.UpdatedAt, `
+		INSERT INTO rejection_reasons (code, field, text_de, text_en, active)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (code) DO UPDATE
+		SET field = EXCLUDED.field, text_de = EXCLUDED.text_de, text_en = EXCLUDED.text_en,
+		    active = EXCLUDED.active, updated_at = CURRENT_TIMESTAMP
+		RETURNING updated_at
+	`, reason.Code, reason.Field, reason.TextDE, reason.TextEN, reason.Active); err != nil {
+		return err
+	}
+
+	if err := auditlog.Record(tx, entry); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RejectionReasonStats counts how often each catalog reason was given in rejections between
+// from (inclusive) and to (exclusive), most common first. Either bound may be nil.
+func (r *sqlxRepository) RejectionReasonStats(from, to *time.Time) ([]RejectionReasonCount, error) {
+	counts := []RejectionReasonCount{}
+	err := r.db.Select(&counts, `
+		SELECT c.value AS code, c.key AS field, COUNT(*) AS count
+		FROM user_rejections ur
+		CROSS JOIN LATERAL jsonb_each_text(ur.reason_codes) c
+		WHERE jsonb_typeof(ur.reason_codes) = 'object'
+		  AND ($1::timestamp IS NULL OR ur.rejected_at >= $1)
+		  AND ($2::timestamp IS NULL OR ur.rejected_at < $2)
+		GROUP BY c.value, c.key
+		ORDER BY count DESC, code
+	`, from, to)
+	return counts, err
+}
+
+// decisionsInRange selects the approvals and rejections made in a period with the time the
+// user had been waiting since they last became pending.
+const decisionsInRange = `
+	WITH decisions AS (
+		SELECT t.actor_id, t.transition, t.created_at - (
+			SELECT MAX(p.created_at) FROM user_status_transitions p
+			WHERE p.user_id = t.user_id AND p.to_status = 'pending' AND p.created_at <= t.created_at
+		) AS wait
+		FROM user_status_transitions t
+		WHERE t.transition IN ('approve', 'reject') AND t.created_at >= $1 AND t.created_at < $2
+	)
+`
+
+// GetStats computes the moderation dashboard figures from one consistent snapshot.
+func (r *sqlxRepository) GetStats(from, to time.Time) (*Stats, error) {
+	tx, err := r.db.BeginTxx(context.Background(), &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stats := &Stats{
+		From:          from,
+		To:            to,
+		Registrations: []DailyCount{},
+		Backlog:       []BacklogBucket{},
+		Decisions:     []AdminDecisions{},
+	}
+
+	if err := tx.Select(&stats.Registrations, `
+		WITH counts AS (
+			SELECT date_trunc('day', created_at) AS day, COUNT(*) AS count
+			FROM users
+			WHERE created_at >= $1 AND created_at < $2
+			GROUP BY 1
+		)
+		SELECT to_char(d.day, 'YYYY-MM-DD') AS day, COALESCE(c.count, 0) AS count
+		FROM generate_series(date_trunc('day', $1::timestamp), $2::timestamp - interval '1 microsecond', interval '1 day') AS d(day)
+		LEFT JOIN counts c ON c.day = d.day
+		ORDER BY d.day
+	`, from, to); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Get(&stats.Funnel, `
+		SELECT COUNT(*) AS created,
+		       COUNT(*) FILTER (WHERE u.email_confirmed) AS email_confirmed,
+		       COUNT(*) FILTER (WHERE EXISTS (SELECT 1 FROM user_profiles p WHERE p.user_id = u.id)) AS profile_submitted,
+		       COUNT(*) FILTER (WHERE EXISTS (
+		           SELECT 1 FROM user_status_transitions t WHERE t.user_id = u.id AND t.transition = 'approve'
+		       )) AS approved,
+		       COUNT(*) FILTER (WHERE EXISTS (SELECT 1 FROM user_rejections r WHERE r.user_id = u.id)) AS rejected
+		FROM users u
+		WHERE u.created_at >= $1 AND u.created_at < $2
+	`, from, to); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Select(&stats.Backlog, `
+		WITH pending AS (
+			SELECT NOW() - COALESCE((
+				SELECT MAX(t.created_at) FROM user_status_transitions t
+				WHERE t.user_id = u.id AND t.to_status = 'pending'
+			), u.created_at) AS age
+			FROM users u
+			WHERE u.status = 'pending'
+		)
+		SELECT b.age, COUNT(p.age) AS count
+		FROM (VALUES (1, $1), (2, $2), (3, $3), (4, $4)) AS b(ord, age)
+		LEFT JOIN pending p ON b.ord = CASE
+			WHEN p.age < interval '1 day' THEN 1
+			WHEN p.age < interval '3 days' THEN 2
+			WHEN p.age < interval '7 days' THEN 3
+			ELSE 4
+		END
+		GROUP BY b.ord, b.age
+		ORDER BY b.ord
+	`, BacklogUnderOneDay, BacklogOneToThreeDays, BacklogThreeToSevenDays, BacklogOverSevenDays); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Get(&stats.MedianDecisionSeconds, decisionsInRange+`
+		SELECT percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM wait))
+		FROM decisions
+		WHERE wait IS NOT NULL
+	`, from, to); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Select(&stats.Decisions, decisionsInRange+`
+		SELECT d.actor_id AS admin_id, u.email,
+		       COUNT(*) FILTER (WHERE d.transition = 'approve') AS approved,
+		       COUNT(*) FILTER (WHERE d.transition = 'reject') AS rejected
+		FROM decisions d
+		LEFT JOIN users u ON u.id = d.actor_id
+		GROUP BY d.actor_id, u.email
+		ORDER BY COUNT(*) DESC, u.email
+	`, from, to); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// ListNotes returns the notes about a user with their authors, newest first.
+func (r *sqlxRepository) ListNotes(userID string) ([]Note, error) {
+	notes := []Note{}
+	err := r.db.Select(&notes, `
+		SELECT n.id, n.user_id, n.author_id, a.email AS author_email, n.body, n.created_at
+		FROM user_notes n
+		LEFT JOIN users a ON a.id = n.author_id
+		WHERE n.user_id = $1
+		ORDER BY n.created_at DESC, n.id
+	`, userID)
+	return notes, err
+}
+
+// AddNote stores a note and records it in the audit log.
+func (r *sqlxRepository) AddNote(note *Note, entry domainuser.AuditEntry) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := tx.Get(&note.CreatedAt, `
+		INSERT INTO user_notes (id, user_id, author_id, body)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at
+	`, note.ID, note.UserID, note.AuthorID, note.Body); err != nil {
+		return err
+	}
+
+	if err := auditlog.Record(tx, entry); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteNote removes a note about a user and records it in the audit log. It returns false
+// if the user has no such note.
+func (r *sqlxRepository) DeleteNote(userID, noteID string, entry domainuser.AuditEntry) (bool, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.Exec(`DELETE FROM user_notes WHERE id = $1 AND user_id = $2`, noteID, userID)
+	if err != nil {
+		return false, err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return false, err
+	}
+
+	if err := auditlog.Record(tx, entry); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// ListTags returns the tags of a user in alphabetical order.
+func (r *sqlxRepository) ListTags(userID string) ([]string, error) {
+	tags := []string{}
+	err := r.db.Select(&tags, `SELECT tag FROM user_tags WHERE user_id = $1 ORDER BY tag`, userID)
+	return tags, err
+}
+
+// SetTags replaces the tags of a user and records the change in the audit log. Tags the user
+// already had keep their creator and creation time.
+func (r *sqlxRepository) SetTags(userID string, tags []string, createdBy string, entry domainuser.AuditEntry) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(`
+		DELETE FROM user_tags WHERE user_id = $1 AND NOT (tag = ANY($2))
+	`, userID, pq.Array(tags)); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO user_tags (user_id, tag, created_by)
+		SELECT $1, tag, $3 FROM unnest($2::text[]) AS tag
+		ON CONFLICT (user_id, tag) DO NOTHING
+	`, userID, pq.Array(tags), createdBy); err != nil {
+		return err
+	}
+
+	if err := auditlog.Record(tx, entry); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ListAllTags returns every tag in use with the number of users carrying it, most used first.
+func (r *sqlxRepository) ListAllTags() ([]TagCount, error) {
+	tags := []TagCount{}
+	err := r.db.Select(&tags, `
+		SELECT tag, COUNT(*) AS count
+		FROM user_tags
+		GROUP BY tag
+		ORDER BY count DESC, tag
+	`)
+	return tags, err
+}
@@ -4,27 +4,42 @@ import (
 	domainuser "carowebapp/core/internal/domain/user"
 	"carowebapp/core/internal/infrastructure/email"
 	"errors"
+	"fmt"
 	"go.uber.org/zap"
 )
 
 const (
-	errMsgInvalidUserStatus    = "user cannot be approved in current status"
 	errMsgUserNotFound         = "user not found"
+	errMsgUnknownRole          = "unknown role"
+	errMsgUnknownPermission    = "unknown permission"
+	errMsgAdminLockout         = "admins cannot lose the permission to manage roles"
+	errMsgUnknownProfileField  = "rejection reasons must name profile fields"
+	errMsgSelfManagement       = "admins cannot change their own role or status"
+	errMsgUnknownAuditAction   = "unknown audit action"
+	errMsgUnknownAuditTarget   = "audit target must be user, role or rejection_reason"
 	logMsgApprovalEmailFailed  = "failed to send approval email"
 	logMsgRejectionEmailFailed = "failed to send rejection email"
 	logMsgSaveRejectionFailed  = "failed to save rejection reasons"
 )
 
 var (
-	ErrInvalidUserStatus = errors.New(errMsgInvalidUserStatus)
-	ErrUserNotFound      = errors.New(errMsgUserNotFound)
+	ErrUserNotFound        = errors.New(errMsgUserNotFound)
+	ErrUnknownRole         = errors.New(errMsgUnknownRole)
+	ErrUnknownPermission   = errors.New(errMsgUnknownPermission)
+	ErrAdminLockout        = errors.New(errMsgAdminLockout)
+	ErrUnknownProfileField = errors.New(errMsgUnknownProfileField)
+	ErrSelfManagement      = errors.New(errMsgSelfManagement)
+	ErrUnknownAuditAction  = errors.New(errMsgUnknownAuditAction)
+	ErrUnknownAuditTarget  = errors.New(errMsgUnknownAuditTarget)
 )
 
 // Service handles user moderation operations such as approval and rejection.
+// Mails holds the notifications of bulk moderation; it only sends them once its Run is started.
 type Service struct {
 	repo   Repository
 	logger *zap.Logger
 	Sender email.Sender
+	Mails  *email.Queue
 }
 
 // NewService creates a new instance of the Service.
@@ -33,17 +48,18 @@ func NewService(repo Repository, logger *zap.Logger, sender email.Sender) *Servi
 		repo:   repo,
 		logger: logger,
 		Sender: sender,
+		Mails:  email.NewQueue(logger, mailQueueSize),
 	}
 }
 
 // ApproveUser approves a pending user and sends them a confirmation email.
-func (s *Service) ApproveUser(userID string) error {
-	u, err := s.getPendingUserOrError(userID)
+func (s *Service) ApproveUser(actor domainuser.Actor, userID string) error {
+	u, change, err := s.prepareTransition(actor.ID, userID, domainuser.TransitionApprove)
 	if err != nil {
 		return err
 	}
 
-	if err := s.repo.SetUserApproved(userID); err != nil {
+	if err := s.repo.ChangeStatus(change, statusAudit(actor, domainuser.AuditUserApproved, change, nil)); err != nil {
 		return err
 	}
 
@@ -59,26 +75,31 @@ func (s *Service) ApproveUser(userID string) error {
 }
 
 // RejectUser marks a user as rejected, stores the rejection reasons, and sends a notification.
-func (s *Service) RejectUser(userID string, rejectionErrors map[string]string) error {
-	u, err := s.getPendingUserOrError(userID)
+// Reasons are catalog codes keyed by profile field so the user can correct and resubmit exactly
+// those fields. The user sees the texts in their language.
+func (s *Service) RejectUser(actor domainuser.Actor, userID string, reasonCodes map[string]string) error {
+	rej, err := s.resolveRejection(reasonCodes)
 	if err != nil {
 		return err
 	}
 
-	if err := s.repo.InsertUserRejection(userID, rejectionErrors); err != nil {
+	u, change, err := s.prepareTransition(actor.ID, userID, domainuser.TransitionReject)
+	if err != nil {
+		return err
+	}
+
+	codes, texts := rej.codes(), rej.texts(u.Language)
+	entry := statusAudit(actor, domainuser.AuditUserRejected, change, domainuser.AuditState{"reasons": codes})
+	if err := s.repo.RejectUser(change, texts, codes, entry); err != nil {
 		s.logger.Error(logMsgSaveRejectionFailed,
 			zap.String("user_id", userID),
-			zap.Any("errors", rejectionErrors),
+			zap.Any("errors", codes),
 			zap.Error(err),
 		)
 		return err
 	}
 
-	if err := s.repo.SetUserRejected(userID); err != nil {
-		return err
-	}
-
-	if err := s.Sender.SendRejectionNotification(u.Email, rejectionErrors); err != nil {
+	if err := s.Sender.SendRejectionNotification(u.Email, u.Language, texts); err != nil {
 		s.logger.Warn(logMsgRejectionEmailFailed,
 			zap.String("user_id", u.ID),
 			zap.String("email", u.Email),
@@ -89,18 +110,101 @@ func (s *Service) RejectUser(userID string, rejectionErrors map[string]string) e
 	return nil
 }
 
-// ListPendingUsers retrieves users with 'pending' status and supports pagination and optional search.
-func (s *Service) ListPendingUsers(search string, limit, offset int) ([]*domainuser.User, error) {
-	return s.repo.ListPendingUsers(search, limit, offset)
+// ListPendingUsers retrieves users with 'pending' status, newest first, and supports pagination and optional search.
+// Users another admin has claimed are left out.
+func (s *Service) ListPendingUsers(adminID, search string, limit, offset int) (*UserPage, error) {
+	return s.repo.ListUsers(UserFilter{
+		Status:     domainuser.StatusPending,
+		Search:     search,
+		VisibleTo:  adminID,
+		Descending: true,
+		Limit:      limit,
+		Offset:     offset,
+	})
+}
+
+// ListUsers returns a page of users matching the filter.
+func (s *Service) ListUsers(filter UserFilter) (*UserPage, error) {
+	if filter.Status != "" && !domainuser.IsValidStatus(filter.Status) {
+		return nil, domainuser.ErrUnknownStatus
+	}
+	if filter.Role != "" && !domainuser.IsKnownRole(filter.Role) {
+		return nil, ErrUnknownRole
+	}
+	tags, err := normalizeTags(filter.Tags)
+	if err != nil {
+		return nil, err
+	}
+	filter.Tags = tags
+	return s.repo.ListUsers(filter)
+}
+
+// GetUserDetail returns the admin view of a user with their status history and the
+// suspension or ban in force, if any.
+func (s *Service) GetUserDetail(userID string) (*UserDetail, error) {
+	detail, err := s.repo.GetUserDetail(userID)
+	if err != nil {
+		return nil, err
+	}
+	if detail == nil {
+		return nil, ErrUserNotFound
+	}
+
+	detail.StatusHistory, err = s.repo.ListStatusChanges(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	detail.Block, err = s.repo.GetOpenBlock(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return detail, nil
 }
 
-// GetUserProfile returns the user's profile from the repository layer.
-func (s *Service) GetUserProfile(userID string) (*domainuser.Profile, error) {
-	return s.repo.GetUserProfile(userID)
+// SetUserRole assigns another role to a user. The user is signed out on all devices.
+func (s *Service) SetUserRole(actor domainuser.Actor, userID, role string) error {
+	if !domainuser.IsKnownRole(role) {
+		return ErrUnknownRole
+	}
+	if actor.ID == userID {
+		return ErrSelfManagement
+	}
+
+	u, err := s.repo.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if u == nil {
+		return ErrUserNotFound
+	}
+	if u.Role == role {
+		return nil
+	}
+
+	return s.repo.SetUserRole(userID, role, domainuser.SecurityEvent{
+		UserID: userID,
+		Type:   domainuser.SecurityEventRoleChanged,
+		IP:     actor.IP,
+		Details: map[string]any{
+			"admin_id": actor.ID,
+			"from":     u.Role,
+			"to":       role,
+		},
+	}, domainuser.NewAuditEntry(actor, domainuser.AuditUserRoleChanged, domainuser.AuditTargetUser, userID,
+		domainuser.AuditState{"role": u.Role},
+		domainuser.AuditState{"role": role},
+	))
 }
 
-// getPendingUserOrError fetches a user and ensures their status is 'pending'.
-func (s *Service) getPendingUserOrError(userID string) (*User, error) {
+// OverrideStatus sets the status of a user regardless of the regular lifecycle. The
+// target status must still meet its preconditions and the reason is kept in the history.
+func (s *Service) OverrideStatus(actor domainuser.Actor, userID, status, reason string) (*domainuser.StatusChange, error) {
+	if actor.ID == userID {
+		return nil, ErrSelfManagement
+	}
+
 	u, err := s.repo.GetUserByID(userID)
 	if err != nil {
 		return nil, err
@@ -108,8 +212,158 @@ func (s *Service) getPendingUserOrError(userID string) (*User, error) {
 	if u == nil {
 		return nil, ErrUserNotFound
 	}
-	if u.Status != domainuser.StatusPending {
-		return nil, ErrInvalidUserStatus
+
+	change, err := domainuser.OverrideStatus(u.ID, u.Status, status, domainuser.StatusFacts{
+		EmailConfirmed: u.EmailConfirmed,
+		HasProfile:     u.HasProfile,
+	}, actor.ID, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := statusAudit(actor, domainuser.AuditUserStatusOverridden, change, nil)
+	if err := s.repo.ChangeStatus(change, entry); err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+// GetUserProfile returns the user's profile with its rejections, edit history and the
+// internal notes and tags. Users who have not created a profile yet are returned with
+// their notes and tags and a nil profile; unknown users give ErrUserNotFound.
+func (s *Service) GetUserProfile(userID string) (*ProfileDetail, error) {
+	if err := s.requireUser(userID); err != nil {
+		return nil, err
+	}
+
+	profile, err := s.repo.GetUserProfile(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rejections, err := s.repo.ListRejections(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := s.repo.ListProfileChanges(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	notes, err := s.repo.ListNotes(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := s.repo.ListTags(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ProfileDetail{
+		Profile:    profile,
+		HasProfile: profile != nil,
+		Rejections: rejections,
+		Changes:    changes,
+		Notes:      notes,
+		Tags:       tags,
+	}, nil
+}
+
+// ListRolePermissions returns the permissions of every known role, including roles without any.
+func (s *Service) ListRolePermissions() (map[string][]string, error) {
+	mapping, err := s.repo.ListRolePermissions()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string][]string, len(domainuser.Roles))
+	for _, role := range domainuser.Roles {
+		result[role] = mapping[role]
+		if result[role] == nil {
+			result[role] = []string{}
+		}
+	}
+	return result, nil
+}
+
+// SetRolePermissions replaces the permissions granted to a role.
+// Changes reach users with their next token refresh.
+func (s *Service) SetRolePermissions(actor domainuser.Actor, role string, permissions []string) error {
+	if !domainuser.IsKnownRole(role) {
+		return ErrUnknownRole
+	}
+
+	unique := make([]string, 0, len(permissions))
+	seen := make(map[string]bool, len(permissions))
+	for _, permission := range permissions {
+		if !domainuser.IsKnownPermission(permission) {
+			return fmt.Errorf("%w: %s", ErrUnknownPermission, permission)
+		}
+		if !seen[permission] {
+			seen[permission] = true
+			unique = append(unique, permission)
+		}
+	}
+
+	if role == domainuser.RoleAdmin && !seen[domainuser.PermissionRolesManage] {
+		return ErrAdminLockout
+	}
+
+	mapping, err := s.repo.ListRolePermissions()
+	if err != nil {
+		return err
+	}
+	previous := mapping[role]
+	if previous == nil {
+		previous = []string{}
+	}
+
+	return s.repo.SetRolePermissions(role, unique, domainuser.NewAuditEntry(actor, domainuser.AuditRolePermissionsChanged, domainuser.AuditTargetRole, role,
+		domainuser.AuditState{"permissions": previous},
+		domainuser.AuditState{"permissions": unique},
+	))
+}
+
+// UnlockUser lifts the login lockout of a user on behalf of an admin.
+func (s *Service) UnlockUser(actor domainuser.Actor, userID string) error {
+	found, err := s.repo.UnlockUser(userID, domainuser.SecurityEvent{
+		Type: domainuser.SecurityEventAccountUnlocked,
+		IP:   actor.IP,
+		Details: map[string]any{
+			"method":   "admin",
+			"admin_id": actor.ID,
+		},
+	}, domainuser.NewAuditEntry(actor, domainuser.AuditUserUnlocked, domainuser.AuditTargetUser, userID, nil,
+		domainuser.AuditState{"failed_login_attempts": 0, "locked_until": nil},
+	))
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// prepareTransition fetches a user and checks that the admin may apply the transition
+// in the user's current status.
+func (s *Service) prepareTransition(adminID, userID string, transition domainuser.Transition) (*User, *domainuser.StatusChange, error) {
+	u, err := s.repo.GetUserByID(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if u == nil {
+		return nil, nil, ErrUserNotFound
+	}
+
+	change, err := domainuser.ChangeStatus(u.ID, u.Status, transition, domainuser.StatusFacts{
+		EmailConfirmed: u.EmailConfirmed,
+		HasProfile:     u.HasProfile,
+	}, adminID)
+	if err != nil {
+		return nil, nil, err
 	}
-	return u, nil
+	return u, change, nil
 }
@@ -0,0 +1,31 @@
+package admin
+
+import (
+	domainuser "carowebapp/core/internal/domain/user"
+)
+
+// ListAuditEntries returns a page of the admin audit log matching the filter.
+func (s *Service) ListAuditEntries(filter AuditFilter) (*AuditPage, error) {
+	if filter.Action != "" && !domainuser.IsAuditAction(filter.Action) {
+		return nil, ErrUnknownAuditAction
+	}
+	switch filter.TargetType {
+	case "", domainuser.AuditTargetUser, domainuser.AuditTargetRole, domainuser.AuditTargetRejectionReason,
+		domainuser.AuditTargetDeletion:
+	default:
+		return nil, ErrUnknownAuditTarget
+	}
+	return s.repo.ListAuditEntries(filter)
+}
+
+// statusAudit builds the audit entry of a status change. Extra values are added to the
+// state after the change.
+func statusAudit(actor domainuser.Actor, action string, change *domainuser.StatusChange, extra domainuser.AuditState) domainuser.AuditEntry {
+	after := domainuser.AuditState{"status": change.To}
+	for key, value := range extra {
+		after[key] = value
+	}
+	return domainuser.NewAuditEntry(actor, action, domainuser.AuditTargetUser, change.UserID,
+		domainuser.AuditState{"status": change.From}, after,
+	)
+}
@@ -0,0 +1,260 @@
+package admin
+
+import (
+	"context"
+
+	domainuser "carowebapp/core/internal/domain/user"
+
+	"errors"
+
+	"github.com/google/uuid"
+
+	"go.uber.org/zap"
+
+	"time"
+)
+
+const (
+	logMsgBlockEmailFailed   = "failed to send account block email"
+	logMsgReactivationFailed = "failed to reactivate user after suspension"
+)
+
+var (
+	ErrNotBlocked           = errors.New("user is neither suspended nor banned")
+	ErrInvalidSuspensionEnd = errors.New("suspension must end in the future")
+)
+
+// SuspendUser blocks a user until the given time. The user is signed out on all devices
+// and notified by email. An existing suspension is replaced.
+func (s *Service) SuspendUser(actor domainuser.Actor, userID, reason string, until time.Time) (*domainuser.AccountBlock, error) {
+	if !until.After(time.Now()) {
+		return nil, ErrInvalidSuspensionEnd
+	}
+
+	u, block, err := s.blockUser(actor, userID, domainuser.BlockSuspension, reason, &until)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Sender.SendAccountSuspended(u.Email, reason, until); err != nil {
+		s.logger.Warn(logMsgBlockEmailFailed, zap.String("user_id", u.ID), zap.String("kind", block.Kind), zap.Error(err))
+	}
+	return block, nil
+}
+
+// BanUser blocks a user permanently. The user is signed out on all devices and notified by email.
+func (s *Service) BanUser(actor domainuser.Actor, userID, reason string) (*domainuser.AccountBlock, error) {
+	u, block, err := s.blockUser(actor, userID, domainuser.BlockBan, reason, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Sender.SendAccountBanned(u.Email, reason); err != nil {
+		s.logger.Warn(logMsgBlockEmailFailed, zap.String("user_id", u.ID), zap.String("kind", block.Kind), zap.Error(err))
+	}
+	return block, nil
+}
+
+// ReactivateUser lifts the suspension or ban of a user and restores their previous status.
+func (s *Service) ReactivateUser(actor domainuser.Actor, userID string) (*domainuser.StatusChange, error) {
+	if actor.ID == userID {
+		return nil, ErrSelfManagement
+	}
+
+	u, err := s.repo.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return nil, ErrUserNotFound
+	}
+
+	block, err := s.repo.GetOpenBlock(userID)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, ErrNotBlocked
+	}
+
+	return s.liftBlock(u, block, actor)
+}
+
+// ReactivateExpired lifts every suspension whose end has passed and returns how many users
+// were reactivated.
+func (s *Service) ReactivateExpired() (int, error) {
+	blocks, err := s.repo.ListExpiredSuspensions(time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	reactivated := 0
+	for i := range blocks {
+		block := &blocks[i]
+		u, err := s.repo.GetUserByID(block.UserID)
+		if err == nil && u == nil {
+			err = ErrUserNotFound
+		}
+		if err == nil {
+			_, err = s.liftBlock(u, block, domainuser.Actor{})
+		}
+		if err != nil {
+			s.logger.Error(logMsgReactivationFailed, zap.String("block_id", block.ID), zap.String("user_id", block.UserID), zap.Error(err))
+			continue
+		}
+		reactivated++
+	}
+	return reactivated, nil
+}
+
+// RunReactivator calls ReactivateExpired every interval until the context is cancelled.
+func (s *Service) RunReactivator(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if reactivated, err := s.ReactivateExpired(); err != nil {
+			s.logger.Error("failed to list expired suspensions", zap.Error(err))
+		} else if reactivated > 0 {
+			s.logger.Info("reactivated users after suspension", zap.Int("count", reactivated))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// blockUser stores a suspension or ban for the user. Blocking a suspended user again keeps
+// the status from before the first block, so reactivation restores it.
+func (s *Service) blockUser(actor domainuser.Actor, userID, kind, reason string, until *time.Time) (*User, *domainuser.AccountBlock, error) {
+	if actor.ID == userID {
+		return nil, nil, ErrSelfManagement
+	}
+
+	u, err := s.repo.GetUserByID(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if u == nil {
+		return nil, nil, ErrUserNotFound
+	}
+
+	before := domainuser.AuditState{"status": u.Status}
+	previous := u.Status
+	if domainuser.IsBlockedStatus(u.Status) {
+		open, err := s.repo.GetOpenBlock(userID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if open == nil {
+			return nil, nil, domainuser.ErrStatusConflict
+		}
+		previous = open.PreviousStatus
+		before["block"] = blockState(open)
+	}
+
+	transition, eventType, action := domainuser.TransitionSuspend, domainuser.SecurityEventAccountSuspended, domainuser.AuditUserSuspended
+	if kind == domainuser.BlockBan {
+		transition, eventType, action = domainuser.TransitionBan, domainuser.SecurityEventAccountBanned, domainuser.AuditUserBanned
+	}
+
+	// Suspending a suspended user only moves the end of the suspension, the status stays.
+	var change *domainuser.StatusChange
+	if u.Status != domainuser.StatusSuspended || kind != domainuser.BlockSuspension {
+		change, err = domainuser.ChangeStatus(u.ID, u.Status, transition, domainuser.StatusFacts{
+			EmailConfirmed: u.EmailConfirmed,
+			HasProfile:     u.HasProfile,
+		}, actor.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		change.Reason = &reason
+	}
+
+	block := &domainuser.AccountBlock{
+		ID:             uuid.New().String(),
+		UserID:         u.ID,
+		Kind:           kind,
+		Reason:         reason,
+		PreviousStatus: previous,
+		EndsAt:         until,
+		CreatedBy:      &actor.ID,
+		CreatedAt:      time.Now(),
+	}
+
+	details := map[string]any{
+		"admin_id": actor.ID,
+		"block_id": block.ID,
+		"reason":   reason,
+	}
+	if until != nil {
+		details["until"] = until.UTC().Format(time.RFC3339)
+	}
+
+	status := u.Status
+	if change != nil {
+		status = change.To
+	}
+	entry := domainuser.NewAuditEntry(actor, action, domainuser.AuditTargetUser, u.ID, before,
+		domainuser.AuditState{"status": status, "block": blockState(block)},
+	)
+
+	if err := s.repo.BlockUser(block, change, domainuser.SecurityEvent{
+		UserID:  u.ID,
+		Type:    eventType,
+		IP:      actor.IP,
+		Details: details,
+	}, entry); err != nil {
+		return nil, nil, err
+	}
+	return u, block, nil
+}
+
+// liftBlock ends the block and restores the previous status. An actor without ID means the
+// suspension ran out.
+func (s *Service) liftBlock(u *User, block *domainuser.AccountBlock, actor domainuser.Actor) (*domainuser.StatusChange, error) {
+	change, err := domainuser.Reactivate(u.ID, u.Status, block.PreviousStatus, actor.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := domainuser.NewAuditEntry(actor, domainuser.AuditUserReactivated, domainuser.AuditTargetUser, u.ID,
+		domainuser.AuditState{"status": change.From, "block": blockState(block)},
+		domainuser.AuditState{"status": change.To},
+	)
+
+	now := time.Now()
+	block.LiftedAt = &now
+	details := map[string]any{"block_id": block.ID, "method": "expired"}
+	if actor.ID != "" {
+		block.LiftedBy = &actor.ID
+		details["method"] = "admin"
+		details["admin_id"] = actor.ID
+	}
+
+	if err := s.repo.LiftBlock(block, change, domainuser.SecurityEvent{
+		UserID:  u.ID,
+		Type:    domainuser.SecurityEventAccountReactivated,
+		IP:      actor.IP,
+		Details: details,
+	}, entry); err != nil {
+		return nil, err
+	}
+
+	if err := s.Sender.SendAccountReactivated(u.Email); err != nil {
+		s.logger.Warn(logMsgBlockEmailFailed, zap.String("user_id", u.ID), zap.String("kind", "reactivation"), zap.Error(err))
+	}
+	return change, nil
+}
+
+// blockState is the audit log view of a block.
+func blockState(block *domainuser.AccountBlock) map[string]any {
+	return map[string]any{
+		"id":      block.ID,
+		"kind":    block.Kind,
+		"ends_at": block.EndsAt,
+	}
+}
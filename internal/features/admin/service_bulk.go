@@ -0,0 +1,165 @@
+package admin
+
+import (
+	domainuser "carowebapp/core/internal/domain/user"
+
+	"errors"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// BulkMaxUsers is the most users a single bulk request may name.
+	BulkMaxUsers = 500
+	// bulkBatchSize is how many users are loaded and stored per database round trip.
+	bulkBatchSize = 50
+	// mailQueueSize is how many notification emails bulk moderation may have waiting.
+	mailQueueSize = 2000
+
+	logMsgMailQueueFull = "mail queue full, notification dropped"
+)
+
+var ErrTooManyUsers = errors.New("too many users in one bulk request")
+
+// BulkResult is the outcome of a bulk moderation for one user. In a dry run, OK reports
+// whether the change would be applied and Status is the status the user would get.
+type BulkResult struct {
+	UserID      string `json:"user_id"`
+	OK          bool   `json:"ok"`
+	Status      string `json:"status,omitempty"`
+	Error       string `json:"error,omitempty"`
+	EmailQueued bool   `json:"email_queued,omitempty"`
+}
+
+// BulkApprove approves pending users in batches and queues their approval emails.
+// Results are in the order of the IDs; duplicates are handled once.
+func (s *Service) BulkApprove(actor domainuser.Actor, userIDs []string, dryRun bool) ([]BulkResult, error) {
+	return s.bulkModerate(actor, userIDs, domainuser.TransitionApprove, nil, dryRun)
+}
+
+// BulkReject rejects pending users with the same catalog reasons in batches and queues their
+// rejection emails, each in the user's language.
+func (s *Service) BulkReject(actor domainuser.Actor, userIDs []string, reasonCodes map[string]string, dryRun bool) ([]BulkResult, error) {
+	rej, err := s.resolveRejection(reasonCodes)
+	if err != nil {
+		return nil, err
+	}
+	return s.bulkModerate(actor, userIDs, domainuser.TransitionReject, rej, dryRun)
+}
+
+// bulkModerate applies the transition to every user that is pending and meets its guards.
+// Users that fail are reported in their result and do not stop the others.
+func (s *Service) bulkModerate(actor domainuser.Actor, userIDs []string, transition domainuser.Transition, rej rejection, dryRun bool) ([]BulkResult, error) {
+	userIDs = uniqueIDs(userIDs)
+	if len(userIDs) > BulkMaxUsers {
+		return nil, ErrTooManyUsers
+	}
+
+	action := domainuser.AuditUserApproved
+	if transition == domainuser.TransitionReject {
+		action = domainuser.AuditUserRejected
+	}
+
+	results := make([]BulkResult, 0, len(userIDs))
+	for start := 0; start < len(userIDs); start += bulkBatchSize {
+		batch := userIDs[start:min(start+bulkBatchSize, len(userIDs))]
+
+		found, err := s.repo.GetUsersByIDs(batch)
+		if err != nil {
+			return nil, err
+		}
+		users := make(map[string]*User, len(found))
+		for i := range found {
+			users[found[i].ID] = &found[i]
+		}
+
+		batchResults := make([]BulkResult, len(batch))
+		var decisions []Decision
+		var decided []int
+		for i, userID := range batch {
+			result := &batchResults[i]
+			result.UserID = userID
+
+			change, err := bulkChange(actor, users[userID], transition)
+			if err != nil {
+				result.Error = err.Error()
+				continue
+			}
+			result.OK, result.Status = true, change.To
+			if dryRun {
+				continue
+			}
+
+			decision := Decision{Change: change}
+			extra := domainuser.AuditState{"bulk": true}
+			if rej != nil {
+				decision.Reasons, decision.Codes = rej.texts(users[userID].Language), rej.codes()
+				extra["reasons"] = decision.Codes
+			}
+			decision.Entry = statusAudit(actor, action, change, extra)
+			decisions = append(decisions, decision)
+			decided = append(decided, i)
+		}
+
+		if len(decisions) > 0 {
+			errs, err := s.repo.ApplyDecisions(decisions)
+			if err != nil {
+				return nil, err
+			}
+			for j, i := range decided {
+				result := &batchResults[i]
+				if errs[j] != nil {
+					result.OK, result.Status, result.Error = false, "", errs[j].Error()
+					continue
+				}
+				result.EmailQueued = s.queueDecisionEmail(users[result.UserID], decisions[j].Reasons)
+			}
+		}
+
+		results = append(results, batchResults...)
+	}
+
+	return results, nil
+}
+
+// bulkChange checks that the user exists and is pending, then prepares the transition.
+func bulkChange(actor domainuser.Actor, u *User, transition domainuser.Transition) (*domainuser.StatusChange, error) {
+	if u == nil {
+		return nil, ErrUserNotFound
+	}
+	if u.Status != domainuser.StatusPending {
+		return nil, ErrNotPending
+	}
+	return domainuser.ChangeStatus(u.ID, u.Status, transition, domainuser.StatusFacts{
+		EmailConfirmed: u.EmailConfirmed,
+		HasProfile:     u.HasProfile,
+	}, actor.ID)
+}
+
+// queueDecisionEmail queues the approval email, or the rejection email if there are reasons.
+func (s *Service) queueDecisionEmail(u *User, reasons map[string]string) bool {
+	to := u.Email
+	kind, send := "approval", func() error { return s.Sender.SendApprovalNotification(to) }
+	if reasons != nil {
+		kind, send = "rejection", func() error { return s.Sender.SendRejectionNotification(to, u.Language, reasons) }
+	}
+
+	if !s.Mails.Enqueue(to, kind, send) {
+		s.logger.Warn(logMsgMailQueueFull, zap.String("email", to), zap.String("kind", kind))
+		return false
+	}
+	return true
+}
+
+// uniqueIDs drops repeated IDs and keeps the first occurrence.
+func uniqueIDs(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	unique := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,75 @@
+package admin
+
+import (
+	domainuser "carowebapp/core/internal/domain/user"
+
+	"errors"
+
+	"time"
+)
+
+// ClaimLease is how long a moderation claim keeps a pending user away from other admins.
+const ClaimLease = 15 * time.Minute
+
+var (
+	ErrNotPending     = errors.New("user is not pending")
+	ErrClaimedByOther = errors.New("user is claimed by another admin")
+	ErrQueueEmpty     = errors.New("no pending users to claim")
+	ErrNotClaimed     = errors.New("user is not claimed by this admin")
+)
+
+// ClaimNext claims the longest waiting pending user for the admin and returns the claim
+// together with the user's details.
+func (s *Service) ClaimNext(actor domainuser.Actor) (*ModerationCase, error) {
+	claim, err := s.repo.ClaimNext(actor.ID, ClaimLease)
+	if err != nil {
+		return nil, err
+	}
+	if claim == nil {
+		return nil, ErrQueueEmpty
+	}
+
+	detail, err := s.GetUserDetail(claim.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return &ModerationCase{Claim: claim, User: detail}, nil
+}
+
+// ClaimUser claims a specific pending user for the admin. Claiming a user the admin already
+// holds renews the lease.
+func (s *Service) ClaimUser(actor domainuser.Actor, userID string) (*Claim, error) {
+	u, err := s.repo.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return nil, ErrUserNotFound
+	}
+	if u.Status != domainuser.StatusPending {
+		return nil, ErrNotPending
+	}
+
+	claim, err := s.repo.ClaimUser(userID, actor.ID, ClaimLease)
+	if err != nil {
+		return nil, err
+	}
+	if claim == nil {
+		// The user may also have left the pending state in the meantime, but the claim
+		// is the usual reason.
+		return nil, ErrClaimedByOther
+	}
+	return claim, nil
+}
+
+// ReleaseClaim hands a claimed user back to the queue.
+func (s *Service) ReleaseClaim(actor domainuser.Actor, userID string) error {
+	released, err := s.repo.ReleaseClaim(userID, actor.ID)
+	if err != nil {
+		return err
+	}
+	if !released {
+		return ErrNotClaimed
+	}
+	return nil
+}
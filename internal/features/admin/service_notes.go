@@ -0,0 +1,136 @@
+package admin
+
+import (
+	domainuser "carowebapp/core/internal/domain/user"
+
+	"errors"
+
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"sort"
+
+	"strings"
+)
+
+// Limits of the tags on a user.
+const (
+	MaxTagsPerUser = 20
+	MaxTagLength   = 50
+)
+
+var (
+	ErrNoteNotFound = errors.New("note not found")
+	ErrInvalidTag   = errors.New("tags must be between 1 and 50 characters")
+	ErrTooManyTags  = errors.New("a user can have at most 20 tags")
+)
+
+// ListNotes returns the notes about a user, newest first.
+func (s *Service) ListNotes(userID string) ([]Note, error) {
+	if err := s.requireUser(userID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListNotes(userID)
+}
+
+// AddNote stores a note about a user written by the admin. The audit log records that a
+// note was added but not what it says.
+func (s *Service) AddNote(actor domainuser.Actor, userID, body string) (*Note, error) {
+	if err := s.requireUser(userID); err != nil {
+		return nil, err
+	}
+
+	author := actor.ID
+	note := &Note{
+		ID:       uuid.New().String(),
+		UserID:   userID,
+		AuthorID: &author,
+		Body:     body,
+	}
+	entry := domainuser.NewAuditEntry(actor, domainuser.AuditUserNoteAdded, domainuser.AuditTargetUser, userID,
+		nil, domainuser.AuditState{"note_id": note.ID},
+	)
+	if err := s.repo.AddNote(note, entry); err != nil {
+		return nil, err
+	}
+	return note, nil
+}
+
+// DeleteNote removes a note about a user.
+func (s *Service) DeleteNote(actor domainuser.Actor, userID, noteID string) error {
+	entry := domainuser.NewAuditEntry(actor, domainuser.AuditUserNoteDeleted, domainuser.AuditTargetUser, userID,
+		domainuser.AuditState{"note_id": noteID}, nil,
+	)
+	deleted, err := s.repo.DeleteNote(userID, noteID, entry)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrNoteNotFound
+	}
+	return nil
+}
+
+// SetTags replaces the tags of a user. Tags are trimmed and lowercased, and duplicates are dropped.
+func (s *Service) SetTags(actor domainuser.Actor, userID string, tags []string) ([]string, error) {
+	tags, err := normalizeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+	if len(tags) > MaxTagsPerUser {
+		return nil, ErrTooManyTags
+	}
+	if err := s.requireUser(userID); err != nil {
+		return nil, err
+	}
+
+	previous, err := s.repo.ListTags(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := domainuser.NewAuditEntry(actor, domainuser.AuditUserTagsChanged, domainuser.AuditTargetUser, userID,
+		domainuser.AuditState{"tag_count": len(previous)},
+		domainuser.AuditState{"tag_count": len(tags)},
+	)
+	if err := s.repo.SetTags(userID, tags, actor.ID, entry); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// ListAllTags returns every tag in use with the number of users carrying it.
+func (s *Service) ListAllTags() ([]TagCount, error) {
+	return s.repo.ListAllTags()
+}
+
+// requireUser returns ErrUserNotFound if the user does not exist.
+func (s *Service) requireUser(userID string) error {
+	u, err := s.repo.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if u == nil {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// normalizeTags trims and lowercases the tags, drops duplicates and sorts them.
+func normalizeTags(tags []string) ([]string, error) {
+	seen := make(map[string]bool, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || len([]rune(tag)) > MaxTagLength {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidTag, tag)
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
@@ -0,0 +1,138 @@
+package admin
+
+import (
+	domainuser "carowebapp/core/internal/domain/user"
+
+	"errors"
+
+	"fmt"
+
+	"regexp"
+
+	"time"
+)
+
+var (
+	ErrUnknownRejectionReason = errors.New("unknown or inactive rejection reason")
+	ErrReasonFieldMismatch    = errors.New("rejection reason does not apply to this profile field")
+	ErrInvalidReasonCode      = errors.New("reason codes consist of lowercase letters, digits and underscores")
+	ErrNoRejectionReasons     = errors.New("a rejection needs at least one reason")
+)
+
+var reasonCodePattern = regexp.MustCompile(`^[a-z0-9_]{1,64}$`)
+
+// rejection maps the rejected profile fields to the catalog reason given for each.
+type rejection map[string]domainuser.RejectionReason
+
+// codes returns the catalog codes keyed by profile field.
+func (r rejection) codes() map[string]string {
+	codes := make(map[string]string, len(r))
+	for field, reason := range r {
+		codes[field] = reason.Code
+	}
+	return codes
+}
+
+// texts returns the reasons in the language keyed by profile field.
+func (r rejection) texts(language string) map[string]string {
+	texts := make(map[string]string, len(r))
+	for field, reason := range r {
+		texts[field] = reason.Text(language)
+	}
+	return texts
+}
+
+// resolveRejection looks up the catalog reasons for a rejection given as reason codes keyed
+// by profile field, in snake_case or camelCase. Every code must be active and meant for its field.
+func (s *Service) resolveRejection(codes map[string]string) (rejection, error) {
+	// Without a field the user has nothing to correct and could never resubmit.
+	if len(codes) == 0 {
+		return nil, ErrNoRejectionReasons
+	}
+
+	byField := make(map[string]string, len(codes))
+	for key, code := range codes {
+		field, ok := domainuser.ProfileFieldName(key)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownProfileField, key)
+		}
+		byField[field] = code
+	}
+
+	lookup := make([]string, 0, len(byField))
+	for _, code := range byField {
+		lookup = append(lookup, code)
+	}
+	found, err := s.repo.GetRejectionReasons(lookup)
+	if err != nil {
+		return nil, err
+	}
+	catalog := make(map[string]domainuser.RejectionReason, len(found))
+	for _, reason := range found {
+		catalog[reason.Code] = reason
+	}
+
+	resolved := make(rejection, len(byField))
+	for field, code := range byField {
+		reason, ok := catalog[code]
+		if !ok || !reason.Active {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownRejectionReason, code)
+		}
+		if reason.Field != field {
+			return nil, fmt.Errorf("%w: %s is for %s, not %s", ErrReasonFieldMismatch, code, reason.Field, field)
+		}
+		resolved[field] = reason
+	}
+	return resolved, nil
+}
+
+// ListRejectionReasons returns the rejection reason catalog, optionally with retired reasons.
+func (s *Service) ListRejectionReasons(includeInactive bool) ([]domainuser.RejectionReason, error) {
+	return s.repo.ListRejectionReasons(includeInactive)
+}
+
+// SaveRejectionReason adds a reason to the catalog or changes an existing one. Reasons are
+// retired by saving them inactive, so past rejections keep their codes.
+func (s *Service) SaveRejectionReason(actor domainuser.Actor, reason domainuser.RejectionReason) (*domainuser.RejectionReason, error) {
+	if !reasonCodePattern.MatchString(reason.Code) {
+		return nil, ErrInvalidReasonCode
+	}
+	field, ok := domainuser.ProfileFieldName(reason.Field)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProfileField, reason.Field)
+	}
+	reason.Field = field
+
+	existing, err := s.repo.GetRejectionReasons([]string{reason.Code})
+	if err != nil {
+		return nil, err
+	}
+	var before domainuser.AuditState
+	if len(existing) > 0 {
+		before = rejectionReasonState(existing[0])
+	}
+
+	entry := domainuser.NewAuditEntry(actor, domainuser.AuditRejectionReasonSaved, domainuser.AuditTargetRejectionReason,
+		reason.Code, before, rejectionReasonState(reason),
+	)
+	if err := s.repo.SaveRejectionReason(&reason, entry); err != nil {
+		return nil, err
+	}
+	return &reason, nil
+}
+
+// RejectionReasonStats returns how often each catalog reason was given, most common first.
+// Rejections made before the catalog existed are not counted.
+func (s *Service) RejectionReasonStats(from, to *time.Time) ([]RejectionReasonCount, error) {
+	return s.repo.RejectionReasonStats(from, to)
+}
+
+// rejectionReasonState is the audit log view of a catalog entry.
+func rejectionReasonState(reason domainuser.RejectionReason) domainuser.AuditState {
+	return domainuser.AuditState{
+		"field":   reason.Field,
+		"text_de": reason.TextDE,
+		"text_en": reason.TextEN,
+		"active":  reason.Active,
+	}
+}
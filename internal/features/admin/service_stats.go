@@ -0,0 +1,41 @@
+package admin
+
+import (
+	"errors"
+
+	"time"
+)
+
+const (
+	// DefaultStatsDays is the length of the statistics period if none is given.
+	DefaultStatsDays = 30
+	// maxStatsDays limits the period so the daily series stays small.
+	maxStatsDays = 366
+)
+
+var ErrInvalidStatsRange = errors.New("stats period must start before it ends and span at most 366 days")
+
+// GetStats returns the moderation dashboard figures for the period from from (inclusive) to
+// to (exclusive). Without to, the period ends with today; without from, it spans
+// DefaultStatsDays days.
+func (s *Service) GetStats(from, to *time.Time) (*Stats, error) {
+	end := startOfDay(time.Now()).AddDate(0, 0, 1)
+	if to != nil {
+		end = *to
+	}
+	start := end.AddDate(0, 0, -DefaultStatsDays)
+	if from != nil {
+		start = *from
+	}
+
+	if !start.Before(end) || end.Sub(start) > maxStatsDays*24*time.Hour {
+		return nil, ErrInvalidStatsRange
+	}
+	return s.repo.GetStats(start, end)
+}
+
+// startOfDay returns midnight of the day t falls on, in t's location.
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
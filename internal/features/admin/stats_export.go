@@ -0,0 +1,61 @@
+package admin
+
+import (
+	"encoding/csv"
+
+	"io"
+
+	"strconv"
+
+	"time"
+)
+
+// WriteStatsCSV writes the statistics as CSV with one figure per row under the header
+// section, key, value, so every section fits into a single sheet.
+func WriteStatsCSV(w io.Writer, stats *Stats) error {
+	rows := [][]string{
+		{"section", "key", "value"},
+		{"period", "from", stats.From.Format(time.RFC3339)},
+		{"period", "to", stats.To.Format(time.RFC3339)},
+	}
+
+	for _, day := range stats.Registrations {
+		rows = append(rows, []string{"registrations", day.Day, strconv.Itoa(day.Count)})
+	}
+
+	funnel := stats.Funnel
+	rows = append(rows,
+		[]string{"funnel", "created", strconv.Itoa(funnel.Created)},
+		[]string{"funnel", "email_confirmed", strconv.Itoa(funnel.EmailConfirmed)},
+		[]string{"funnel", "profile_submitted", strconv.Itoa(funnel.ProfileSubmitted)},
+		[]string{"funnel", "approved", strconv.Itoa(funnel.Approved)},
+		[]string{"funnel", "rejected", strconv.Itoa(funnel.Rejected)},
+	)
+
+	for _, bucket := range stats.Backlog {
+		rows = append(rows, []string{"backlog", bucket.Age, strconv.Itoa(bucket.Count)})
+	}
+
+	median := ""
+	if stats.MedianDecisionSeconds != nil {
+		median = strconv.FormatFloat(*stats.MedianDecisionSeconds, 'f', 0, 64)
+	}
+	rows = append(rows, []string{"decision_time", "median_seconds", median})
+
+	for _, d := range stats.Decisions {
+		name := "deleted"
+		if d.Email != nil {
+			name = *d.Email
+		}
+		rows = append(rows,
+			[]string{"decisions_approved", name, strconv.Itoa(d.Approved)},
+			[]string{"decisions_rejected", name, strconv.Itoa(d.Rejected)},
+		)
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+	return writer.Error()
+}
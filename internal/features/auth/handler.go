@@ -1,10 +1,14 @@
 package auth
 
 import (
+	domainuser "carowebapp/core/internal/domain/user"
+
 	"carowebapp/core/internal/infrastructure/response"
 
 	"carowebapp/core/internal/pkg/contextutils"
 
+	"carowebapp/core/internal/pkg/passwordpolicy"
+
 	"errors"
 
 	"github.com/go-playground/validator/v10"
@@ -32,13 +36,19 @@ func NewHandler(service *Service, logger *zap.Logger) *Handler {
 
 type RegisterRequest struct {
 	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=6"`
+	Password string `json:"password" validate:"required"`
 	Role     string `json:"role" validate:"required"`
+	Language string `json:"language" validate:"omitempty,oneof=de en"`
 }
 
 type LoginRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required"`
+	Device   string `json:"device" validate:"max=100"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
 }
 
 type CreateProfileRequest struct {
@@ -58,16 +68,24 @@ type ResetPasswordRequest struct {
 
 type ResetPasswordPayload struct {
 	Token       string `json:"token" validate:"required"`
-	NewPassword string `json:"new_password" validate:"required,min=6"`
+	NewPassword string `json:"new_password" validate:"required"`
 }
 
 func (h *Handler) Register(c *fiber.Ctx) error {
 	req, _ := contextutils.GetValidatedBody[RegisterRequest](c)
 
-	user, err := h.service.RegisterUser(req.Email, req.Password, req.Role)
+	user, err := h.service.RegisterUser(req.Email, req.Password, req.Role, req.Language)
 	if err != nil {
+		var policyErr *passwordpolicy.ViolationError
 		switch {
-		case errors.Is(err, ErrInvalidEmail), errors.Is(err, ErrWeakPassword), errors.Is(err, ErrInvalidRole):
+		case errors.As(err, &policyErr):
+			return response.JSONErrorWithDetails(c, h.logger, fiber.StatusBadRequest, err.Error(), policyErr.Violations,
+				zap.String("email", req.Email),
+				zap.Any("violations", policyErr.Violations),
+			)
+
+		case errors.Is(err, ErrInvalidEmail), errors.Is(err, ErrWeakPassword), errors.Is(err, ErrInvalidRole),
+			errors.Is(err, ErrInvalidLanguage):
 			return response.JSONErrorInfoLog(c, h.logger, fiber.StatusBadRequest, err.Error(),
 				zap.String("email", req.Email),
 				zap.String("reason", err.Error()),
@@ -78,6 +96,9 @@ func (h *Handler) Register(c *fiber.Ctx) error {
 				zap.String("email", req.Email),
 			)
 
+		case errors.Is(err, ErrHashingBusy):
+			return h.serviceBusy(c, err)
+
 		default:
 			return response.JSONErrorWithLog(c, h.logger, fiber.StatusInternalServerError, err.Error(),
 				zap.Error(err),
@@ -105,28 +126,187 @@ func (h *Handler) Register(c *fiber.Ctx) error {
 func (h *Handler) Login(c *fiber.Ctx) error {
 	req, _ := contextutils.GetValidatedBody[LoginRequest](c)
 
-	user, accessToken, refreshToken, err := h.service.Login(req.Email, req.Password)
+	result, err := h.service.Login(req.Email, req.Password, SessionInfo{
+		Device:    req.Device,
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+		IP:        c.IP(),
+	})
 	if err != nil {
-		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusUnauthorized, response.ErrMsgLoginFailed,
-			zap.String("email", req.Email),
+		var lockedErr *AccountLockedError
+		switch {
+		case errors.As(err, &lockedErr):
+			// Answered like a wrong password, so the response does not reveal the account.
+			h.logger.Warn("Login to locked account",
+				zap.String("email", req.Email),
+				zap.String("ip", c.IP()),
+				zap.Time("locked_until", lockedErr.Until),
+			)
+			return response.JSONError(c, fiber.StatusUnauthorized, errors.New(response.ErrMsgLoginFailed))
+
+		case errors.Is(err, ErrHashingBusy):
+			return h.serviceBusy(c, err)
+
+		case errors.Is(err, ErrAccountBlocked):
+			return h.accountBlocked(c, err)
+
+		default:
+			return response.JSONErrorInfoLog(c, h.logger, fiber.StatusUnauthorized, response.ErrMsgLoginFailed,
+				zap.String("email", req.Email),
+				zap.Error(err),
+			)
+		}
+	}
+
+	return h.loginResponse(c, result, "User logged in")
+}
+
+func (h *Handler) Refresh(c *fiber.Ctx) error {
+	req, _ := contextutils.GetValidatedBody[RefreshRequest](c)
+
+	result, err := h.service.Refresh(req.RefreshToken, SessionInfo{
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+		IP:        c.IP(),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrRefreshTokenReuse):
+			fields := []zap.Field{
+				zap.String("ip", c.IP()),
+				zap.String("user_agent", c.Get(fiber.HeaderUserAgent)),
+			}
+			if traceID, ok := contextutils.GetTraceID(c); ok {
+				fields = append(fields, zap.String("trace_id", traceID))
+			}
+			h.logger.Warn("Refresh token reuse detected, session revoked (possible theft)", fields...)
+			return response.JSONError(c, fiber.StatusUnauthorized, ErrInvalidRefreshToken)
+
+		case errors.Is(err, ErrInvalidRefreshToken):
+			return response.JSONErrorInfoLog(c, h.logger, fiber.StatusUnauthorized, err.Error(),
+				zap.String("ip", c.IP()),
+			)
+
+		case errors.Is(err, ErrAccountBlocked):
+			return h.accountBlocked(c, err)
+
+		default:
+			return response.JSONErrorWithLog(c, h.logger, fiber.StatusInternalServerError, response.ErrMsgRefreshFailed,
+				zap.Error(err),
+			)
+		}
+	}
+
+	h.logger.Info("Tokens refreshed",
+		zap.String("user_id", result.User.ID),
+	)
+
+	return response.JSONSuccess(c, fiber.StatusOK, tokenResponse(result))
+}
+
+func (h *Handler) ListSessions(c *fiber.Ctx) error {
+	userID, ok := contextutils.GetUserID(c)
+	if !ok {
+		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusUnauthorized, response.ErrMsgUnauthorized)
+	}
+	currentSessionID, _ := contextutils.GetSessionID(c)
+
+	sessions, err := h.service.ListSessions(userID)
+	if err != nil {
+		return response.JSONErrorWithLog(c, h.logger, fiber.StatusInternalServerError, response.ErrMsgListSessionsFail,
+			zap.String("user_id", userID),
 			zap.Error(err),
 		)
 	}
 
-	h.logger.Info("User logged in",
-		zap.String("user_id", user.ID),
-		zap.String("email", user.Email),
-	)
+	items := make([]fiber.Map, 0, len(sessions))
+	for _, session := range sessions {
+		items = append(items, fiber.Map{
+			"id":           session.ID,
+			"device":       session.Device,
+			"user_agent":   session.UserAgent,
+			"ip":           session.IP,
+			"created_at":   session.CreatedAt,
+			"last_used_at": session.LastUsedAt,
+			"current":      session.ID == currentSessionID,
+		})
+	}
 
 	return response.JSONSuccess(c, fiber.StatusOK, fiber.Map{
-		"access_token":    accessToken,
-		"refresh_token":   refreshToken,
-		"email_confirmed": user.EmailConfirmed,
-		"role":            user.Role,
-		"status":          user.Status,
+		"sessions": items,
 	})
 }
 
+func (h *Handler) Logout(c *fiber.Ctx) error {
+	userID, ok := contextutils.GetUserID(c)
+	sessionID, hasSession := contextutils.GetSessionID(c)
+	if !ok || !hasSession {
+		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusUnauthorized, response.ErrMsgUnauthorized)
+	}
+
+	if err := h.service.Logout(sessionID); err != nil {
+		return response.JSONErrorWithLog(c, h.logger, fiber.StatusInternalServerError, response.ErrMsgLogoutFail,
+			zap.String("user_id", userID),
+			zap.String("session_id", sessionID),
+			zap.Error(err),
+		)
+	}
+
+	h.logger.Info("User logged out",
+		zap.String("user_id", userID),
+		zap.String("session_id", sessionID),
+	)
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *Handler) RevokeSession(c *fiber.Ctx) error {
+	userID, ok := contextutils.GetUserID(c)
+	if !ok {
+		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusUnauthorized, response.ErrMsgUnauthorized)
+	}
+	sessionID := c.Params("id")
+
+	if err := h.service.RevokeSession(userID, sessionID); err != nil {
+		if errors.Is(err, ErrSessionNotFound) {
+			return response.JSONErrorInfoLog(c, h.logger, fiber.StatusNotFound, err.Error(),
+				zap.String("user_id", userID),
+				zap.String("session_id", sessionID),
+			)
+		}
+		return response.JSONErrorWithLog(c, h.logger, fiber.StatusInternalServerError, response.ErrMsgLogoutFail,
+			zap.String("user_id", userID),
+			zap.String("session_id", sessionID),
+			zap.Error(err),
+		)
+	}
+
+	h.logger.Info("Session revoked",
+		zap.String("user_id", userID),
+		zap.String("session_id", sessionID),
+	)
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *Handler) LogoutAll(c *fiber.Ctx) error {
+	userID, ok := contextutils.GetUserID(c)
+	if !ok {
+		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusUnauthorized, response.ErrMsgUnauthorized)
+	}
+
+	if err := h.service.LogoutAll(userID); err != nil {
+		return response.JSONErrorWithLog(c, h.logger, fiber.StatusInternalServerError, response.ErrMsgLogoutFail,
+			zap.String("user_id", userID),
+			zap.Error(err),
+		)
+	}
+
+	h.logger.Info("User logged out from all sessions",
+		zap.String("user_id", userID),
+	)
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
 func (h *Handler) ConfirmEmail(c *fiber.Ctx) error {
 	token := c.Query("token")
 	if token == "" {
@@ -136,17 +316,14 @@ func (h *Handler) ConfirmEmail(c *fiber.Ctx) error {
 		)
 	}
 
-	user, err := h.service.GetByConfirmationToken(token)
-	if err != nil || user == nil {
-		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusBadRequest, response.ErrMsgInvalidOrExpiredToken,
-			zap.String("token", token),
-			zap.Error(err),
-		)
-	}
-
-	if err := h.service.ConfirmEmail(user.ID); err != nil {
+	user, err := h.service.ConfirmEmail(token)
+	if err != nil {
+		if errors.Is(err, ErrInvalidConfirmationToken) {
+			return response.JSONErrorInfoLog(c, h.logger, fiber.StatusBadRequest, response.ErrMsgInvalidOrExpiredToken,
+				zap.String("ip", c.IP()),
+			)
+		}
 		return response.JSONErrorWithLog(c, h.logger, fiber.StatusInternalServerError, response.ErrMsgEmailConfirmationFail,
-			zap.String("user_id", user.ID),
 			zap.Error(err),
 		)
 	}
@@ -161,6 +338,36 @@ func (h *Handler) ConfirmEmail(c *fiber.Ctx) error {
 	})
 }
 
+// UnlockAccount lifts a login lockout with the token from the account locked email.
+func (h *Handler) UnlockAccount(c *fiber.Ctx) error {
+	token := c.Query("token")
+	if token == "" {
+		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusBadRequest, response.ErrMsgMissingToken,
+			zap.String("path", c.Path()),
+			zap.String("method", c.Method()),
+		)
+	}
+
+	if err := h.service.UnlockAccount(token, c.IP()); err != nil {
+		if errors.Is(err, ErrInvalidUnlockToken) {
+			return response.JSONErrorInfoLog(c, h.logger, fiber.StatusBadRequest, response.ErrMsgInvalidOrExpiredToken,
+				zap.String("ip", c.IP()),
+			)
+		}
+		return response.JSONErrorWithLog(c, h.logger, fiber.StatusInternalServerError, response.ErrMsgUnlockFailed,
+			zap.Error(err),
+		)
+	}
+
+	h.logger.Info("Account unlocked by email link",
+		zap.String("ip", c.IP()),
+	)
+
+	return response.JSONSuccess(c, fiber.StatusOK, fiber.Map{
+		"message": "account unlocked successfully",
+	})
+}
+
 func (h *Handler) ResendConfirmation(c *fiber.Ctx) error {
 	userID, ok := contextutils.GetUserID(c)
 	if !ok {
@@ -217,6 +424,13 @@ func (h *Handler) CreateProfile(c *fiber.Ctx) error {
 	}
 
 	if err := h.service.AddUserProfile(profile); err != nil {
+		if errors.Is(err, ErrProfileExists) ||
+			errors.Is(err, domainuser.ErrTransitionForbidden) ||
+			errors.Is(err, domainuser.ErrStatusConflict) {
+			return response.JSONErrorInfoLog(c, h.logger, fiber.StatusConflict, err.Error(),
+				zap.String("user_id", userID),
+			)
+		}
 		return response.JSONErrorWithLog(c, h.logger, fiber.StatusInternalServerError, response.ErrMsgProfileCreationFail,
 			zap.String("user_id", userID),
 			zap.Error(err),
@@ -262,11 +476,88 @@ func (h *Handler) ResetPassword(c *fiber.Ctx) error {
 	req, _ := contextutils.GetValidatedBody[ResetPasswordPayload](c)
 
 	if err := h.service.ResetPassword(req.Token, req.NewPassword); err != nil {
-		return response.JSONErrorWithLog(c, h.logger, fiber.StatusBadRequest, err.Error(), zap.Error(err))
+		var policyErr *passwordpolicy.ViolationError
+		switch {
+		case errors.As(err, &policyErr):
+			return response.JSONErrorWithDetails(c, h.logger, fiber.StatusBadRequest, err.Error(), policyErr.Violations,
+				zap.Any("violations", policyErr.Violations),
+			)
+
+		case errors.Is(err, ErrInvalidResetToken):
+			return response.JSONErrorInfoLog(c, h.logger, fiber.StatusBadRequest, err.Error(),
+				zap.String("ip", c.IP()),
+			)
+
+		case errors.Is(err, ErrHashingBusy):
+			return h.serviceBusy(c, err)
+
+		default:
+			return response.JSONErrorWithLog(c, h.logger, fiber.StatusInternalServerError, response.ErrMsgResetPasswordFail,
+				zap.Error(err),
+			)
+		}
 	}
 
-	h.logger.Info("Password reset successful", zap.String("token", req.Token))
+	h.logger.Info("Password reset successful", zap.String("ip", c.IP()))
 	return response.JSONSuccess(c, fiber.StatusOK, fiber.Map{
 		"message": "password has been reset",
 	})
 }
+
+// loginResponse answers a completed first login step with either a second-factor
+// challenge or the token pair.
+func (h *Handler) loginResponse(c *fiber.Ctx, result *AuthResult, msg string) error {
+	if result.ChallengeToken != "" {
+		h.logger.Info("Second factor required",
+			zap.String("user_id", result.User.ID),
+		)
+
+		return response.JSONSuccess(c, fiber.StatusOK, fiber.Map{
+			"mfa_required":    true,
+			"challenge_token": result.ChallengeToken,
+			"expires_in":      int(mfaChallengeTTL.Seconds()),
+		})
+	}
+
+	h.logger.Info(msg,
+		zap.String("user_id", result.User.ID),
+		zap.String("email", result.User.Email),
+	)
+
+	return response.JSONSuccess(c, fiber.StatusOK, tokenResponse(result))
+}
+
+// tokenResponse builds the response body shared by all endpoints that issue tokens.
+func tokenResponse(result *AuthResult) fiber.Map {
+	return fiber.Map{
+		"access_token":    result.AccessToken,
+		"refresh_token":   result.RefreshToken,
+		"email_confirmed": result.User.EmailConfirmed,
+		"role":            result.User.Role,
+		"status":          result.User.Status,
+	}
+}
+
+// accountBlocked answers sign-in attempts of suspended or banned users with the kind of block,
+// its reason and, for a suspension, when it ends.
+func (h *Handler) accountBlocked(c *fiber.Ctx, err error) error {
+	var blockedErr *AccountBlockedError
+	if !errors.As(err, &blockedErr) {
+		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusForbidden, ErrAccountBlocked.Error())
+	}
+
+	return response.JSONErrorWithDetails(c, h.logger, fiber.StatusForbidden, ErrAccountBlocked.Error(), fiber.Map{
+		"kind":   blockedErr.Kind,
+		"reason": blockedErr.Reason,
+		"until":  blockedErr.Until,
+	}, zap.String("ip", c.IP()), zap.String("kind", blockedErr.Kind))
+}
+
+// serviceBusy answers requests rejected because all password hashing workers are taken.
+func (h *Handler) serviceBusy(c *fiber.Ctx, err error) error {
+	c.Set(fiber.HeaderRetryAfter, "1")
+	return response.JSONErrorWithLog(c, h.logger, fiber.StatusServiceUnavailable, response.ErrMsgServiceBusy,
+		zap.String("ip", c.IP()),
+		zap.Error(err),
+	)
+}
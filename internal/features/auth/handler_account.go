@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"carowebapp/core/internal/infrastructure/response"
+
+	"carowebapp/core/internal/pkg/contextutils"
+
+	"carowebapp/core/internal/pkg/passwordpolicy"
+
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"go.uber.org/zap"
+)
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required"`
+}
+
+type ChangeEmailRequest struct {
+	NewEmail string `json:"new_email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+func (h *Handler) ChangePassword(c *fiber.Ctx) error {
+	req, _ := contextutils.GetValidatedBody[ChangePasswordRequest](c)
+	userID, ok := contextutils.GetUserID(c)
+	sessionID, hasSession := contextutils.GetSessionID(c)
+	if !ok || !hasSession {
+		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusUnauthorized, response.ErrMsgUnauthorized)
+	}
+
+	if err := h.service.ChangePassword(userID, sessionID, req.CurrentPassword, req.NewPassword); err != nil {
+		return h.accountError(c, userID, err, response.ErrMsgChangePasswordFail)
+	}
+
+	h.logger.Info("Password changed",
+		zap.String("user_id", userID),
+		zap.String("session_id", sessionID),
+	)
+
+	return response.JSONSuccess(c, fiber.StatusOK, fiber.Map{
+		"message": "password has been changed",
+	})
+}
+
+func (h *Handler) RequestEmailChange(c *fiber.Ctx) error {
+	req, _ := contextutils.GetValidatedBody[ChangeEmailRequest](c)
+	userID, ok := contextutils.GetUserID(c)
+	if !ok {
+		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusUnauthorized, response.ErrMsgUnauthorized)
+	}
+
+	if err := h.service.RequestEmailChange(userID, req.NewEmail, req.Password); err != nil {
+		return h.accountError(c, userID, err, response.ErrMsgChangeEmailFail)
+	}
+
+	h.logger.Info("Email change requested",
+		zap.String("user_id", userID),
+		zap.String("new_email", req.NewEmail),
+	)
+
+	return response.JSONSuccess(c, fiber.StatusAccepted, fiber.Map{
+		"message": "confirmation link sent to the new email address",
+	})
+}
+
+func (h *Handler) ConfirmEmailChange(c *fiber.Ctx) error {
+	token := c.Query("token")
+	if token == "" {
+		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusBadRequest, response.ErrMsgMissingToken,
+			zap.String("path", c.Path()),
+		)
+	}
+
+	if err := h.service.ConfirmEmailChange(token); err != nil {
+		return h.accountError(c, "", err, response.ErrMsgChangeEmailFail)
+	}
+
+	h.logger.Info("Email change confirmed",
+		zap.String("ip", c.IP()),
+	)
+
+	return response.JSONSuccess(c, fiber.StatusOK, fiber.Map{
+		"message": "email address changed successfully",
+	})
+}
+
+func (h *Handler) UndoEmailChange(c *fiber.Ctx) error {
+	token := c.Query("token")
+	if token == "" {
+		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusBadRequest, response.ErrMsgMissingToken,
+			zap.String("path", c.Path()),
+		)
+	}
+
+	if err := h.service.UndoEmailChange(token); err != nil {
+		return h.accountError(c, "", err, response.ErrMsgChangeEmailFail)
+	}
+
+	h.logger.Warn("Email change undone, all sessions revoked",
+		zap.String("ip", c.IP()),
+	)
+
+	return response.JSONSuccess(c, fiber.StatusOK, fiber.Map{
+		"message": "email change has been undone and all devices were signed out",
+	})
+}
+
+// accountError maps password and email change errors to HTTP responses.
+func (h *Handler) accountError(c *fiber.Ctx, userID string, err error, fallback string) error {
+	var policyErr *passwordpolicy.ViolationError
+	switch {
+	case errors.As(err, &policyErr):
+		return response.JSONErrorWithDetails(c, h.logger, fiber.StatusBadRequest, err.Error(), policyErr.Violations,
+			zap.String("user_id", userID),
+			zap.Any("violations", policyErr.Violations),
+		)
+
+	case errors.Is(err, ErrInvalidCredentials),
+		errors.Is(err, ErrInvalidEmail),
+		errors.Is(err, ErrEmailUnchanged),
+		errors.Is(err, ErrInvalidEmailChange):
+		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusBadRequest, err.Error(),
+			zap.String("user_id", userID),
+		)
+
+	case errors.Is(err, ErrEmailExists):
+		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusConflict, err.Error(),
+			zap.String("user_id", userID),
+		)
+
+	case errors.Is(err, ErrHashingBusy):
+		return h.serviceBusy(c, err)
+
+	default:
+		return response.JSONErrorWithLog(c, h.logger, fiber.StatusInternalServerError, fallback,
+			zap.String("user_id", userID),
+			zap.Error(err),
+		)
+	}
+}
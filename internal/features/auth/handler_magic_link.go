@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"carowebapp/core/internal/infrastructure/response"
+
+	"carowebapp/core/internal/pkg/contextutils"
+
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"go.uber.org/zap"
+
+	"time"
+)
+
+// magicLinkNonceCookie holds the nonce that binds a magic link to the requesting browser.
+const magicLinkNonceCookie = "magic_link_nonce"
+
+type MagicLinkRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type MagicLinkLoginRequest struct {
+	Token  string `json:"token" validate:"required"`
+	Device string `json:"device" validate:"max=100"`
+}
+
+func (h *Handler) RequestMagicLink(c *fiber.Ctx) error {
+	req, _ := contextutils.GetValidatedBody[MagicLinkRequest](c)
+
+	nonce, err := h.service.RequestMagicLink(req.Email)
+	if err != nil {
+		return response.JSONErrorWithLog(c, h.logger, fiber.StatusInternalServerError, response.ErrMsgMagicLinkFail,
+			zap.String("email", req.Email),
+			zap.Error(err),
+		)
+	}
+
+	setMagicLinkNonce(c, nonce, time.Now().Add(magicLinkTTL))
+
+	h.logger.Info("Magic link requested",
+		zap.String("email", req.Email),
+		zap.String("ip", c.IP()),
+	)
+
+	return response.JSONSuccess(c, fiber.StatusOK, fiber.Map{
+		"message":    "if the account exists, a login link has been sent",
+		"expires_in": int(magicLinkTTL.Seconds()),
+	})
+}
+
+func (h *Handler) LoginWithMagicLink(c *fiber.Ctx) error {
+	req, _ := contextutils.GetValidatedBody[MagicLinkLoginRequest](c)
+
+	result, err := h.service.LoginWithMagicLink(req.Token, c.Cookies(magicLinkNonceCookie), SessionInfo{
+		Device:    req.Device,
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+		IP:        c.IP(),
+	})
+	if err != nil {
+		if errors.Is(err, ErrInvalidMagicLink) {
+			return response.JSONErrorInfoLog(c, h.logger, fiber.StatusUnauthorized, err.Error(),
+				zap.String("ip", c.IP()),
+			)
+		}
+		if errors.Is(err, ErrAccountBlocked) {
+			return h.accountBlocked(c, err)
+		}
+		return response.JSONErrorWithLog(c, h.logger, fiber.StatusInternalServerError, response.ErrMsgLoginFailed,
+			zap.Error(err),
+		)
+	}
+
+	setMagicLinkNonce(c, "", time.Unix(0, 0))
+
+	return h.loginResponse(c, result, "User logged in with magic link")
+}
+
+// setMagicLinkNonce stores the nonce in a cookie that is only sent to the magic-link endpoints.
+func setMagicLinkNonce(c *fiber.Ctx, nonce string, expires time.Time) {
+	c.Cookie(&fiber.Cookie{
+		Name:     magicLinkNonceCookie,
+		Value:    nonce,
+		Path:     "/api/v1/auth/magic-link",
+		Expires:  expires,
+		Secure:   true,
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+}
@@ -0,0 +1,158 @@
+package auth
+
+import (
+	domainuser "carowebapp/core/internal/domain/user"
+
+	"carowebapp/core/internal/infrastructure/response"
+
+	"carowebapp/core/internal/pkg/contextutils"
+
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"go.uber.org/zap"
+)
+
+// UpdateProfileRequest is a partial profile update. Omitted fields stay unchanged,
+// an empty title removes it. The limits match the user_profiles columns; values are
+// trimmed and must not be blank.
+type UpdateProfileRequest struct {
+	Salutation  *string `json:"salutation" validate:"omitnil,min=1,max=20"`
+	Title       *string `json:"title" validate:"omitnil,max=50"`
+	FirstName   *string `json:"firstName" validate:"omitnil,min=1,max=100"`
+	LastName    *string `json:"lastName" validate:"omitnil,min=1,max=100"`
+	Street      *string `json:"street" validate:"omitnil,min=1,max=100"`
+	HouseNumber *string `json:"houseNumber" validate:"omitnil,min=1,max=10"`
+	PostalCode  *string `json:"postalCode" validate:"omitnil,min=1,max=10"`
+	City        *string `json:"city" validate:"omitnil,min=1,max=100"`
+}
+
+// ResubmitProfileRequest carries the corrected values of the rejected profile fields.
+type ResubmitProfileRequest = UpdateProfileRequest
+
+func (h *Handler) Me(c *fiber.Ctx) error {
+	userID, ok := contextutils.GetUserID(c)
+	if !ok {
+		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusUnauthorized, response.ErrMsgUnauthorized)
+	}
+
+	user, err := h.service.Me(userID)
+	if err != nil {
+		return response.JSONErrorWithLog(c, h.logger, fiber.StatusInternalServerError, response.ErrMsgLoadUserFail,
+			zap.String("user_id", userID),
+			zap.Error(err),
+		)
+	}
+
+	return response.JSONSuccess(c, fiber.StatusOK, user)
+}
+
+func (h *Handler) UpdateProfile(c *fiber.Ctx) error {
+	req, _ := contextutils.GetValidatedBody[UpdateProfileRequest](c)
+	userID, ok := contextutils.GetUserID(c)
+	if !ok {
+		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusUnauthorized, response.ErrMsgUnauthorized)
+	}
+
+	user, moderationRequired, err := h.service.UpdateProfile(userID, req.update())
+	if err != nil {
+		return h.profileError(c, userID, err, response.ErrMsgProfileUpdateFail)
+	}
+
+	h.logger.Info("User profile updated",
+		zap.String("user_id", userID),
+		zap.Bool("moderation_required", moderationRequired),
+	)
+
+	return response.JSONSuccess(c, fiber.StatusOK, fiber.Map{
+		"status":              user.Status,
+		"profile":             user.Profile,
+		"moderation_required": moderationRequired,
+	})
+}
+
+func (h *Handler) GetRejection(c *fiber.Ctx) error {
+	userID, ok := contextutils.GetUserID(c)
+	if !ok {
+		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusUnauthorized, response.ErrMsgUnauthorized)
+	}
+
+	rejection, err := h.service.GetRejection(userID)
+	if err != nil {
+		return h.profileError(c, userID, err, response.ErrMsgLoadRejectionFail)
+	}
+
+	return response.JSONSuccess(c, fiber.StatusOK, rejection)
+}
+
+func (h *Handler) Resubmit(c *fiber.Ctx) error {
+	req, _ := contextutils.GetValidatedBody[ResubmitProfileRequest](c)
+	userID, ok := contextutils.GetUserID(c)
+	if !ok {
+		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusUnauthorized, response.ErrMsgUnauthorized)
+	}
+
+	user, err := h.service.Resubmit(userID, req.update())
+	if err != nil {
+		return h.profileError(c, userID, err, response.ErrMsgResubmissionFail)
+	}
+
+	h.logger.Info("Rejected registration resubmitted",
+		zap.String("user_id", userID),
+	)
+
+	return response.JSONSuccess(c, fiber.StatusOK, fiber.Map{
+		"status":  user.Status,
+		"profile": user.Profile,
+	})
+}
+
+// profileError maps profile and resubmission errors to HTTP responses.
+func (h *Handler) profileError(c *fiber.Ctx, userID string, err error, fallback string) error {
+	var resubmissionErr *ResubmissionError
+	switch {
+	case errors.As(err, &resubmissionErr):
+		return response.JSONErrorWithDetails(c, h.logger, fiber.StatusBadRequest, err.Error(), resubmissionErr,
+			zap.String("user_id", userID),
+		)
+
+	case errors.Is(err, ErrBlankProfileField):
+		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusBadRequest, err.Error(),
+			zap.String("user_id", userID),
+		)
+
+	case errors.Is(err, ErrProfileNotFound),
+		errors.Is(err, ErrNotRejected):
+		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusNotFound, err.Error(),
+			zap.String("user_id", userID),
+		)
+
+	case errors.Is(err, domainuser.ErrStatusConflict),
+		errors.Is(err, domainuser.ErrTransitionForbidden),
+		errors.Is(err, ErrResubmissionRequired),
+		errors.Is(err, ErrNoRejectedFields):
+		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusConflict, err.Error(),
+			zap.String("user_id", userID),
+		)
+	}
+
+	return response.JSONErrorWithLog(c, h.logger, fiber.StatusInternalServerError, fallback,
+		zap.String("user_id", userID),
+		zap.Error(err),
+	)
+}
+
+// update converts the request into a service profile update.
+func (r UpdateProfileRequest) update() ProfileUpdate {
+	return ProfileUpdate{
+		Salutation:  r.Salutation,
+		Title:       r.Title,
+		FirstName:   r.FirstName,
+		LastName:    r.LastName,
+		Street:      r.Street,
+		HouseNumber: r.HouseNumber,
+		PostalCode:  r.PostalCode,
+		City:        r.City,
+	}
+}
@@ -0,0 +1,170 @@
+package auth
+
+import (
+	"carowebapp/core/internal/infrastructure/response"
+
+	"carowebapp/core/internal/pkg/contextutils"
+
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"go.uber.org/zap"
+)
+
+type LoginTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required"`
+	Device         string `json:"device" validate:"max=100"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+func (h *Handler) LoginTwoFactor(c *fiber.Ctx) error {
+	req, _ := contextutils.GetValidatedBody[LoginTwoFactorRequest](c)
+
+	result, err := h.service.VerifyLoginChallenge(req.ChallengeToken, req.Code, SessionInfo{
+		Device:    req.Device,
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+		IP:        c.IP(),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidChallenge), errors.Is(err, ErrInvalidTwoFactorCode):
+			return response.JSONErrorInfoLog(c, h.logger, fiber.StatusUnauthorized, err.Error(),
+				zap.String("ip", c.IP()),
+			)
+
+		case errors.Is(err, ErrAccountBlocked):
+			return h.accountBlocked(c, err)
+
+		default:
+			return response.JSONErrorWithLog(c, h.logger, fiber.StatusInternalServerError, response.ErrMsgLoginFailed,
+				zap.Error(err),
+			)
+		}
+	}
+
+	h.logger.Info("User logged in with second factor",
+		zap.String("user_id", result.User.ID),
+		zap.String("email", result.User.Email),
+	)
+
+	return response.JSONSuccess(c, fiber.StatusOK, tokenResponse(result))
+}
+
+func (h *Handler) SetupTwoFactor(c *fiber.Ctx) error {
+	userID, ok := contextutils.GetUserID(c)
+	if !ok {
+		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusUnauthorized, response.ErrMsgUnauthorized)
+	}
+
+	secret, uri, err := h.service.SetupTwoFactor(userID)
+	if err != nil {
+		return h.twoFactorError(c, userID, err)
+	}
+
+	h.logger.Info("Two-factor setup started",
+		zap.String("user_id", userID),
+	)
+
+	return response.JSONSuccess(c, fiber.StatusOK, fiber.Map{
+		"secret":           secret,
+		"provisioning_uri": uri,
+	})
+}
+
+func (h *Handler) ConfirmTwoFactor(c *fiber.Ctx) error {
+	req, _ := contextutils.GetValidatedBody[TwoFactorCodeRequest](c)
+	userID, ok := contextutils.GetUserID(c)
+	if !ok {
+		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusUnauthorized, response.ErrMsgUnauthorized)
+	}
+
+	codes, err := h.service.ConfirmTwoFactor(userID, req.Code)
+	if err != nil {
+		return h.twoFactorError(c, userID, err)
+	}
+
+	h.logger.Info("Two-factor authentication enabled",
+		zap.String("user_id", userID),
+	)
+
+	return response.JSONSuccess(c, fiber.StatusOK, fiber.Map{
+		"recovery_codes": codes,
+	})
+}
+
+func (h *Handler) DisableTwoFactor(c *fiber.Ctx) error {
+	req, _ := contextutils.GetValidatedBody[DisableTwoFactorRequest](c)
+	userID, ok := contextutils.GetUserID(c)
+	if !ok {
+		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusUnauthorized, response.ErrMsgUnauthorized)
+	}
+
+	if err := h.service.DisableTwoFactor(userID, req.Password, req.Code); err != nil {
+		return h.twoFactorError(c, userID, err)
+	}
+
+	h.logger.Info("Two-factor authentication disabled",
+		zap.String("user_id", userID),
+	)
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *Handler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	req, _ := contextutils.GetValidatedBody[TwoFactorCodeRequest](c)
+	userID, ok := contextutils.GetUserID(c)
+	if !ok {
+		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusUnauthorized, response.ErrMsgUnauthorized)
+	}
+
+	codes, err := h.service.RegenerateRecoveryCodes(userID, req.Code)
+	if err != nil {
+		return h.twoFactorError(c, userID, err)
+	}
+
+	h.logger.Info("Recovery codes regenerated",
+		zap.String("user_id", userID),
+	)
+
+	return response.JSONSuccess(c, fiber.StatusOK, fiber.Map{
+		"recovery_codes": codes,
+	})
+}
+
+// twoFactorError maps two-factor service errors to HTTP responses.
+func (h *Handler) twoFactorError(c *fiber.Ctx, userID string, err error) error {
+	switch {
+	case errors.Is(err, ErrTwoFactorAlreadyEnabled),
+		errors.Is(err, ErrTwoFactorNotEnabled),
+		errors.Is(err, ErrTwoFactorNotSetUp):
+		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusConflict, err.Error(),
+			zap.String("user_id", userID),
+		)
+
+	case errors.Is(err, ErrTwoFactorRequiredForRole):
+		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusForbidden, err.Error(),
+			zap.String("user_id", userID),
+		)
+
+	case errors.Is(err, ErrInvalidTwoFactorCode), errors.Is(err, ErrInvalidCredentials):
+		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusBadRequest, err.Error(),
+			zap.String("user_id", userID),
+		)
+
+	default:
+		return response.JSONErrorWithLog(c, h.logger, fiber.StatusInternalServerError, response.ErrMsgTwoFactorFail,
+			zap.String("user_id", userID),
+			zap.Error(err),
+		)
+	}
+}
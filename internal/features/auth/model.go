@@ -1,16 +1,19 @@
 package auth
 
-import "time"
+import (
+	domainuser "carowebapp/core/internal/domain/user"
+
+	"time"
+)
 
 const (
-	RoleHomeowner        = "ROLE_HOMEOWNER"
-	RoleManager          = "ROLE_MANAGER"
-	RoleAdmin            = "ROLE_ADMIN"
-	StatusCreated        = "created"
-	StatusEmailConfirmed = "email_confirmed"
-	StatusPending        = "pending"
-	StatusApproved       = "approved"
-	StatusRejected       = "rejected"
+	RoleHomeowner  = "ROLE_HOMEOWNER"
+	RoleManager    = "ROLE_MANAGER"
+	RoleAdmin      = "ROLE_ADMIN"
+	StatusCreated  = domainuser.StatusCreated
+	StatusPending  = domainuser.StatusPending
+	StatusApproved = domainuser.StatusApproved
+	StatusRejected = domainuser.StatusRejected
 )
 
 type User struct {
@@ -19,10 +22,15 @@ type User struct {
 	Password               string       `db:"password" json:"-"`
 	Role                   string       `db:"role" json:"role"`
 	EmailConfirmed         bool         `db:"email_confirmed" json:"email_confirmed"`
-	EmailConfirmationToken *string      `db:"email_confirmation_token" json:"email_confirmation_token"`
+	Language               string       `db:"language" json:"language"`
 	LastConfirmationSentAt *time.Time   `db:"last_confirmation_sent_at" json:"last_confirmation_sent_at"`
 	Status                 string       `db:"status" json:"status"`
 	CreatedAt              time.Time    `db:"created_at" json:"created_at"`
+	TOTPSecret             *string      `db:"totp_secret" json:"-"`
+	TOTPEnabled            bool         `db:"totp_enabled" json:"totp_enabled"`
+	TOTPLastUsedStep       *int64       `db:"totp_last_used_step" json:"-"`
+	FailedLoginAttempts    int          `db:"failed_login_attempts" json:"-"`
+	LockedUntil            *time.Time   `db:"locked_until" json:"-"`
 	Profile                *UserProfile `db:"-" json:"profile"`
 }
 
@@ -40,19 +48,69 @@ type UserProfile struct {
 	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
 }
 
-type RefreshToken struct {
-	ID        string    `db:"id"`
-	UserID    string    `db:"user_id"`
-	Token     string    `db:"token"`
-	ExpiresAt time.Time `db:"expires_at"`
-	CreatedAt time.Time `db:"created_at"`
+// Session is a single device login. Every refresh token belongs to exactly one session.
+type Session struct {
+	ID         string     `db:"id" json:"id"`
+	UserID     string     `db:"user_id" json:"-"`
+	Device     string     `db:"device" json:"device"`
+	UserAgent  string     `db:"user_agent" json:"user_agent"`
+	IP         string     `db:"ip" json:"ip"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	LastUsedAt time.Time  `db:"last_used_at" json:"last_used_at"`
+	RevokedAt  *time.Time `db:"revoked_at" json:"-"`
+	MFA        bool       `db:"mfa" json:"mfa"`
+}
+
+// SessionInfo describes the client a session is created or refreshed from.
+type SessionInfo struct {
+	Device    string
+	UserAgent string
+	IP        string
 }
 
-type UserPasswordResetToken struct {
+// AuthResult is the outcome of a login step. When a second factor is still
+// required, ChallengeToken is set instead of the access and refresh tokens.
+type AuthResult struct {
+	User           *User
+	AccessToken    string
+	RefreshToken   string
+	ChallengeToken string
+}
+
+// MFAChallenge is the short-lived proof that the password step of a login succeeded.
+type MFAChallenge struct {
 	ID        string     `db:"id"`
 	UserID    string     `db:"user_id"`
-	Token     string     `db:"token"`
-	CreatedAt time.Time  `db:"created_at"`
+	TokenHash string     `db:"token_hash"`
+	Attempts  int        `db:"attempts"`
+	ExpiresAt time.Time  `db:"expires_at"`
 	UsedAt    *time.Time `db:"used_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
+
+// EmailChange is a request to move an account to a new email address. It takes
+// effect once the new address is confirmed and can be undone from the old address.
+type EmailChange struct {
+	ID            string     `db:"id"`
+	UserID        string     `db:"user_id"`
+	OldEmail      string     `db:"old_email"`
+	NewEmail      string     `db:"new_email"`
+	TokenHash     string     `db:"token_hash"`
+	ExpiresAt     time.Time  `db:"expires_at"`
+	ConfirmedAt   *time.Time `db:"confirmed_at"`
+	UndoTokenHash *string    `db:"undo_token_hash"`
+	UndoExpiresAt *time.Time `db:"undo_expires_at"`
+	RevertedAt    *time.Time `db:"reverted_at"`
+	CreatedAt     time.Time  `db:"created_at"`
+}
+
+type RefreshToken struct {
+	ID        string     `db:"id"`
+	UserID    string     `db:"user_id"`
+	SessionID string     `db:"session_id"`
+	TokenHash string     `db:"token_hash"`
 	ExpiresAt time.Time  `db:"expires_at"`
+	CreatedAt time.Time  `db:"created_at"`
+	UsedAt    *time.Time `db:"used_at"`
+	RevokedAt *time.Time `db:"revoked_at"`
 }
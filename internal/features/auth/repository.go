@@ -1,27 +1,63 @@
 package auth
 
-import "time"
+import (
+	domainuser "carowebapp/core/internal/domain/user"
+
+	"time"
+)
 
 type Repository interface {
 	Create(user *User) (*User, error)
 	Update(user *User) error
-	GetByConfirmationToken(token string) (*User, error)
 	GetByID(id string) (*User, error)
 
 	EmailExists(email string) (bool, error)
 	GetByEmail(email string) (*User, error)
 	SetEmailConfirmed(userID string) error
-	SetUserPending(userID string) error
-	UpdateEmailConfirmation(userID string, token string, sentAt time.Time) error
+	SetConfirmationSentAt(userID string, sentAt time.Time) error
 
-	CreateProfile(profile *UserProfile) error
+	CreateProfile(profile *UserProfile, statusChange *domainuser.StatusChange) error
+	GetProfile(userID string) (*UserProfile, error)
+	UpdateProfile(profile *UserProfile, change *domainuser.ProfileChange, statusChange *domainuser.StatusChange) error
+	GetLatestRejection(userID string) (*domainuser.Rejection, error)
+	GetActiveBlock(userID string) (*domainuser.AccountBlock, error)
 
 	StoreRefreshToken(token *RefreshToken) error
-	GetRefreshToken(tokenStr string) (*RefreshToken, error)
-	DeleteRefreshTokensByUserID(userID string) error
+	GetRefreshToken(tokenHash string) (*RefreshToken, error)
+	MarkRefreshTokenUsed(id string) (bool, error)
+
+	GetRolePermissions(role string) ([]string, error)
+
+	CreateSession(session *Session) error
+	GetSession(id string) (*Session, error)
+	ListActiveSessions(userID string) ([]Session, error)
+	TouchSession(id, ip string) error
+	IsSessionActive(id string) (bool, error)
+	RevokeSession(id string) error
+	RevokeUserSessions(userID, exceptSessionID string) error
+
+	SetTOTPSecret(userID, secret string) error
+	EnableTOTP(userID string) error
+	DisableTOTP(userID string) error
+	UseTOTPStep(userID string, step int64) (bool, error)
+	ReplaceRecoveryCodes(userID string, codeHashes []string) error
+	UseRecoveryCode(userID, codeHash string) (bool, error)
+
+	CreateMFAChallenge(challenge *MFAChallenge) error
+	GetMFAChallenge(tokenHash string) (*MFAChallenge, error)
+	IncrementMFAChallengeAttempts(id string) error
+	MarkMFAChallengeUsed(id string) (bool, error)
+
+	CreateEmailChange(change *EmailChange) error
+	GetEmailChange(tokenHash string) (*EmailChange, error)
+	GetEmailChangeByUndoToken(undoTokenHash string) (*EmailChange, error)
+	ApplyEmailChange(id, undoTokenHash string, undoExpiresAt time.Time) (bool, error)
+	RevertEmailChange(id string) (bool, error)
+
+	RecordFailedLogin(userID string) (int, error)
+	LockAccount(userID string, until time.Time, event domainuser.SecurityEvent) error
+	ResetFailedLogins(userID string) error
+	UnlockAccount(userID string, event domainuser.SecurityEvent) error
 
-	CreatePasswordResetToken(token *UserPasswordResetToken) error
-	GetPasswordResetToken(token string) (*UserPasswordResetToken, error)
-	MarkResetTokenUsed(token string) error
 	UpdateUserPassword(userID, newHashedPassword string) error
 }
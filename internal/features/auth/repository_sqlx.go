@@ -1,8 +1,20 @@
 package auth
 
 import (
+	domainuser "carowebapp/core/internal/domain/user"
+
+	"carowebapp/core/internal/infrastructure/securitylog"
+
+	"carowebapp/core/internal/infrastructure/userstatus"
+
+	"database/sql"
+
+	"errors"
+
 	"github.com/jmoiron/sqlx"
 
+	"github.com/lib/pq"
+
 	"time"
 )
 
@@ -19,14 +31,12 @@ func (r *SQLXRepository) Create(user *User) (*User, error) {
 
 	query := `
 		INSERT INTO users (
-			id, email, password, role, email_confirmation_token,
-			last_confirmation_sent_at, email_confirmed, created_at,
-		    status
+			id, email, password, role, language, last_confirmation_sent_at,
+			email_confirmed, created_at, status
 		)
 		VALUES (
-			:id, :email, :password, :role, :email_confirmation_token,
-			:last_confirmation_sent_at, :email_confirmed, :created_at,
-			:status
+			:id, :email, :password, :role, :language, :last_confirmation_sent_at,
+			:email_confirmed, :created_at, :status
 		)
 	`
 
@@ -52,9 +62,9 @@ func (r *SQLXRepository) GetByEmail(email string) (*User, error) {
 	query := `
 	SELECT 
 		u.id, u.email, u.password, u.role, 
-		u.email_confirmed, u.email_confirmation_token, 
-		u.last_confirmation_sent_at, u.created_at,
-		u.status,
+		u.email_confirmed, u.last_confirmation_sent_at, u.created_at,
+		u.status, u.totp_secret, u.totp_enabled, u.totp_last_used_step,
+		u.failed_login_attempts, u.locked_until,
 		(up.user_id IS NOT NULL) AS has_profile
 	FROM users u
 	LEFT JOIN user_profiles up ON u.id = up.user_id
@@ -81,35 +91,24 @@ func (r *SQLXRepository) GetByEmail(email string) (*User, error) {
 	return &result.User, nil
 }
 
-func (r *SQLXRepository) GetByConfirmationToken(token string) (*User, error) {
-	var user User
-	err := r.db.Get(&user, `SELECT * FROM users WHERE email_confirmation_token = $1`, token)
-	if err != nil {
-		return nil, err
-	}
-	return &user, nil
-}
-
 func (r *SQLXRepository) SetEmailConfirmed(userID string) error {
-	_, err := r.db.Exec(`UPDATE users SET email_confirmed = true, status = $1, email_confirmation_token = NULL WHERE id = $2`, StatusEmailConfirmed, userID)
+	_, err := r.db.Exec(`UPDATE users SET email_confirmed = true WHERE id = $1`, userID)
 	return err
 }
 
-func (r *SQLXRepository) SetUserPending(userID string) error {
-	_, err := r.db.Exec(`UPDATE users SET status = $1 WHERE id = $2`, StatusPending, userID)
+func (r *SQLXRepository) SetConfirmationSentAt(userID string, sentAt time.Time) error {
+	_, err := r.db.Exec(`UPDATE users SET last_confirmation_sent_at = $1 WHERE id = $2`, sentAt, userID)
 	return err
 }
 
-func (r *SQLXRepository) UpdateEmailConfirmation(userID string, token string, sentAt time.Time) error {
-	_, err := r.db.Exec(`
-		UPDATE users
-		SET email_confirmation_token = $1, last_confirmation_sent_at = $2
-		WHERE id = $3
-	`, token, sentAt, userID)
-	return err
-}
+// CreateProfile stores the first profile of a user and submits the registration for moderation.
+func (r *SQLXRepository) CreateProfile(profile *UserProfile, statusChange *domainuser.StatusChange) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
 
-func (r *SQLXRepository) CreateProfile(profile *UserProfile) error {
 	query := `
 		INSERT INTO user_profiles (
 			user_id, salutation, title, first_name, last_name,
@@ -120,8 +119,116 @@ func (r *SQLXRepository) CreateProfile(profile *UserProfile) error {
 			:street, :house_number, :postal_code, :city, :updated_at
 		)
 	`
-	_, err := r.db.NamedExec(query, profile)
-	return err
+	if _, err := tx.NamedExec(query, profile); err != nil {
+		return err
+	}
+
+	if err := userstatus.Apply(tx, statusChange); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetProfile returns the user's profile, or nil if the user has not created one yet.
+func (r *SQLXRepository) GetProfile(userID string) (*UserProfile, error) {
+	var profile UserProfile
+	err := r.db.Get(&profile, `
+		SELECT user_id, salutation, title, first_name, last_name,
+		       street, house_number, postal_code, city, updated_at
+		FROM user_profiles
+		WHERE user_id = $1
+	`, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// UpdateProfile stores an edited profile together with its diff and, if the edit moves
+// the user to another status, the status transition. The user row stays locked while the
+// profile is written, so an edit computed for a pending user cannot land after an approval.
+// It returns domainuser.ErrStatusConflict if the status is no longer change.PreviousStatus.
+func (r *SQLXRepository) UpdateProfile(profile *UserProfile, change *domainuser.ProfileChange, statusChange *domainuser.StatusChange) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var status string
+	if err := tx.Get(&status, `SELECT status FROM users WHERE id = $1 FOR UPDATE`, change.UserID); err != nil {
+		return err
+	}
+	if status != change.PreviousStatus {
+		return domainuser.ErrStatusConflict
+	}
+
+	if _, err := tx.NamedExec(`
+		UPDATE user_profiles
+		SET salutation = :salutation, title = :title, first_name = :first_name, last_name = :last_name,
+		    street = :street, house_number = :house_number, postal_code = :postal_code, city = :city,
+		    updated_at = :updated_at
+		WHERE user_id = :user_id
+	`, profile); err != nil {
+		return err
+	}
+
+	if statusChange != nil {
+		if err := userstatus.Apply(tx, statusChange); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.NamedExec(`
+		INSERT INTO profile_changes (id, user_id, rejection_id, changes, previous_status, new_status, created_at)
+		VALUES (:id, :user_id, :rejection_id, :changes, :previous_status, :new_status, :created_at)
+	`, change); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetLatestRejection returns the most recent rejection of the user, or nil if there is none.
+func (r *SQLXRepository) GetLatestRejection(userID string) (*domainuser.Rejection, error) {
+	var rejection domainuser.Rejection
+	err := r.db.Get(&rejection, `
+		SELECT id, user_id, errors, rejected_at
+		FROM user_rejections
+		WHERE user_id = $1
+		ORDER BY rejected_at DESC
+		LIMIT 1
+	`, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &rejection, nil
+}
+
+// GetActiveBlock returns the suspension or ban currently in force, or nil if there is none.
+func (r *SQLXRepository) GetActiveBlock(userID string) (*domainuser.AccountBlock, error) {
+	var block domainuser.AccountBlock
+	err := r.db.Get(&block, `
+		SELECT id, user_id, kind, reason, previous_status, ends_at, created_by, created_at, lifted_at, lifted_by
+		FROM account_blocks
+		WHERE user_id = $1 AND lifted_at IS NULL AND (ends_at IS NULL OR ends_at > NOW())
+	`, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &block, nil
 }
 
 func (r *SQLXRepository) GetByID(id string) (*User, error) {
@@ -139,7 +246,6 @@ func (r *SQLXRepository) Update(user *User) error {
 		SET email = :email,
 		    password = :password,
 		    role = :role,
-		    email_confirmation_token = :email_confirmation_token,
 		    last_confirmation_sent_at = :last_confirmation_sent_at,
 		    email_confirmed = :email_confirmed,
 		    created_at = :created_at
@@ -151,57 +257,409 @@ func (r *SQLXRepository) Update(user *User) error {
 
 func (r *SQLXRepository) StoreRefreshToken(token *RefreshToken) error {
 	query := `
-		INSERT INTO refresh_tokens (id, user_id, token, expires_at, created_at)
-		VALUES (:id, :user_id, :token, :expires_at, :created_at)
+		INSERT INTO refresh_tokens (id, user_id, session_id, token_hash, expires_at, created_at)
+		VALUES (:id, :user_id, :session_id, :token_hash, :expires_at, :created_at)
 	`
 	_, err := r.db.NamedExec(query, token)
 	return err
 }
 
-func (r *SQLXRepository) GetRefreshToken(tokenStr string) (*RefreshToken, error) {
+func (r *SQLXRepository) GetRefreshToken(tokenHash string) (*RefreshToken, error) {
 	var token RefreshToken
-	err := r.db.Get(&token, "SELECT * FROM refresh_tokens WHERE token=$1", tokenStr)
+	err := r.db.Get(&token, "SELECT * FROM refresh_tokens WHERE token_hash = $1", tokenHash)
 	if err != nil {
 		return nil, err
 	}
 	return &token, nil
 }
 
-func (r *SQLXRepository) DeleteRefreshTokensByUserID(userID string) error {
-	_, err := r.db.Exec("DELETE FROM refresh_tokens WHERE user_id = $1", userID)
-	return err
+// MarkRefreshTokenUsed flags a refresh token as rotated. It reports false when the
+// token was already used or revoked, so concurrent refreshes cannot both succeed.
+func (r *SQLXRepository) MarkRefreshTokenUsed(id string) (bool, error) {
+	res, err := r.db.Exec(`
+		UPDATE refresh_tokens
+		SET used_at = NOW()
+		WHERE id = $1 AND used_at IS NULL AND revoked_at IS NULL
+	`, id)
+	if err != nil {
+		return false, err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows == 1, nil
+}
+
+func (r *SQLXRepository) GetRolePermissions(role string) ([]string, error) {
+	var permissions []string
+	err := r.db.Select(&permissions, `
+		SELECT permission FROM role_permissions
+		WHERE role = $1
+		ORDER BY permission
+	`, role)
+	return permissions, err
 }
 
-func (r *SQLXRepository) CreatePasswordResetToken(token *UserPasswordResetToken) error {
+func (r *SQLXRepository) CreateSession(session *Session) error {
 	query := `
-		INSERT INTO user_password_reset_tokens (id, user_id, token, created_at, expires_at)
-		VALUES (:id, :user_id, :token, :created_at, :expires_at)
+		INSERT INTO user_sessions (id, user_id, device, user_agent, ip, created_at, last_used_at, mfa)
+		VALUES (:id, :user_id, :device, :user_agent, :ip, :created_at, :last_used_at, :mfa)
 	`
-	_, err := r.db.NamedExec(query, token)
+	_, err := r.db.NamedExec(query, session)
+	return err
+}
+
+func (r *SQLXRepository) GetSession(id string) (*Session, error) {
+	var session Session
+	err := r.db.Get(&session, "SELECT * FROM user_sessions WHERE id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *SQLXRepository) ListActiveSessions(userID string) ([]Session, error) {
+	var sessions []Session
+	err := r.db.Select(&sessions, `
+		SELECT * FROM user_sessions
+		WHERE user_id = $1 AND revoked_at IS NULL
+		ORDER BY last_used_at DESC
+	`, userID)
+	return sessions, err
+}
+
+func (r *SQLXRepository) TouchSession(id, ip string) error {
+	_, err := r.db.Exec(`
+		UPDATE user_sessions
+		SET last_used_at = NOW(), ip = $1
+		WHERE id = $2
+	`, ip, id)
+	return err
+}
+
+func (r *SQLXRepository) IsSessionActive(id string) (bool, error) {
+	var active bool
+	err := r.db.Get(&active, `
+		SELECT EXISTS (SELECT 1 FROM user_sessions WHERE id = $1 AND revoked_at IS NULL)
+	`, id)
+	return active, err
+}
+
+// RevokeSession revokes a session together with all of its refresh tokens.
+func (r *SQLXRepository) RevokeSession(id string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(`
+		UPDATE user_sessions SET revoked_at = NOW()
+		WHERE id = $1 AND revoked_at IS NULL
+	`, id); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE refresh_tokens SET revoked_at = NOW()
+		WHERE session_id = $1 AND revoked_at IS NULL
+	`, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RevokeUserSessions revokes every session of a user except exceptSessionID.
+// Pass an empty exceptSessionID to revoke all of them.
+func (r *SQLXRepository) RevokeUserSessions(userID, exceptSessionID string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(`
+		UPDATE user_sessions SET revoked_at = NOW()
+		WHERE user_id = $1 AND revoked_at IS NULL AND id::text <> $2
+	`, userID, exceptSessionID); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE refresh_tokens SET revoked_at = NOW()
+		WHERE user_id = $1 AND revoked_at IS NULL AND session_id::text <> $2
+	`, userID, exceptSessionID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SetTOTPSecret stores a new, not yet confirmed TOTP secret for the user.
+func (r *SQLXRepository) SetTOTPSecret(userID, secret string) error {
+	_, err := r.db.Exec(`
+		UPDATE users
+		SET totp_secret = $1, totp_enabled = false, totp_last_used_step = NULL
+		WHERE id = $2
+	`, secret, userID)
 	return err
 }
 
-func (r *SQLXRepository) GetPasswordResetToken(token string) (*UserPasswordResetToken, error) {
-	var result UserPasswordResetToken
+func (r *SQLXRepository) EnableTOTP(userID string) error {
+	_, err := r.db.Exec(`UPDATE users SET totp_enabled = true WHERE id = $1`, userID)
+	return err
+}
+
+// DisableTOTP removes the TOTP secret and all recovery codes of the user.
+func (r *SQLXRepository) DisableTOTP(userID string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(`
+		UPDATE users
+		SET totp_secret = NULL, totp_enabled = false, totp_last_used_step = NULL
+		WHERE id = $1
+	`, userID); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM user_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UseTOTPStep records the time step of an accepted code. It reports false when
+// the same or a later step was already used, which rejects replayed codes.
+func (r *SQLXRepository) UseTOTPStep(userID string, step int64) (bool, error) {
+	res, err := r.db.Exec(`
+		UPDATE users
+		SET totp_last_used_step = $1
+		WHERE id = $2 AND (totp_last_used_step IS NULL OR totp_last_used_step < $1)
+	`, step, userID)
+	if err != nil {
+		return false, err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows == 1, nil
+}
+
+// ReplaceRecoveryCodes deletes the user's recovery codes and stores the new hashes.
+func (r *SQLXRepository) ReplaceRecoveryCodes(userID string, codeHashes []string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(`DELETE FROM user_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	for _, hash := range codeHashes {
+		if _, err := tx.Exec(`
+			INSERT INTO user_recovery_codes (user_id, code_hash)
+			VALUES ($1, $2)
+		`, userID, hash); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// UseRecoveryCode marks an unused recovery code as used and reports whether one matched.
+func (r *SQLXRepository) UseRecoveryCode(userID, codeHash string) (bool, error) {
+	res, err := r.db.Exec(`
+		UPDATE user_recovery_codes
+		SET used_at = NOW()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows == 1, nil
+}
+
+func (r *SQLXRepository) CreateMFAChallenge(challenge *MFAChallenge) error {
 	query := `
-		SELECT id, user_id, token, created_at, used_at, expires_at
-		FROM user_password_reset_tokens
-		WHERE token = $1
+		INSERT INTO mfa_challenges (id, user_id, token_hash, attempts, expires_at, created_at)
+		VALUES (:id, :user_id, :token_hash, :attempts, :expires_at, :created_at)
 	`
-	err := r.db.Get(&result, query, token)
+	_, err := r.db.NamedExec(query, challenge)
+	return err
+}
+
+func (r *SQLXRepository) GetMFAChallenge(tokenHash string) (*MFAChallenge, error) {
+	var challenge MFAChallenge
+	err := r.db.Get(&challenge, "SELECT * FROM mfa_challenges WHERE token_hash = $1", tokenHash)
 	if err != nil {
 		return nil, err
 	}
-	return &result, nil
+	return &challenge, nil
 }
 
-func (r *SQLXRepository) MarkResetTokenUsed(token string) error {
-	query := `
-		UPDATE user_password_reset_tokens
+func (r *SQLXRepository) IncrementMFAChallengeAttempts(id string) error {
+	_, err := r.db.Exec(`UPDATE mfa_challenges SET attempts = attempts + 1 WHERE id = $1`, id)
+	return err
+}
+
+// MarkMFAChallengeUsed consumes a challenge. It reports false when it was already used.
+func (r *SQLXRepository) MarkMFAChallengeUsed(id string) (bool, error) {
+	res, err := r.db.Exec(`
+		UPDATE mfa_challenges
 		SET used_at = NOW()
-		WHERE token = $1
-	`
-	_, err := r.db.Exec(query, token)
+		WHERE id = $1 AND used_at IS NULL
+	`, id)
+	if err != nil {
+		return false, err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows == 1, nil
+}
+
+// CreateEmailChange stores a new email change and discards unconfirmed older ones of the user.
+func (r *SQLXRepository) CreateEmailChange(change *EmailChange) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(`
+		DELETE FROM email_change_requests
+		WHERE user_id = $1 AND confirmed_at IS NULL
+	`, change.UserID); err != nil {
+		return err
+	}
+
+	if _, err := tx.NamedExec(`
+		INSERT INTO email_change_requests (id, user_id, old_email, new_email, token_hash, expires_at, created_at)
+		VALUES (:id, :user_id, :old_email, :new_email, :token_hash, :expires_at, :created_at)
+	`, change); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *SQLXRepository) GetEmailChange(tokenHash string) (*EmailChange, error) {
+	var change EmailChange
+	err := r.db.Get(&change, "SELECT * FROM email_change_requests WHERE token_hash = $1", tokenHash)
+	if err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+func (r *SQLXRepository) GetEmailChangeByUndoToken(undoTokenHash string) (*EmailChange, error) {
+	var change EmailChange
+	err := r.db.Get(&change, "SELECT * FROM email_change_requests WHERE undo_token_hash = $1", undoTokenHash)
+	if err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+// ApplyEmailChange switches the user to the new address and stores the undo token.
+// It reports false when the change was already applied or the user's address has
+// changed in the meantime, and returns ErrEmailExists when the address was taken.
+func (r *SQLXRepository) ApplyEmailChange(id, undoTokenHash string, undoExpiresAt time.Time) (bool, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var change EmailChange
+	err = tx.Get(&change, `
+		UPDATE email_change_requests
+		SET confirmed_at = NOW(), undo_token_hash = $1, undo_expires_at = $2
+		WHERE id = $3 AND confirmed_at IS NULL
+		RETURNING *
+	`, undoTokenHash, undoExpiresAt, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	res, err := tx.Exec(`
+		UPDATE users
+		SET email = $1, email_confirmed = true
+		WHERE id = $2 AND email = $3
+	`, change.NewEmail, change.UserID, change.OldEmail)
+	if err != nil {
+		return false, mapUniqueViolation(err)
+	}
+	if rows, err := res.RowsAffected(); err != nil || rows == 0 {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// RevertEmailChange moves the user back to the old address of a confirmed change.
+// It reports false when the change was already reverted or the address has changed again.
+func (r *SQLXRepository) RevertEmailChange(id string) (bool, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var change EmailChange
+	err = tx.Get(&change, `
+		UPDATE email_change_requests
+		SET reverted_at = NOW()
+		WHERE id = $1 AND confirmed_at IS NOT NULL AND reverted_at IS NULL
+		RETURNING *
+	`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	res, err := tx.Exec(`
+		UPDATE users
+		SET email = $1
+		WHERE id = $2 AND email = $3
+	`, change.OldEmail, change.UserID, change.NewEmail)
+	if err != nil {
+		return false, mapUniqueViolation(err)
+	}
+	if rows, err := res.RowsAffected(); err != nil || rows == 0 {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// mapUniqueViolation turns a violated unique email constraint into ErrEmailExists.
+func mapUniqueViolation(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return ErrEmailExists
+	}
 	return err
 }
 
@@ -214,3 +672,71 @@ func (r *SQLXRepository) UpdateUserPassword(userID, newHashedPassword string) er
 	_, err := r.db.Exec(query, newHashedPassword, userID)
 	return err
 }
+
+// RecordFailedLogin increments the failed login counter and returns its new value.
+func (r *SQLXRepository) RecordFailedLogin(userID string) (int, error) {
+	var attempts int
+	err := r.db.Get(&attempts, `
+		UPDATE users
+		SET failed_login_attempts = failed_login_attempts + 1
+		WHERE id = $1
+		RETURNING failed_login_attempts
+	`, userID)
+	return attempts, err
+}
+
+// LockAccount locks the account until the given time and records the event in the security log.
+func (r *SQLXRepository) LockAccount(userID string, until time.Time, event domainuser.SecurityEvent) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(`
+		UPDATE users
+		SET locked_until = $1
+		WHERE id = $2
+	`, until, userID); err != nil {
+		return err
+	}
+
+	if err := securitylog.Record(tx, event); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ResetFailedLogins clears the failed login counter and the lockout.
+func (r *SQLXRepository) ResetFailedLogins(userID string) error {
+	_, err := r.db.Exec(`
+		UPDATE users
+		SET failed_login_attempts = 0, locked_until = NULL
+		WHERE id = $1
+	`, userID)
+	return err
+}
+
+// UnlockAccount clears the failed login counter and the lockout and records the event.
+func (r *SQLXRepository) UnlockAccount(userID string, event domainuser.SecurityEvent) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(`
+		UPDATE users
+		SET failed_login_attempts = 0, locked_until = NULL
+		WHERE id = $1
+	`, userID); err != nil {
+		return err
+	}
+
+	if err := securitylog.Record(tx, event); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
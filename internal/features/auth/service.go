@@ -1,10 +1,22 @@
 package auth
 
 import (
+	domainuser "carowebapp/core/internal/domain/user"
+
+	"carowebapp/core/internal/features/onetimetoken"
+
 	"carowebapp/core/internal/infrastructure/email"
 
+	"carowebapp/core/internal/pkg/passwordhash"
+
+	"carowebapp/core/internal/pkg/passwordpolicy"
+
 	"crypto/rand"
 
+	"crypto/sha256"
+
+	"crypto/subtle"
+
 	"encoding/hex"
 
 	"errors"
@@ -13,41 +25,82 @@ import (
 
 	"github.com/google/uuid"
 
-	"golang.org/x/crypto/bcrypt"
-
-	"os"
+	"net/mail"
 
 	"strings"
 
+	"sync"
+
 	"time"
 )
 
 var (
 	ErrEmailExists        = errors.New("email already registered")
 	ErrInvalidEmail       = errors.New("invalid email format")
-	ErrWeakPassword       = errors.New("password too weak")
+	ErrWeakPassword       = passwordpolicy.ErrWeakPassword
 	ErrInvalidRole        = errors.New("registration with this role is not allowed")
+	ErrInvalidLanguage    = errors.New("unsupported language")
 	ErrInvalidCredentials = errors.New("invalid email or password")
 	ErrAlreadyConfirmed   = errors.New("email already confirmed")
+	ErrHashingBusy        = passwordhash.ErrBusy
+
+	ErrInvalidConfirmationToken = errors.New("invalid or expired confirmation token")
+	ErrInvalidResetToken        = errors.New("invalid or expired token")
+
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReuse   = errors.New("refresh token reuse detected")
+	ErrSessionNotFound     = errors.New("session not found")
 )
 
+const (
+	accessTokenTTL  = 24 * time.Hour
+	refreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// TokenSigner signs access token claims. The signer adds the registered claims.
+type TokenSigner interface {
+	Sign(claims jwt.MapClaims, ttl time.Duration) (string, error)
+}
+
+// PasswordHasher hashes passwords and verifies them against stored hashes.
+// needsRehash reports a matching hash that was made with outdated settings.
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(hash, password string) (match bool, needsRehash bool, err error)
+}
+
 // Service provides authentication and user management functionality.
 type Service struct {
-	repo   Repository
-	Sender email.Sender
+	repo      Repository
+	Sender    email.Sender
+	signer    TokenSigner
+	passwords *passwordpolicy.Policy
+	hasher    PasswordHasher
+	tokens    *onetimetoken.Service
+
+	// dummyHash is verified for unknown emails, so they take as long as known ones.
+	dummyOnce sync.Once
+	dummyHash string
 }
 
 // NewService creates a new instance of the Service.
-func NewService(repo Repository, sender email.Sender) *Service {
+// The signer may be nil for callers that never issue tokens, such as CLI commands.
+// Confirmation, password reset and login links are issued through tokens.
+func NewService(repo Repository, sender email.Sender, signer TokenSigner, passwords *passwordpolicy.Policy, hasher PasswordHasher, tokens *onetimetoken.Service) *Service {
 	return &Service{
-		repo:   repo,
-		Sender: sender,
+		repo:      repo,
+		Sender:    sender,
+		signer:    signer,
+		passwords: passwords,
+		hasher:    hasher,
+		tokens:    tokens,
 	}
 }
 
 // RegisterUser registers a new user with the specified email, password, and role.
+// Emails are sent in the given language, or in the default language if it is empty.
 // If calledFromScript is true, admin users can be registered.
-func (s *Service) RegisterUser(email, password, role string, calledFromScript ...bool) (*User, error) {
+func (s *Service) RegisterUser(email, password, role, language string, calledFromScript ...bool) (*User, error) {
 	email = strings.TrimSpace(email)
 
 	isCalledFromScript := len(calledFromScript) > 0 && calledFromScript[0]
@@ -56,8 +109,19 @@ func (s *Service) RegisterUser(email, password, role string, calledFromScript ..
 		return nil, ErrInvalidRole
 	}
 
-	if len(password) < 6 {
-		return nil, ErrWeakPassword
+	if language == "" {
+		language = domainuser.DefaultLanguage
+	}
+	if !domainuser.IsSupportedLanguage(language) {
+		return nil, ErrInvalidLanguage
+	}
+
+	if _, err := mail.ParseAddress(email); err != nil {
+		return nil, ErrInvalidEmail
+	}
+
+	if err := s.passwords.Validate(password, email); err != nil {
+		return nil, err
 	}
 
 	exists, err := s.repo.EmailExists(email)
@@ -68,81 +132,292 @@ func (s *Service) RegisterUser(email, password, role string, calledFromScript ..
 		return nil, ErrEmailExists
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hashedPassword, err := s.hasher.Hash(password)
 	if err != nil {
 		return nil, err
 	}
 
-	token := generateToken()
 	now := time.Now()
-
 	user := &User{
 		ID:                     uuid.New().String(),
 		Email:                  email,
-		Password:               string(hashedPassword),
+		Password:               hashedPassword,
 		Role:                   role,
+		Language:               language,
 		EmailConfirmed:         false,
-		EmailConfirmationToken: &token,
 		CreatedAt:              now,
 		LastConfirmationSentAt: &now,
 	}
 
+	created, err := s.repo.Create(user)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := s.tokens.Issue(onetimetoken.PurposeEmailConfirmation, created.ID, "")
+	if err != nil {
+		return nil, err
+	}
+
 	go func() {
-		_ = s.Sender.SendConfirmation(user.Email, token)
+		_ = s.Sender.SendConfirmation(created.Email, token)
 	}()
 
-	return s.repo.Create(user)
+	return created, nil
 }
 
 // Login authenticates a user with the provided email and password.
-// It generates and returns access and refresh tokens upon successful authentication.
-func (s *Service) Login(email, password string) (*User, string, string, error) {
+// It opens a new session for the client and returns access and refresh tokens.
+// Sessions on other devices stay active. Users with two-factor authentication
+// receive a challenge token instead, see VerifyLoginChallenge.
+// Hashes made with an outdated algorithm or cost are replaced on success.
+// Repeated failures lock the account temporarily, see recordFailedLogin.
+// Unknown emails and locked accounts are answered like a wrong password, after the
+// same password check, so neither the error nor the timing shows whether an email
+// is registered. The owner of a locked account learns about it from the unlock email.
+func (s *Service) Login(email, password string, info SessionInfo) (*AuthResult, error) {
 	email = strings.TrimSpace(email)
 	if email == "" || password == "" {
-		return nil, "", "", ErrInvalidCredentials
+		return nil, ErrInvalidCredentials
 	}
 
 	user, err := s.repo.GetByEmail(email)
 	if err != nil || user == nil {
-		return nil, "", "", ErrInvalidCredentials
+		if err := s.verifyDummy(password); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidCredentials
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
+	match, needsRehash, err := s.hasher.Verify(user.Password, password)
 	if err != nil {
-		return nil, "", "", ErrInvalidCredentials
+		return nil, err
+	}
+
+	if user.LockedUntil != nil && time.Now().Before(*user.LockedUntil) {
+		return nil, &AccountLockedError{Until: *user.LockedUntil}
+	}
+	if !match {
+		return nil, s.recordFailedLogin(user, info)
+	}
+
+	// With two-factor authentication the login is not complete yet, so failures are
+	// kept until VerifyLoginChallenge succeeds.
+	if !user.TOTPEnabled {
+		if err := s.resetFailedLogins(user); err != nil {
+			return nil, err
+		}
+	}
+
+	if needsRehash {
+		// The old hash still works, so a failed upgrade must not fail the login.
+		if hashed, err := s.hasher.Hash(password); err == nil {
+			_ = s.repo.UpdateUserPassword(user.ID, hashed)
+		}
+	}
+
+	return s.finishLogin(user, info)
+}
+
+// verifyDummy checks the password against a hash no password matches, to spend the time
+// a real check would take.
+func (s *Service) verifyDummy(password string) error {
+	s.dummyOnce.Do(func() {
+		s.dummyHash, _ = s.hasher.Hash(generateToken())
+	})
+	if s.dummyHash == "" {
+		return nil
+	}
+	_, _, err := s.hasher.Verify(s.dummyHash, password)
+	return err
+}
+
+// finishLogin completes the first login step. Suspended and banned users are turned away,
+// users with two-factor authentication get a challenge, everyone else a new session.
+func (s *Service) finishLogin(user *User, info SessionInfo) (*AuthResult, error) {
+	if err := s.checkBlocked(user.ID); err != nil {
+		return nil, err
+	}
+
+	if user.TOTPEnabled {
+		challengeToken, err := s.createMFAChallenge(user.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &AuthResult{User: user, ChallengeToken: challengeToken}, nil
+	}
+
+	return s.startSession(user, info, false)
+}
+
+// Refresh exchanges a refresh token for a new access and refresh token pair.
+// Every refresh token can be used only once. Presenting an already rotated
+// token revokes its whole session and returns ErrRefreshTokenReuse.
+func (s *Service) Refresh(tokenStr string, info SessionInfo) (*AuthResult, error) {
+	stored, err := s.repo.GetRefreshToken(hashToken(tokenStr))
+	if err != nil || stored == nil || !tokenMatches(stored.TokenHash, tokenStr) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if stored.RevokedAt != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if stored.UsedAt != nil {
+		if err := s.repo.RevokeSession(stored.SessionID); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReuse
 	}
 
-	if err := s.repo.DeleteRefreshTokensByUserID(user.ID); err != nil {
-		return nil, "", "", err
+	if time.Now().After(stored.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
 	}
 
-	refreshTokenStr := generateToken()
-	accessToken, err := generateJWT(user.ID)
+	rotated, err := s.repo.MarkRefreshTokenUsed(stored.ID)
 	if err != nil {
-		return nil, "", "", err
+		return nil, err
+	}
+	if !rotated {
+		// Another request rotated the same token first.
+		if err := s.repo.RevokeSession(stored.SessionID); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReuse
+	}
+
+	session, err := s.repo.GetSession(stored.SessionID)
+	if err != nil || session == nil || session.RevokedAt != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := s.repo.GetByID(stored.UserID)
+	if err != nil || user == nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if err := s.checkBlocked(user.ID); err != nil {
+		return nil, err
 	}
 
+	if err := s.repo.TouchSession(session.ID, info.IP); err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(user, session)
+}
+
+// ListSessions returns the active sessions of a user, most recently used first.
+func (s *Service) ListSessions(userID string) ([]Session, error) {
+	return s.repo.ListActiveSessions(userID)
+}
+
+// Logout revokes the given session and all tokens issued for it.
+func (s *Service) Logout(sessionID string) error {
+	return s.repo.RevokeSession(sessionID)
+}
+
+// RevokeSession revokes one of the user's own sessions.
+func (s *Service) RevokeSession(userID, sessionID string) error {
+	session, err := s.repo.GetSession(sessionID)
+	if err != nil || session == nil || session.UserID != userID || session.RevokedAt != nil {
+		return ErrSessionNotFound
+	}
+
+	return s.repo.RevokeSession(sessionID)
+}
+
+// LogoutAll revokes every session of the user, including the current one.
+func (s *Service) LogoutAll(userID string) error {
+	return s.repo.RevokeUserSessions(userID, "")
+}
+
+// startSession opens a new session for the user and issues its first token pair.
+// mfa records whether the login was completed with a second factor.
+func (s *Service) startSession(user *User, info SessionInfo, mfa bool) (*AuthResult, error) {
+	now := time.Now()
+	session := &Session{
+		ID:         uuid.New().String(),
+		UserID:     user.ID,
+		Device:     info.Device,
+		UserAgent:  info.UserAgent,
+		IP:         info.IP,
+		CreatedAt:  now,
+		LastUsedAt: now,
+		MFA:        mfa,
+	}
+	if err := s.repo.CreateSession(session); err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(user, session)
+}
+
+// issueTokens signs an access token and stores a new refresh token for the session.
+// The role and its permissions are embedded in the access token, so changes to the
+// role mapping take effect with the next refresh.
+func (s *Service) issueTokens(user *User, session *Session) (*AuthResult, error) {
+	permissions, err := s.repo.GetRolePermissions(user.Role)
+	if err != nil {
+		return nil, err
+	}
+	if permissions == nil {
+		permissions = []string{}
+	}
+
+	accessToken, err := s.signer.Sign(jwt.MapClaims{
+		"sub":   user.ID,
+		"sid":   session.ID,
+		"mfa":   session.MFA,
+		"role":  user.Role,
+		"perms": permissions,
+	}, accessTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	token := generateToken()
+	now := time.Now()
 	refreshToken := &RefreshToken{
 		ID:        uuid.New().String(),
-		Token:     refreshTokenStr,
 		UserID:    user.ID,
-		ExpiresAt: time.Now().Add(30 * 24 * time.Hour),
+		SessionID: session.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: now.Add(refreshTokenTTL),
+		CreatedAt: now,
 	}
 	if err := s.repo.StoreRefreshToken(refreshToken); err != nil {
-		return nil, "", "", err
+		return nil, err
 	}
 
-	return user, accessToken, refreshTokenStr, nil
+	return &AuthResult{
+		User:         user,
+		AccessToken:  accessToken,
+		RefreshToken: token,
+	}, nil
 }
 
-// GetByConfirmationToken retrieves a user by their email confirmation token.
-func (s *Service) GetByConfirmationToken(token string) (*User, error) {
-	return s.repo.GetByConfirmationToken(token)
-}
+// ConfirmEmail uses up an email confirmation token and marks the user's email as confirmed.
+// Unknown, used and expired tokens return ErrInvalidConfirmationToken.
+func (s *Service) ConfirmEmail(token string) (*User, error) {
+	confirmation, err := s.tokens.Consume(onetimetoken.PurposeEmailConfirmation, token, "")
+	if errors.Is(err, onetimetoken.ErrInvalidToken) {
+		return nil, ErrInvalidConfirmationToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.repo.GetByID(confirmation.UserID)
+	if err != nil || user == nil {
+		return nil, ErrInvalidConfirmationToken
+	}
+
+	if err := s.repo.SetEmailConfirmed(user.ID); err != nil {
+		return nil, err
+	}
 
-// ConfirmEmail confirms a user's email by setting the email_confirmed field to true.
-func (s *Service) ConfirmEmail(userID string) error {
-	return s.repo.SetEmailConfirmed(userID)
+	return user, nil
 }
 
 // ResendConfirmationByUserID resends an email confirmation link to the user.
@@ -163,13 +438,12 @@ func (s *Service) ResendConfirmationByUserID(userID string) error {
 		return errors.New("please wait before requesting another email")
 	}
 
-	token := generateToken()
-	now := time.Now()
-
-	user.EmailConfirmationToken = &token
-	user.LastConfirmationSentAt = &now
+	token, err := s.tokens.Issue(onetimetoken.PurposeEmailConfirmation, user.ID, "")
+	if err != nil {
+		return err
+	}
 
-	if err := s.repo.Update(user); err != nil {
+	if err := s.repo.SetConfirmationSentAt(user.ID, time.Now()); err != nil {
 		return err
 	}
 
@@ -191,16 +465,23 @@ func (s *Service) AddUserProfile(profile *UserProfile) error {
 		return errors.New("user not found")
 	}
 
-	if user.Profile != nil {
-		return errors.New("profile already exists")
+	existing, err := s.repo.GetProfile(profile.UserID)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return ErrProfileExists
 	}
 
-	err = s.repo.CreateProfile(profile)
+	statusChange, err := domainuser.ChangeStatus(user.ID, user.Status, domainuser.TransitionSubmit, domainuser.StatusFacts{
+		EmailConfirmed: user.EmailConfirmed,
+		HasProfile:     true,
+	}, "")
 	if err != nil {
 		return err
 	}
 
-	return s.repo.SetUserPending(profile.UserID)
+	return s.repo.CreateProfile(profile, statusChange)
 }
 
 // RequestPasswordReset sends a password reset link to the specified email.
@@ -210,62 +491,78 @@ func (s *Service) RequestPasswordReset(email string) error {
 		return nil
 	}
 
-	token := generateToken()
-	now := time.Now()
-
-	reset := &UserPasswordResetToken{
-		ID:        uuid.New().String(),
-		UserID:    user.ID,
-		Token:     token,
-		CreatedAt: now,
-		ExpiresAt: now.Add(30 * time.Minute),
-	}
-
-	if err := s.repo.CreatePasswordResetToken(reset); err != nil {
+	token, err := s.tokens.Issue(onetimetoken.PurposePasswordReset, user.ID, "")
+	if err != nil {
 		return err
 	}
 
 	return s.Sender.SendResetPasswordLink(email, token)
 }
 
-// IsResetTokenValid checks if the given password reset token is still valid.
+// IsResetTokenValid checks if the given password reset token is known, unused and not expired.
+// Every check counts against the token's attempt limit.
 func (s *Service) IsResetTokenValid(token string) (bool, error) {
-	reset, err := s.repo.GetPasswordResetToken(token)
-	if err != nil || reset == nil {
-		return true, err
-	}
-
-	if reset.UsedAt != nil || time.Now().After(reset.ExpiresAt) {
+	_, err := s.tokens.Check(onetimetoken.PurposePasswordReset, token, "")
+	if errors.Is(err, onetimetoken.ErrInvalidToken) {
 		return false, nil
 	}
-
-	return true, nil
+	return err == nil, err
 }
 
 // ResetPassword updates the user's password using the provided reset token.
+// The token stays usable when the new password is rejected by the policy.
 func (s *Service) ResetPassword(token string, newPassword string) error {
-	reset, err := s.repo.GetPasswordResetToken(token)
-	if err != nil || reset == nil {
-		return errors.New("invalid or expired token")
+	reset, err := s.tokens.Check(onetimetoken.PurposePasswordReset, token, "")
+	if err != nil {
+		return resetTokenError(err)
 	}
 
-	if reset.UsedAt != nil || time.Now().After(reset.ExpiresAt) {
-		return errors.New("token is no longer valid")
+	if err := s.validatePasswordFor(reset.UserID, newPassword); err != nil {
+		return err
 	}
 
-	hashed, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	hashed, err := s.hasher.Hash(newPassword)
 	if err != nil {
 		return err
 	}
 
-	if err := s.repo.UpdateUserPassword(reset.UserID, string(hashed)); err != nil {
+	if _, err := s.tokens.Consume(onetimetoken.PurposePasswordReset, token, ""); err != nil {
+		return resetTokenError(err)
+	}
+
+	return s.repo.UpdateUserPassword(reset.UserID, hashed)
+}
+
+// resetTokenError maps token failures to ErrInvalidResetToken.
+func resetTokenError(err error) error {
+	if errors.Is(err, onetimetoken.ErrInvalidToken) {
+		return ErrInvalidResetToken
+	}
+	return err
+}
+
+// validatePasswordFor checks a new password of an existing user against the
+// password policy, including the user's email address and names.
+func (s *Service) validatePasswordFor(userID, password string) error {
+	user, err := s.repo.GetByID(userID)
+	if err != nil {
 		return err
 	}
 
-	return s.repo.MarkResetTokenUsed(token)
+	personalInfo := []string{user.Email}
+
+	profile, err := s.repo.GetProfile(userID)
+	if err != nil {
+		return err
+	}
+	if profile != nil {
+		personalInfo = append(personalInfo, profile.FirstName, profile.LastName)
+	}
+
+	return s.passwords.Validate(password, personalInfo...)
 }
 
-// generateToken generates a secure random token as a string.
+// isValidRole checks if the provided role is one of the allowed roles.
 func isValidRole(role string) bool {
 	switch role {
 	case RoleHomeowner, RoleManager:
@@ -275,21 +572,20 @@ func isValidRole(role string) bool {
 	}
 }
 
-// generateJWT generates a JSON Web Token (JWT) for the specified user ID.
+// generateToken generates a secure random token as a string.
 func generateToken() string {
 	b := make([]byte, 32)
 	_, _ = rand.Read(b)
 	return hex.EncodeToString(b)
 }
 
-// isValidRole checks if the provided role is one of the allowed roles.
-func generateJWT(userID string) (string, error) {
-	secret := os.Getenv("JWT_SECRET")
-	claims := jwt.MapClaims{
-		"sub": userID,
-		"exp": time.Now().Add(24 * time.Hour).Unix(),
-	}
+// tokenMatches compares a presented token with a stored digest in constant time.
+func tokenMatches(hash, token string) bool {
+	return subtle.ConstantTimeCompare([]byte(hash), []byte(hashToken(token))) == 1
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+// hashToken returns the hex-encoded SHA-256 digest of a secret value.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
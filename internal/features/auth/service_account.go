@@ -0,0 +1,185 @@
+package auth
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+
+	"net/mail"
+
+	"strings"
+
+	"time"
+)
+
+var (
+	ErrEmailUnchanged     = errors.New("new email is the same as the current one")
+	ErrInvalidEmailChange = errors.New("invalid or expired email change link")
+)
+
+const (
+	emailChangeTTL     = 24 * time.Hour
+	emailChangeUndoTTL = 7 * 24 * time.Hour
+)
+
+// ChangePassword replaces the password of a logged-in user after checking the
+// current one. All sessions except the current one are revoked.
+func (s *Service) ChangePassword(userID, sessionID, currentPassword, newPassword string) error {
+	user, err := s.repo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+
+	match, _, err := s.hasher.Verify(user.Password, currentPassword)
+	if err != nil {
+		return err
+	}
+	if !match {
+		return ErrInvalidCredentials
+	}
+
+	if err := s.validatePasswordFor(userID, newPassword); err != nil {
+		return err
+	}
+
+	hashed, err := s.hasher.Hash(newPassword)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.UpdateUserPassword(userID, hashed); err != nil {
+		return err
+	}
+
+	return s.repo.RevokeUserSessions(userID, sessionID)
+}
+
+// RequestEmailChange starts moving the account to a new address. The change takes
+// effect once the link sent to the new address is confirmed, see ConfirmEmailChange.
+func (s *Service) RequestEmailChange(userID, newEmail, password string) error {
+	newEmail = strings.TrimSpace(newEmail)
+	if _, err := mail.ParseAddress(newEmail); err != nil {
+		return ErrInvalidEmail
+	}
+
+	user, err := s.repo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+
+	match, _, err := s.hasher.Verify(user.Password, password)
+	if err != nil {
+		return err
+	}
+	if !match {
+		return ErrInvalidCredentials
+	}
+
+	if strings.EqualFold(user.Email, newEmail) {
+		return ErrEmailUnchanged
+	}
+
+	exists, err := s.repo.EmailExists(newEmail)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrEmailExists
+	}
+
+	token := generateToken()
+	now := time.Now()
+	change := &EmailChange{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		OldEmail:  user.Email,
+		NewEmail:  newEmail,
+		TokenHash: hashToken(token),
+		ExpiresAt: now.Add(emailChangeTTL),
+		CreatedAt: now,
+	}
+	if err := s.repo.CreateEmailChange(change); err != nil {
+		return err
+	}
+
+	go func() {
+		_ = s.Sender.SendEmailChangeConfirmation(newEmail, token)
+	}()
+
+	return nil
+}
+
+// ConfirmEmailChange switches the account to the confirmed address and sends the
+// old address an alert with a link to undo the change.
+func (s *Service) ConfirmEmailChange(token string) error {
+	change, err := s.repo.GetEmailChange(hashToken(token))
+	if err != nil || change == nil || !tokenMatches(change.TokenHash, token) {
+		return ErrInvalidEmailChange
+	}
+
+	if change.ConfirmedAt != nil || time.Now().After(change.ExpiresAt) {
+		return ErrInvalidEmailChange
+	}
+
+	// The address may have been registered since the change was requested.
+	exists, err := s.repo.EmailExists(change.NewEmail)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrEmailExists
+	}
+
+	undoToken := generateToken()
+	applied, err := s.repo.ApplyEmailChange(change.ID, hashToken(undoToken), time.Now().Add(emailChangeUndoTTL))
+	if err != nil {
+		return err
+	}
+	if !applied {
+		return ErrInvalidEmailChange
+	}
+
+	go func() {
+		_ = s.Sender.SendEmailChangedAlert(change.OldEmail, change.NewEmail, undoToken)
+	}()
+
+	return nil
+}
+
+// UndoEmailChange restores the previous address of a confirmed change. Because the
+// change may have been made by someone else, every session of the user is revoked.
+func (s *Service) UndoEmailChange(undoToken string) error {
+	change, err := s.repo.GetEmailChangeByUndoToken(hashToken(undoToken))
+	if err != nil || change == nil || change.UndoTokenHash == nil || !tokenMatches(*change.UndoTokenHash, undoToken) {
+		return ErrInvalidEmailChange
+	}
+
+	if change.RevertedAt != nil || change.UndoExpiresAt == nil || time.Now().After(*change.UndoExpiresAt) {
+		return ErrInvalidEmailChange
+	}
+
+	reverted, err := s.repo.RevertEmailChange(change.ID)
+	if err != nil {
+		return err
+	}
+	if !reverted {
+		return ErrInvalidEmailChange
+	}
+
+	return s.repo.RevokeUserSessions(change.UserID, "")
+}
+
+// CheckPassword reports whether password is the user's current password.
+// It is used to re-authenticate sensitive actions of other features.
+func (s *Service) CheckPassword(userID, password string) (bool, error) {
+	user, err := s.repo.GetByID(userID)
+	if err != nil {
+		return false, err
+	}
+	if user == nil {
+		return false, nil
+	}
+
+	match, _, err := s.hasher.Verify(user.Password, password)
+	return match, err
+}
@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"errors"
+
+	"fmt"
+
+	"time"
+)
+
+var ErrAccountBlocked = errors.New("account is suspended or banned")
+
+// AccountBlockedError is returned when a suspended or banned user tries to sign in or
+// refresh their tokens. Until is nil for a ban.
+type AccountBlockedError struct {
+	Kind   string
+	Reason string
+	Until  *time.Time
+}
+
+func (e *AccountBlockedError) Error() string {
+	if e.Until == nil {
+		return fmt.Sprintf("%s: %s", ErrAccountBlocked, e.Kind)
+	}
+	return fmt.Sprintf("%s: %s until %s", ErrAccountBlocked, e.Kind, e.Until.UTC().Format(time.RFC3339))
+}
+
+func (e *AccountBlockedError) Is(target error) bool {
+	return target == ErrAccountBlocked
+}
+
+// checkBlocked returns an AccountBlockedError if a suspension or ban of the user is in force.
+func (s *Service) checkBlocked(userID string) error {
+	block, err := s.repo.GetActiveBlock(userID)
+	if err != nil {
+		return err
+	}
+	if block == nil {
+		return nil
+	}
+	return &AccountBlockedError{Kind: block.Kind, Reason: block.Reason, Until: block.EndsAt}
+}
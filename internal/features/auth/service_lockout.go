@@ -0,0 +1,121 @@
+package auth
+
+import (
+	domainuser "carowebapp/core/internal/domain/user"
+
+	"carowebapp/core/internal/features/onetimetoken"
+
+	"errors"
+
+	"fmt"
+
+	"time"
+)
+
+var (
+	ErrAccountLocked      = errors.New("account temporarily locked after too many failed logins")
+	ErrInvalidUnlockToken = errors.New("invalid or expired unlock token")
+)
+
+const (
+	// lockoutThreshold is the number of consecutive failed logins that locks an account.
+	lockoutThreshold = 5
+	lockoutBaseDelay = time.Minute
+	lockoutMaxDelay  = time.Hour
+)
+
+// AccountLockedError is returned for logins to a temporarily locked account. It also
+// matches ErrInvalidCredentials, because clients must not be able to tell a locked account
+// from a wrong password; Until is for the server log only.
+type AccountLockedError struct {
+	Until time.Time
+}
+
+func (e *AccountLockedError) Error() string {
+	return fmt.Sprintf("%s until %s", ErrAccountLocked, e.Until.UTC().Format(time.RFC3339))
+}
+
+func (e *AccountLockedError) Is(target error) bool {
+	return target == ErrAccountLocked || target == ErrInvalidCredentials
+}
+
+// UnlockAccount lifts a lockout using the token from the account locked email.
+func (s *Service) UnlockAccount(token, ip string) error {
+	unlock, err := s.tokens.Consume(onetimetoken.PurposeAccountUnlock, token, "")
+	if errors.Is(err, onetimetoken.ErrInvalidToken) {
+		return ErrInvalidUnlockToken
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.repo.UnlockAccount(unlock.UserID, domainuser.SecurityEvent{
+		UserID:  unlock.UserID,
+		Type:    domainuser.SecurityEventAccountUnlocked,
+		IP:      ip,
+		Details: map[string]any{"method": "email"},
+	})
+}
+
+// recordFailedLogin counts a failed password or second-factor check. From lockoutThreshold failures on,
+// every further failure locks the account for an exponentially growing time. The first
+// lockout of a streak emails the user an unlock link, which expires after the TTL of
+// onetimetoken.PurposeAccountUnlock.
+func (s *Service) recordFailedLogin(user *User, info SessionInfo) error {
+	attempts, err := s.repo.RecordFailedLogin(user.ID)
+	if err != nil {
+		return err
+	}
+	if attempts < lockoutThreshold {
+		return ErrInvalidCredentials
+	}
+
+	until := time.Now().Add(lockoutDelay(attempts))
+
+	var token string
+	if attempts == lockoutThreshold {
+		if token, err = s.tokens.Issue(onetimetoken.PurposeAccountUnlock, user.ID, ""); err != nil {
+			return err
+		}
+	}
+
+	if err := s.repo.LockAccount(user.ID, until, domainuser.SecurityEvent{
+		UserID: user.ID,
+		Type:   domainuser.SecurityEventAccountLocked,
+		IP:     info.IP,
+		Details: map[string]any{
+			"failed_attempts": attempts,
+			"locked_until":    until.UTC(),
+		},
+	}); err != nil {
+		return err
+	}
+
+	if token != "" {
+		go func() {
+			_ = s.Sender.SendAccountLocked(user.Email, token, until)
+		}()
+	}
+
+	return &AccountLockedError{Until: until}
+}
+
+// resetFailedLogins clears the failure streak after a completed login.
+func (s *Service) resetFailedLogins(user *User) error {
+	if user.FailedLoginAttempts == 0 {
+		return nil
+	}
+	return s.repo.ResetFailedLogins(user.ID)
+}
+
+// lockoutDelay doubles the lockout with every failure past the threshold.
+func lockoutDelay(attempts int) time.Duration {
+	delay := lockoutBaseDelay
+	for i := lockoutThreshold; i < attempts && delay < lockoutMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > lockoutMaxDelay {
+		return lockoutMaxDelay
+	}
+	return delay
+}
@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"carowebapp/core/internal/features/onetimetoken"
+
+	"errors"
+
+	"strings"
+)
+
+var ErrInvalidMagicLink = errors.New("invalid or expired login link")
+
+// magicLinkTTL is how long a login link and the nonce cookie of its browser stay valid.
+var magicLinkTTL = onetimetoken.Policies[onetimetoken.PurposeLoginLink].TTL
+
+// RequestMagicLink emails a one-time login link and returns the nonce that binds the
+// link to the requesting browser. A nonce is returned for unknown addresses as well,
+// so the response does not reveal whether an account exists.
+func (s *Service) RequestMagicLink(email string) (string, error) {
+	nonce := generateToken()
+
+	user, err := s.repo.GetByEmail(strings.TrimSpace(email))
+	if err != nil || user == nil {
+		return nonce, nil
+	}
+
+	token, err := s.tokens.Issue(onetimetoken.PurposeLoginLink, user.ID, nonce)
+	if err != nil {
+		return "", err
+	}
+
+	go func() {
+		_ = s.Sender.SendMagicLink(user.Email, token)
+	}()
+
+	return nonce, nil
+}
+
+// LoginWithMagicLink exchanges a magic link for the same result Login returns.
+// The nonce must be the one handed to the browser that requested the link.
+func (s *Service) LoginWithMagicLink(token, nonce string, info SessionInfo) (*AuthResult, error) {
+	link, err := s.tokens.Consume(onetimetoken.PurposeLoginLink, token, nonce)
+	if errors.Is(err, onetimetoken.ErrInvalidToken) {
+		return nil, ErrInvalidMagicLink
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.repo.GetByID(link.UserID)
+	if err != nil || user == nil {
+		return nil, ErrInvalidMagicLink
+	}
+
+	return s.finishLogin(user, info)
+}
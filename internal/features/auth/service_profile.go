@@ -0,0 +1,198 @@
+package auth
+
+import (
+	domainuser "carowebapp/core/internal/domain/user"
+
+	"errors"
+
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"strings"
+
+	"time"
+)
+
+var (
+	ErrProfileExists     = errors.New("profile already exists")
+	ErrProfileNotFound   = errors.New("profile not found, create it first")
+	ErrBlankProfileField = errors.New("profile fields must not be blank")
+)
+
+// ProfileUpdate holds the fields of a partial profile update. Nil fields stay unchanged;
+// an empty Title removes the title.
+type ProfileUpdate struct {
+	Salutation  *string
+	Title       *string
+	FirstName   *string
+	LastName    *string
+	Street      *string
+	HouseNumber *string
+	PostalCode  *string
+	City        *string
+}
+
+// normalized returns the update with surrounding whitespace removed from every set field.
+// Only the title may end up empty.
+func (u ProfileUpdate) normalized() (ProfileUpdate, error) {
+	var blank []string
+	trim := func(field string, value *string) *string {
+		if value == nil {
+			return nil
+		}
+		trimmed := strings.TrimSpace(*value)
+		if trimmed == "" && field != "title" {
+			blank = append(blank, field)
+		}
+		return &trimmed
+	}
+
+	normalized := ProfileUpdate{
+		Salutation:  trim("salutation", u.Salutation),
+		Title:       trim("title", u.Title),
+		FirstName:   trim("first_name", u.FirstName),
+		LastName:    trim("last_name", u.LastName),
+		Street:      trim("street", u.Street),
+		HouseNumber: trim("house_number", u.HouseNumber),
+		PostalCode:  trim("postal_code", u.PostalCode),
+		City:        trim("city", u.City),
+	}
+	if len(blank) > 0 {
+		return ProfileUpdate{}, fmt.Errorf("%w: %s", ErrBlankProfileField, strings.Join(blank, ", "))
+	}
+	return normalized, nil
+}
+
+// moderatedFields are the profile fields a moderator checks before approval.
+var moderatedFields = map[string]bool{
+	"title":        true,
+	"first_name":   true,
+	"last_name":    true,
+	"street":       true,
+	"house_number": true,
+	"postal_code":  true,
+	"city":         true,
+}
+
+// Me returns the user together with their profile, if they created one.
+func (s *Service) Me(userID string) (*User, error) {
+	user, err := s.repo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := s.repo.GetProfile(userID)
+	if err != nil {
+		return nil, err
+	}
+	user.Profile = profile
+
+	return user, nil
+}
+
+// UpdateProfile applies a partial update to the user's profile and records the diff.
+// Changing a name or address field of an approved account sends it back to moderation,
+// which is reported by the returned flag. If the status changes before the edit is stored,
+// for example because a moderator approved the account meanwhile, it returns
+// domainuser.ErrStatusConflict and nothing is stored.
+func (s *Service) UpdateProfile(userID string, update ProfileUpdate) (*User, bool, error) {
+	update, err := update.normalized()
+	if err != nil {
+		return nil, false, err
+	}
+
+	user, err := s.Me(userID)
+	if err != nil {
+		return nil, false, err
+	}
+	if user.Profile == nil {
+		return nil, false, ErrProfileNotFound
+	}
+	if user.Status == StatusRejected {
+		return nil, false, ErrResubmissionRequired
+	}
+
+	profile := *user.Profile
+	diff := applyProfileUpdate(&profile, update)
+	if len(diff) == 0 {
+		return user, false, nil
+	}
+
+	newStatus := user.Status
+	var statusChange *domainuser.StatusChange
+	if user.Status == StatusApproved && touchesModeratedFields(diff) {
+		statusChange, err = domainuser.ChangeStatus(userID, user.Status, domainuser.TransitionProfileEdited, domainuser.StatusFacts{
+			EmailConfirmed: user.EmailConfirmed,
+			HasProfile:     true,
+		}, "")
+		if err != nil {
+			return nil, false, err
+		}
+		newStatus = statusChange.To
+	}
+
+	profile.UpdatedAt = time.Now()
+	change := &domainuser.ProfileChange{
+		ID:             uuid.New().String(),
+		UserID:         userID,
+		Changes:        diff,
+		PreviousStatus: user.Status,
+		NewStatus:      newStatus,
+		CreatedAt:      profile.UpdatedAt,
+	}
+
+	if err := s.repo.UpdateProfile(&profile, change, statusChange); err != nil {
+		return nil, false, err
+	}
+
+	user.Status = newStatus
+	user.Profile = &profile
+	return user, statusChange != nil, nil
+}
+
+// applyProfileUpdate writes the set fields of the update into the profile and
+// returns the fields whose value actually changed.
+func applyProfileUpdate(profile *UserProfile, update ProfileUpdate) domainuser.ProfileDiff {
+	diff := domainuser.ProfileDiff{}
+
+	set := func(field string, target *string, value *string) {
+		if value == nil || *target == *value {
+			return
+		}
+		diff[field] = domainuser.FieldChange{Old: *target, New: *value}
+		*target = *value
+	}
+
+	set("salutation", &profile.Salutation, update.Salutation)
+	set("first_name", &profile.FirstName, update.FirstName)
+	set("last_name", &profile.LastName, update.LastName)
+	set("street", &profile.Street, update.Street)
+	set("house_number", &profile.HouseNumber, update.HouseNumber)
+	set("postal_code", &profile.PostalCode, update.PostalCode)
+	set("city", &profile.City, update.City)
+
+	if update.Title != nil {
+		var title string
+		if profile.Title != nil {
+			title = *profile.Title
+		}
+		set("title", &title, update.Title)
+		profile.Title = nil
+		if title != "" {
+			profile.Title = &title
+		}
+	}
+
+	return diff
+}
+
+// touchesModeratedFields reports whether the diff changes a field moderators review.
+func touchesModeratedFields(diff domainuser.ProfileDiff) bool {
+	for field := range diff {
+		if moderatedFields[field] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,228 @@
+package auth
+
+import (
+	domainuser "carowebapp/core/internal/domain/user"
+
+	"errors"
+
+	"github.com/google/uuid"
+
+	"time"
+)
+
+var (
+	ErrNotRejected          = errors.New("registration has not been rejected")
+	ErrResubmissionRequired = errors.New("registration was rejected, resubmit the rejected fields instead")
+	ErrInvalidResubmission  = errors.New("resubmission must correct exactly the rejected fields")
+	ErrNoRejectedFields     = errors.New("rejection does not name any profile field, please contact support")
+)
+
+// ResubmissionError lists why a resubmission does not match the rejected fields.
+type ResubmissionError struct {
+	Missing    []string `json:"missing,omitempty"`
+	Unexpected []string `json:"unexpected,omitempty"`
+	Unchanged  []string `json:"unchanged,omitempty"`
+}
+
+func (e *ResubmissionError) Error() string {
+	return ErrInvalidResubmission.Error()
+}
+
+// Is makes errors.Is(err, ErrInvalidResubmission) true for field mismatches.
+func (e *ResubmissionError) Is(target error) bool {
+	return target == ErrInvalidResubmission
+}
+
+// RejectedField is a rejected profile field with the moderator's reason and the current value.
+type RejectedField struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+	Value  string `json:"value"`
+}
+
+// RejectionDetails is the latest rejection of a registration, mapped to profile fields.
+// Reasons that do not belong to a profile field are listed under Other.
+type RejectionDetails struct {
+	ID         string            `json:"id"`
+	RejectedAt time.Time         `json:"rejected_at"`
+	Fields     []RejectedField   `json:"fields"`
+	Other      map[string]string `json:"other,omitempty"`
+}
+
+// GetRejection returns the reasons of the latest rejection of a rejected user.
+func (s *Service) GetRejection(userID string) (*RejectionDetails, error) {
+	user, rejection, err := s.rejectedUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	details := &RejectionDetails{
+		ID:         rejection.ID,
+		RejectedAt: rejection.RejectedAt,
+		Fields:     []RejectedField{},
+	}
+
+	reasons, other := splitRejectionReasons(rejection.Reasons)
+	if len(other) > 0 {
+		details.Other = other
+	}
+
+	values := profileValues(user.Profile)
+	for _, field := range domainuser.ProfileFields {
+		if reason, ok := reasons[field]; ok {
+			details.Fields = append(details.Fields, RejectedField{Field: field, Reason: reason, Value: values[field]})
+		}
+	}
+
+	return details, nil
+}
+
+// Resubmit applies corrections to the rejected fields and moves the registration back
+// to pending. The update must change every rejected field and contain nothing else.
+func (s *Service) Resubmit(userID string, update ProfileUpdate) (*User, error) {
+	update, err := update.normalized()
+	if err != nil {
+		return nil, err
+	}
+
+	user, rejection, err := s.rejectedUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rejected, _ := splitRejectionReasons(rejection.Reasons)
+	if len(rejected) == 0 {
+		return nil, ErrNoRejectedFields
+	}
+
+	submitted := update.fields()
+	mismatch := &ResubmissionError{}
+	for _, field := range domainuser.ProfileFields {
+		_, isRejected := rejected[field]
+		switch {
+		case isRejected && !submitted[field]:
+			mismatch.Missing = append(mismatch.Missing, field)
+		case !isRejected && submitted[field]:
+			mismatch.Unexpected = append(mismatch.Unexpected, field)
+		}
+	}
+	if len(mismatch.Missing) > 0 || len(mismatch.Unexpected) > 0 {
+		return nil, mismatch
+	}
+
+	profile := *user.Profile
+	diff := applyProfileUpdate(&profile, update)
+	for _, field := range domainuser.ProfileFields {
+		if _, isRejected := rejected[field]; isRejected {
+			if _, changed := diff[field]; !changed {
+				mismatch.Unchanged = append(mismatch.Unchanged, field)
+			}
+		}
+	}
+	if len(mismatch.Unchanged) > 0 {
+		return nil, mismatch
+	}
+
+	statusChange, err := domainuser.ChangeStatus(userID, user.Status, domainuser.TransitionResubmit, domainuser.StatusFacts{
+		EmailConfirmed: user.EmailConfirmed,
+		HasProfile:     true,
+	}, "")
+	if err != nil {
+		return nil, err
+	}
+
+	profile.UpdatedAt = time.Now()
+	change := &domainuser.ProfileChange{
+		ID:             uuid.New().String(),
+		UserID:         userID,
+		RejectionID:    &rejection.ID,
+		Changes:        diff,
+		PreviousStatus: statusChange.From,
+		NewStatus:      statusChange.To,
+		CreatedAt:      profile.UpdatedAt,
+	}
+
+	if err := s.repo.UpdateProfile(&profile, change, statusChange); err != nil {
+		return nil, err
+	}
+
+	user.Status = statusChange.To
+	user.Profile = &profile
+	return user, nil
+}
+
+// rejectedUser loads a rejected user with profile and their latest rejection.
+func (s *Service) rejectedUser(userID string) (*User, *domainuser.Rejection, error) {
+	user, err := s.Me(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user.Status != StatusRejected {
+		return nil, nil, ErrNotRejected
+	}
+	if user.Profile == nil {
+		return nil, nil, ErrProfileNotFound
+	}
+
+	rejection, err := s.repo.GetLatestRejection(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if rejection == nil {
+		return nil, nil, ErrNotRejected
+	}
+
+	return user, rejection, nil
+}
+
+// splitRejectionReasons separates reasons for profile fields, keyed by canonical field name,
+// from reasons that do not map to a field.
+func splitRejectionReasons(reasons domainuser.RejectionReasons) (map[string]string, map[string]string) {
+	fields := map[string]string{}
+	other := map[string]string{}
+	for key, reason := range reasons {
+		if field, ok := domainuser.ProfileFieldName(key); ok {
+			fields[field] = reason
+		} else {
+			other[key] = reason
+		}
+	}
+	return fields, other
+}
+
+// fields returns the canonical names of the fields set in the update.
+func (u ProfileUpdate) fields() map[string]bool {
+	set := map[string]bool{}
+	for field, value := range map[string]*string{
+		"salutation":   u.Salutation,
+		"title":        u.Title,
+		"first_name":   u.FirstName,
+		"last_name":    u.LastName,
+		"street":       u.Street,
+		"house_number": u.HouseNumber,
+		"postal_code":  u.PostalCode,
+		"city":         u.City,
+	} {
+		if value != nil {
+			set[field] = true
+		}
+	}
+	return set
+}
+
+// profileValues returns the profile keyed by canonical field name.
+func profileValues(profile *UserProfile) map[string]string {
+	values := map[string]string{
+		"salutation":   profile.Salutation,
+		"first_name":   profile.FirstName,
+		"last_name":    profile.LastName,
+		"street":       profile.Street,
+		"house_number": profile.HouseNumber,
+		"postal_code":  profile.PostalCode,
+		"city":         profile.City,
+	}
+	if profile.Title != nil {
+		values["title"] = *profile.Title
+	}
+	return values
+}
@@ -0,0 +1,285 @@
+package auth
+
+import (
+	"carowebapp/core/internal/pkg/totp"
+
+	"crypto/rand"
+
+	"encoding/base32"
+
+	"errors"
+
+	"github.com/google/uuid"
+
+	"os"
+
+	"strings"
+
+	"time"
+)
+
+var (
+	ErrTwoFactorAlreadyEnabled  = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled      = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorNotSetUp        = errors.New("two-factor authentication has not been set up")
+	ErrTwoFactorRequiredForRole = errors.New("two-factor authentication is mandatory for this role")
+	ErrInvalidTwoFactorCode     = errors.New("invalid two-factor code")
+	ErrInvalidChallenge         = errors.New("invalid or expired login challenge")
+)
+
+const (
+	mfaChallengeTTL         = 5 * time.Minute
+	mfaChallengeMaxAttempts = 5
+	recoveryCodeCount       = 10
+	defaultTOTPIssuer       = "CaroWebApp"
+)
+
+// SetupTwoFactor generates a new TOTP secret for the user and returns it together
+// with the otpauth:// provisioning URI. The secret becomes active after ConfirmTwoFactor.
+func (s *Service) SetupTwoFactor(userID string) (string, string, error) {
+	user, err := s.repo.GetByID(userID)
+	if err != nil {
+		return "", "", err
+	}
+
+	if user.TOTPEnabled {
+		return "", "", ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := s.repo.SetTOTPSecret(userID, secret); err != nil {
+		return "", "", err
+	}
+
+	return secret, totp.ProvisioningURI(totpIssuer(), user.Email, secret), nil
+}
+
+// ConfirmTwoFactor enables two-factor authentication once the user proves possession
+// of the secret. It returns the one-time recovery codes, which are shown only once.
+func (s *Service) ConfirmTwoFactor(userID, code string) ([]string, error) {
+	user, err := s.repo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TOTPEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if user.TOTPSecret == nil {
+		return nil, ErrTwoFactorNotSetUp
+	}
+
+	ok, err := s.verifyTOTP(user, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	if err := s.repo.EnableTOTP(userID); err != nil {
+		return nil, err
+	}
+
+	return s.replaceRecoveryCodes(userID)
+}
+
+// DisableTwoFactor turns two-factor authentication off after re-checking the
+// password and a current code. Admins cannot disable it.
+func (s *Service) DisableTwoFactor(userID, password, code string) error {
+	user, err := s.repo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if user.Role == RoleAdmin {
+		return ErrTwoFactorRequiredForRole
+	}
+	if !user.TOTPEnabled {
+		return ErrTwoFactorNotEnabled
+	}
+
+	match, _, err := s.hasher.Verify(user.Password, password)
+	if err != nil {
+		return err
+	}
+	if !match {
+		return ErrInvalidCredentials
+	}
+
+	ok, err := s.verifySecondFactor(user, code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidTwoFactorCode
+	}
+
+	return s.repo.DisableTOTP(userID)
+}
+
+// RegenerateRecoveryCodes invalidates the existing recovery codes and returns new ones.
+func (s *Service) RegenerateRecoveryCodes(userID, code string) ([]string, error) {
+	user, err := s.repo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.TOTPEnabled {
+		return nil, ErrTwoFactorNotEnabled
+	}
+
+	ok, err := s.verifyTOTP(user, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	return s.replaceRecoveryCodes(userID)
+}
+
+// VerifyLoginChallenge completes a two-step login. The code may be a TOTP code
+// or one of the user's recovery codes. On success a second-factor session is opened.
+// Wrong codes count toward the account lockout like wrong passwords, and a locked
+// account cannot complete a pending challenge.
+func (s *Service) VerifyLoginChallenge(challengeToken, code string, info SessionInfo) (*AuthResult, error) {
+	challenge, err := s.repo.GetMFAChallenge(hashToken(challengeToken))
+	if err != nil || challenge == nil || !tokenMatches(challenge.TokenHash, challengeToken) {
+		return nil, ErrInvalidChallenge
+	}
+
+	if challenge.UsedAt != nil || time.Now().After(challenge.ExpiresAt) || challenge.Attempts >= mfaChallengeMaxAttempts {
+		return nil, ErrInvalidChallenge
+	}
+
+	user, err := s.repo.GetByID(challenge.UserID)
+	if err != nil || user == nil {
+		return nil, ErrInvalidChallenge
+	}
+	if user.LockedUntil != nil && time.Now().Before(*user.LockedUntil) {
+		return nil, ErrInvalidChallenge
+	}
+
+	ok, err := s.verifySecondFactor(user, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if err := s.repo.IncrementMFAChallengeAttempts(challenge.ID); err != nil {
+			return nil, err
+		}
+		if err := s.recordFailedLogin(user, info); !errors.Is(err, ErrInvalidCredentials) {
+			return nil, err
+		}
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	used, err := s.repo.MarkMFAChallengeUsed(challenge.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !used {
+		return nil, ErrInvalidChallenge
+	}
+
+	if err := s.checkBlocked(user.ID); err != nil {
+		return nil, err
+	}
+	if err := s.resetFailedLogins(user); err != nil {
+		return nil, err
+	}
+
+	return s.startSession(user, info, true)
+}
+
+// createMFAChallenge stores a short-lived challenge for the password step of a login.
+func (s *Service) createMFAChallenge(userID string) (string, error) {
+	token := generateToken()
+	now := time.Now()
+	challenge := &MFAChallenge{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		TokenHash: hashToken(token),
+		ExpiresAt: now.Add(mfaChallengeTTL),
+		CreatedAt: now,
+	}
+	if err := s.repo.CreateMFAChallenge(challenge); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// verifySecondFactor accepts either a TOTP code or an unused recovery code.
+func (s *Service) verifySecondFactor(user *User, code string) (bool, error) {
+	ok, err := s.verifyTOTP(user, code)
+	if err != nil || ok {
+		return ok, err
+	}
+
+	return s.repo.UseRecoveryCode(user.ID, hashToken(normalizeRecoveryCode(code)))
+}
+
+// verifyTOTP checks a TOTP code and rejects codes whose time step was already used.
+func (s *Service) verifyTOTP(user *User, code string) (bool, error) {
+	if user.TOTPSecret == nil {
+		return false, nil
+	}
+
+	step, ok := totp.Validate(*user.TOTPSecret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+
+	return s.repo.UseTOTPStep(user.ID, step)
+}
+
+// replaceRecoveryCodes generates a new set of recovery codes and stores their hashes.
+func (s *Service) replaceRecoveryCodes(userID string) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, hashToken(normalizeRecoveryCode(code)))
+	}
+
+	if err := s.repo.ReplaceRecoveryCodes(userID, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// generateRecoveryCode returns a random code in the form "xxxx-xxxx".
+func generateRecoveryCode() (string, error) {
+	b := make([]byte, 5)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	code := strings.ToLower(base32.StdEncoding.EncodeToString(b))
+	return code[:4] + "-" + code[4:], nil
+}
+
+// normalizeRecoveryCode makes recovery codes comparable regardless of case and dashes.
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.ReplaceAll(code, "-", "")
+}
+
+// totpIssuer returns the issuer shown in authenticator apps.
+func totpIssuer() string {
+	if issuer := os.Getenv("TOTP_ISSUER"); issuer != "" {
+		return issuer
+	}
+	return defaultTOTPIssuer
+}
@@ -0,0 +1,14 @@
+package documents
+
+const (
+	ErrMsgMissingFile      = "file is required"
+	ErrMsgUploadFailed     = "failed to upload document"
+	ErrMsgListFailed       = "failed to list documents"
+	ErrMsgDownloadFailed   = "failed to download document"
+	ErrMsgDeleteFailed     = "failed to delete document"
+	ErrMsgReviewFailed     = "failed to review document"
+	SuccessMsgUploaded     = "Document uploaded"
+	SuccessMsgDeleted      = "document deleted"
+	SuccessMsgReviewed     = "Document reviewed"
+	ErrMsgInvalidReviewReq = "invalid document review"
+)
@@ -0,0 +1,275 @@
+package documents
+
+import (
+	domainuser "carowebapp/core/internal/domain/user"
+
+	"carowebapp/core/internal/infrastructure/response"
+
+	"carowebapp/core/internal/pkg/contextutils"
+
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/google/uuid"
+
+	"go.uber.org/zap"
+)
+
+// Handler provides the HTTP handlers for uploading verification documents and for
+// reviewing them in the moderation screen.
+type Handler struct {
+	service *Service
+	logger  *zap.Logger
+}
+
+func NewHandler(service *Service, logger *zap.Logger) *Handler {
+	return &Handler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// ReviewDocumentRequest represents the payload for accepting or rejecting a document.
+type ReviewDocumentRequest struct {
+	Status string `json:"status" validate:"required,oneof=accepted rejected"`
+	Note   string `json:"note" validate:"max=1000"`
+}
+
+// Upload stores a document of the current user. It expects a multipart form with
+// the document type in "type" and the file in "file".
+func (h *Handler) Upload(c *fiber.Ctx) error {
+	userID, ok := contextutils.GetUserID(c)
+	if !ok {
+		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusUnauthorized, response.ErrMsgUnauthorized)
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusBadRequest, ErrMsgMissingFile,
+			zap.String("user_id", userID),
+		)
+	}
+	if header.Size > MaxSize {
+		return h.documentError(c, userID, ErrTooLarge, ErrMsgUploadFailed)
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return response.JSONErrorWithLog(c, h.logger, fiber.StatusInternalServerError, ErrMsgUploadFailed,
+			zap.String("user_id", userID),
+			zap.Error(err),
+		)
+	}
+	defer func() { _ = file.Close() }()
+
+	doc, err := h.service.Upload(userID, c.FormValue("type"), header.Filename, file)
+	if err != nil {
+		return h.documentError(c, userID, err, ErrMsgUploadFailed)
+	}
+
+	h.logger.Info(SuccessMsgUploaded,
+		zap.String("user_id", userID),
+		zap.String("document_id", doc.ID),
+		zap.String("type", doc.Type),
+		zap.String("content_type", doc.ContentType),
+		zap.Int64("size", doc.Size),
+	)
+
+	return response.JSONSuccess(c, fiber.StatusCreated, NewApplicantDocument(*doc))
+}
+
+// List returns the documents of the current user, without the moderators' review details.
+func (h *Handler) List(c *fiber.Ctx) error {
+	userID, ok := contextutils.GetUserID(c)
+	if !ok {
+		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusUnauthorized, response.ErrMsgUnauthorized)
+	}
+
+	docs, err := h.service.List(userID)
+	if err != nil {
+		return h.documentError(c, userID, err, ErrMsgListFailed)
+	}
+
+	views := make([]ApplicantDocument, 0, len(docs))
+	for _, doc := range docs {
+		views = append(views, NewApplicantDocument(doc))
+	}
+
+	return response.JSONSuccess(c, fiber.StatusOK, views)
+}
+
+// Download sends a document of the current user.
+func (h *Handler) Download(c *fiber.Ctx) error {
+	userID, ok := contextutils.GetUserID(c)
+	if !ok {
+		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusUnauthorized, response.ErrMsgUnauthorized)
+	}
+	documentID, ok := h.pathID(c, "documentId")
+	if !ok {
+		return response.JSONError(c, fiber.StatusBadRequest, fiber.ErrBadRequest)
+	}
+
+	return h.send(c, userID, documentID)
+}
+
+// Delete removes a document of the current user that has not been reviewed yet.
+func (h *Handler) Delete(c *fiber.Ctx) error {
+	userID, ok := contextutils.GetUserID(c)
+	if !ok {
+		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusUnauthorized, response.ErrMsgUnauthorized)
+	}
+	documentID, ok := h.pathID(c, "documentId")
+	if !ok {
+		return response.JSONError(c, fiber.StatusBadRequest, fiber.ErrBadRequest)
+	}
+
+	if err := h.service.Delete(userID, documentID); err != nil {
+		return h.documentError(c, userID, err, ErrMsgDeleteFailed)
+	}
+
+	h.logger.Info(SuccessMsgDeleted,
+		zap.String("user_id", userID),
+		zap.String("document_id", documentID),
+	)
+
+	return response.JSONSuccess(c, fiber.StatusOK, fiber.Map{
+		"message": SuccessMsgDeleted,
+	})
+}
+
+// AdminList returns the documents of the user given in the path, for the moderation screen.
+func (h *Handler) AdminList(c *fiber.Ctx) error {
+	userID, ok := h.pathID(c, "id")
+	if !ok {
+		return response.JSONError(c, fiber.StatusBadRequest, fiber.ErrBadRequest)
+	}
+
+	docs, err := h.service.List(userID)
+	if err != nil {
+		return h.documentError(c, userID, err, ErrMsgListFailed)
+	}
+
+	return response.JSONSuccess(c, fiber.StatusOK, docs)
+}
+
+// AdminDownload sends a document of the user given in the path.
+func (h *Handler) AdminDownload(c *fiber.Ctx) error {
+	userID, ok := h.pathID(c, "id")
+	if !ok {
+		return response.JSONError(c, fiber.StatusBadRequest, fiber.ErrBadRequest)
+	}
+	documentID, ok := h.pathID(c, "documentId")
+	if !ok {
+		return response.JSONError(c, fiber.StatusBadRequest, fiber.ErrBadRequest)
+	}
+	adminID, _ := contextutils.GetUserID(c)
+
+	h.logger.Info("Document viewed",
+		zap.String("admin_id", adminID),
+		zap.String("target_user_id", userID),
+		zap.String("document_id", documentID),
+	)
+
+	return h.send(c, userID, documentID)
+}
+
+// Review marks a document of the user given in the path as accepted or rejected.
+func (h *Handler) Review(c *fiber.Ctx) error {
+	userID, ok := h.pathID(c, "id")
+	if !ok {
+		return response.JSONError(c, fiber.StatusBadRequest, fiber.ErrBadRequest)
+	}
+	documentID, ok := h.pathID(c, "documentId")
+	if !ok {
+		return response.JSONError(c, fiber.StatusBadRequest, fiber.ErrBadRequest)
+	}
+	req, ok := contextutils.GetValidatedBody[ReviewDocumentRequest](c)
+	if !ok {
+		h.logger.Debug(ErrMsgInvalidReviewReq, zap.String("handler", "Review"))
+		return response.JSONError(c, fiber.StatusBadRequest, fiber.ErrBadRequest)
+	}
+
+	adminID, _ := contextutils.GetUserID(c)
+	traceID, _ := contextutils.GetTraceID(c)
+	actor := domainuser.Actor{ID: adminID, IP: c.IP(), TraceID: traceID}
+
+	doc, err := h.service.Review(actor, userID, documentID, req.Status, req.Note)
+	if err != nil {
+		return h.documentError(c, userID, err, ErrMsgReviewFailed)
+	}
+
+	h.logger.Info(SuccessMsgReviewed,
+		zap.String("admin_id", adminID),
+		zap.String("target_user_id", userID),
+		zap.String("document_id", doc.ID),
+		zap.String("status", doc.Status),
+	)
+
+	return response.JSONSuccess(c, fiber.StatusOK, doc)
+}
+
+// send streams a stored document as an attachment. The content type was sniffed on
+// upload, and nosniff keeps browsers from second-guessing it.
+func (h *Handler) send(c *fiber.Ctx, userID, documentID string) error {
+	doc, content, err := h.service.Open(userID, documentID)
+	if err != nil {
+		return h.documentError(c, userID, err, ErrMsgDownloadFailed)
+	}
+
+	c.Attachment(doc.FileName)
+	c.Set(fiber.HeaderContentType, doc.ContentType)
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Status(fiber.StatusOK).SendStream(content, int(doc.Size))
+}
+
+// pathID reads and validates a UUID path parameter.
+func (h *Handler) pathID(c *fiber.Ctx, param string) (string, bool) {
+	id := c.Params(param)
+	if _, err := uuid.Parse(id); err != nil {
+		h.logger.Debug("invalid ID in path", zap.String("param", param), zap.String("path", c.Path()))
+		return "", false
+	}
+	return id, true
+}
+
+// documentError maps document service errors to HTTP responses.
+func (h *Handler) documentError(c *fiber.Ctx, userID string, err error, fallback string) error {
+	switch {
+	case errors.Is(err, ErrUnknownType),
+		errors.Is(err, ErrEmptyDocument),
+		errors.Is(err, ErrInvalidReview):
+		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusBadRequest, err.Error(),
+			zap.String("user_id", userID),
+		)
+
+	case errors.Is(err, ErrTooLarge):
+		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusRequestEntityTooLarge, err.Error(),
+			zap.String("user_id", userID),
+		)
+
+	case errors.Is(err, ErrUnsupportedContent):
+		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusUnsupportedMediaType, err.Error(),
+			zap.String("user_id", userID),
+		)
+
+	case errors.Is(err, ErrUserNotFound), errors.Is(err, ErrDocumentNotFound):
+		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusNotFound, err.Error(),
+			zap.String("user_id", userID),
+		)
+
+	case errors.Is(err, ErrTooManyDocuments),
+		errors.Is(err, ErrUploadClosed),
+		errors.Is(err, ErrAlreadyReviewed):
+		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusConflict, err.Error(),
+			zap.String("user_id", userID),
+		)
+
+	default:
+		return response.JSONErrorWithLog(c, h.logger, fiber.StatusInternalServerError, fallback,
+			zap.String("user_id", userID),
+			zap.Error(err),
+		)
+	}
+}
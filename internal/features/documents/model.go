@@ -0,0 +1,73 @@
+// Package documents handles the verification documents applicants upload during onboarding
+// and moderators review before approving a registration.
+package documents
+
+import (
+	domainuser "carowebapp/core/internal/domain/user"
+
+	"fmt"
+
+	"os"
+
+	"time"
+)
+
+const (
+	// MaxSize is the largest document an applicant may upload.
+	MaxSize = 10 << 20
+	// MaxPerUser is how many documents an applicant may keep at a time.
+	MaxPerUser = 10
+	// DefaultRetention is how long documents are kept after the registration was approved.
+	DefaultRetention = 30 * 24 * time.Hour
+	// purgeBatchSize is how many documents a purge run deletes at most.
+	purgeBatchSize = 100
+)
+
+// contentTypes are the file formats accepted for documents, keyed by the sniffed MIME type.
+var contentTypes = map[string]bool{
+	"application/pdf": true,
+	"image/jpeg":      true,
+	"image/png":       true,
+}
+
+// ApplicantDocument is the view of a document its uploader gets. The review note is meant
+// for other moderators and, like the reviewer, is left out.
+type ApplicantDocument struct {
+	ID          string     `json:"id"`
+	Type        string     `json:"type"`
+	FileName    string     `json:"file_name"`
+	ContentType string     `json:"content_type"`
+	Size        int64      `json:"size"`
+	Status      string     `json:"status"`
+	ReviewedAt  *time.Time `json:"reviewed_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// NewApplicantDocument returns the applicant's view of a document.
+func NewApplicantDocument(doc domainuser.Document) ApplicantDocument {
+	return ApplicantDocument{
+		ID:          doc.ID,
+		Type:        doc.Type,
+		FileName:    doc.FileName,
+		ContentType: doc.ContentType,
+		Size:        doc.Size,
+		Status:      doc.Status,
+		ReviewedAt:  doc.ReviewedAt,
+		CreatedAt:   doc.CreatedAt,
+	}
+}
+
+// RetentionFromEnv reads the retention after approval from DOCUMENT_RETENTION,
+// for example "720h". It falls back to DefaultRetention.
+func RetentionFromEnv() (time.Duration, error) {
+	value := os.Getenv("DOCUMENT_RETENTION")
+	if value == "" {
+		return DefaultRetention, nil
+	}
+
+	retention, err := time.ParseDuration(value)
+	if err != nil || retention < 0 {
+		return 0, fmt.Errorf("invalid DOCUMENT_RETENTION %q", value)
+	}
+	return retention, nil
+}
@@ -0,0 +1,17 @@
+package documents
+
+import (
+	domainuser "carowebapp/core/internal/domain/user"
+
+	"time"
+)
+
+type Repository interface {
+	GetUserStatus(userID string) (string, error)
+	ListDocuments(userID string) ([]domainuser.Document, error)
+	GetDocument(userID, documentID string) (*domainuser.Document, error)
+	CreateDocument(doc *domainuser.Document) error
+	DeleteDocument(documentID string) error
+	ReviewDocument(doc *domainuser.Document, entry domainuser.AuditEntry) error
+	ListExpiredDocuments(approvedBefore time.Time, limit int) ([]domainuser.Document, error)
+}
@@ -0,0 +1,116 @@
+package documents
+
+import (
+	domainuser "carowebapp/core/internal/domain/user"
+
+	"carowebapp/core/internal/infrastructure/auditlog"
+
+	"database/sql"
+
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+
+	"time"
+)
+
+// sqlxRepository provides SQL-backed implementation of the documents.Repository interface.
+type sqlxRepository struct {
+	db *sqlx.DB
+}
+
+// NewSQLXRepository creates a new instance of sqlxRepository using the given database connection.
+func NewSQLXRepository(db *sqlx.DB) Repository {
+	return &sqlxRepository{db: db}
+}
+
+// GetUserStatus returns the status of a user, or "" if the user does not exist.
+func (r *sqlxRepository) GetUserStatus(userID string) (string, error) {
+	var status string
+	err := r.db.Get(&status, `SELECT status FROM users WHERE id = $1`, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return status, err
+}
+
+// ListDocuments returns the documents of a user, oldest first.
+func (r *sqlxRepository) ListDocuments(userID string) ([]domainuser.Document, error) {
+	docs := []domainuser.Document{}
+	err := r.db.Select(&docs, `
+		SELECT * FROM user_documents
+		WHERE user_id = $1
+		ORDER BY created_at, id
+	`, userID)
+	return docs, err
+}
+
+// GetDocument returns a document of a user, or nil if the user has no such document.
+func (r *sqlxRepository) GetDocument(userID, documentID string) (*domainuser.Document, error) {
+	var doc domainuser.Document
+	err := r.db.Get(&doc, `SELECT * FROM user_documents WHERE id = $1 AND user_id = $2`, documentID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// CreateDocument stores the metadata of an uploaded document.
+func (r *sqlxRepository) CreateDocument(doc *domainuser.Document) error {
+	return r.db.Get(&doc.CreatedAt, `
+		INSERT INTO user_documents (id, user_id, type, file_name, content_type, size_bytes, storage_key, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING created_at
+	`, doc.ID, doc.UserID, doc.Type, doc.FileName, doc.ContentType, doc.Size, doc.StorageKey, doc.Status)
+}
+
+// DeleteDocument removes the metadata of a document.
+func (r *sqlxRepository) DeleteDocument(documentID string) error {
+	_, err := r.db.Exec(`DELETE FROM user_documents WHERE id = $1`, documentID)
+	return err
+}
+
+// ReviewDocument stores the review of a document and records it in the audit log.
+func (r *sqlxRepository) ReviewDocument(doc *domainuser.Document, entry domainuser.AuditEntry) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := tx.Get(&doc.ReviewedAt, `
+		UPDATE user_documents
+		SET status = $2, review_note = $3, reviewed_by = $4, reviewed_at = NOW()
+		WHERE id = $1
+		RETURNING reviewed_at
+	`, doc.ID, doc.Status, doc.ReviewNote, doc.ReviewedBy); err != nil {
+		return err
+	}
+
+	if err := auditlog.Record(tx, entry); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ListExpiredDocuments returns documents of approved users whose last approval happened
+// before the given time, oldest first.
+func (r *sqlxRepository) ListExpiredDocuments(approvedBefore time.Time, limit int) ([]domainuser.Document, error) {
+	docs := []domainuser.Document{}
+	err := r.db.Select(&docs, `
+		SELECT d.* FROM user_documents d
+		JOIN users u ON u.id = d.user_id
+		WHERE u.status = 'approved'
+		  AND (
+			SELECT MAX(t.created_at) FROM user_status_transitions t
+			WHERE t.user_id = u.id AND t.to_status = 'approved'
+		  ) < $1
+		ORDER BY d.created_at
+		LIMIT $2
+	`, approvedBefore, limit)
+	return docs, err
+}
@@ -0,0 +1,300 @@
+package documents
+
+import (
+	domainuser "carowebapp/core/internal/domain/user"
+
+	"carowebapp/core/internal/infrastructure/storage"
+
+	"bytes"
+
+	"context"
+
+	"errors"
+
+	"github.com/google/uuid"
+
+	"go.uber.org/zap"
+
+	"io"
+
+	"net/http"
+
+	"path/filepath"
+
+	"strings"
+
+	"time"
+
+	"unicode/utf8"
+)
+
+var (
+	ErrUserNotFound       = errors.New("user not found")
+	ErrDocumentNotFound   = errors.New("document not found")
+	ErrUnknownType        = errors.New("document type must be proof_of_ownership, identity or trade_register_extract")
+	ErrUnsupportedContent = errors.New("documents must be PDF, JPEG or PNG files")
+	ErrTooLarge           = errors.New("documents must not be larger than 10 MB")
+	ErrEmptyDocument      = errors.New("document is empty")
+	ErrTooManyDocuments   = errors.New("a user can keep at most 10 documents")
+	ErrUploadClosed       = errors.New("documents can only be changed while the registration is not approved")
+	ErrAlreadyReviewed    = errors.New("reviewed documents cannot be deleted")
+	ErrInvalidReview      = errors.New("review status must be accepted or rejected")
+)
+
+// Service stores verification documents and lets moderators review them.
+type Service struct {
+	repo      Repository
+	store     storage.Store
+	retention time.Duration
+	logger    *zap.Logger
+}
+
+// NewService creates a new instance of the Service. Documents are deleted once the
+// registration has been approved for the retention period.
+func NewService(repo Repository, store storage.Store, retention time.Duration, logger *zap.Logger) *Service {
+	return &Service{
+		repo:      repo,
+		store:     store,
+		retention: retention,
+		logger:    logger,
+	}
+}
+
+// Upload stores a document of the user. The file format is taken from the content, not
+// from the name or the type the client claims.
+func (s *Service) Upload(userID, docType, fileName string, content io.Reader) (*domainuser.Document, error) {
+	if !domainuser.IsDocumentType(docType) {
+		return nil, ErrUnknownType
+	}
+	if err := s.requireOnboarding(userID); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.ListDocuments(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= MaxPerUser {
+		return nil, ErrTooManyDocuments
+	}
+
+	data, err := io.ReadAll(io.LimitReader(content, MaxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, ErrEmptyDocument
+	}
+	if len(data) > MaxSize {
+		return nil, ErrTooLarge
+	}
+	contentType := http.DetectContentType(data)
+	if !contentTypes[contentType] {
+		return nil, ErrUnsupportedContent
+	}
+
+	doc := &domainuser.Document{
+		ID:          uuid.New().String(),
+		UserID:      userID,
+		Type:        docType,
+		FileName:    cleanFileName(fileName),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Status:      domainuser.DocumentPending,
+	}
+	doc.StorageKey = userID + "/" + doc.ID
+
+	if err := s.store.Put(doc.StorageKey, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateDocument(doc); err != nil {
+		s.removeFile(doc)
+		return nil, err
+	}
+	return doc, nil
+}
+
+// List returns the documents of a user.
+func (s *Service) List(userID string) ([]domainuser.Document, error) {
+	return s.repo.ListDocuments(userID)
+}
+
+// Open returns a document of a user with its content. The caller closes the content.
+func (s *Service) Open(userID, documentID string) (*domainuser.Document, io.ReadCloser, error) {
+	doc, err := s.getDocument(userID, documentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := s.store.Open(doc.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, ErrDocumentNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return doc, content, nil
+}
+
+// Delete removes a document the user uploaded by mistake. Reviewed documents stay
+// until the retention ends.
+func (s *Service) Delete(userID, documentID string) error {
+	if err := s.requireOnboarding(userID); err != nil {
+		return err
+	}
+	doc, err := s.getDocument(userID, documentID)
+	if err != nil {
+		return err
+	}
+	if doc.Status != domainuser.DocumentPending {
+		return ErrAlreadyReviewed
+	}
+	return s.deleteDocument(doc)
+}
+
+// Review marks a document as accepted or rejected. The note explains a rejection to other moderators.
+func (s *Service) Review(actor domainuser.Actor, userID, documentID, status, note string) (*domainuser.Document, error) {
+	if status != domainuser.DocumentAccepted && status != domainuser.DocumentRejected {
+		return nil, ErrInvalidReview
+	}
+	doc, err := s.getDocument(userID, documentID)
+	if err != nil {
+		return nil, err
+	}
+
+	before := domainuser.AuditState{"document_id": doc.ID, "status": doc.Status}
+	after := domainuser.AuditState{"document_id": doc.ID, "status": status}
+	doc.Status, doc.ReviewNote, doc.ReviewedBy = status, nil, &actor.ID
+	if note != "" {
+		doc.ReviewNote = &note
+	}
+
+	entry := domainuser.NewAuditEntry(actor, domainuser.AuditUserDocumentReviewed, domainuser.AuditTargetUser, userID, before, after)
+	if err := s.repo.ReviewDocument(doc, entry); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// DeleteAll removes every document of a user, reviewed or not. It is called before an
+// account is erased, because the files are not removed together with the database rows.
+func (s *Service) DeleteAll(userID string) error {
+	docs, err := s.repo.ListDocuments(userID)
+	if err != nil {
+		return err
+	}
+	for i := range docs {
+		if err := s.deleteDocument(&docs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PurgeExpired deletes the documents of registrations approved longer ago than the retention
+// and returns how many were deleted. A failing document is logged and retried on the next run.
+func (s *Service) PurgeExpired() (int, error) {
+	docs, err := s.repo.ListExpiredDocuments(time.Now().Add(-s.retention), purgeBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for i := range docs {
+		if err := s.deleteDocument(&docs[i]); err != nil {
+			s.logger.Error("failed to delete expired document",
+				zap.String("document_id", docs[i].ID),
+				zap.Error(err),
+			)
+			continue
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// RunPurger calls PurgeExpired every interval until the context is cancelled.
+func (s *Service) RunPurger(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if deleted, err := s.PurgeExpired(); err != nil {
+			s.logger.Error("failed to purge expired documents", zap.Error(err))
+		} else if deleted > 0 {
+			s.logger.Info("deleted documents after retention", zap.Int("count", deleted))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// requireOnboarding checks that the user exists and is not approved or blocked.
+func (s *Service) requireOnboarding(userID string) error {
+	status, err := s.repo.GetUserStatus(userID)
+	if err != nil {
+		return err
+	}
+	switch status {
+	case "":
+		return ErrUserNotFound
+	case domainuser.StatusCreated, domainuser.StatusPending, domainuser.StatusRejected:
+		return nil
+	}
+	return ErrUploadClosed
+}
+
+// getDocument returns a document of the user or ErrDocumentNotFound.
+func (s *Service) getDocument(userID, documentID string) (*domainuser.Document, error) {
+	doc, err := s.repo.GetDocument(userID, documentID)
+	if err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		return nil, ErrDocumentNotFound
+	}
+	return doc, nil
+}
+
+// deleteDocument removes the file before the metadata, so a failure never leaves a file
+// nothing points to.
+func (s *Service) deleteDocument(doc *domainuser.Document) error {
+	if err := s.store.Delete(doc.StorageKey); err != nil {
+		return err
+	}
+	return s.repo.DeleteDocument(doc.ID)
+}
+
+// removeFile deletes the file of a document whose metadata could not be stored.
+func (s *Service) removeFile(doc *domainuser.Document) {
+	if err := s.store.Delete(doc.StorageKey); err != nil {
+		s.logger.Error("failed to remove orphaned document file",
+			zap.String("storage_key", doc.StorageKey),
+			zap.Error(err),
+		)
+	}
+}
+
+// cleanFileName keeps the base name of an uploaded file, without control characters
+// and at most 255 bytes long.
+func cleanFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, name)
+	for len(name) > 255 {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	if name == "" || name == "." || name == "/" {
+		return "document"
+	}
+	return name
+}
@@ -0,0 +1,53 @@
+// Package onetimetoken issues and verifies single-use tokens for email links such as
+// confirmation, password reset, invitations, login and unlock links. Only SHA-256 digests
+// of the tokens are stored.
+package onetimetoken
+
+import "time"
+
+// Purpose says what a token may be used for. A token is only accepted for the purpose it was issued for.
+type Purpose string
+
+const (
+	PurposeEmailConfirmation Purpose = "email_confirmation"
+	PurposePasswordReset     Purpose = "password_reset"
+	PurposeInvite            Purpose = "invite"
+	PurposeLoginLink         Purpose = "login_link"
+	PurposeAccountDeletion   Purpose = "account_deletion"
+	PurposeAccountUnlock     Purpose = "account_unlock"
+)
+
+// Policy configures the lifetime of the tokens of one purpose.
+type Policy struct {
+	TTL time.Duration
+	// MaxAttempts is how often a token may be presented, checks included, before it stops working.
+	MaxAttempts int
+	// SingleActive revokes the unused tokens of the same user and purpose when a new one is issued.
+	SingleActive bool
+}
+
+// Policies holds the policy of every known purpose.
+var Policies = map[Purpose]Policy{
+	PurposeEmailConfirmation: {TTL: 48 * time.Hour, MaxAttempts: 10, SingleActive: true},
+	PurposePasswordReset:     {TTL: 30 * time.Minute, MaxAttempts: 10, SingleActive: true},
+	PurposeInvite:            {TTL: 7 * 24 * time.Hour, MaxAttempts: 10, SingleActive: false},
+	PurposeLoginLink:         {TTL: 15 * time.Minute, MaxAttempts: 3, SingleActive: false},
+	PurposeAccountDeletion:   {TTL: 24 * time.Hour, MaxAttempts: 10, SingleActive: true},
+	PurposeAccountUnlock:     {TTL: 24 * time.Hour, MaxAttempts: 10, SingleActive: true},
+}
+
+// Token is a stored one-time token.
+type Token struct {
+	ID            string     `db:"id"`
+	Purpose       Purpose    `db:"purpose"`
+	UserID        string     `db:"user_id"`
+	TokenHash     string     `db:"token_hash"`
+	BindingHash   *string    `db:"binding_hash"`
+	Attempts      int        `db:"attempts"`
+	MaxAttempts   int        `db:"max_attempts"`
+	ExpiresAt     time.Time  `db:"expires_at"`
+	CreatedAt     time.Time  `db:"created_at"`
+	LastAttemptAt *time.Time `db:"last_attempt_at"`
+	UsedAt        *time.Time `db:"used_at"`
+	RevokedAt     *time.Time `db:"revoked_at"`
+}
@@ -0,0 +1,9 @@
+package onetimetoken
+
+type Repository interface {
+	Create(token *Token, revokeActive bool) error
+	GetByHash(purpose Purpose, tokenHash string) (*Token, error)
+	RecordAttempt(id string) (int, error)
+	MarkUsed(id string) (bool, error)
+	RevokeActive(userID string, purpose Purpose) error
+}
@@ -0,0 +1,101 @@
+package onetimetoken
+
+import (
+	"database/sql"
+
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// sqlxRepository stores one-time tokens in the one_time_tokens table.
+type sqlxRepository struct {
+	db *sqlx.DB
+}
+
+// NewSQLXRepository creates a new instance of sqlxRepository using the given database connection.
+func NewSQLXRepository(db *sqlx.DB) Repository {
+	return &sqlxRepository{db: db}
+}
+
+// Create stores a token. With revokeActive, unused tokens of the same user and
+// purpose are revoked in the same transaction.
+func (r *sqlxRepository) Create(token *Token, revokeActive bool) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if revokeActive {
+		if _, err := tx.Exec(`
+			UPDATE one_time_tokens SET revoked_at = NOW()
+			WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL AND revoked_at IS NULL
+		`, token.UserID, token.Purpose); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.NamedExec(`
+		INSERT INTO one_time_tokens (id, purpose, user_id, token_hash, binding_hash, max_attempts, expires_at, created_at)
+		VALUES (:id, :purpose, :user_id, :token_hash, :binding_hash, :max_attempts, :expires_at, :created_at)
+	`, token); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetByHash returns the token with the given digest, or nil if there is none for the purpose.
+func (r *sqlxRepository) GetByHash(purpose Purpose, tokenHash string) (*Token, error) {
+	var token Token
+	err := r.db.Get(&token, `
+		SELECT * FROM one_time_tokens
+		WHERE purpose = $1 AND token_hash = $2
+	`, purpose, tokenHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// RecordAttempt counts a presentation of the token and returns the new count.
+func (r *sqlxRepository) RecordAttempt(id string) (int, error) {
+	var attempts int
+	err := r.db.Get(&attempts, `
+		UPDATE one_time_tokens
+		SET attempts = attempts + 1, last_attempt_at = NOW()
+		WHERE id = $1
+		RETURNING attempts
+	`, id)
+	return attempts, err
+}
+
+// MarkUsed consumes a token. It reports false when it was already used or revoked.
+func (r *sqlxRepository) MarkUsed(id string) (bool, error) {
+	res, err := r.db.Exec(`
+		UPDATE one_time_tokens
+		SET used_at = NOW()
+		WHERE id = $1 AND used_at IS NULL AND revoked_at IS NULL
+	`, id)
+	if err != nil {
+		return false, err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows == 1, nil
+}
+
+// RevokeActive revokes every unused token of the user for the purpose.
+func (r *sqlxRepository) RevokeActive(userID string, purpose Purpose) error {
+	_, err := r.db.Exec(`
+		UPDATE one_time_tokens SET revoked_at = NOW()
+		WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL AND revoked_at IS NULL
+	`, userID, purpose)
+	return err
+}
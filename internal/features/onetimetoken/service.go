@@ -0,0 +1,143 @@
+package onetimetoken
+
+import (
+	"crypto/rand"
+
+	"crypto/sha256"
+
+	"crypto/subtle"
+
+	"encoding/hex"
+
+	"errors"
+
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"time"
+)
+
+var (
+	ErrInvalidToken   = errors.New("invalid or expired token")
+	ErrUnknownPurpose = errors.New("unknown token purpose")
+)
+
+// Service issues and verifies one-time tokens according to the policy of their purpose.
+type Service struct {
+	repo Repository
+}
+
+// NewService creates a new instance of the Service.
+func NewService(repo Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// Issue creates a token for the user and returns it. Only its digest is stored.
+// A non-empty binding must be presented again whenever the token is used, for
+// example a nonce held by the browser that requested a login link.
+func (s *Service) Issue(purpose Purpose, userID, binding string) (string, error) {
+	policy, ok := Policies[purpose]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownPurpose, purpose)
+	}
+
+	value, err := generate()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	token := &Token{
+		ID:          uuid.New().String(),
+		Purpose:     purpose,
+		UserID:      userID,
+		TokenHash:   hash(value),
+		MaxAttempts: policy.MaxAttempts,
+		ExpiresAt:   now.Add(policy.TTL),
+		CreatedAt:   now,
+	}
+	if binding != "" {
+		bindingHash := hash(binding)
+		token.BindingHash = &bindingHash
+	}
+
+	if err := s.repo.Create(token, policy.SingleActive); err != nil {
+		return "", err
+	}
+
+	return value, nil
+}
+
+// Check verifies a token without using it up. Every check counts as an attempt.
+func (s *Service) Check(purpose Purpose, value, binding string) (*Token, error) {
+	token, err := s.repo.GetByHash(purpose, hash(value))
+	if err != nil {
+		return nil, err
+	}
+	if token == nil || !matches(token.TokenHash, value) {
+		return nil, ErrInvalidToken
+	}
+
+	attempts, err := s.repo.RecordAttempt(token.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if attempts > token.MaxAttempts ||
+		token.UsedAt != nil ||
+		token.RevokedAt != nil ||
+		time.Now().After(token.ExpiresAt) {
+		return nil, ErrInvalidToken
+	}
+
+	if token.BindingHash != nil && !matches(*token.BindingHash, binding) {
+		return nil, ErrInvalidToken
+	}
+
+	return token, nil
+}
+
+// Consume verifies a token and uses it up. It succeeds at most once per token,
+// also for concurrent requests.
+func (s *Service) Consume(purpose Purpose, value, binding string) (*Token, error) {
+	token, err := s.Check(purpose, value, binding)
+	if err != nil {
+		return nil, err
+	}
+
+	used, err := s.repo.MarkUsed(token.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !used {
+		return nil, ErrInvalidToken
+	}
+
+	return token, nil
+}
+
+// Revoke invalidates every unused token of the user for the purpose.
+func (s *Service) Revoke(purpose Purpose, userID string) error {
+	return s.repo.RevokeActive(userID, purpose)
+}
+
+// generate returns a random token with 256 bits of entropy.
+func generate() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hash returns the hex-encoded SHA-256 digest of a token.
+func hash(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+// matches compares a presented value with a stored digest in constant time.
+func matches(digest, value string) bool {
+	return subtle.ConstantTimeCompare([]byte(digest), []byte(hash(value))) == 1
+}
@@ -0,0 +1,10 @@
+package privacy
+
+const (
+	ErrMsgInvalidFormat   = "format must be json or zip"
+	ErrMsgExportFailed    = "failed to export user data"
+	ErrMsgDeletionFailed  = "failed to process account deletion"
+	SuccessMsgDeletionReq = "check your inbox to confirm the deletion of your account"
+	SuccessMsgCancelled   = "account deletion cancelled"
+	SuccessMsgDeleted     = "account deleted"
+)
@@ -0,0 +1,166 @@
+package privacy
+
+import (
+	"archive/zip"
+
+	"encoding/csv"
+
+	"encoding/json"
+
+	"io"
+
+	"strconv"
+
+	"time"
+)
+
+// WriteJSON writes the export as indented JSON.
+func WriteJSON(w io.Writer, export *Export) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(export)
+}
+
+// WriteZip writes a zip archive with the JSON export and one CSV file per section.
+func WriteZip(w io.Writer, export *Export) error {
+	archive := zip.NewWriter(w)
+
+	file, err := archive.Create("export.json")
+	if err != nil {
+		return err
+	}
+	if err := WriteJSON(file, export); err != nil {
+		return err
+	}
+
+	for _, section := range csvSections(export) {
+		file, err := archive.Create(section.name)
+		if err != nil {
+			return err
+		}
+		writer := csv.NewWriter(file)
+		if err := writer.WriteAll(section.rows); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+// csvSection is one CSV file of the archive. The first row is the header.
+type csvSection struct {
+	name string
+	rows [][]string
+}
+
+// csvSections flattens the export into CSV tables.
+func csvSections(export *Export) []csvSection {
+	account := export.Account
+	sections := []csvSection{{
+		name: "account.csv",
+		rows: [][]string{
+			{"id", "email", "role", "status", "email_confirmed", "language", "last_confirmation_sent_at",
+				"totp_enabled", "failed_login_attempts", "locked_until", "created_at"},
+			{account.ID, account.Email, account.Role, account.Status, strconv.FormatBool(account.EmailConfirmed), account.Language,
+				formatTimePtr(account.LastConfirmationSentAt), strconv.FormatBool(account.TOTPEnabled),
+				strconv.Itoa(account.FailedLoginAttempts), formatTimePtr(account.LockedUntil), formatTime(account.CreatedAt)},
+		},
+	}}
+
+	profile := [][]string{{"salutation", "title", "first_name", "last_name", "street", "house_number",
+		"postal_code", "city", "updated_at"}}
+	if p := export.Profile; p != nil {
+		profile = append(profile, []string{p.Salutation, formatStringPtr(p.Title), p.FirstName, p.LastName,
+			p.Street, p.HouseNumber, p.PostalCode, p.City, formatTime(p.UpdatedAt)})
+	}
+	sections = append(sections, csvSection{name: "profile.csv", rows: profile})
+
+	rejections := [][]string{{"id", "reasons", "rejected_at"}}
+	for _, r := range export.Rejections {
+		rejections = append(rejections, []string{r.ID, string(r.Reasons), formatTime(r.RejectedAt)})
+	}
+	sections = append(sections, csvSection{name: "rejections.csv", rows: rejections})
+
+	sessions := [][]string{{"id", "device", "user_agent", "ip", "created_at", "last_used_at", "revoked_at"}}
+	for _, s := range export.Sessions {
+		sessions = append(sessions, []string{s.ID, s.Device, s.UserAgent, s.IP,
+			formatTime(s.CreatedAt), formatTime(s.LastUsedAt), formatTimePtr(s.RevokedAt)})
+	}
+	sections = append(sections, csvSection{name: "sessions.csv", rows: sessions})
+
+	history := [][]string{{"from", "to", "transition", "created_at"}}
+	for _, h := range export.StatusHistory {
+		history = append(history, []string{h.From, h.To, h.Transition, formatTime(h.CreatedAt)})
+	}
+	sections = append(sections, csvSection{name: "status_history.csv", rows: history})
+
+	blocks := [][]string{{"kind", "reason", "ends_at", "created_at", "lifted_at"}}
+	for _, b := range export.AccountBlocks {
+		blocks = append(blocks, []string{b.Kind, b.Reason, formatTimePtr(b.EndsAt), formatTime(b.CreatedAt), formatTimePtr(b.LiftedAt)})
+	}
+	sections = append(sections, csvSection{name: "account_blocks.csv", rows: blocks})
+
+	notes := [][]string{{"body", "created_at"}}
+	for _, n := range export.AdminNotes {
+		notes = append(notes, []string{n.Body, formatTime(n.CreatedAt)})
+	}
+	sections = append(sections, csvSection{name: "admin_notes.csv", rows: notes})
+
+	tags := [][]string{{"tag"}}
+	for _, tag := range export.Tags {
+		tags = append(tags, []string{tag})
+	}
+	sections = append(sections, csvSection{name: "tags.csv", rows: tags})
+
+	documents := [][]string{{"type", "file_name", "content_type", "size", "status", "reviewed_at", "created_at"}}
+	for _, d := range export.Documents {
+		documents = append(documents, []string{d.Type, d.FileName, d.ContentType, strconv.FormatInt(d.Size, 10),
+			d.Status, formatTimePtr(d.ReviewedAt), formatTime(d.CreatedAt)})
+	}
+	sections = append(sections, csvSection{name: "documents.csv", rows: documents})
+
+	profileChanges := [][]string{{"changes", "previous_status", "new_status", "created_at"}}
+	for _, c := range export.ProfileChanges {
+		profileChanges = append(profileChanges, []string{string(c.Changes), c.PreviousStatus, c.NewStatus, formatTime(c.CreatedAt)})
+	}
+	sections = append(sections, csvSection{name: "profile_changes.csv", rows: profileChanges})
+
+	changes := [][]string{{"old_email", "new_email", "created_at", "confirmed_at", "reverted_at"}}
+	for _, c := range export.EmailChanges {
+		changes = append(changes, []string{c.OldEmail, c.NewEmail,
+			formatTime(c.CreatedAt), formatTimePtr(c.ConfirmedAt), formatTimePtr(c.RevertedAt)})
+	}
+	sections = append(sections, csvSection{name: "email_changes.csv", rows: changes})
+
+	events := [][]string{{"event", "ip", "details", "created_at"}}
+	for _, e := range export.SecurityEvents {
+		events = append(events, []string{e.Event, formatStringPtr(e.IP), string(e.Details), formatTime(e.CreatedAt)})
+	}
+	sections = append(sections, csvSection{name: "security_events.csv", rows: events})
+
+	tickets := [][]string{{"id", "title", "content", "status", "created_at"}}
+	for _, t := range export.Tickets {
+		tickets = append(tickets, []string{t.ID, t.Title, t.Content, t.Status, formatTime(t.CreatedAt)})
+	}
+	sections = append(sections, csvSection{name: "tickets.csv", rows: tickets})
+
+	return sections
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatTimePtr(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return formatTime(*t)
+}
+
+func formatStringPtr(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,284 @@
+package privacy
+
+import (
+	"carowebapp/core/internal/infrastructure/response"
+
+	"carowebapp/core/internal/pkg/contextutils"
+
+	"bytes"
+
+	"errors"
+
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/google/uuid"
+
+	"go.uber.org/zap"
+)
+
+// Handler provides the HTTP handlers for data exports and account deletion,
+// both for the users themselves and for admins.
+type Handler struct {
+	service *Service
+	logger  *zap.Logger
+}
+
+func NewHandler(service *Service, logger *zap.Logger) *Handler {
+	return &Handler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password" validate:"required"`
+}
+
+type AdminDeleteAccountRequest struct {
+	// Immediate skips the grace period, for requests already verified offline.
+	Immediate bool `json:"immediate"`
+}
+
+// Export downloads all data held about the current user, as JSON or as a zip
+// archive with the JSON and one CSV file per table (?format=zip).
+func (h *Handler) Export(c *fiber.Ctx) error {
+	userID, ok := contextutils.GetUserID(c)
+	if !ok {
+		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusUnauthorized, response.ErrMsgUnauthorized)
+	}
+
+	return h.export(c, userID, RequestedByUser, "")
+}
+
+// RequestDeletion emails a link that confirms the deletion of the current user's account.
+func (h *Handler) RequestDeletion(c *fiber.Ctx) error {
+	req, _ := contextutils.GetValidatedBody[DeleteAccountRequest](c)
+	userID, ok := contextutils.GetUserID(c)
+	if !ok {
+		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusUnauthorized, response.ErrMsgUnauthorized)
+	}
+
+	if err := h.service.RequestDeletion(userID, req.Password); err != nil {
+		return h.deletionError(c, userID, err)
+	}
+
+	h.logger.Info("Account deletion requested",
+		zap.String("user_id", userID),
+	)
+
+	return response.JSONSuccess(c, fiber.StatusAccepted, fiber.Map{
+		"message": SuccessMsgDeletionReq,
+	})
+}
+
+// ConfirmDeletion schedules the deletion with the link from the confirmation email.
+func (h *Handler) ConfirmDeletion(c *fiber.Ctx) error {
+	token := c.Query("token")
+	if token == "" {
+		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusBadRequest, response.ErrMsgMissingToken,
+			zap.String("path", c.Path()),
+		)
+	}
+
+	deletion, err := h.service.ConfirmDeletion(token, c.IP())
+	if err != nil {
+		return h.deletionError(c, "", err)
+	}
+
+	h.logger.Info("Account deletion scheduled",
+		zap.String("user_id", deletion.UserID),
+		zap.Time("scheduled_for", deletion.ScheduledFor),
+	)
+
+	return response.JSONSuccess(c, fiber.StatusOK, deletion)
+}
+
+// GetDeletion returns the pending deletion of the current user.
+func (h *Handler) GetDeletion(c *fiber.Ctx) error {
+	userID, ok := contextutils.GetUserID(c)
+	if !ok {
+		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusUnauthorized, response.ErrMsgUnauthorized)
+	}
+
+	deletion, err := h.service.GetDeletion(userID)
+	if err != nil {
+		return h.deletionError(c, userID, err)
+	}
+
+	return response.JSONSuccess(c, fiber.StatusOK, deletion)
+}
+
+// CancelDeletion keeps the current user's account.
+func (h *Handler) CancelDeletion(c *fiber.Ctx) error {
+	userID, ok := contextutils.GetUserID(c)
+	if !ok {
+		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusUnauthorized, response.ErrMsgUnauthorized)
+	}
+
+	if err := h.service.CancelDeletion(userID, RequestedByUser, "", c.IP()); err != nil {
+		return h.deletionError(c, userID, err)
+	}
+
+	h.logger.Info(SuccessMsgCancelled,
+		zap.String("user_id", userID),
+	)
+
+	return response.JSONSuccess(c, fiber.StatusOK, fiber.Map{
+		"message": SuccessMsgCancelled,
+	})
+}
+
+// AdminExport downloads the data of any user, for requests that arrive by email or post.
+func (h *Handler) AdminExport(c *fiber.Ctx) error {
+	userID, ok := h.targetUserID(c)
+	if !ok {
+		return response.JSONError(c, fiber.StatusBadRequest, fiber.ErrBadRequest)
+	}
+	adminID, _ := contextutils.GetUserID(c)
+
+	return h.export(c, userID, RequestedByAdmin, adminID)
+}
+
+// AdminDeleteAccount schedules the deletion of a user's account, or deletes it right away.
+func (h *Handler) AdminDeleteAccount(c *fiber.Ctx) error {
+	req, _ := contextutils.GetValidatedBody[AdminDeleteAccountRequest](c)
+	userID, ok := h.targetUserID(c)
+	if !ok {
+		return response.JSONError(c, fiber.StatusBadRequest, fiber.ErrBadRequest)
+	}
+	adminID, _ := contextutils.GetUserID(c)
+
+	if req.Immediate {
+		if err := h.service.DeleteNow(userID, RequestedByAdmin, adminID, c.IP()); err != nil {
+			return h.deletionError(c, userID, err)
+		}
+
+		h.logger.Info(SuccessMsgDeleted,
+			zap.String("admin_id", adminID),
+			zap.String("target_user_id", userID),
+		)
+
+		return response.JSONSuccess(c, fiber.StatusOK, fiber.Map{
+			"message": SuccessMsgDeleted,
+		})
+	}
+
+	deletion, err := h.service.ScheduleDeletion(userID, RequestedByAdmin, adminID, c.IP())
+	if err != nil {
+		return h.deletionError(c, userID, err)
+	}
+
+	h.logger.Info("Account deletion scheduled",
+		zap.String("admin_id", adminID),
+		zap.String("target_user_id", userID),
+		zap.Time("scheduled_for", deletion.ScheduledFor),
+	)
+
+	return response.JSONSuccess(c, fiber.StatusAccepted, deletion)
+}
+
+// AdminCancelDeletion cancels the pending deletion of a user's account.
+func (h *Handler) AdminCancelDeletion(c *fiber.Ctx) error {
+	userID, ok := h.targetUserID(c)
+	if !ok {
+		return response.JSONError(c, fiber.StatusBadRequest, fiber.ErrBadRequest)
+	}
+	adminID, _ := contextutils.GetUserID(c)
+
+	if err := h.service.CancelDeletion(userID, RequestedByAdmin, adminID, c.IP()); err != nil {
+		return h.deletionError(c, userID, err)
+	}
+
+	h.logger.Info(SuccessMsgCancelled,
+		zap.String("admin_id", adminID),
+		zap.String("target_user_id", userID),
+	)
+
+	return response.JSONSuccess(c, fiber.StatusOK, fiber.Map{
+		"message": SuccessMsgCancelled,
+	})
+}
+
+// export writes the export of a user in the format requested by ?format=.
+func (h *Handler) export(c *fiber.Ctx, userID, requestedBy, adminID string) error {
+	format := c.Query("format", "json")
+	if format != "json" && format != "zip" {
+		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusBadRequest, ErrMsgInvalidFormat)
+	}
+
+	export, err := h.service.Export(userID, requestedBy, adminID, c.IP())
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return response.JSONErrorInfoLog(c, h.logger, fiber.StatusNotFound, err.Error(),
+				zap.String("target_user_id", userID),
+			)
+		}
+		return response.JSONErrorWithLog(c, h.logger, fiber.StatusInternalServerError, ErrMsgExportFailed,
+			zap.String("target_user_id", userID),
+			zap.Error(err),
+		)
+	}
+
+	var buf bytes.Buffer
+	if format == "zip" {
+		err = WriteZip(&buf, export)
+		c.Set(fiber.HeaderContentType, "application/zip")
+	} else {
+		err = WriteJSON(&buf, export)
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+	}
+	if err != nil {
+		return response.JSONErrorWithLog(c, h.logger, fiber.StatusInternalServerError, ErrMsgExportFailed,
+			zap.String("target_user_id", userID),
+			zap.Error(err),
+		)
+	}
+
+	h.logger.Info("User data exported",
+		zap.String("target_user_id", userID),
+		zap.String("requested_by", requestedBy),
+		zap.String("format", format),
+	)
+
+	c.Attachment(fmt.Sprintf("export-%s.%s", userID, format))
+	return c.Status(fiber.StatusOK).Send(buf.Bytes())
+}
+
+// targetUserID reads and validates the :id path parameter of admin routes.
+func (h *Handler) targetUserID(c *fiber.Ctx) (string, bool) {
+	userID := c.Params("id")
+	if _, err := uuid.Parse(userID); err != nil {
+		h.logger.Debug("invalid user ID in path", zap.String("path", c.Path()))
+		return "", false
+	}
+	return userID, true
+}
+
+// deletionError maps deletion service errors to HTTP responses.
+func (h *Handler) deletionError(c *fiber.Ctx, userID string, err error) error {
+	switch {
+	case errors.Is(err, ErrUserNotFound), errors.Is(err, ErrNoDeletionScheduled):
+		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusNotFound, err.Error(),
+			zap.String("user_id", userID),
+		)
+
+	case errors.Is(err, ErrDeletionScheduled):
+		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusConflict, err.Error(),
+			zap.String("user_id", userID),
+		)
+
+	case errors.Is(err, ErrInvalidPassword), errors.Is(err, ErrInvalidDeletionLink):
+		return response.JSONErrorInfoLog(c, h.logger, fiber.StatusBadRequest, err.Error(),
+			zap.String("user_id", userID),
+			zap.String("ip", c.IP()),
+		)
+
+	default:
+		return response.JSONErrorWithLog(c, h.logger, fiber.StatusInternalServerError, ErrMsgDeletionFailed,
+			zap.String("user_id", userID),
+			zap.Error(err),
+		)
+	}
+}
@@ -0,0 +1,153 @@
+// Package privacy answers data subject requests under the DSGVO: the export of all
+// data held about a user (Art. 15) and the deletion of their account (Art. 17).
+package privacy
+
+import (
+	"encoding/json"
+
+	"time"
+)
+
+// Who asked for an account deletion.
+const (
+	RequestedByUser  = "user"
+	RequestedByAdmin = "admin"
+	RequestedByCLI   = "cli"
+)
+
+// Export holds everything stored about a user. Secrets such as password hashes,
+// TOTP secrets and token digests are left out.
+type Export struct {
+	GeneratedAt    time.Time       `json:"generated_at"`
+	Account        Account         `json:"account"`
+	Profile        *Profile        `json:"profile"`
+	Rejections     []Rejection     `json:"rejections"`
+	Sessions       []Session       `json:"sessions"`
+	StatusHistory  []StatusChange  `json:"status_history"`
+	AccountBlocks  []AccountBlock  `json:"account_blocks"`
+	AdminNotes     []AdminNote     `json:"admin_notes"`
+	Tags           []string        `json:"tags"`
+	Documents      []Document      `json:"documents"`
+	ProfileChanges []ProfileChange `json:"profile_changes"`
+	EmailChanges   []EmailChange   `json:"email_changes"`
+	SecurityEvents []SecurityEvent `json:"security_events"`
+	Tickets        []Ticket        `json:"tickets"`
+}
+
+type Account struct {
+	ID                     string     `db:"id" json:"id"`
+	Email                  string     `db:"email" json:"email"`
+	Role                   string     `db:"role" json:"role"`
+	Status                 string     `db:"status" json:"status"`
+	EmailConfirmed         bool       `db:"email_confirmed" json:"email_confirmed"`
+	Language               string     `db:"language" json:"language"`
+	LastConfirmationSentAt *time.Time `db:"last_confirmation_sent_at" json:"last_confirmation_sent_at"`
+	TOTPEnabled            bool       `db:"totp_enabled" json:"totp_enabled"`
+	FailedLoginAttempts    int        `db:"failed_login_attempts" json:"failed_login_attempts"`
+	LockedUntil            *time.Time `db:"locked_until" json:"locked_until"`
+	CreatedAt              time.Time  `db:"created_at" json:"created_at"`
+}
+
+type Profile struct {
+	Salutation  string    `db:"salutation" json:"salutation"`
+	Title       *string   `db:"title" json:"title"`
+	FirstName   string    `db:"first_name" json:"first_name"`
+	LastName    string    `db:"last_name" json:"last_name"`
+	Street      string    `db:"street" json:"street"`
+	HouseNumber string    `db:"house_number" json:"house_number"`
+	PostalCode  string    `db:"postal_code" json:"postal_code"`
+	City        string    `db:"city" json:"city"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+}
+
+type Rejection struct {
+	ID         string          `db:"id" json:"id"`
+	Reasons    json.RawMessage `db:"errors" json:"reasons"`
+	RejectedAt time.Time       `db:"rejected_at" json:"rejected_at"`
+}
+
+type Session struct {
+	ID         string     `db:"id" json:"id"`
+	Device     string     `db:"device" json:"device"`
+	UserAgent  string     `db:"user_agent" json:"user_agent"`
+	IP         string     `db:"ip" json:"ip"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	LastUsedAt time.Time  `db:"last_used_at" json:"last_used_at"`
+	RevokedAt  *time.Time `db:"revoked_at" json:"revoked_at"`
+}
+
+type StatusChange struct {
+	From       string    `db:"from_status" json:"from"`
+	To         string    `db:"to_status" json:"to"`
+	Transition string    `db:"transition" json:"transition"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+}
+
+type AccountBlock struct {
+	Kind      string     `db:"kind" json:"kind"`
+	Reason    string     `db:"reason" json:"reason"`
+	EndsAt    *time.Time `db:"ends_at" json:"ends_at"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	LiftedAt  *time.Time `db:"lifted_at" json:"lifted_at"`
+}
+
+// AdminNote is a note staff wrote about the user. The author is left out.
+type AdminNote struct {
+	Body      string    `db:"body" json:"body"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// Document describes an uploaded verification document. The file itself is not exported,
+// the user can download it while it is kept. The moderators' review note is internal and
+// left out, as in the applicant's document list.
+type Document struct {
+	Type        string     `db:"type" json:"type"`
+	FileName    string     `db:"file_name" json:"file_name"`
+	ContentType string     `db:"content_type" json:"content_type"`
+	Size        int64      `db:"size_bytes" json:"size"`
+	Status      string     `db:"status" json:"status"`
+	ReviewedAt  *time.Time `db:"reviewed_at" json:"reviewed_at"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+}
+
+type ProfileChange struct {
+	Changes        json.RawMessage `db:"changes" json:"changes"`
+	PreviousStatus string          `db:"previous_status" json:"previous_status"`
+	NewStatus      string          `db:"new_status" json:"new_status"`
+	CreatedAt      time.Time       `db:"created_at" json:"created_at"`
+}
+
+type EmailChange struct {
+	OldEmail    string     `db:"old_email" json:"old_email"`
+	NewEmail    string     `db:"new_email" json:"new_email"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+	ConfirmedAt *time.Time `db:"confirmed_at" json:"confirmed_at"`
+	RevertedAt  *time.Time `db:"reverted_at" json:"reverted_at"`
+}
+
+type SecurityEvent struct {
+	Event     string          `db:"event" json:"event"`
+	IP        *string         `db:"ip" json:"ip"`
+	Details   json.RawMessage `db:"details" json:"details"`
+	CreatedAt time.Time       `db:"created_at" json:"created_at"`
+}
+
+type Ticket struct {
+	ID        string    `db:"id" json:"id"`
+	Title     string    `db:"title" json:"title"`
+	Content   string    `db:"content" json:"content"`
+	Status    string    `db:"status" json:"status"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// Deletion is a scheduled account deletion. Until ScheduledFor it can be cancelled.
+type Deletion struct {
+	ID           string     `db:"id" json:"id"`
+	UserID       string     `db:"user_id" json:"-"`
+	RequestedBy  string     `db:"requested_by" json:"requested_by"`
+	AdminID      *string    `db:"admin_id" json:"-"`
+	RequestedAt  time.Time  `db:"requested_at" json:"requested_at"`
+	ScheduledFor time.Time  `db:"scheduled_for" json:"scheduled_for"`
+	CancelledAt  *time.Time `db:"cancelled_at" json:"-"`
+	CompletedAt  *time.Time `db:"completed_at" json:"-"`
+}
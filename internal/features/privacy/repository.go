@@ -0,0 +1,20 @@
+package privacy
+
+import (
+	domainuser "carowebapp/core/internal/domain/user"
+
+	"time"
+)
+
+type Repository interface {
+	GetAccount(userID string) (*Account, error)
+	FindUserIDByEmail(email string) (string, error)
+	Export(userID string) (*Export, error)
+	RecordExport(event domainuser.SecurityEvent, entry *domainuser.AuditEntry) error
+
+	GetActiveDeletion(userID string) (*Deletion, error)
+	CreateDeletion(deletion *Deletion, event domainuser.SecurityEvent, entry *domainuser.AuditEntry) error
+	CancelDeletion(userID string, event domainuser.SecurityEvent, entry *domainuser.AuditEntry) (bool, error)
+	ListDueDeletions(now time.Time) ([]Deletion, error)
+	DeleteAccount(deletion *Deletion, event domainuser.SecurityEvent, entry domainuser.AuditEntry) error
+}
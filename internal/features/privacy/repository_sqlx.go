@@ -0,0 +1,387 @@
+package privacy
+
+import (
+	domainuser "carowebapp/core/internal/domain/user"
+
+	"carowebapp/core/internal/infrastructure/auditlog"
+
+	"carowebapp/core/internal/infrastructure/securitylog"
+
+	"context"
+
+	"database/sql"
+
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/lib/pq"
+
+	"time"
+)
+
+// sqlxRepository provides SQL-backed implementation of the privacy.Repository interface.
+type sqlxRepository struct {
+	db *sqlx.DB
+}
+
+// NewSQLXRepository creates a new instance of sqlxRepository using the given database connection.
+func NewSQLXRepository(db *sqlx.DB) Repository {
+	return &sqlxRepository{db: db}
+}
+
+const selectAccount = `
+	SELECT id, email, role, status, email_confirmed, language, last_confirmation_sent_at,
+	       totp_enabled, failed_login_attempts, locked_until, created_at
+	FROM users
+	WHERE id = $1
+`
+
+// GetAccount returns the account of a user, or nil if it does not exist.
+func (r *sqlxRepository) GetAccount(userID string) (*Account, error) {
+	var account Account
+	if err := r.db.Get(&account, selectAccount, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &account, nil
+}
+
+// FindUserIDByEmail returns the ID of the user with the email, or "" if there is none.
+func (r *sqlxRepository) FindUserIDByEmail(email string) (string, error) {
+	var id string
+	err := r.db.Get(&id, `SELECT id FROM users WHERE email = $1`, email)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return id, err
+}
+
+// Export collects all data of a user from one consistent snapshot.
+// It returns nil if the user does not exist.
+func (r *sqlxRepository) Export(userID string) (*Export, error) {
+	tx, err := r.db.BeginTxx(context.Background(), &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	export := &Export{
+		GeneratedAt:    time.Now().UTC(),
+		Rejections:     []Rejection{},
+		Sessions:       []Session{},
+		StatusHistory:  []StatusChange{},
+		AccountBlocks:  []AccountBlock{},
+		AdminNotes:     []AdminNote{},
+		Tags:           []string{},
+		Documents:      []Document{},
+		ProfileChanges: []ProfileChange{},
+		EmailChanges:   []EmailChange{},
+		SecurityEvents: []SecurityEvent{},
+		Tickets:        []Ticket{},
+	}
+
+	if err := tx.Get(&export.Account, selectAccount, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var profile Profile
+	err = tx.Get(&profile, `
+		SELECT salutation, title, first_name, last_name, street, house_number, postal_code, city, updated_at
+		FROM user_profiles
+		WHERE user_id = $1
+	`, userID)
+	switch {
+	case err == nil:
+		export.Profile = &profile
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, err
+	}
+
+	if err := tx.Select(&export.Rejections, `
+		SELECT id, COALESCE(errors, '{}'::jsonb) AS errors, rejected_at
+		FROM user_rejections
+		WHERE user_id = $1
+		ORDER BY rejected_at
+	`, userID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Select(&export.Sessions, `
+		SELECT id, device, user_agent, ip, created_at, last_used_at, revoked_at
+		FROM user_sessions
+		WHERE user_id = $1
+		ORDER BY created_at
+	`, userID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Select(&export.StatusHistory, `
+		SELECT from_status, to_status, transition, created_at
+		FROM user_status_transitions
+		WHERE user_id = $1
+		ORDER BY created_at
+	`, userID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Select(&export.AccountBlocks, `
+		SELECT kind, reason, ends_at, created_at, lifted_at
+		FROM account_blocks
+		WHERE user_id = $1
+		ORDER BY created_at
+	`, userID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Select(&export.AdminNotes, `
+		SELECT body, created_at
+		FROM user_notes
+		WHERE user_id = $1
+		ORDER BY created_at
+	`, userID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Select(&export.Tags, `
+		SELECT tag
+		FROM user_tags
+		WHERE user_id = $1
+		ORDER BY tag
+	`, userID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Select(&export.Documents, `
+		SELECT type, file_name, content_type, size_bytes, status, reviewed_at, created_at
+		FROM user_documents
+		WHERE user_id = $1
+		ORDER BY created_at
+	`, userID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Select(&export.ProfileChanges, `
+		SELECT changes, previous_status, new_status, created_at
+		FROM profile_changes
+		WHERE user_id = $1
+		ORDER BY created_at
+	`, userID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Select(&export.EmailChanges, `
+		SELECT old_email, new_email, created_at, confirmed_at, reverted_at
+		FROM email_change_requests
+		WHERE user_id = $1
+		ORDER BY created_at
+	`, userID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Select(&export.SecurityEvents, `
+		SELECT event, ip, COALESCE(details, '{}'::jsonb) AS details, created_at
+		FROM security_events
+		WHERE user_id = $1
+		ORDER BY created_at
+	`, userID); err != nil {
+		return nil, err
+	}
+
+	hasTickets, err := ticketsTableExists(tx)
+	if err != nil {
+		return nil, err
+	}
+	if hasTickets {
+		if err := tx.Select(&export.Tickets, `
+			SELECT id, title, content, status, created_at
+			FROM tickets
+			WHERE user_id = $1
+			ORDER BY created_at
+		`, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	return export, nil
+}
+
+// RecordExport writes an export to the security log and, for admin and CLI requests,
+// to the audit log.
+func (r *sqlxRepository) RecordExport(event domainuser.SecurityEvent, entry *domainuser.AuditEntry) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := securitylog.Record(tx, event); err != nil {
+		return err
+	}
+	if err := recordAudit(tx, entry); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetActiveDeletion returns the pending deletion of a user, or nil if there is none.
+func (r *sqlxRepository) GetActiveDeletion(userID string) (*Deletion, error) {
+	var deletion Deletion
+	err := r.db.Get(&deletion, `
+		SELECT * FROM account_deletions
+		WHERE user_id = $1 AND cancelled_at IS NULL AND completed_at IS NULL
+	`, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &deletion, nil
+}
+
+// CreateDeletion schedules a deletion and records it in the security log and, unless
+// entry is nil, the audit log. It returns ErrDeletionScheduled if the user already has
+// a pending deletion.
+func (r *sqlxRepository) CreateDeletion(deletion *Deletion, event domainuser.SecurityEvent, entry *domainuser.AuditEntry) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.NamedExec(`
+		INSERT INTO account_deletions (id, user_id, requested_by, admin_id, requested_at, scheduled_for)
+		VALUES (:id, :user_id, :requested_by, :admin_id, :requested_at, :scheduled_for)
+	`, deletion); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return ErrDeletionScheduled
+		}
+		return err
+	}
+
+	if err := securitylog.Record(tx, event); err != nil {
+		return err
+	}
+	if err := recordAudit(tx, entry); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// CancelDeletion cancels the pending deletion of a user. It reports false when there is none.
+// A non-nil entry is written to the audit log with the cancelled deletion as its target.
+func (r *sqlxRepository) CancelDeletion(userID string, event domainuser.SecurityEvent, entry *domainuser.AuditEntry) (bool, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var deletionID string
+	err = tx.Get(&deletionID, `
+		UPDATE account_deletions SET cancelled_at = NOW()
+		WHERE user_id = $1 AND cancelled_at IS NULL AND completed_at IS NULL
+		RETURNING id
+	`, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if err := securitylog.Record(tx, event); err != nil {
+		return false, err
+	}
+	if entry != nil {
+		entry.TargetID = deletionID
+	}
+	if err := recordAudit(tx, entry); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// ListDueDeletions returns the pending deletions whose grace period has ended.
+func (r *sqlxRepository) ListDueDeletions(now time.Time) ([]Deletion, error) {
+	var deletions []Deletion
+	err := r.db.Select(&deletions, `
+		SELECT * FROM account_deletions
+		WHERE cancelled_at IS NULL AND completed_at IS NULL AND scheduled_for <= $1
+		ORDER BY scheduled_for
+	`, now)
+	return deletions, err
+}
+
+// DeleteAccount erases a user in one transaction. Tables with a foreign key to users
+// are cleared by ON DELETE CASCADE. The security log is kept for abuse investigations,
+// but its entries of the user are anonymised. The admin audit log is append-only and
+// holds nothing but IDs and codes, so it is left as it is and gets the entry of the erasure.
+func (r *sqlxRepository) DeleteAccount(deletion *Deletion, event domainuser.SecurityEvent, entry domainuser.AuditEntry) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(`
+		UPDATE security_events SET user_id = NULL, ip = NULL, details = NULL
+		WHERE user_id = $1
+	`, deletion.UserID); err != nil {
+		return err
+	}
+
+	hasTickets, err := ticketsTableExists(tx)
+	if err != nil {
+		return err
+	}
+	if hasTickets {
+		if _, err := tx.Exec(`DELETE FROM tickets WHERE user_id = $1`, deletion.UserID); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(`DELETE FROM users WHERE id = $1`, deletion.UserID); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`UPDATE account_deletions SET completed_at = NOW() WHERE id = $1`, deletion.ID); err != nil {
+		return err
+	}
+
+	if err := securitylog.Record(tx, event); err != nil {
+		return err
+	}
+	if err := auditlog.Record(tx, entry); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// recordAudit writes the audit entry of an admin or CLI request. Requests users make
+// for their own account have none.
+func recordAudit(exec sqlx.Execer, entry *domainuser.AuditEntry) error {
+	if entry == nil {
+		return nil
+	}
+	return auditlog.Record(exec, *entry)
+}
+
+// ticketsTableExists reports whether the tickets table of the service card feature
+// has been created. It is not part of the migrations of every deployment.
+func ticketsTableExists(q sqlx.Queryer) (bool, error) {
+	var exists bool
+	err := sqlx.Get(q, &exists, `SELECT to_regclass('tickets') IS NOT NULL`)
+	return exists, err
+}
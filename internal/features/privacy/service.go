@@ -0,0 +1,400 @@
+package privacy
+
+import (
+	domainuser "carowebapp/core/internal/domain/user"
+
+	"carowebapp/core/internal/features/onetimetoken"
+
+	"carowebapp/core/internal/infrastructure/email"
+
+	"context"
+
+	"errors"
+
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"go.uber.org/zap"
+
+	"os"
+
+	"time"
+)
+
+var (
+	ErrUserNotFound        = errors.New("user not found")
+	ErrInvalidPassword     = errors.New("invalid password")
+	ErrDeletionScheduled   = errors.New("account deletion is already scheduled")
+	ErrNoDeletionScheduled = errors.New("no account deletion is scheduled")
+	ErrInvalidDeletionLink = errors.New("invalid or expired deletion link")
+)
+
+// DefaultGracePeriod is how long a confirmed deletion can still be cancelled.
+const DefaultGracePeriod = 14 * 24 * time.Hour
+
+// PasswordChecker re-authenticates a user before their account is deleted.
+type PasswordChecker interface {
+	CheckPassword(userID, password string) (bool, error)
+}
+
+// DocumentEraser removes the uploaded documents of a user. Their files live outside
+// the database and are not removed with the account.
+type DocumentEraser interface {
+	DeleteAll(userID string) error
+}
+
+// Service exports user data and deletes accounts after a grace period.
+type Service struct {
+	repo        Repository
+	sender      email.Sender
+	tokens      *onetimetoken.Service
+	passwords   PasswordChecker
+	documents   DocumentEraser
+	gracePeriod time.Duration
+	logger      *zap.Logger
+}
+
+// NewService creates a new instance of the Service.
+// The password checker may be nil for callers that never handle self-service
+// requests, such as CLI commands.
+func NewService(repo Repository, sender email.Sender, tokens *onetimetoken.Service, passwords PasswordChecker, documents DocumentEraser, gracePeriod time.Duration, logger *zap.Logger) *Service {
+	return &Service{
+		repo:        repo,
+		sender:      sender,
+		tokens:      tokens,
+		passwords:   passwords,
+		documents:   documents,
+		gracePeriod: gracePeriod,
+		logger:      logger,
+	}
+}
+
+// GracePeriodFromEnv reads the grace period from ACCOUNT_DELETION_GRACE_PERIOD,
+// for example "336h". It falls back to DefaultGracePeriod.
+func GracePeriodFromEnv() (time.Duration, error) {
+	value := os.Getenv("ACCOUNT_DELETION_GRACE_PERIOD")
+	if value == "" {
+		return DefaultGracePeriod, nil
+	}
+
+	grace, err := time.ParseDuration(value)
+	if err != nil || grace < 0 {
+		return 0, fmt.Errorf("invalid ACCOUNT_DELETION_GRACE_PERIOD %q", value)
+	}
+	return grace, nil
+}
+
+// Export collects all data held about the user. Every export is recorded in the
+// security log together with who requested it, exports by admins and on the CLI also
+// in the audit log.
+func (s *Service) Export(userID, requestedBy, adminID, ip string) (*Export, error) {
+	export, err := s.repo.Export(userID)
+	if err != nil {
+		return nil, err
+	}
+	if export == nil {
+		return nil, ErrUserNotFound
+	}
+
+	if err := s.repo.RecordExport(domainuser.SecurityEvent{
+		UserID:  userID,
+		Type:    domainuser.SecurityEventDataExported,
+		IP:      ip,
+		Details: requestDetails(requestedBy, adminID),
+	}, auditEntry(requestedBy, adminID, ip, domainuser.AuditUserDataExported, domainuser.AuditTargetUser, userID, nil)); err != nil {
+		return nil, err
+	}
+
+	return export, nil
+}
+
+// FindUserID resolves an email address for requests that arrive offline.
+func (s *Service) FindUserID(email string) (string, error) {
+	userID, err := s.repo.FindUserIDByEmail(email)
+	if err != nil {
+		return "", err
+	}
+	if userID == "" {
+		return "", ErrUserNotFound
+	}
+	return userID, nil
+}
+
+// RequestDeletion starts a self-service deletion. After re-checking the password
+// a confirmation link is emailed; the deletion is scheduled once it is opened.
+func (s *Service) RequestDeletion(userID, password string) error {
+	account, err := s.repo.GetAccount(userID)
+	if err != nil {
+		return err
+	}
+	if account == nil {
+		return ErrUserNotFound
+	}
+
+	pending, err := s.repo.GetActiveDeletion(userID)
+	if err != nil {
+		return err
+	}
+	if pending != nil {
+		return ErrDeletionScheduled
+	}
+
+	match, err := s.passwords.CheckPassword(userID, password)
+	if err != nil {
+		return err
+	}
+	if !match {
+		return ErrInvalidPassword
+	}
+
+	token, err := s.tokens.Issue(onetimetoken.PurposeAccountDeletion, userID, "")
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		_ = s.sender.SendAccountDeletionConfirmation(account.Email, token)
+	}()
+
+	return nil
+}
+
+// ConfirmDeletion schedules the deletion requested with RequestDeletion.
+func (s *Service) ConfirmDeletion(token, ip string) (*Deletion, error) {
+	confirmation, err := s.tokens.Consume(onetimetoken.PurposeAccountDeletion, token, "")
+	if errors.Is(err, onetimetoken.ErrInvalidToken) {
+		return nil, ErrInvalidDeletionLink
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.ScheduleDeletion(confirmation.UserID, RequestedByUser, "", ip)
+}
+
+// ScheduleDeletion deletes the account after the grace period and informs the user,
+// who can still cancel it. Admins and the CLI use it for requests that arrive offline.
+func (s *Service) ScheduleDeletion(userID, requestedBy, adminID, ip string) (*Deletion, error) {
+	account, err := s.repo.GetAccount(userID)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, ErrUserNotFound
+	}
+
+	now := time.Now()
+	deletion := &Deletion{
+		ID:           uuid.New().String(),
+		UserID:       userID,
+		RequestedBy:  requestedBy,
+		RequestedAt:  now,
+		ScheduledFor: now.Add(s.gracePeriod),
+	}
+	if adminID != "" {
+		deletion.AdminID = &adminID
+	}
+
+	details := requestDetails(requestedBy, adminID)
+	details["scheduled_for"] = deletion.ScheduledFor.UTC()
+
+	if err := s.repo.CreateDeletion(deletion, domainuser.SecurityEvent{
+		UserID:  userID,
+		Type:    domainuser.SecurityEventDeletionScheduled,
+		IP:      ip,
+		Details: details,
+	}, auditEntry(requestedBy, adminID, ip, domainuser.AuditDeletionScheduled, domainuser.AuditTargetDeletion, deletion.ID,
+		domainuser.AuditState{"scheduled_for": deletion.ScheduledFor.UTC()},
+	)); err != nil {
+		return nil, err
+	}
+
+	// Sent synchronously, so the CLI does not exit before the email is out.
+	if err := s.sender.SendAccountDeletionScheduled(account.Email, deletion.ScheduledFor); err != nil {
+		s.logger.Warn("failed to send account deletion scheduled email",
+			zap.String("user_id", userID),
+			zap.Error(err),
+		)
+	}
+
+	return deletion, nil
+}
+
+// GetDeletion returns the pending deletion of the user.
+func (s *Service) GetDeletion(userID string) (*Deletion, error) {
+	deletion, err := s.repo.GetActiveDeletion(userID)
+	if err != nil {
+		return nil, err
+	}
+	if deletion == nil {
+		return nil, ErrNoDeletionScheduled
+	}
+	return deletion, nil
+}
+
+// CancelDeletion keeps the account during the grace period.
+func (s *Service) CancelDeletion(userID, requestedBy, adminID, ip string) error {
+	cancelled, err := s.repo.CancelDeletion(userID, domainuser.SecurityEvent{
+		UserID:  userID,
+		Type:    domainuser.SecurityEventDeletionCancelled,
+		IP:      ip,
+		Details: requestDetails(requestedBy, adminID),
+	}, auditEntry(requestedBy, adminID, ip, domainuser.AuditDeletionCancelled, domainuser.AuditTargetDeletion, "", nil))
+	if err != nil {
+		return err
+	}
+	if !cancelled {
+		return ErrNoDeletionScheduled
+	}
+	return nil
+}
+
+// DeleteNow erases an account without a grace period. It is meant for requests
+// whose identity check and waiting period were handled offline.
+func (s *Service) DeleteNow(userID, requestedBy, adminID, ip string) error {
+	pending, err := s.repo.GetActiveDeletion(userID)
+	if err != nil {
+		return err
+	}
+
+	if pending == nil {
+		account, err := s.repo.GetAccount(userID)
+		if err != nil {
+			return err
+		}
+		if account == nil {
+			return ErrUserNotFound
+		}
+
+		now := time.Now()
+		pending = &Deletion{
+			ID:           uuid.New().String(),
+			UserID:       userID,
+			RequestedBy:  requestedBy,
+			RequestedAt:  now,
+			ScheduledFor: now,
+		}
+		if adminID != "" {
+			pending.AdminID = &adminID
+		}
+
+		details := requestDetails(requestedBy, adminID)
+		details["scheduled_for"] = now.UTC()
+		if err := s.repo.CreateDeletion(pending, domainuser.SecurityEvent{
+			UserID:  userID,
+			Type:    domainuser.SecurityEventDeletionScheduled,
+			IP:      ip,
+			Details: details,
+		}, auditEntry(requestedBy, adminID, ip, domainuser.AuditDeletionScheduled, domainuser.AuditTargetDeletion, pending.ID,
+			domainuser.AuditState{"scheduled_for": now.UTC()},
+		)); err != nil {
+			return err
+		}
+	}
+
+	return s.deleteAccount(pending, domainuser.Actor{ID: adminID, IP: ip})
+}
+
+// PurgeDue deletes every account whose grace period has ended and returns how many were deleted.
+// A failing account is logged and skipped, so it is retried on the next run.
+func (s *Service) PurgeDue() (int, error) {
+	deletions, err := s.repo.ListDueDeletions(time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for i := range deletions {
+		if err := s.deleteAccount(&deletions[i], domainuser.Actor{}); err != nil {
+			s.logger.Error("failed to delete account",
+				zap.String("deletion_id", deletions[i].ID),
+				zap.Error(err),
+			)
+			continue
+		}
+		deleted++
+	}
+
+	return deleted, nil
+}
+
+// RunPurger calls PurgeDue every interval until the context is cancelled.
+func (s *Service) RunPurger(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if deleted, err := s.PurgeDue(); err != nil {
+			s.logger.Error("failed to purge deleted accounts", zap.Error(err))
+		} else if deleted > 0 {
+			s.logger.Info("deleted accounts after grace period", zap.Int("count", deleted))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// deleteAccount erases the account with its documents and sends a final email to its address.
+// The actor is empty for deletions whose grace period ended.
+func (s *Service) deleteAccount(deletion *Deletion, actor domainuser.Actor) error {
+	account, err := s.repo.GetAccount(deletion.UserID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.documents.DeleteAll(deletion.UserID); err != nil {
+		return err
+	}
+
+	details := requestDetails(deletion.RequestedBy, "")
+	details["deletion_id"] = deletion.ID
+
+	// The log entries must not point to the erased person, so they carry no user ID.
+	if err := s.repo.DeleteAccount(deletion, domainuser.SecurityEvent{
+		Type:    domainuser.SecurityEventAccountDeleted,
+		Details: details,
+	}, domainuser.NewAuditEntry(actor, domainuser.AuditDeletionCompleted, domainuser.AuditTargetDeletion, deletion.ID, nil,
+		domainuser.AuditState{"requested_by": deletion.RequestedBy},
+	)); err != nil {
+		return err
+	}
+
+	if account != nil {
+		if err := s.sender.SendAccountDeleted(account.Email); err != nil {
+			s.logger.Warn("failed to send account deleted email",
+				zap.String("deletion_id", deletion.ID),
+				zap.Error(err),
+			)
+		}
+	}
+
+	return nil
+}
+
+// auditEntry builds the audit log entry of a request made by an admin or on the CLI.
+// Users acting on their own account are not audited and get nil.
+func auditEntry(requestedBy, adminID, ip, action, targetType, targetID string, after domainuser.AuditState) *domainuser.AuditEntry {
+	if requestedBy == RequestedByUser {
+		return nil
+	}
+	if after == nil {
+		after = domainuser.AuditState{}
+	}
+	after["requested_by"] = requestedBy
+	entry := domainuser.NewAuditEntry(domainuser.Actor{ID: adminID, IP: ip}, action, targetType, targetID, nil, after)
+	return &entry
+}
+
+// requestDetails describes who made a request for the security log.
+func requestDetails(requestedBy, adminID string) map[string]any {
+	details := map[string]any{"requested_by": requestedBy}
+	if adminID != "" {
+		details["admin_id"] = adminID
+	}
+	return details
+}
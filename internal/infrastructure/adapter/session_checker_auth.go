@@ -0,0 +1,26 @@
+package adapter
+
+import (
+	"carowebapp/core/internal/features/auth"
+
+	"context"
+)
+
+// AuthSessionChecker adapts auth.Repository to the middleware.SessionChecker interface.
+type AuthSessionChecker struct {
+	Repo auth.Repository
+}
+
+// IsSessionActive reports whether the session exists and has not been revoked.
+func (c *AuthSessionChecker) IsSessionActive(_ context.Context, sessionID string) (bool, error) {
+	return c.Repo.IsSessionActive(sessionID)
+}
+
+// IsAccountBlocked reports whether a suspension or ban of the user is in force.
+func (c *AuthSessionChecker) IsAccountBlocked(_ context.Context, userID string) (bool, error) {
+	block, err := c.Repo.GetActiveBlock(userID)
+	if err != nil {
+		return false, err
+	}
+	return block != nil, nil
+}
@@ -0,0 +1,24 @@
+// Package auditlog writes admin actions to the append-only admin_audit_log table.
+package auditlog
+
+import (
+	domainuser "carowebapp/core/internal/domain/user"
+
+	"github.com/google/uuid"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Record inserts the entry. Pass the transaction of the change it describes, so the action
+// and its audit entry are stored together or not at all.
+func Record(exec sqlx.Execer, entry domainuser.AuditEntry) error {
+	if entry.ID == "" {
+		entry.ID = uuid.New().String()
+	}
+
+	_, err := exec.Exec(`
+		INSERT INTO admin_audit_log (id, actor_id, action, target_type, target_id, before_state, after_state, ip, trace_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`, entry.ID, entry.ActorID, entry.Action, entry.TargetType, entry.TargetID, entry.Before, entry.After, entry.IP, entry.TraceID, entry.CreatedAt)
+	return err
+}
@@ -0,0 +1,7 @@
+-- Migration: Remove refresh token rotation columns
+DROP INDEX IF EXISTS idx_refresh_tokens_family_id;
+
+ALTER TABLE refresh_tokens
+DROP COLUMN revoked_at,
+DROP COLUMN used_at,
+DROP COLUMN family_id;
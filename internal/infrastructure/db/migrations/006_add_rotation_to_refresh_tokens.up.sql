@@ -0,0 +1,12 @@
+-- Migration: Track refresh token families for rotation and reuse detection
+ALTER TABLE refresh_tokens
+    ADD COLUMN family_id UUID,
+    ADD COLUMN used_at TIMESTAMP,
+    ADD COLUMN revoked_at TIMESTAMP;
+
+UPDATE refresh_tokens SET family_id = id WHERE family_id IS NULL;
+
+ALTER TABLE refresh_tokens
+    ALTER COLUMN family_id SET NOT NULL;
+
+CREATE INDEX idx_refresh_tokens_family_id ON refresh_tokens (family_id);
@@ -0,0 +1,32 @@
+-- Migration: Turn refresh token families into device sessions
+CREATE TABLE user_sessions (
+                               id UUID PRIMARY KEY,
+                               user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                               device VARCHAR(100) NOT NULL DEFAULT '',
+                               user_agent TEXT NOT NULL DEFAULT '',
+                               ip VARCHAR(45) NOT NULL DEFAULT '',
+                               created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                               last_used_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                               revoked_at TIMESTAMP
+);
+
+CREATE INDEX idx_user_sessions_user_id ON user_sessions (user_id);
+
+INSERT INTO user_sessions (id, user_id, created_at, last_used_at, revoked_at)
+SELECT family_id,
+       user_id,
+       MIN(created_at),
+       MAX(COALESCE(used_at, created_at)),
+       CASE WHEN BOOL_AND(revoked_at IS NOT NULL) THEN MAX(revoked_at) END
+FROM refresh_tokens
+GROUP BY family_id, user_id;
+
+ALTER TABLE refresh_tokens RENAME COLUMN family_id TO session_id;
+
+ALTER INDEX idx_refresh_tokens_family_id RENAME TO idx_refresh_tokens_session_id;
+
+ALTER TABLE refresh_tokens
+    ADD CONSTRAINT fk_refresh_tokens_session
+        FOREIGN KEY (session_id)
+            REFERENCES user_sessions(id)
+            ON DELETE CASCADE;
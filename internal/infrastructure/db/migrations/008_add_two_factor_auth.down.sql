@@ -0,0 +1,12 @@
+-- Migration: Remove TOTP two-factor authentication
+DROP TABLE IF EXISTS mfa_challenges;
+
+DROP TABLE IF EXISTS user_recovery_codes;
+
+ALTER TABLE user_sessions
+DROP COLUMN mfa;
+
+ALTER TABLE users
+DROP COLUMN totp_last_used_step,
+DROP COLUMN totp_enabled,
+DROP COLUMN totp_secret;
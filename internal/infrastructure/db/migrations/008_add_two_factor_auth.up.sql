@@ -0,0 +1,27 @@
+-- Migration: Add TOTP two-factor authentication
+ALTER TABLE users
+    ADD COLUMN totp_secret VARCHAR(64),
+    ADD COLUMN totp_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN totp_last_used_step BIGINT;
+
+ALTER TABLE user_sessions
+    ADD COLUMN mfa BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE user_recovery_codes (
+                                     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                                     user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                     code_hash VARCHAR(64) NOT NULL,
+                                     used_at TIMESTAMP,
+                                     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                     UNIQUE (user_id, code_hash)
+);
+
+CREATE TABLE mfa_challenges (
+                                id UUID PRIMARY KEY,
+                                user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                token TEXT NOT NULL UNIQUE,
+                                attempts INT NOT NULL DEFAULT 0,
+                                expires_at TIMESTAMP NOT NULL,
+                                used_at TIMESTAMP,
+                                created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
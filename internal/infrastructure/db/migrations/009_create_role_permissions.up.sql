@@ -0,0 +1,15 @@
+-- Migration: Map roles to named permissions
+CREATE TABLE role_permissions (
+                                  role VARCHAR(20) NOT NULL,
+                                  permission VARCHAR(64) NOT NULL,
+                                  PRIMARY KEY (role, permission)
+);
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('ROLE_ADMIN', 'users.moderate'),
+    ('ROLE_ADMIN', 'users.read'),
+    ('ROLE_ADMIN', 'tickets.assign'),
+    ('ROLE_ADMIN', 'tickets.read_all'),
+    ('ROLE_ADMIN', 'roles.manage'),
+    ('ROLE_MANAGER', 'tickets.assign'),
+    ('ROLE_MANAGER', 'tickets.read_all');
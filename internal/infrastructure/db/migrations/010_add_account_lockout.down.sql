@@ -0,0 +1,9 @@
+-- Migration: Remove per-account login lockout and security log
+DROP TABLE IF EXISTS security_events;
+
+DROP INDEX IF EXISTS idx_users_unlock_token_hash;
+
+ALTER TABLE users
+DROP COLUMN unlock_token_hash,
+DROP COLUMN locked_until,
+DROP COLUMN failed_login_attempts;
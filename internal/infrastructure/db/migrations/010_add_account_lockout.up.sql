@@ -0,0 +1,18 @@
+-- Migration: Per-account login lockout and security log
+ALTER TABLE users
+    ADD COLUMN failed_login_attempts INT NOT NULL DEFAULT 0,
+    ADD COLUMN locked_until TIMESTAMP,
+    ADD COLUMN unlock_token_hash VARCHAR(64);
+
+CREATE INDEX idx_users_unlock_token_hash ON users(unlock_token_hash);
+
+CREATE TABLE security_events (
+                                 id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                                 user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+                                 event VARCHAR(50) NOT NULL,
+                                 ip VARCHAR(45),
+                                 details JSONB,
+                                 created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_security_events_user_id ON security_events(user_id, created_at);
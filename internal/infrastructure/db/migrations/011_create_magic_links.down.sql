@@ -0,0 +1,2 @@
+-- Migration: Drop magic-link login tokens
+DROP TABLE IF EXISTS magic_links;
@@ -0,0 +1,12 @@
+-- Migration: Create one-time magic-link login tokens
+CREATE TABLE magic_links (
+                             id UUID PRIMARY KEY,
+                             user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                             token_hash VARCHAR(64) NOT NULL UNIQUE,
+                             nonce_hash VARCHAR(64) NOT NULL,
+                             expires_at TIMESTAMP NOT NULL,
+                             used_at TIMESTAMP,
+                             created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_magic_links_user_id ON magic_links(user_id);
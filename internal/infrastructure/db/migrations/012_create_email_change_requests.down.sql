@@ -0,0 +1,2 @@
+-- Migration: Drop email address changes
+DROP TABLE IF EXISTS email_change_requests;
@@ -0,0 +1,16 @@
+-- Migration: Create pending and confirmed email address changes
+CREATE TABLE email_change_requests (
+                                       id UUID PRIMARY KEY,
+                                       user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                       old_email VARCHAR(255) NOT NULL,
+                                       new_email VARCHAR(255) NOT NULL,
+                                       token_hash VARCHAR(64) NOT NULL UNIQUE,
+                                       expires_at TIMESTAMP NOT NULL,
+                                       confirmed_at TIMESTAMP,
+                                       undo_token_hash VARCHAR(64) UNIQUE,
+                                       undo_expires_at TIMESTAMP,
+                                       reverted_at TIMESTAMP,
+                                       created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_email_change_requests_user_id ON email_change_requests(user_id);
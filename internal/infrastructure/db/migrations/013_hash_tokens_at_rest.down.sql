@@ -0,0 +1,16 @@
+-- Migration: Store one-time tokens in plaintext again
+-- Digests cannot be turned back into tokens, so all outstanding tokens are dropped.
+DELETE FROM mfa_challenges;
+ALTER TABLE mfa_challenges RENAME COLUMN token_hash TO token;
+
+DELETE FROM user_password_reset_tokens;
+ALTER TABLE user_password_reset_tokens RENAME COLUMN token_hash TO token;
+
+UPDATE user_sessions SET revoked_at = NOW() WHERE revoked_at IS NULL;
+DELETE FROM refresh_tokens;
+ALTER TABLE refresh_tokens RENAME COLUMN token_hash TO token;
+
+DROP INDEX IF EXISTS idx_users_email_confirmation_token_hash;
+UPDATE users SET email_confirmation_token_hash = NULL;
+ALTER TABLE users DROP COLUMN email_confirmation_expires_at;
+ALTER TABLE users RENAME COLUMN email_confirmation_token_hash TO email_confirmation_token;
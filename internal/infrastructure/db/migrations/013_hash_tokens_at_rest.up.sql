@@ -0,0 +1,22 @@
+-- Migration: Store one-time tokens as SHA-256 digests
+-- Existing plaintext tokens cannot be turned into usable digests without keeping
+-- the plaintext valid, so they are invalidated instead.
+
+-- Email confirmation: unconfirmed users can request a new link.
+ALTER TABLE users RENAME COLUMN email_confirmation_token TO email_confirmation_token_hash;
+ALTER TABLE users ADD COLUMN email_confirmation_expires_at TIMESTAMP;
+UPDATE users SET email_confirmation_token_hash = NULL WHERE email_confirmation_token_hash IS NOT NULL;
+CREATE INDEX idx_users_email_confirmation_token_hash ON users(email_confirmation_token_hash);
+
+-- Refresh tokens: end all sessions, users log in again.
+UPDATE user_sessions SET revoked_at = NOW() WHERE revoked_at IS NULL;
+DELETE FROM refresh_tokens;
+ALTER TABLE refresh_tokens RENAME COLUMN token TO token_hash;
+
+-- Password reset links: users request a new link.
+DELETE FROM user_password_reset_tokens;
+ALTER TABLE user_password_reset_tokens RENAME COLUMN token TO token_hash;
+
+-- Login challenges live for minutes only.
+DELETE FROM mfa_challenges;
+ALTER TABLE mfa_challenges RENAME COLUMN token TO token_hash;
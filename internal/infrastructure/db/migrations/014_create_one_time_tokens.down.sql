@@ -0,0 +1,47 @@
+-- Migration: Split one-time tokens back into their own tables
+ALTER TABLE users ADD COLUMN email_confirmation_token_hash VARCHAR(64);
+ALTER TABLE users ADD COLUMN email_confirmation_expires_at TIMESTAMP;
+CREATE INDEX idx_users_email_confirmation_token_hash ON users(email_confirmation_token_hash);
+
+UPDATE users u
+SET email_confirmation_token_hash = t.token_hash,
+    email_confirmation_expires_at = t.expires_at
+FROM one_time_tokens t
+WHERE t.user_id = u.id
+  AND t.purpose = 'email_confirmation'
+  AND t.used_at IS NULL
+  AND t.revoked_at IS NULL;
+
+CREATE TABLE user_password_reset_tokens (
+                                            id UUID PRIMARY KEY,
+                                            user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                            token_hash TEXT NOT NULL,
+                                            created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+                                            used_at TIMESTAMP WITH TIME ZONE,
+                                            expires_at TIMESTAMP WITH TIME ZONE,
+                                            UNIQUE(token_hash)
+);
+
+INSERT INTO user_password_reset_tokens (id, user_id, token_hash, created_at, used_at, expires_at)
+SELECT id, user_id, token_hash, created_at, COALESCE(used_at, revoked_at), expires_at
+FROM one_time_tokens
+WHERE purpose = 'password_reset';
+
+CREATE TABLE magic_links (
+                             id UUID PRIMARY KEY,
+                             user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                             token_hash VARCHAR(64) NOT NULL UNIQUE,
+                             nonce_hash VARCHAR(64) NOT NULL,
+                             expires_at TIMESTAMP NOT NULL,
+                             used_at TIMESTAMP,
+                             created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_magic_links_user_id ON magic_links(user_id);
+
+INSERT INTO magic_links (id, user_id, token_hash, nonce_hash, expires_at, used_at, created_at)
+SELECT id, user_id, token_hash, binding_hash, expires_at, COALESCE(used_at, revoked_at), created_at
+FROM one_time_tokens
+WHERE purpose = 'login_link' AND binding_hash IS NOT NULL;
+
+DROP TABLE IF EXISTS one_time_tokens;
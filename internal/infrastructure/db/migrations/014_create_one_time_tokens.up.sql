@@ -0,0 +1,41 @@
+-- Migration: Move confirmation, reset and login-link tokens into one table
+CREATE TABLE one_time_tokens (
+                                 id UUID PRIMARY KEY,
+                                 purpose VARCHAR(32) NOT NULL,
+                                 user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                 token_hash VARCHAR(64) NOT NULL,
+                                 binding_hash VARCHAR(64),
+                                 attempts INTEGER NOT NULL DEFAULT 0,
+                                 max_attempts INTEGER NOT NULL,
+                                 expires_at TIMESTAMP NOT NULL,
+                                 created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                 last_attempt_at TIMESTAMP,
+                                 used_at TIMESTAMP,
+                                 revoked_at TIMESTAMP,
+                                 UNIQUE (purpose, token_hash)
+);
+
+CREATE INDEX idx_one_time_tokens_user_purpose ON one_time_tokens(user_id, purpose);
+
+-- Outstanding links keep working after the move.
+INSERT INTO one_time_tokens (id, purpose, user_id, token_hash, max_attempts, expires_at, created_at)
+SELECT gen_random_uuid(), 'email_confirmation', id, email_confirmation_token_hash, 10,
+       COALESCE(email_confirmation_expires_at, NOW()), COALESCE(last_confirmation_sent_at, NOW())
+FROM users
+WHERE email_confirmation_token_hash IS NOT NULL AND email_confirmed = FALSE;
+
+INSERT INTO one_time_tokens (id, purpose, user_id, token_hash, max_attempts, expires_at, created_at, used_at)
+SELECT id, 'password_reset', user_id, token_hash, 10,
+       COALESCE(expires_at, NOW()), COALESCE(created_at, NOW()), used_at
+FROM user_password_reset_tokens;
+
+INSERT INTO one_time_tokens (id, purpose, user_id, token_hash, binding_hash, max_attempts, expires_at, created_at, used_at)
+SELECT id, 'login_link', user_id, token_hash, nonce_hash, 3, expires_at, created_at, used_at
+FROM magic_links;
+
+DROP TABLE user_password_reset_tokens;
+DROP TABLE magic_links;
+
+DROP INDEX IF EXISTS idx_users_email_confirmation_token_hash;
+ALTER TABLE users DROP COLUMN email_confirmation_token_hash;
+ALTER TABLE users DROP COLUMN email_confirmation_expires_at;
@@ -0,0 +1,3 @@
+-- Migration: Drop scheduled account deletions
+DELETE FROM role_permissions WHERE permission = 'users.privacy';
+DROP TABLE IF EXISTS account_deletions;
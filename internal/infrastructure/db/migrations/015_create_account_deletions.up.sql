@@ -0,0 +1,23 @@
+-- Migration: Scheduled account deletions (DSGVO Art. 17)
+-- Rows outlive the deleted account as proof of the erasure, so user_id has no foreign key.
+CREATE TABLE account_deletions (
+                                   id UUID PRIMARY KEY,
+                                   user_id UUID NOT NULL,
+                                   requested_by VARCHAR(20) NOT NULL,
+                                   admin_id UUID,
+                                   requested_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                   scheduled_for TIMESTAMP NOT NULL,
+                                   cancelled_at TIMESTAMP,
+                                   completed_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_account_deletions_active_user
+    ON account_deletions(user_id)
+    WHERE cancelled_at IS NULL AND completed_at IS NULL;
+
+CREATE INDEX idx_account_deletions_due
+    ON account_deletions(scheduled_for)
+    WHERE cancelled_at IS NULL AND completed_at IS NULL;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('ROLE_ADMIN', 'users.privacy');
@@ -0,0 +1,2 @@
+-- Migration: Drop profile change history
+DROP TABLE IF EXISTS profile_changes;
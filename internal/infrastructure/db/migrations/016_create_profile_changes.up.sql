@@ -0,0 +1,11 @@
+-- Migration: Keep a diff of every profile edit for moderators
+CREATE TABLE profile_changes (
+                                 id UUID PRIMARY KEY,
+                                 user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                 changes JSONB NOT NULL,
+                                 previous_status VARCHAR(20) NOT NULL,
+                                 new_status VARCHAR(20) NOT NULL,
+                                 created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_profile_changes_user_id ON profile_changes(user_id, created_at);
@@ -0,0 +1,4 @@
+-- Migration: Remove the rejection link from profile changes
+DROP INDEX IF EXISTS idx_user_rejections_user_id;
+
+ALTER TABLE profile_changes DROP COLUMN IF EXISTS rejection_id;
@@ -0,0 +1,5 @@
+-- Migration: Link resubmitted profile changes to the rejection they answer
+ALTER TABLE profile_changes
+    ADD COLUMN rejection_id UUID REFERENCES user_rejections(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_user_rejections_user_id ON user_rejections(user_id, rejected_at);
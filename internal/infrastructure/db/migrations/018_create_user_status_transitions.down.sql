@@ -0,0 +1,6 @@
+-- Migration: Drop the status transition history and the status constraint
+DROP TABLE IF EXISTS user_status_transitions;
+
+ALTER TABLE users
+    DROP CONSTRAINT IF EXISTS users_status_check,
+    ALTER COLUMN status SET DEFAULT 'ACTIVE';
@@ -0,0 +1,23 @@
+-- Migration: Clean up legacy user statuses and record every status transition
+UPDATE users u
+SET status = CASE
+                 WHEN EXISTS (SELECT 1 FROM user_profiles p WHERE p.user_id = u.id) THEN 'pending'
+                 ELSE 'created'
+             END
+WHERE status NOT IN ('created', 'pending', 'approved', 'rejected');
+
+ALTER TABLE users
+    ALTER COLUMN status SET DEFAULT 'created',
+    ADD CONSTRAINT users_status_check CHECK (status IN ('created', 'pending', 'approved', 'rejected'));
+
+CREATE TABLE user_status_transitions (
+                                         id UUID PRIMARY KEY,
+                                         user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                         from_status VARCHAR(20) NOT NULL,
+                                         to_status VARCHAR(20) NOT NULL,
+                                         transition VARCHAR(30) NOT NULL,
+                                         actor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+                                         created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_user_status_transitions_user_id ON user_status_transitions(user_id, created_at);
@@ -0,0 +1,6 @@
+-- Migration: Remove admin user management support
+DELETE FROM role_permissions WHERE permission = 'users.manage';
+
+DROP INDEX IF EXISTS idx_users_created_at;
+
+ALTER TABLE user_status_transitions DROP COLUMN IF EXISTS reason;
@@ -0,0 +1,8 @@
+-- Migration: Support admin user management with status override reasons
+ALTER TABLE user_status_transitions
+    ADD COLUMN reason TEXT;
+
+CREATE INDEX idx_users_created_at ON users(created_at);
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('ROLE_ADMIN', 'users.manage');
@@ -0,0 +1,12 @@
+-- Migration: Drop account blocks and restore the status blocked users had before
+UPDATE users u
+SET status = b.previous_status
+FROM account_blocks b
+WHERE b.user_id = u.id AND b.lifted_at IS NULL;
+
+DROP TABLE IF EXISTS account_blocks;
+
+ALTER TABLE users DROP CONSTRAINT users_status_check;
+ALTER TABLE users
+    ADD CONSTRAINT users_status_check
+        CHECK (status IN ('created', 'pending', 'approved', 'rejected'));
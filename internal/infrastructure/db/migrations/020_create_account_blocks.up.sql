@@ -0,0 +1,24 @@
+-- Migration: Suspend and ban accounts
+ALTER TABLE users DROP CONSTRAINT users_status_check;
+ALTER TABLE users
+    ADD CONSTRAINT users_status_check
+        CHECK (status IN ('created', 'pending', 'approved', 'rejected', 'suspended', 'banned'));
+
+CREATE TABLE account_blocks (
+                                id UUID PRIMARY KEY,
+                                user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                kind VARCHAR(20) NOT NULL CHECK (kind IN ('suspension', 'ban')),
+                                reason TEXT NOT NULL,
+                                previous_status VARCHAR(20) NOT NULL,
+                                ends_at TIMESTAMP,
+                                created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+                                created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                lifted_at TIMESTAMP,
+                                lifted_by UUID REFERENCES users(id) ON DELETE SET NULL,
+                                CHECK ((kind = 'suspension') = (ends_at IS NOT NULL))
+);
+
+-- At most one block is in force per user.
+CREATE UNIQUE INDEX idx_account_blocks_open ON account_blocks(user_id) WHERE lifted_at IS NULL;
+
+CREATE INDEX idx_account_blocks_ends_at ON account_blocks(ends_at) WHERE lifted_at IS NULL;
@@ -0,0 +1,7 @@
+-- Migration: Remove the admin audit log
+DELETE FROM role_permissions WHERE permission = 'audit.read';
+
+ALTER TABLE user_rejections DROP COLUMN IF EXISTS rejected_by;
+
+DROP TABLE IF EXISTS admin_audit_log;
+DROP FUNCTION IF EXISTS admin_audit_log_append_only();
@@ -0,0 +1,38 @@
+-- Migration: Append-only audit log of admin actions
+CREATE TABLE admin_audit_log (
+                                 id UUID PRIMARY KEY,
+                                 actor_id UUID,
+                                 action VARCHAR(50) NOT NULL,
+                                 target_type VARCHAR(20) NOT NULL,
+                                 target_id TEXT NOT NULL,
+                                 before_state JSONB,
+                                 after_state JSONB,
+                                 ip TEXT,
+                                 trace_id TEXT,
+                                 created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- No foreign keys: entries must outlive the accounts they mention.
+CREATE INDEX idx_admin_audit_log_created_at ON admin_audit_log(created_at);
+CREATE INDEX idx_admin_audit_log_actor ON admin_audit_log(actor_id, created_at);
+CREATE INDEX idx_admin_audit_log_target ON admin_audit_log(target_type, target_id, created_at);
+
+CREATE FUNCTION admin_audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'admin_audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER admin_audit_log_append_only
+    BEFORE UPDATE OR DELETE ON admin_audit_log
+    FOR EACH ROW EXECUTE FUNCTION admin_audit_log_append_only();
+
+CREATE TRIGGER admin_audit_log_no_truncate
+    BEFORE TRUNCATE ON admin_audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION admin_audit_log_append_only();
+
+ALTER TABLE user_rejections
+    ADD COLUMN rejected_by UUID REFERENCES users(id) ON DELETE SET NULL;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('ROLE_ADMIN', 'audit.read');
@@ -0,0 +1,4 @@
+-- Migration: Remove moderation queue claims
+DROP INDEX IF EXISTS idx_users_pending_created_at;
+
+DROP TABLE IF EXISTS moderation_claims;
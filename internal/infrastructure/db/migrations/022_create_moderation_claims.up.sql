@@ -0,0 +1,11 @@
+-- Migration: Moderation queue claims with expiring leases
+CREATE TABLE moderation_claims (
+                                   user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+                                   admin_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                   claimed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                   expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_moderation_claims_admin ON moderation_claims(admin_id);
+
+CREATE INDEX idx_users_pending_created_at ON users(created_at) WHERE status = 'pending';
@@ -0,0 +1,8 @@
+-- Migration: Remove rejection reason catalog and user language
+DELETE FROM role_permissions WHERE permission = 'rejection_reasons.manage';
+
+ALTER TABLE user_rejections DROP COLUMN IF EXISTS reason_codes;
+
+DROP TABLE IF EXISTS rejection_reasons;
+
+ALTER TABLE users DROP COLUMN IF EXISTS language;
@@ -0,0 +1,28 @@
+-- Migration: Rejection reason catalog and user language
+ALTER TABLE users
+    ADD COLUMN language VARCHAR(2) NOT NULL DEFAULT 'en' CHECK (language IN ('de', 'en'));
+
+CREATE TABLE rejection_reasons (
+                                   code VARCHAR(64) PRIMARY KEY,
+                                   field VARCHAR(32) NOT NULL,
+                                   text_de TEXT NOT NULL,
+                                   text_en TEXT NOT NULL,
+                                   active BOOLEAN NOT NULL DEFAULT TRUE,
+                                   created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                   updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE user_rejections
+    ADD COLUMN reason_codes JSONB;
+
+INSERT INTO rejection_reasons (code, field, text_de, text_en) VALUES
+    ('salutation_invalid', 'salutation', 'Die Anrede ist ungültig.', 'The salutation is not valid.'),
+    ('first_name_illegible', 'first_name', 'Der Vorname ist nicht lesbar.', 'The first name is not legible.'),
+    ('last_name_illegible', 'last_name', 'Der Nachname ist nicht lesbar.', 'The last name is not legible.'),
+    ('street_unknown', 'street', 'Die Straße konnte nicht gefunden werden.', 'The street could not be found.'),
+    ('house_number_missing', 'house_number', 'Die Hausnummer fehlt oder ist unvollständig.', 'The house number is missing or incomplete.'),
+    ('postal_code_invalid', 'postal_code', 'Die Postleitzahl ist ungültig.', 'The postal code is not valid.'),
+    ('city_mismatch', 'city', 'Der Ort passt nicht zur Postleitzahl.', 'The city does not match the postal code.');
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('ROLE_ADMIN', 'rejection_reasons.manage');
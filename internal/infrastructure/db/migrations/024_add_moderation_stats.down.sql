@@ -0,0 +1,4 @@
+-- Migration: Remove the moderation dashboard statistics index and permission
+DELETE FROM role_permissions WHERE permission = 'stats.read';
+
+DROP INDEX IF EXISTS idx_user_status_transitions_decisions;
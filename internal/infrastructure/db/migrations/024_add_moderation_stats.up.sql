@@ -0,0 +1,6 @@
+-- Migration: Index and permission for the moderation dashboard statistics
+CREATE INDEX idx_user_status_transitions_decisions ON user_status_transitions(created_at)
+    WHERE transition IN ('approve', 'reject');
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('ROLE_ADMIN', 'stats.read');
@@ -0,0 +1,5 @@
+-- Migration: Remove admin notes and tags
+DELETE FROM role_permissions WHERE permission = 'users.notes';
+
+DROP TABLE IF EXISTS user_tags;
+DROP TABLE IF EXISTS user_notes;
@@ -0,0 +1,23 @@
+-- Migration: Internal admin notes and tags on user accounts
+CREATE TABLE user_notes (
+                            id UUID PRIMARY KEY,
+                            user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                            author_id UUID REFERENCES users(id) ON DELETE SET NULL,
+                            body TEXT NOT NULL,
+                            created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_user_notes_user_id ON user_notes(user_id, created_at);
+
+CREATE TABLE user_tags (
+                           user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                           tag VARCHAR(50) NOT NULL,
+                           created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+                           created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                           PRIMARY KEY (user_id, tag)
+);
+
+CREATE INDEX idx_user_tags_tag ON user_tags(tag);
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('ROLE_ADMIN', 'users.notes');
@@ -0,0 +1,2 @@
+-- Migration: Drop verification documents. The stored files are not removed.
+DROP TABLE IF EXISTS user_documents;
@@ -0,0 +1,19 @@
+-- Migration: Verification documents uploaded during onboarding
+CREATE TABLE user_documents (
+                                id UUID PRIMARY KEY,
+                                user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                type VARCHAR(30) NOT NULL,
+                                file_name VARCHAR(255) NOT NULL,
+                                content_type VARCHAR(100) NOT NULL,
+                                size_bytes BIGINT NOT NULL,
+                                storage_key VARCHAR(255) NOT NULL UNIQUE,
+                                status VARCHAR(20) NOT NULL DEFAULT 'pending',
+                                review_note TEXT,
+                                reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+                                reviewed_at TIMESTAMP,
+                                created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                CONSTRAINT user_documents_type_check CHECK (type IN ('proof_of_ownership', 'identity', 'trade_register_extract')),
+                                CONSTRAINT user_documents_status_check CHECK (status IN ('pending', 'accepted', 'rejected'))
+);
+
+CREATE INDEX idx_user_documents_user_id ON user_documents(user_id, created_at);
@@ -0,0 +1,14 @@
+-- Migration: Move account unlock tokens back into users
+ALTER TABLE users ADD COLUMN unlock_token_hash VARCHAR(64);
+CREATE INDEX idx_users_unlock_token_hash ON users(unlock_token_hash);
+
+UPDATE users u
+SET unlock_token_hash = t.token_hash
+FROM one_time_tokens t
+WHERE t.user_id = u.id
+  AND t.purpose = 'account_unlock'
+  AND t.used_at IS NULL
+  AND t.revoked_at IS NULL
+  AND t.expires_at > NOW();
+
+DELETE FROM one_time_tokens WHERE purpose = 'account_unlock';
@@ -0,0 +1,8 @@
+-- Migration: Move account unlock tokens into one_time_tokens, so they expire
+INSERT INTO one_time_tokens (id, purpose, user_id, token_hash, max_attempts, expires_at, created_at)
+SELECT gen_random_uuid(), 'account_unlock', id, unlock_token_hash, 10, NOW() + INTERVAL '24 hours', NOW()
+FROM users
+WHERE unlock_token_hash IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_users_unlock_token_hash;
+ALTER TABLE users DROP COLUMN unlock_token_hash;
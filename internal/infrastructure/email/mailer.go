@@ -1,13 +1,23 @@
 package email
 
 import (
+	"bytes"
+
 	"fmt"
 
 	"go.uber.org/zap"
 
+	"mime"
+
+	"mime/quotedprintable"
+
 	"net/smtp"
 
 	"os"
+
+	"sort"
+
+	"time"
 )
 
 // Sender defines methods for sending various types of notification emails.
@@ -16,7 +26,17 @@ type Sender interface {
 	SendConfirmation(to, token string) error
 	SendResetPasswordLink(to, token string) error
 	SendApprovalNotification(email string) error
-	SendRejectionNotification(email string, errors map[string]string) error
+	SendRejectionNotification(email, language string, errors map[string]string) error
+	SendAccountLocked(to, token string, until time.Time) error
+	SendMagicLink(to, token string) error
+	SendEmailChangeConfirmation(to, token string) error
+	SendEmailChangedAlert(to, newEmail, undoToken string) error
+	SendAccountDeletionConfirmation(to, token string) error
+	SendAccountDeletionScheduled(to string, scheduledFor time.Time) error
+	SendAccountDeleted(to string) error
+	SendAccountSuspended(to, reason string, until time.Time) error
+	SendAccountBanned(to, reason string) error
+	SendAccountReactivated(to string) error
 }
 
 // Mailer implements the Sender interface using SMTP.
@@ -43,11 +63,14 @@ func NewMailer(logger *zap.Logger) *Mailer {
 	}
 }
 
-// SendMail sends a raw email using SMTP with the given recipient, subject, and body.
+// SendMail sends a plain text email using SMTP with the given recipient, subject, and body.
 func (m *Mailer) SendMail(to, subject, body string) error {
 	addr := fmt.Sprintf("%s:%s", m.host, m.port)
 
-	msg := []byte(fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s", m.from, to, subject, body))
+	msg, err := buildMessage(m.from, to, subject, body)
+	if err != nil {
+		return err
+	}
 
 	var auth smtp.Auth
 	if m.user != "" && m.password != "" {
@@ -61,7 +84,7 @@ func (m *Mailer) SendMail(to, subject, body string) error {
 		zap.String("from", m.from),
 	)
 
-	err := smtp.SendMail(addr, auth, m.from, []string{to}, msg)
+	err = smtp.SendMail(addr, auth, m.from, []string{to}, msg)
 	if err != nil {
 		m.logger.Error("Failed to send email",
 			zap.String("to", to),
@@ -77,6 +100,27 @@ func (m *Mailer) SendMail(to, subject, body string) error {
 	return nil
 }
 
+// buildMessage formats a UTF-8 plain text message. The subject is Q-encoded and the body
+// quoted-printable, so umlauts in German emails survive any mail client.
+func buildMessage(from, to, subject, body string) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", to)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	w := quotedprintable.NewWriter(&buf)
+	if _, err := w.Write([]byte(body)); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
 // SendConfirmation sends a confirmation email with a tokenized confirmation link.
 func (m *Mailer) SendConfirmation(to, token string) error {
 	subject := "Email Confirmation"
@@ -85,7 +129,6 @@ func (m *Mailer) SendConfirmation(to, token string) error {
 
 	m.logger.Info("Preparing confirmation email",
 		zap.String("to", to),
-		zap.String("confirmation_link", link),
 	)
 
 	return m.SendMail(to, subject, body)
@@ -99,7 +142,6 @@ func (m *Mailer) SendResetPasswordLink(to, token string) error {
 
 	m.logger.Info("Preparing reset password email",
 		zap.String("to", to),
-		zap.String("reset_link", link),
 	)
 
 	return m.SendMail(to, subject, body)
@@ -117,20 +159,204 @@ func (m *Mailer) SendApprovalNotification(email string) error {
 	return m.SendMail(email, subject, body)
 }
 
+// rejectionFieldLabels names the profile fields in the rejection email, per language.
+var rejectionFieldLabels = map[string]map[string]string{
+	"de": {
+		"salutation":   "Anrede",
+		"title":        "Titel",
+		"first_name":   "Vorname",
+		"last_name":    "Nachname",
+		"street":       "Straße",
+		"house_number": "Hausnummer",
+		"postal_code":  "Postleitzahl",
+		"city":         "Ort",
+	},
+	"en": {
+		"salutation":   "Salutation",
+		"title":        "Title",
+		"first_name":   "First name",
+		"last_name":    "Last name",
+		"street":       "Street",
+		"house_number": "House number",
+		"postal_code":  "Postal code",
+		"city":         "City",
+	},
+}
+
 // SendRejectionNotification sends an email informing the user that their registration was rejected, along with the reasons.
-func (m *Mailer) SendRejectionNotification(email string, errors map[string]string) error {
+// The reasons are keyed by profile field and already in the user's language ("de" or "en"); other languages get English.
+func (m *Mailer) SendRejectionNotification(email, language string, errors map[string]string) error {
 	subject := "Registration Rejected"
+	intro := "Unfortunately, your registration was rejected due to the following issues:"
+	outro := "Please correct these issues and try again."
+	if language == "de" {
+		subject = "Registrierung abgelehnt"
+		intro = "Leider wurde Ihre Registrierung aus folgenden Gründen abgelehnt:"
+		outro = "Bitte korrigieren Sie diese Angaben und versuchen Sie es erneut."
+	}
+	labels, ok := rejectionFieldLabels[language]
+	if !ok {
+		labels = rejectionFieldLabels["en"]
+	}
 
-	body := "Unfortunately, your registration was rejected due to the following issues:\n\n"
-	for field, reason := range errors {
-		body += fmt.Sprintf("- %s: %s\n", field, reason)
+	fields := make([]string, 0, len(errors))
+	for field := range errors {
+		fields = append(fields, field)
 	}
-	body += "\nPlease correct these issues and try again."
+	sort.Strings(fields)
+
+	body := intro + "\n\n"
+	for _, field := range fields {
+		label, ok := labels[field]
+		if !ok {
+			label = field
+		}
+		body += fmt.Sprintf("- %s: %s\n", label, errors[field])
+	}
+	body += "\n" + outro
 
 	m.logger.Info("Account rejection mail was sent",
 		zap.String("email", email),
+		zap.String("language", language),
 		zap.Any("rejection_errors", errors),
 	)
 
 	return m.SendMail(email, subject, body)
 }
+
+// SendAccountLocked informs the user that their account was locked after repeated failed
+// logins and includes a link to unlock it right away.
+func (m *Mailer) SendAccountLocked(to, token string, until time.Time) error {
+	subject := "Account temporarily locked"
+	link := fmt.Sprintf("%s/api/v1/auth/unlock?token=%s", m.projectURL, token)
+	body := fmt.Sprintf("We noticed several failed login attempts for your account, so it has been locked until %s.\n\n"+
+		"If this was you, you can unlock your account right away:\n\n%s\n\n"+
+		"If this was not you, consider changing your password after unlocking.", until.UTC().Format(time.RFC1123), link)
+
+	m.logger.Info("Preparing account locked email",
+		zap.String("to", to),
+	)
+
+	return m.SendMail(to, subject, body)
+}
+
+// SendMagicLink sends a one-time login link. The link only works in the browser that requested it.
+func (m *Mailer) SendMagicLink(to, token string) error {
+	subject := "Your login link"
+	link := fmt.Sprintf("%s/magic-link?token=%s", m.projectURL, token)
+	body := fmt.Sprintf("Click the link below to log in. It is valid for 15 minutes and can be used once, "+
+		"in the browser where you requested it:\n\n%s\n\nIf you did not request this link, please ignore this email.", link)
+
+	m.logger.Info("Preparing magic link email",
+		zap.String("to", to),
+	)
+
+	return m.SendMail(to, subject, body)
+}
+
+// SendEmailChangeConfirmation asks the owner of a new address to confirm the email change.
+func (m *Mailer) SendEmailChangeConfirmation(to, token string) error {
+	subject := "Confirm your new email address"
+	link := fmt.Sprintf("%s/api/v1/auth/change-email/confirm?token=%s", m.projectURL, token)
+	body := fmt.Sprintf("Click the link to use this address for your account: %s\n\nIf you did not request this change, please ignore this email.", link)
+
+	m.logger.Info("Preparing email change confirmation",
+		zap.String("to", to),
+	)
+
+	return m.SendMail(to, subject, body)
+}
+
+// SendEmailChangedAlert informs the previous address about an email change and includes an undo link.
+func (m *Mailer) SendEmailChangedAlert(to, newEmail, undoToken string) error {
+	subject := "Your email address was changed"
+	link := fmt.Sprintf("%s/api/v1/auth/change-email/undo?token=%s", m.projectURL, undoToken)
+	body := fmt.Sprintf("The email address of your account was changed to %s.\n\n"+
+		"If you did not make this change, undo it with the link below. This also signs out every device:\n\n%s", newEmail, link)
+
+	m.logger.Info("Preparing email changed alert",
+		zap.String("to", to),
+		zap.String("new_email", newEmail),
+	)
+
+	return m.SendMail(to, subject, body)
+}
+
+// SendAccountDeletionConfirmation asks the user to confirm the deletion of their account.
+func (m *Mailer) SendAccountDeletionConfirmation(to, token string) error {
+	subject := "Confirm the deletion of your account"
+	link := fmt.Sprintf("%s/api/v1/privacy/deletion/confirm?token=%s", m.projectURL, token)
+	body := fmt.Sprintf("Click the link below to delete your account and all data we hold about you. "+
+		"The link is valid for 24 hours:\n\n%s\n\nIf you did not request this, please ignore this email.", link)
+
+	m.logger.Info("Preparing account deletion confirmation",
+		zap.String("to", to),
+	)
+
+	return m.SendMail(to, subject, body)
+}
+
+// SendAccountDeletionScheduled tells the user when their account will be deleted and how to keep it.
+func (m *Mailer) SendAccountDeletionScheduled(to string, scheduledFor time.Time) error {
+	subject := "Your account will be deleted"
+	body := fmt.Sprintf("Your account and all data we hold about you will be deleted on %s.\n\n"+
+		"If you want to keep your account, log in before then and cancel the deletion in your account settings.",
+		scheduledFor.UTC().Format(time.RFC1123))
+
+	m.logger.Info("Preparing account deletion scheduled email",
+		zap.String("to", to),
+	)
+
+	return m.SendMail(to, subject, body)
+}
+
+// SendAccountDeleted confirms that an account and its data were deleted.
+func (m *Mailer) SendAccountDeleted(to string) error {
+	subject := "Your account was deleted"
+	body := "Your account and the personal data we held about you have been deleted. This is the last email you receive from us."
+
+	m.logger.Info("Preparing account deleted email",
+		zap.String("to", to),
+	)
+
+	return m.SendMail(to, subject, body)
+}
+
+// SendAccountSuspended tells the user that their account is suspended, why and until when.
+func (m *Mailer) SendAccountSuspended(to, reason string, until time.Time) error {
+	subject := "Your account has been suspended"
+	body := fmt.Sprintf("Your account has been suspended until %s.\n\nReason: %s\n\n"+
+		"You cannot log in until then. If you think this is a mistake, please contact our support.",
+		until.UTC().Format(time.RFC1123), reason)
+
+	m.logger.Info("Preparing account suspended email",
+		zap.String("to", to),
+	)
+
+	return m.SendMail(to, subject, body)
+}
+
+// SendAccountBanned tells the user that their account has been closed permanently and why.
+func (m *Mailer) SendAccountBanned(to, reason string) error {
+	subject := "Your account has been banned"
+	body := fmt.Sprintf("Your account has been banned permanently.\n\nReason: %s\n\n"+
+		"If you think this is a mistake, please contact our support.", reason)
+
+	m.logger.Info("Preparing account banned email",
+		zap.String("to", to),
+	)
+
+	return m.SendMail(to, subject, body)
+}
+
+// SendAccountReactivated tells the user that they can log in again.
+func (m *Mailer) SendAccountReactivated(to string) error {
+	subject := "Your account has been reactivated"
+	body := "Your account has been reactivated. You can log in again."
+
+	m.logger.Info("Preparing account reactivated email",
+		zap.String("to", to),
+	)
+
+	return m.SendMail(to, subject, body)
+}
@@ -0,0 +1,76 @@
+package email
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"sync"
+)
+
+// Queue sends emails in the background so bulk operations do not wait for the SMTP server.
+// Emails are lost if the process stops before they are sent.
+type Queue struct {
+	jobs   chan mailJob
+	logger *zap.Logger
+}
+
+type mailJob struct {
+	to   string
+	kind string
+	send func() error
+}
+
+// NewQueue creates a queue that holds up to size emails. Nothing is sent until Run is called.
+func NewQueue(logger *zap.Logger, size int) *Queue {
+	return &Queue{
+		jobs:   make(chan mailJob, size),
+		logger: logger,
+	}
+}
+
+// Enqueue adds an email to the queue. kind names the email in the logs and send delivers it,
+// usually by calling a Sender method. It returns false if the queue is full.
+func (q *Queue) Enqueue(to, kind string, send func() error) bool {
+	select {
+	case q.jobs <- mailJob{to: to, kind: kind, send: send}:
+		return true
+	default:
+		return false
+	}
+}
+
+// Len returns the number of emails waiting to be sent.
+func (q *Queue) Len() int {
+	return len(q.jobs)
+}
+
+// Run sends queued emails with the given number of workers until the context is cancelled.
+func (q *Queue) Run(ctx context.Context, workers int) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case job := <-q.jobs:
+					if err := job.send(); err != nil {
+						q.logger.Warn("failed to send queued email",
+							zap.String("to", job.to),
+							zap.String("kind", job.kind),
+							zap.Error(err),
+						)
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
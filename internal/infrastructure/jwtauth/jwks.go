@@ -0,0 +1,61 @@
+package jwtauth
+
+import (
+	"crypto/ed25519"
+
+	"crypto/rsa"
+
+	"encoding/base64"
+
+	"math/big"
+
+	"sort"
+)
+
+// JWK is the public part of a key in RFC 7517 format.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+// JWKS is the document served at /.well-known/jwks.json.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys of every key in the set.
+func (k *KeySet) JWKS() JWKS {
+	doc := JWKS{Keys: make([]JWK, 0, len(k.verify))}
+
+	for id, key := range k.verify {
+		switch pub := key.PublicKey.(type) {
+		case *rsa.PublicKey:
+			doc.Keys = append(doc.Keys, JWK{
+				KeyType:   "RSA",
+				KeyID:     id,
+				Use:       "sig",
+				Algorithm: "RS256",
+				N:         base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			doc.Keys = append(doc.Keys, JWK{
+				KeyType:   "OKP",
+				KeyID:     id,
+				Use:       "sig",
+				Algorithm: "EdDSA",
+				Curve:     "Ed25519",
+				X:         base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+
+	sort.Slice(doc.Keys, func(i, j int) bool { return doc.Keys[i].KeyID < doc.Keys[j].KeyID })
+	return doc
+}
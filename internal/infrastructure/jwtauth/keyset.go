@@ -0,0 +1,159 @@
+// Package jwtauth signs and verifies access tokens with asymmetric keys.
+package jwtauth
+
+import (
+	"crypto"
+
+	"crypto/ed25519"
+
+	"crypto/rsa"
+
+	"errors"
+
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/google/uuid"
+
+	"time"
+)
+
+const minRSAKeyBits = 2048
+
+var (
+	ErrNoSigningKey = errors.New("no usable JWT signing key configured")
+	ErrUnknownKeyID = errors.New("unknown JWT key id")
+)
+
+// Key is a single signing or verification key identified by its kid.
+type Key struct {
+	ID         string
+	PrivateKey crypto.Signer
+	PublicKey  crypto.PublicKey
+}
+
+// KeySet holds the active signing key and every key tokens may still be verified with.
+type KeySet struct {
+	issuer   string
+	audience string
+	signing  Key
+	method   jwt.SigningMethod
+	verify   map[string]Key
+}
+
+// NewKeySet creates a key set that signs with the key identified by activeKeyID.
+// All keys are published in the JWKS and accepted for verification, which allows
+// rotating the active key without invalidating tokens signed by the previous one.
+func NewKeySet(issuer, audience, activeKeyID string, keys ...Key) (*KeySet, error) {
+	set := &KeySet{
+		issuer:   issuer,
+		audience: audience,
+		verify:   make(map[string]Key, len(keys)),
+	}
+
+	for _, key := range keys {
+		if key.ID == "" {
+			return nil, errors.New("JWT key without id")
+		}
+		if key.PublicKey == nil && key.PrivateKey != nil {
+			key.PublicKey = key.PrivateKey.Public()
+		}
+		if _, err := signingMethod(key.PublicKey); err != nil {
+			return nil, fmt.Errorf("JWT key %q: %w", key.ID, err)
+		}
+		set.verify[key.ID] = key
+	}
+
+	active, ok := set.verify[activeKeyID]
+	if !ok || active.PrivateKey == nil {
+		return nil, ErrNoSigningKey
+	}
+	set.signing = active
+	set.method, _ = signingMethod(active.PublicKey)
+
+	return set, nil
+}
+
+// Sign adds the registered claims (iss, aud, iat, exp, jti) to claims and signs
+// them with the active key. The kid header names the key used.
+func (k *KeySet) Sign(claims jwt.MapClaims, ttl time.Duration) (string, error) {
+	now := time.Now()
+
+	signed := jwt.MapClaims{}
+	for name, value := range claims {
+		signed[name] = value
+	}
+	signed["iss"] = k.issuer
+	signed["aud"] = k.audience
+	signed["iat"] = now.Unix()
+	signed["exp"] = now.Add(ttl).Unix()
+	signed["jti"] = uuid.New().String()
+
+	token := jwt.NewWithClaims(k.method, signed)
+	token.Header["kid"] = k.signing.ID
+
+	return token.SignedString(k.signing.PrivateKey)
+}
+
+// Parse verifies the signature and the registered claims of a token.
+// Only the asymmetric algorithms of the configured keys are accepted.
+func (k *KeySet) Parse(tokenStr string) (jwt.MapClaims, error) {
+	parser := jwt.NewParser(
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}),
+		jwt.WithIssuer(k.issuer),
+		jwt.WithAudience(k.audience),
+		jwt.WithIssuedAt(),
+		jwt.WithExpirationRequired(),
+	)
+
+	token, err := parser.Parse(tokenStr, k.keyFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid claims")
+	}
+	if jti, _ := claims["jti"].(string); jti == "" {
+		return nil, errors.New("token has no jti")
+	}
+
+	return claims, nil
+}
+
+// keyFunc selects the verification key named by the kid header and makes sure
+// the token algorithm matches the key type.
+func (k *KeySet) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := k.verify[kid]
+	if !ok {
+		return nil, ErrUnknownKeyID
+	}
+
+	method, err := signingMethod(key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	if token.Method.Alg() != method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %q for key %q", token.Method.Alg(), kid)
+	}
+
+	return key.PublicKey, nil
+}
+
+// signingMethod returns the JWT algorithm for a public key.
+func signingMethod(pub crypto.PublicKey) (jwt.SigningMethod, error) {
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		if key.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("RSA key must be at least %d bits", minRSAKeyBits)
+		}
+		return jwt.SigningMethodRS256, nil
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", pub)
+	}
+}
@@ -0,0 +1,134 @@
+package jwtauth
+
+import (
+	"crypto"
+
+	"crypto/x509"
+
+	"encoding/pem"
+
+	"errors"
+
+	"fmt"
+
+	"os"
+
+	"path/filepath"
+
+	"sort"
+
+	"strings"
+)
+
+const (
+	defaultIssuer   = "carowebapp-core"
+	defaultAudience = "carowebapp"
+
+	privateKeySuffix = ".pem"
+	publicKeySuffix  = ".pub.pem"
+)
+
+// LoadFromEnv builds the key set from the environment:
+//
+//	JWT_KEYS_DIR   directory with "<kid>.pem" private keys and "<kid>.pub.pem"
+//	               public keys of retired signing keys (RSA or Ed25519)
+//	JWT_ACTIVE_KID kid of the key used for signing; optional with a single private key
+//	JWT_ISSUER     value of the iss claim
+//	JWT_AUDIENCE   value of the aud claim
+func LoadFromEnv() (*KeySet, error) {
+	dir := os.Getenv("JWT_KEYS_DIR")
+	if dir == "" {
+		return nil, fmt.Errorf("%w: JWT_KEYS_DIR is not set", ErrNoSigningKey)
+	}
+
+	keys, err := loadKeys(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	activeKeyID := os.Getenv("JWT_ACTIVE_KID")
+	if activeKeyID == "" {
+		var private []string
+		for _, key := range keys {
+			if key.PrivateKey != nil {
+				private = append(private, key.ID)
+			}
+		}
+		if len(private) != 1 {
+			return nil, fmt.Errorf("%w: set JWT_ACTIVE_KID to choose one of %d private keys", ErrNoSigningKey, len(private))
+		}
+		activeKeyID = private[0]
+	}
+
+	return NewKeySet(envOrDefault("JWT_ISSUER", defaultIssuer), envOrDefault("JWT_AUDIENCE", defaultAudience), activeKeyID, keys...)
+}
+
+// loadKeys reads all PEM keys from dir, sorted by kid.
+func loadKeys(dir string) ([]Key, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read JWT keys: %w", err)
+	}
+
+	var keys []Key
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, privateKeySuffix) {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("read JWT key %s: %w", name, err)
+		}
+
+		var key Key
+		if strings.HasSuffix(name, publicKeySuffix) {
+			key.ID = strings.TrimSuffix(name, publicKeySuffix)
+			key.PublicKey, err = parsePublicKey(data)
+		} else {
+			key.ID = strings.TrimSuffix(name, privateKeySuffix)
+			key.PrivateKey, err = parsePrivateKey(data)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse JWT key %s: %w", name, err)
+		}
+
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+	return keys, nil
+}
+
+func parsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T", key)
+		}
+		return signer, nil
+	}
+
+	return x509.ParsePKCS1PrivateKey(block.Bytes)
+}
+
+func parsePublicKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	return x509.ParsePKIXPublicKey(block.Bytes)
+}
+
+func envOrDefault(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -1,7 +1,9 @@
 package middleware
 
 import (
-	"errors"
+	"carowebapp/core/internal/pkg/contextutils"
+
+	"context"
 
 	"github.com/gofiber/fiber/v2"
 
@@ -10,7 +12,21 @@ import (
 	"strings"
 )
 
-func JWTMiddleware(secret string) fiber.Handler {
+// TokenVerifier validates an access token and returns its claims.
+type TokenVerifier interface {
+	Parse(tokenStr string) (jwt.MapClaims, error)
+}
+
+// SessionChecker reports whether the session an access token was issued for is still active
+// and whether its user is suspended or banned.
+type SessionChecker interface {
+	IsSessionActive(ctx context.Context, sessionID string) (bool, error)
+	IsAccountBlocked(ctx context.Context, userID string) (bool, error)
+}
+
+// JWTMiddleware authenticates the bearer token and rejects tokens whose session was revoked
+// or whose user is suspended or banned.
+func JWTMiddleware(verifier TokenVerifier, sessions SessionChecker) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		auth := c.Get("Authorization")
 		if auth == "" || !strings.HasPrefix(auth, "Bearer ") {
@@ -18,30 +34,53 @@ func JWTMiddleware(secret string) fiber.Handler {
 		}
 
 		token := strings.TrimPrefix(auth, "Bearer ")
-		claims, err := ParseJWT(token, secret)
+		claims, err := verifier.Parse(token)
 		if err != nil {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid or expired token"})
 		}
 
-		userID := claims["sub"].(string)
-		c.Locals("userID", userID)
+		userID, _ := claims["sub"].(string)
+		sessionID, _ := claims["sid"].(string)
+		mfa, _ := claims["mfa"].(bool)
+		role, _ := claims["role"].(string)
+		if userID == "" || sessionID == "" {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid or expired token"})
+		}
 
-		return c.Next()
-	}
-}
+		active, err := sessions.IsSessionActive(c.Context(), sessionID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to verify session"})
+		}
+		if !active {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "session has been revoked"})
+		}
+
+		blocked, err := sessions.IsAccountBlocked(c.Context(), userID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to verify session"})
+		}
+		if blocked {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "account is suspended or banned"})
+		}
 
-func ParseJWT(tokenStr, secret string) (jwt.MapClaims, error) {
-	token, err := jwt.Parse(tokenStr, func(_ *jwt.Token) (interface{}, error) {
-		return []byte(secret), nil
-	})
+		c.Locals(contextutils.ContextKeyUserID, userID)
+		c.Locals(contextutils.ContextKeySessionID, sessionID)
+		c.Locals(contextutils.ContextKeyMFA, mfa)
+		c.Locals(contextutils.ContextKeyRole, role)
+		c.Locals(contextutils.ContextKeyPermissions, stringSlice(claims["perms"]))
 
-	if err != nil || !token.Valid {
-		return nil, err
+		return c.Next()
 	}
+}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return nil, errors.New("invalid claims")
+// stringSlice converts a JSON array claim into a string slice.
+func stringSlice(claim interface{}) []string {
+	values, _ := claim.([]interface{})
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if s, ok := v.(string); ok {
+			result = append(result, s)
+		}
 	}
-	return claims, nil
+	return result
 }
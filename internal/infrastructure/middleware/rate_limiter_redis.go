@@ -14,19 +14,46 @@ import (
 var ctx = context.Background()
 
 type RateLimiterConfig struct {
-	RedisClient  *redis.Client
+	RedisClient *redis.Client
+	// Prefix separates the counters of different limiters that use the same key.
+	Prefix       string
 	MaxAttempts  int
 	Window       time.Duration
 	KeyGenerator func(*fiber.Ctx) string
 	Logger       *zap.Logger
+	// FailuresOnly counts only requests answered with a 4xx status.
+	FailuresOnly bool
 }
 
+// reserveScript counts an attempt unless the limit is reached and returns the new count,
+// or the limit plus one when the attempt was refused. Doing both in one script keeps
+// parallel requests from passing the check before any of them is counted.
+var reserveScript = redis.NewScript(`
+local attempts = redis.call('INCR', KEYS[1])
+if attempts > tonumber(ARGV[1]) then
+	redis.call('DECR', KEYS[1])
+	return attempts
+end
+redis.call('PEXPIRE', KEYS[1], ARGV[2])
+return attempts
+`)
+
+// releaseScript takes back a reserved attempt. A counter that expired in the meantime
+// is left alone, so it cannot go negative.
+var releaseScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return redis.call('DECR', KEYS[1])
+end
+return 0
+`)
+
 func RateLimitByRedis(cfg RateLimiterConfig) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		key := fmt.Sprintf("rl:%s", cfg.KeyGenerator(c))
+		key := fmt.Sprintf("rl:%s:%s", cfg.Prefix, cfg.KeyGenerator(c))
 
-		attempts, err := cfg.RedisClient.Get(ctx, key).Int()
-		if err != nil && !errors.Is(err, redis.Nil) {
+		attempts, err := reserveScript.Run(ctx, cfg.RedisClient, []string{key},
+			cfg.MaxAttempts, cfg.Window.Milliseconds()).Int()
+		if err != nil {
 			cfg.Logger.Error("Redis error during rate limiting",
 				zap.String("key", key),
 				zap.Error(err),
@@ -36,10 +63,10 @@ func RateLimitByRedis(cfg RateLimiterConfig) fiber.Handler {
 			})
 		}
 
-		if attempts >= cfg.MaxAttempts {
+		if attempts > cfg.MaxAttempts {
 			cfg.Logger.Warn("Rate limit exceeded",
 				zap.String("key", key),
-				zap.Int("attempts", attempts),
+				zap.Int("attempts", attempts-1),
 				zap.Duration("window", cfg.Window),
 				zap.String("ip", c.IP()),
 				zap.String("path", c.Path()),
@@ -49,23 +76,55 @@ func RateLimitByRedis(cfg RateLimiterConfig) fiber.Handler {
 			})
 		}
 
-		pipe := cfg.RedisClient.TxPipeline()
-		pipe.Incr(ctx, key)
-		pipe.Expire(ctx, key, cfg.Window)
-		_, _ = pipe.Exec(ctx)
+		if !cfg.FailuresOnly {
+			logAttempt(c, cfg, key, attempts)
+			return c.Next()
+		}
 
-		cfg.Logger.Info("Rate limit attempt recorded",
-			zap.String("key", key),
-			zap.Int("current_attempts", attempts+1),
-			zap.String("ip", c.IP()),
-			zap.String("path", c.Path()),
-		)
+		// The attempt is reserved before the handler runs and taken back unless the
+		// response turns out to be a failure.
+		err = c.Next()
 
-		return c.Next()
+		status := c.Response().StatusCode()
+		var fiberErr *fiber.Error
+		if errors.As(err, &fiberErr) {
+			status = fiberErr.Code
+		}
+		if status >= fiber.StatusBadRequest && status < fiber.StatusInternalServerError {
+			logAttempt(c, cfg, key, attempts)
+		} else if releaseErr := releaseScript.Run(ctx, cfg.RedisClient, []string{key}).Err(); releaseErr != nil {
+			cfg.Logger.Error("Redis error releasing rate limit attempt",
+				zap.String("key", key),
+				zap.Error(releaseErr),
+			)
+		}
+
+		return err
 	}
 }
 
+func logAttempt(c *fiber.Ctx, cfg RateLimiterConfig, key string, attempts int) {
+	cfg.Logger.Info("Rate limit attempt recorded",
+		zap.String("key", key),
+		zap.Int("current_attempts", attempts),
+		zap.String("ip", c.IP()),
+		zap.String("path", c.Path()),
+	)
+}
+
+// ResetPasswordLimiter limits password reset requests per email address.
 func ResetPasswordLimiter(redisClient *redis.Client, logger *zap.Logger) fiber.Handler {
+	return emailLimiter(redisClient, logger, "reset")
+}
+
+// MagicLinkLimiter limits magic-link login requests per email address.
+func MagicLinkLimiter(redisClient *redis.Client, logger *zap.Logger) fiber.Handler {
+	return emailLimiter(redisClient, logger, "magic")
+}
+
+// emailLimiter allows a few requests per email address within a short window
+// and blocks the address for an hour once the limit is exceeded.
+func emailLimiter(redisClient *redis.Client, logger *zap.Logger, prefix string) fiber.Handler {
 	const (
 		maxAttempts   = 5
 		shortWindow   = 1 * time.Minute
@@ -77,7 +136,8 @@ func ResetPasswordLimiter(redisClient *redis.Client, logger *zap.Logger) fiber.H
 			Email string `json:"email"`
 		}
 		if err := c.BodyParser(&body); err != nil || body.Email == "" {
-			logger.Info("Missing or invalid email in reset-password request",
+			logger.Info("Missing or invalid email in rate limited request",
+				zap.String("limiter", prefix),
 				zap.String("ip", c.IP()),
 				zap.String("path", c.Path()),
 			)
@@ -85,8 +145,8 @@ func ResetPasswordLimiter(redisClient *redis.Client, logger *zap.Logger) fiber.H
 		}
 
 		email := body.Email
-		keyAttempts := fmt.Sprintf("rl:reset:%s:count", email)
-		keyBlock := fmt.Sprintf("rl:reset:%s:block", email)
+		keyAttempts := fmt.Sprintf("rl:%s:%s:count", prefix, email)
+		keyBlock := fmt.Sprintf("rl:%s:%s:block", prefix, email)
 
 		// Проверка блокировки
 		blocked, err := redisClient.Exists(ctx, keyBlock).Result()
@@ -95,7 +155,8 @@ func ResetPasswordLimiter(redisClient *redis.Client, logger *zap.Logger) fiber.H
 			return fiber.ErrInternalServerError
 		}
 		if blocked > 0 {
-			logger.Info("Password reset blocked due to rate limiting",
+			logger.Info("Request blocked due to rate limiting",
+				zap.String("limiter", prefix),
 				zap.String("email", email),
 				zap.String("ip", c.IP()),
 			)
@@ -117,7 +178,8 @@ func ResetPasswordLimiter(redisClient *redis.Client, logger *zap.Logger) fiber.H
 			_ = redisClient.Set(ctx, keyBlock, 1, blockDuration).Err()
 			_ = redisClient.Del(ctx, keyAttempts).Err()
 
-			logger.Info("Password reset rate limit exceeded",
+			logger.Info("Email rate limit exceeded",
+				zap.String("limiter", prefix),
 				zap.String("email", email),
 				zap.String("ip", c.IP()),
 				zap.Int64("attempts", attempts),
@@ -126,7 +188,8 @@ func ResetPasswordLimiter(redisClient *redis.Client, logger *zap.Logger) fiber.H
 			return fiber.NewError(fiber.StatusTooManyRequests, "Too many attempts, try again in 1 hour.")
 		}
 
-		logger.Info("Password reset attempt recorded",
+		logger.Info("Email rate limit attempt recorded",
+			zap.String("limiter", prefix),
 			zap.String("email", email),
 			zap.String("ip", c.IP()),
 			zap.Int64("attempt", attempts),
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"carowebapp/core/internal/infrastructure/response"
+
+	"carowebapp/core/internal/pkg/contextutils"
+
+	"github.com/gofiber/fiber/v2"
+
+	"go.uber.org/zap"
+)
+
+// RequirePermission checks that the access token grants all of the given permissions.
+// It expects JWT middleware to already have set the permission claims in context.
+func RequirePermission(logger *zap.Logger, permissions ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID, ok := contextutils.GetUserID(c)
+		if !ok {
+			return response.JSONErrorInfoLog(c, logger, fiber.StatusUnauthorized, response.ErrMsgUnauthorized)
+		}
+
+		for _, permission := range permissions {
+			if !contextutils.HasPermission(c, permission) {
+				role, _ := contextutils.GetRole(c)
+				return response.JSONErrorInfoLog(c, logger, fiber.StatusForbidden, response.ErrMsgForbidden,
+					zap.String("user_id", userID),
+					zap.String("role", role),
+					zap.String("permission", permission),
+					zap.String("path", c.Path()),
+				)
+			}
+		}
+
+		return c.Next()
+	}
+}
+
+// RequireSecondFactor rejects access tokens of the given roles that were issued
+// without a second factor.
+func RequireSecondFactor(logger *zap.Logger, roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		role, _ := contextutils.GetRole(c)
+
+		for _, r := range roles {
+			if r == role && !contextutils.HasSecondFactor(c) {
+				userID, _ := contextutils.GetUserID(c)
+				return response.JSONErrorInfoLog(c, logger, fiber.StatusForbidden, response.ErrMsgTwoFactorRequired,
+					zap.String("user_id", userID),
+					zap.String("role", role),
+				)
+			}
+		}
+
+		return c.Next()
+	}
+}
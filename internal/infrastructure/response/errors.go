@@ -2,10 +2,26 @@ package response
 
 const (
 	ErrMsgUnauthorized          = "unauthorized"
+	ErrMsgForbidden             = "insufficient permissions"
 	ErrMsgMissingToken          = "missing token"
 	ErrMsgInvalidOrExpiredToken = "invalid or expired token"
 	ErrMsgEmailConfirmationFail = "could not confirm email"
 	ErrMsgProfileCreationFail   = "failed to create user profile"
+	ErrMsgProfileUpdateFail     = "failed to update user profile"
+	ErrMsgLoadUserFail          = "failed to load user"
+	ErrMsgLoadRejectionFail     = "failed to load rejection"
+	ErrMsgResubmissionFail      = "failed to resubmit registration"
 	ErrMsgLoginFailed           = "login failed"
 	ErrMsgAlreadyConfirmed      = "email already confirmed"
+	ErrMsgRefreshFailed         = "could not refresh tokens"
+	ErrMsgListSessionsFail      = "failed to list sessions"
+	ErrMsgLogoutFail            = "failed to log out"
+	ErrMsgTwoFactorFail         = "two-factor operation failed"
+	ErrMsgTwoFactorRequired     = "two-factor authentication required"
+	ErrMsgServiceBusy           = "service is busy, please try again later"
+	ErrMsgUnlockFailed          = "failed to unlock account"
+	ErrMsgMagicLinkFail         = "could not send login link"
+	ErrMsgChangePasswordFail    = "failed to change password"
+	ErrMsgChangeEmailFail       = "failed to change email"
+	ErrMsgResetPasswordFail     = "failed to reset password"
 )
@@ -19,6 +19,12 @@ func JSONErrorInfoLog(c *fiber.Ctx, logger *zap.Logger, status int, msg string,
 	return c.Status(status).JSON(fiber.Map{"error": msg})
 }
 
+// JSONErrorWithDetails logs at info level and returns the error message with structured details.
+func JSONErrorWithDetails(c *fiber.Ctx, logger *zap.Logger, status int, msg string, details interface{}, fields ...zap.Field) error {
+	logger.Info(msg, fields...)
+	return c.Status(status).JSON(fiber.Map{"error": msg, "details": details})
+}
+
 func JSONSuccess(c *fiber.Ctx, status int, payload interface{}) error {
 	return c.Status(status).JSON(payload)
 }
@@ -0,0 +1,32 @@
+// Package securitylog writes security relevant account events to the security_events table.
+package securitylog
+
+import (
+	domainuser "carowebapp/core/internal/domain/user"
+
+	"encoding/json"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Record inserts the event. Pass a transaction to store it atomically with the change it describes.
+func Record(exec sqlx.Execer, event domainuser.SecurityEvent) error {
+	details, err := json.Marshal(event.Details)
+	if err != nil {
+		return err
+	}
+
+	var userID, ip *string
+	if event.UserID != "" {
+		userID = &event.UserID
+	}
+	if event.IP != "" {
+		ip = &event.IP
+	}
+
+	_, err = exec.Exec(`
+		INSERT INTO security_events (user_id, event, ip, details)
+		VALUES ($1, $2, $3, $4)
+	`, userID, event.Type, ip, details)
+	return err
+}
@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"errors"
+
+	"fmt"
+
+	"io"
+
+	"os"
+
+	"path/filepath"
+)
+
+// DefaultLocalDir is where LocalFromEnv stores files if DOCUMENT_STORAGE_DIR is not set.
+const DefaultLocalDir = "./data/documents"
+
+// Local stores files in a directory of the local filesystem. Keys map to paths below it.
+type Local struct {
+	dir string
+}
+
+// NewLocal creates the directory if needed and returns a store writing into it.
+func NewLocal(dir string) (*Local, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create storage directory: %w", err)
+	}
+	return &Local{dir: dir}, nil
+}
+
+// LocalFromEnv returns a store in the directory named by DOCUMENT_STORAGE_DIR.
+func LocalFromEnv() (*Local, error) {
+	dir := os.Getenv("DOCUMENT_STORAGE_DIR")
+	if dir == "" {
+		dir = DefaultLocalDir
+	}
+	return NewLocal(dir)
+}
+
+// Put writes the content to a temporary file first, so readers never see a partial file.
+func (l *Local) Put(key string, content io.Reader) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := io.Copy(tmp, content); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (l *Local) Open(key string) (io.ReadCloser, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+func (l *Local) Delete(key string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// path returns the file path of a key.
+func (l *Local) path(key string) (string, error) {
+	if !ValidKey(key) {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(l.dir, filepath.FromSlash(key)), nil
+}
@@ -0,0 +1,34 @@
+// Package storage keeps uploaded files outside the database.
+package storage
+
+import (
+	"errors"
+
+	"io"
+
+	"regexp"
+)
+
+var (
+	ErrNotFound   = errors.New("file not found")
+	ErrInvalidKey = errors.New("invalid storage key")
+)
+
+// keyPattern allows slash separated segments of letters, digits, dashes and underscores,
+// so a key can never leave the store.
+var keyPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+(/[A-Za-z0-9_-]+)*$`)
+
+// Store saves files under keys chosen by the caller.
+type Store interface {
+	// Put stores the content under the key, replacing any file already there.
+	Put(key string, content io.Reader) error
+	// Open returns the file stored under the key or ErrNotFound.
+	Open(key string) (io.ReadCloser, error)
+	// Delete removes the file stored under the key. Deleting a missing file is not an error.
+	Delete(key string) error
+}
+
+// ValidKey reports whether the key can be used with a Store.
+func ValidKey(key string) bool {
+	return len(key) <= 255 && keyPattern.MatchString(key)
+}
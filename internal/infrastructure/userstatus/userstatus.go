@@ -0,0 +1,42 @@
+// Package userstatus persists user status transitions together with their history.
+package userstatus
+
+import (
+	domainuser "carowebapp/core/internal/domain/user"
+
+	"github.com/google/uuid"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Apply moves the user to the new status if they are still in the status the change was
+// computed from, and records the transition. Pass a transaction to store it atomically with
+// the change that caused it. It returns domainuser.ErrStatusConflict if the status moved on.
+func Apply(exec sqlx.Execer, change *domainuser.StatusChange) error {
+	res, err := exec.Exec(`
+		UPDATE users
+		SET status = $1
+		WHERE id = $2 AND status = $3
+	`, change.To, change.UserID, change.From)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domainuser.ErrStatusConflict
+	}
+
+	if change.ID == "" {
+		change.ID = uuid.New().String()
+	}
+
+	_, err = exec.Exec(`
+		INSERT INTO user_status_transitions (id, user_id, from_status, to_status, transition, actor_id, reason, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, change.ID, change.UserID, change.From, change.To, change.Transition, change.ActorID, change.Reason, change.CreatedAt)
+	return err
+}
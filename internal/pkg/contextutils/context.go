@@ -6,6 +6,10 @@ import (
 
 const (
 	ContextKeyUserID        = "userID"
+	ContextKeySessionID     = "sessionID"
+	ContextKeyMFA           = "mfa"
+	ContextKeyRole          = "role"
+	ContextKeyPermissions   = "permissions"
 	ContextKeyValidatedBody = "validatedBody"
 	ContextKeyTraceID       = "traceID"
 )
@@ -15,6 +19,33 @@ func GetUserID(c *fiber.Ctx) (string, bool) {
 	return id, ok && id != ""
 }
 
+func GetSessionID(c *fiber.Ctx) (string, bool) {
+	id, ok := c.Locals(ContextKeySessionID).(string)
+	return id, ok && id != ""
+}
+
+// HasSecondFactor reports whether the access token was issued after a second-factor check.
+func HasSecondFactor(c *fiber.Ctx) bool {
+	mfa, ok := c.Locals(ContextKeyMFA).(bool)
+	return ok && mfa
+}
+
+func GetRole(c *fiber.Ctx) (string, bool) {
+	role, ok := c.Locals(ContextKeyRole).(string)
+	return role, ok && role != ""
+}
+
+// HasPermission reports whether the access token grants the permission.
+func HasPermission(c *fiber.Ctx, permission string) bool {
+	permissions, _ := c.Locals(ContextKeyPermissions).([]string)
+	for _, p := range permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
 func GetValidatedBody[T any](c *fiber.Ctx) (*T, bool) {
 	body, ok := c.Locals(ContextKeyValidatedBody).(*T)
 	return body, ok
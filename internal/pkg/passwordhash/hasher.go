@@ -0,0 +1,190 @@
+// Package passwordhash hashes passwords with a configurable algorithm and verifies
+// hashes produced by any supported algorithm.
+package passwordhash
+
+import (
+	"errors"
+
+	"fmt"
+
+	"os"
+
+	"runtime"
+
+	"strconv"
+
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	// ErrBusy is returned when no hashing worker became free within the wait time.
+	ErrBusy = errors.New("password hashing is overloaded")
+	// ErrUnknownAlgorithm is returned for hashes no configured algorithm recognises.
+	ErrUnknownAlgorithm = errors.New("unknown password hash algorithm")
+)
+
+// Algorithm is a single password hashing scheme.
+type Algorithm interface {
+	Hash(password string) (string, error)
+	// Identifies reports whether the encoded hash was produced by this scheme.
+	Identifies(encoded string) bool
+	Verify(encoded, password string) (bool, error)
+	// Outdated reports whether the encoded hash uses other parameters than configured.
+	Outdated(encoded string) bool
+}
+
+// Hasher hashes with the current algorithm and verifies hashes of every known one.
+// At most Workers hash computations run at the same time; callers wait up to
+// MaxWait for a free slot, so request floods cannot occupy every CPU.
+type Hasher struct {
+	current Algorithm
+	known   []Algorithm
+	slots   chan struct{}
+	maxWait time.Duration
+}
+
+// NewHasher creates a hasher. current is used for new hashes and is always known.
+func NewHasher(current Algorithm, workers int, maxWait time.Duration, known ...Algorithm) *Hasher {
+	if workers < 1 {
+		workers = 1
+	}
+	return &Hasher{
+		current: current,
+		known:   append([]Algorithm{current}, known...),
+		slots:   make(chan struct{}, workers),
+		maxWait: maxWait,
+	}
+}
+
+// FromEnv builds the hasher from the environment:
+//
+//	PASSWORD_HASH_ALGORITHM  "argon2id" (default) or "bcrypt"
+//	ARGON2_MEMORY_KIB        Argon2id memory in KiB
+//	ARGON2_ITERATIONS        Argon2id iterations
+//	ARGON2_PARALLELISM       Argon2id parallelism
+//	BCRYPT_COST              bcrypt cost
+//	PASSWORD_HASH_WORKERS    concurrent hash computations, defaults to the CPU count
+//	PASSWORD_HASH_MAX_WAIT   how long a request waits for a worker, e.g. "5s"
+func FromEnv() (*Hasher, error) {
+	params := DefaultArgon2idParams
+	memory, err := intFromEnv("ARGON2_MEMORY_KIB", int(params.Memory))
+	if err != nil {
+		return nil, err
+	}
+	iterations, err := intFromEnv("ARGON2_ITERATIONS", int(params.Iterations))
+	if err != nil {
+		return nil, err
+	}
+	parallelism, err := intFromEnv("ARGON2_PARALLELISM", int(params.Parallelism))
+	if err != nil {
+		return nil, err
+	}
+	if memory < 1 || iterations < 1 || parallelism < 1 || parallelism > 255 {
+		return nil, errors.New("invalid Argon2id parameters")
+	}
+	params.Memory, params.Iterations, params.Parallelism = uint32(memory), uint32(iterations), uint8(parallelism)
+
+	cost, err := intFromEnv("BCRYPT_COST", bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return nil, fmt.Errorf("invalid BCRYPT_COST %d", cost)
+	}
+
+	workers, err := intFromEnv("PASSWORD_HASH_WORKERS", runtime.NumCPU())
+	if err != nil {
+		return nil, err
+	}
+
+	maxWait := 5 * time.Second
+	if value := os.Getenv("PASSWORD_HASH_MAX_WAIT"); value != "" {
+		if maxWait, err = time.ParseDuration(value); err != nil {
+			return nil, fmt.Errorf("invalid PASSWORD_HASH_MAX_WAIT: %w", err)
+		}
+	}
+
+	argon := &Argon2id{Params: params}
+	bcryptAlg := &Bcrypt{Cost: cost}
+
+	switch os.Getenv("PASSWORD_HASH_ALGORITHM") {
+	case "", "argon2id":
+		return NewHasher(argon, workers, maxWait, bcryptAlg), nil
+	case "bcrypt":
+		return NewHasher(bcryptAlg, workers, maxWait, argon), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownAlgorithm, os.Getenv("PASSWORD_HASH_ALGORITHM"))
+	}
+}
+
+// Hash hashes the password with the current algorithm.
+func (h *Hasher) Hash(password string) (string, error) {
+	if err := h.acquire(); err != nil {
+		return "", err
+	}
+	defer h.release()
+
+	return h.current.Hash(password)
+}
+
+// Verify checks the password against an encoded hash of any known algorithm.
+// needsRehash is true when the password matched but the hash was produced by
+// another algorithm or with outdated parameters.
+func (h *Hasher) Verify(encoded, password string) (bool, bool, error) {
+	for _, alg := range h.known {
+		if !alg.Identifies(encoded) {
+			continue
+		}
+
+		if err := h.acquire(); err != nil {
+			return false, false, err
+		}
+		match, err := alg.Verify(encoded, password)
+		h.release()
+
+		if err != nil || !match {
+			return false, false, err
+		}
+
+		needsRehash := alg != h.current || h.current.Outdated(encoded)
+		return true, needsRehash, nil
+	}
+
+	return false, false, ErrUnknownAlgorithm
+}
+
+func (h *Hasher) acquire() error {
+	select {
+	case h.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	timer := time.NewTimer(h.maxWait)
+	defer timer.Stop()
+
+	select {
+	case h.slots <- struct{}{}:
+		return nil
+	case <-timer.C:
+		return ErrBusy
+	}
+}
+
+func (h *Hasher) release() {
+	<-h.slots
+}
+
+func intFromEnv(name string, fallback int) (int, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return n, nil
+}
@@ -0,0 +1,81 @@
+package passwordpolicy
+
+import (
+	"bufio"
+
+	"crypto/sha1"
+
+	"encoding/hex"
+
+	"errors"
+
+	"fmt"
+
+	"io/fs"
+
+	"os"
+
+	"path/filepath"
+
+	"strings"
+)
+
+const rangePrefixLength = 5
+
+// RangeCorpus looks passwords up in a local copy of a k-anonymity breached-password
+// corpus. The directory holds one file per SHA-1 prefix, named after the first five
+// hex characters of the hash (optionally with a .txt extension). Each line contains
+// the remaining 35 characters, optionally followed by ":<count>".
+type RangeCorpus struct {
+	dir string
+}
+
+// NewRangeCorpus creates a corpus reader for dir.
+func NewRangeCorpus(dir string) (*RangeCorpus, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("breached password corpus: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("breached password corpus: %s is not a directory", dir)
+	}
+	return &RangeCorpus{dir: dir}, nil
+}
+
+// IsBreached hashes the password and scans the range file of its prefix for the suffix.
+// Only the prefix is used to pick a file, so the plaintext never leaves memory.
+func (c *RangeCorpus) IsBreached(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:rangePrefixLength], hash[rangePrefixLength:]
+
+	file, err := c.openRange(prefix)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer func() { _ = file.Close() }()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.IndexByte(line, ':'); i >= 0 {
+			line = line[:i]
+		}
+		if strings.EqualFold(line, suffix) {
+			return true, nil
+		}
+	}
+
+	return false, scanner.Err()
+}
+
+func (c *RangeCorpus) openRange(prefix string) (*os.File, error) {
+	file, err := os.Open(filepath.Join(c.dir, prefix))
+	if errors.Is(err, fs.ErrNotExist) {
+		return os.Open(filepath.Join(c.dir, prefix+".txt"))
+	}
+	return file, err
+}
@@ -0,0 +1,218 @@
+// Package passwordpolicy validates passwords against a configurable policy.
+package passwordpolicy
+
+import (
+	"errors"
+
+	"fmt"
+
+	"os"
+
+	"strconv"
+
+	"strings"
+
+	"unicode"
+
+	"unicode/utf8"
+)
+
+// bcryptMaxBytes is the number of bytes bcrypt takes into account; the rest is silently ignored.
+const bcryptMaxBytes = 72
+
+// minPersonalInfoLength is the shortest email or name fragment that is checked for.
+const minPersonalInfoLength = 3
+
+// ErrWeakPassword is matched by every ViolationError.
+var ErrWeakPassword = errors.New("password too weak")
+
+// Violation codes returned to the frontend.
+const (
+	CodeTooShort         = "too_short"
+	CodeTooLong          = "too_long"
+	CodeMissingUpper     = "missing_uppercase"
+	CodeMissingLower     = "missing_lowercase"
+	CodeMissingDigit     = "missing_digit"
+	CodeMissingSymbol    = "missing_symbol"
+	CodeContainsPersonal = "contains_personal_info"
+	CodeBreached         = "breached"
+)
+
+// Violation is a single reason why a password was rejected.
+type Violation struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ViolationError lists every rule a password broke.
+type ViolationError struct {
+	Violations []Violation
+}
+
+func (e *ViolationError) Error() string {
+	return ErrWeakPassword.Error()
+}
+
+// Is makes errors.Is(err, ErrWeakPassword) true for policy violations.
+func (e *ViolationError) Is(target error) bool {
+	return target == ErrWeakPassword
+}
+
+// BreachChecker reports whether a password is known from a data breach.
+type BreachChecker interface {
+	IsBreached(password string) (bool, error)
+}
+
+// Policy describes the rules a password must satisfy.
+type Policy struct {
+	MinLength     int
+	MaxBytes      int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	Breached      BreachChecker
+}
+
+// Default returns the policy used when nothing is configured.
+func Default() *Policy {
+	return &Policy{
+		MinLength: 8,
+		MaxBytes:  bcryptMaxBytes,
+	}
+}
+
+// FromEnv builds the policy from the environment:
+//
+//	PASSWORD_MIN_LENGTH        minimum number of characters
+//	PASSWORD_MAX_BYTES         maximum length in bytes, at most 72
+//	PASSWORD_REQUIRE_UPPER     "true" to require an uppercase letter
+//	PASSWORD_REQUIRE_LOWER     "true" to require a lowercase letter
+//	PASSWORD_REQUIRE_DIGIT     "true" to require a digit
+//	PASSWORD_REQUIRE_SYMBOL    "true" to require a symbol
+//	PASSWORD_BREACHED_CORPUS   directory with SHA-1 prefix range files
+func FromEnv() (*Policy, error) {
+	policy := Default()
+
+	var err error
+	if policy.MinLength, err = intFromEnv("PASSWORD_MIN_LENGTH", policy.MinLength); err != nil {
+		return nil, err
+	}
+	if policy.MaxBytes, err = intFromEnv("PASSWORD_MAX_BYTES", policy.MaxBytes); err != nil {
+		return nil, err
+	}
+	if policy.MaxBytes <= 0 || policy.MaxBytes > bcryptMaxBytes {
+		policy.MaxBytes = bcryptMaxBytes
+	}
+	if policy.MinLength > policy.MaxBytes {
+		return nil, fmt.Errorf("PASSWORD_MIN_LENGTH %d exceeds PASSWORD_MAX_BYTES %d", policy.MinLength, policy.MaxBytes)
+	}
+
+	policy.RequireUpper = os.Getenv("PASSWORD_REQUIRE_UPPER") == "true"
+	policy.RequireLower = os.Getenv("PASSWORD_REQUIRE_LOWER") == "true"
+	policy.RequireDigit = os.Getenv("PASSWORD_REQUIRE_DIGIT") == "true"
+	policy.RequireSymbol = os.Getenv("PASSWORD_REQUIRE_SYMBOL") == "true"
+
+	if dir := os.Getenv("PASSWORD_BREACHED_CORPUS"); dir != "" {
+		corpus, err := NewRangeCorpus(dir)
+		if err != nil {
+			return nil, err
+		}
+		policy.Breached = corpus
+	}
+
+	return policy, nil
+}
+
+// Validate checks the password against the policy. personalInfo holds values the
+// password must not contain, such as the email address or the user's names.
+// It returns a *ViolationError listing every broken rule.
+func (p *Policy) Validate(password string, personalInfo ...string) error {
+	var violations []Violation
+
+	if utf8.RuneCountInString(password) < p.MinLength {
+		violations = append(violations, Violation{CodeTooShort, fmt.Sprintf("must be at least %d characters long", p.MinLength)})
+	}
+	if len(password) > p.MaxBytes {
+		violations = append(violations, Violation{CodeTooLong, fmt.Sprintf("must not be longer than %d bytes", p.MaxBytes)})
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case !unicode.IsLetter(r) && !unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	if p.RequireUpper && !hasUpper {
+		violations = append(violations, Violation{CodeMissingUpper, "must contain an uppercase letter"})
+	}
+	if p.RequireLower && !hasLower {
+		violations = append(violations, Violation{CodeMissingLower, "must contain a lowercase letter"})
+	}
+	if p.RequireDigit && !hasDigit {
+		violations = append(violations, Violation{CodeMissingDigit, "must contain a digit"})
+	}
+	if p.RequireSymbol && !hasSymbol {
+		violations = append(violations, Violation{CodeMissingSymbol, "must contain a symbol"})
+	}
+
+	if containsPersonalInfo(password, personalInfo) {
+		violations = append(violations, Violation{CodeContainsPersonal, "must not contain your email address or name"})
+	}
+
+	if p.Breached != nil {
+		breached, err := p.Breached.IsBreached(password)
+		if err != nil {
+			return err
+		}
+		if breached {
+			violations = append(violations, Violation{CodeBreached, "appears in a known data breach"})
+		}
+	}
+
+	if len(violations) > 0 {
+		return &ViolationError{Violations: violations}
+	}
+	return nil
+}
+
+// containsPersonalInfo checks the password for the given values and, for email
+// addresses, for the local part on its own.
+func containsPersonalInfo(password string, personalInfo []string) bool {
+	lower := strings.ToLower(password)
+
+	for _, value := range personalInfo {
+		value = strings.ToLower(strings.TrimSpace(value))
+		candidates := []string{value}
+		if at := strings.Index(value, "@"); at > 0 {
+			candidates = append(candidates, value[:at])
+		}
+
+		for _, candidate := range candidates {
+			if utf8.RuneCountInString(candidate) >= minPersonalInfoLength && strings.Contains(lower, candidate) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func intFromEnv(name string, fallback int) (int, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return n, nil
+}
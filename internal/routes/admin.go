@@ -1,47 +1,225 @@
 package routes
 
 import (
+	domainuser "carowebapp/core/internal/domain/user"
+
 	"carowebapp/core/internal/features/admin"
 
-	"carowebapp/core/internal/infrastructure/adapter"
+	"carowebapp/core/internal/features/documents"
+
+	"carowebapp/core/internal/features/privacy"
 
 	"carowebapp/core/internal/infrastructure/middleware"
 
 	"github.com/gofiber/fiber/v2"
 
 	"go.uber.org/zap"
-
-	"os"
 )
 
 // RegisterAdminRoutes sets up admin-specific endpoints under /api/v1/admin.
-func RegisterAdminRoutes(app *fiber.App, service *admin.Service, repo admin.Repository, logger *zap.Logger) {
-	userProvider := &adapter.AdminUserProvider{Repo: repo}
-
+// Access is granted by the permissions carried in the access token.
+func RegisterAdminRoutes(app *fiber.App, service *admin.Service, privacyService *privacy.Service, documentService *documents.Service, logger *zap.Logger, requireAuth fiber.Handler) {
 	handler := &admin.Handler{
-		Service:      service,
-		Logger:       logger,
-		UserProvider: userProvider,
+		Service: service,
+		Logger:  logger,
 	}
 
+	privacyHandler := privacy.NewHandler(privacyService, logger)
+	documentHandler := documents.NewHandler(documentService, logger)
+
 	adminGroup := app.Group("/api/v1/admin")
 
 	adminGroup.Use(
-		middleware.JWTMiddleware(os.Getenv("JWT_SECRET")),
-		middleware.RequireAdmin(userProvider, logger),
+		requireAuth,
+		middleware.RequireSecondFactor(logger, domainuser.RoleAdmin),
 	)
 
 	adminGroup.Post("/approve-user",
+		middleware.RequirePermission(logger, domainuser.PermissionUsersModerate),
 		middleware.ValidateBody[admin.ApproveUserRequest](),
 		handler.ApproveUser,
 	)
 
 	adminGroup.Post("/reject-user",
+		middleware.RequirePermission(logger, domainuser.PermissionUsersModerate),
 		middleware.ValidateBody[admin.RejectUserRequest](),
 		handler.RejectUser,
 	)
 
-	adminGroup.Get("/pending-users", handler.ListPendingUsers)
+	adminGroup.Post("/users/:id/unlock",
+		middleware.RequirePermission(logger, domainuser.PermissionUsersModerate),
+		handler.UnlockUser,
+	)
+
+	adminGroup.Get("/users/:id/export",
+		middleware.RequirePermission(logger, domainuser.PermissionUsersPrivacy),
+		privacyHandler.AdminExport,
+	)
+
+	adminGroup.Post("/users/:id/deletion",
+		middleware.RequirePermission(logger, domainuser.PermissionUsersPrivacy),
+		middleware.ValidateBody[privacy.AdminDeleteAccountRequest](),
+		privacyHandler.AdminDeleteAccount,
+	)
+
+	adminGroup.Delete("/users/:id/deletion",
+		middleware.RequirePermission(logger, domainuser.PermissionUsersPrivacy),
+		privacyHandler.AdminCancelDeletion,
+	)
+
+	adminGroup.Get("/users",
+		middleware.RequirePermission(logger, domainuser.PermissionUsersRead),
+		handler.ListUsers,
+	)
+
+	adminGroup.Get("/users/:id",
+		middleware.RequirePermission(logger, domainuser.PermissionUsersRead),
+		handler.GetUser,
+	)
+
+	adminGroup.Get("/users/:id/notes",
+		middleware.RequirePermission(logger, domainuser.PermissionUsersRead),
+		handler.ListNotes,
+	)
 
-	adminGroup.Get("/user-profile/:id", handler.GetUserProfile)
+	adminGroup.Post("/users/:id/notes",
+		middleware.RequirePermission(logger, domainuser.PermissionUsersNotes),
+		middleware.ValidateBody[admin.AddNoteRequest](),
+		handler.AddNote,
+	)
+
+	adminGroup.Delete("/users/:id/notes/:noteId",
+		middleware.RequirePermission(logger, domainuser.PermissionUsersNotes),
+		handler.DeleteNote,
+	)
+
+	adminGroup.Put("/users/:id/tags",
+		middleware.RequirePermission(logger, domainuser.PermissionUsersNotes),
+		middleware.ValidateBody[admin.SetTagsRequest](),
+		handler.SetTags,
+	)
+
+	adminGroup.Get("/tags",
+		middleware.RequirePermission(logger, domainuser.PermissionUsersRead),
+		handler.ListTags,
+	)
+
+	adminGroup.Get("/users/:id/documents",
+		middleware.RequirePermission(logger, domainuser.PermissionUsersModerate),
+		documentHandler.AdminList,
+	)
+
+	adminGroup.Get("/users/:id/documents/:documentId",
+		middleware.RequirePermission(logger, domainuser.PermissionUsersModerate),
+		documentHandler.AdminDownload,
+	)
+
+	adminGroup.Put("/users/:id/documents/:documentId/review",
+		middleware.RequirePermission(logger, domainuser.PermissionUsersModerate),
+		middleware.ValidateBody[documents.ReviewDocumentRequest](),
+		documentHandler.Review,
+	)
+
+	adminGroup.Put("/users/:id/role",
+		middleware.RequirePermission(logger, domainuser.PermissionUsersManage),
+		middleware.ValidateBody[admin.SetUserRoleRequest](),
+		handler.SetUserRole,
+	)
+
+	adminGroup.Put("/users/:id/status",
+		middleware.RequirePermission(logger, domainuser.PermissionUsersManage),
+		middleware.ValidateBody[admin.SetUserStatusRequest](),
+		handler.SetUserStatus,
+	)
+
+	adminGroup.Post("/users/:id/suspension",
+		middleware.RequirePermission(logger, domainuser.PermissionUsersManage),
+		middleware.ValidateBody[admin.SuspendUserRequest](),
+		handler.SuspendUser,
+	)
+
+	adminGroup.Post("/users/:id/ban",
+		middleware.RequirePermission(logger, domainuser.PermissionUsersManage),
+		middleware.ValidateBody[admin.BanUserRequest](),
+		handler.BanUser,
+	)
+
+	adminGroup.Post("/users/:id/reactivate",
+		middleware.RequirePermission(logger, domainuser.PermissionUsersManage),
+		handler.ReactivateUser,
+	)
+
+	adminGroup.Get("/stats",
+		middleware.RequirePermission(logger, domainuser.PermissionStatsRead),
+		handler.GetStats,
+	)
+
+	adminGroup.Get("/audit-log",
+		middleware.RequirePermission(logger, domainuser.PermissionAuditRead),
+		handler.ListAuditLog,
+	)
+
+	adminGroup.Post("/moderation/claim",
+		middleware.RequirePermission(logger, domainuser.PermissionUsersModerate),
+		handler.ClaimNext,
+	)
+
+	adminGroup.Post("/moderation/approve",
+		middleware.RequirePermission(logger, domainuser.PermissionUsersModerate),
+		middleware.ValidateBody[admin.BulkApproveRequest](),
+		handler.BulkApprove,
+	)
+
+	adminGroup.Post("/moderation/reject",
+		middleware.RequirePermission(logger, domainuser.PermissionUsersModerate),
+		middleware.ValidateBody[admin.BulkRejectRequest](),
+		handler.BulkReject,
+	)
+
+	adminGroup.Post("/users/:id/claim",
+		middleware.RequirePermission(logger, domainuser.PermissionUsersModerate),
+		handler.ClaimUser,
+	)
+
+	adminGroup.Delete("/users/:id/claim",
+		middleware.RequirePermission(logger, domainuser.PermissionUsersModerate),
+		handler.ReleaseClaim,
+	)
+
+	adminGroup.Get("/pending-users",
+		middleware.RequirePermission(logger, domainuser.PermissionUsersRead),
+		handler.ListPendingUsers,
+	)
+
+	adminGroup.Get("/user-profile/:id",
+		middleware.RequirePermission(logger, domainuser.PermissionUsersRead),
+		handler.GetUserProfile,
+	)
+
+	adminGroup.Get("/rejection-reasons",
+		middleware.RequirePermission(logger, domainuser.PermissionUsersModerate),
+		handler.ListRejectionReasons,
+	)
+
+	adminGroup.Get("/rejection-reasons/stats",
+		middleware.RequirePermission(logger, domainuser.PermissionUsersModerate),
+		handler.RejectionReasonStats,
+	)
+
+	adminGroup.Put("/rejection-reasons/:code",
+		middleware.RequirePermission(logger, domainuser.PermissionReasonsManage),
+		middleware.ValidateBody[admin.SaveRejectionReasonRequest](),
+		handler.SaveRejectionReason,
+	)
+
+	adminGroup.Get("/roles",
+		middleware.RequirePermission(logger, domainuser.PermissionRolesManage),
+		handler.ListRolePermissions,
+	)
+
+	adminGroup.Put("/roles/:role/permissions",
+		middleware.RequirePermission(logger, domainuser.PermissionRolesManage),
+		middleware.ValidateBody[admin.SetRolePermissionsRequest](),
+		handler.SetRolePermissions,
+	)
 }
@@ -11,20 +11,34 @@ import (
 
 	"go.uber.org/zap"
 
-	"os"
-
 	"time"
 )
 
 // RegisterAuthRoutes sets up all auth-related routes under /api/v1/auth and /api/v1/authenticated.
-func RegisterAuthRoutes(app *fiber.App, service *auth.Service, logger *zap.Logger, redis *redis.Client) {
+// requireAuth is the JWT middleware shared by all authenticated route groups.
+func RegisterAuthRoutes(app *fiber.App, service *auth.Service, logger *zap.Logger, redis *redis.Client, requireAuth fiber.Handler) {
 	handler := auth.NewHandler(service, logger)
 
 	// --- Public routes: /api/v1/auth
 	public := app.Group("/api/v1/auth")
 
+	// Only failed logins count against an IP. Single accounts are protected by the
+	// per-account lockout in the service, so the limit can stay generous for shared IPs.
 	loginLimiter := middleware.RateLimitByRedis(middleware.RateLimiterConfig{
+		RedisClient:  redis,
+		Prefix:       "login",
+		MaxAttempts:  20,
+		Window:       15 * time.Minute,
+		Logger:       logger,
+		FailuresOnly: true,
+		KeyGenerator: func(c *fiber.Ctx) string {
+			return c.IP()
+		},
+	})
+
+	registerLimiter := middleware.RateLimitByRedis(middleware.RateLimiterConfig{
 		RedisClient: redis,
+		Prefix:      "register",
 		MaxAttempts: 5,
 		Window:      15 * time.Minute,
 		Logger:      logger,
@@ -39,8 +53,31 @@ func RegisterAuthRoutes(app *fiber.App, service *auth.Service, logger *zap.Logge
 		handler.Login,
 	)
 
-	public.Post("/register",
+	public.Post("/login/2fa",
+		loginLimiter,
+		middleware.ValidateBody[auth.LoginTwoFactorRequest](),
+		handler.LoginTwoFactor,
+	)
+
+	public.Post("/magic-link",
+		middleware.ValidateBody[auth.MagicLinkRequest](),
+		middleware.MagicLinkLimiter(redis, logger),
+		handler.RequestMagicLink,
+	)
+
+	public.Post("/magic-link/login",
 		loginLimiter,
+		middleware.ValidateBody[auth.MagicLinkLoginRequest](),
+		handler.LoginWithMagicLink,
+	)
+
+	public.Post("/refresh",
+		middleware.ValidateBody[auth.RefreshRequest](),
+		handler.Refresh,
+	)
+
+	public.Post("/register",
+		registerLimiter,
 		middleware.ValidateBody[auth.RegisterRequest](),
 		handler.Register,
 	)
@@ -65,11 +102,23 @@ func RegisterAuthRoutes(app *fiber.App, service *auth.Service, logger *zap.Logge
 		handler.ConfirmEmail,
 	)
 
-	// --- Protected routes: /api/v1/auth
-	protected := app.Group("/api/v1")
-	protected.Use(middleware.JWTMiddleware(os.Getenv("JWT_SECRET")))
+	public.Get("/unlock",
+		handler.UnlockAccount,
+	)
+
+	public.Get("/change-email/confirm",
+		handler.ConfirmEmailChange,
+	)
+
+	public.Get("/change-email/undo",
+		handler.UndoEmailChange,
+	)
 
-	authProtected := protected.Group("/auth")
+	// --- Protected routes: /api/v1/auth
+	// The middleware is scoped to /api/v1/auth, so public routes of other features
+	// registered later are not caught by it.
+	authProtected := app.Group("/api/v1/auth")
+	authProtected.Use(requireAuth)
 
 	authProtected.Post("/resend-confirmation",
 		handler.ResendConfirmation,
@@ -79,4 +128,51 @@ func RegisterAuthRoutes(app *fiber.App, service *auth.Service, logger *zap.Logge
 		middleware.ValidateBody[auth.CreateProfileRequest](),
 		handler.CreateProfile,
 	)
+
+	authProtected.Post("/change-password",
+		loginLimiter,
+		middleware.ValidateBody[auth.ChangePasswordRequest](),
+		handler.ChangePassword,
+	)
+
+	authProtected.Post("/change-email",
+		loginLimiter,
+		middleware.ValidateBody[auth.ChangeEmailRequest](),
+		handler.RequestEmailChange,
+	)
+
+	authProtected.Get("/sessions",
+		handler.ListSessions,
+	)
+
+	authProtected.Delete("/sessions/:id",
+		handler.RevokeSession,
+	)
+
+	authProtected.Post("/logout",
+		handler.Logout,
+	)
+
+	authProtected.Post("/logout-all",
+		handler.LogoutAll,
+	)
+
+	authProtected.Post("/2fa/setup",
+		handler.SetupTwoFactor,
+	)
+
+	authProtected.Post("/2fa/confirm",
+		middleware.ValidateBody[auth.TwoFactorCodeRequest](),
+		handler.ConfirmTwoFactor,
+	)
+
+	authProtected.Post("/2fa/disable",
+		middleware.ValidateBody[auth.DisableTwoFactorRequest](),
+		handler.DisableTwoFactor,
+	)
+
+	authProtected.Post("/2fa/recovery-codes",
+		middleware.ValidateBody[auth.TwoFactorCodeRequest](),
+		handler.RegenerateRecoveryCodes,
+	)
 }
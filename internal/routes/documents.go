@@ -0,0 +1,59 @@
+package routes
+
+import (
+	"carowebapp/core/internal/features/documents"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/valyala/fasthttp"
+
+	"go.uber.org/zap"
+
+	"strings"
+)
+
+// documentUploadPath is the one route that accepts bodies above the default body limit.
+const documentUploadPath = "/api/v1/me/documents"
+
+// documentUploadBodyLimit leaves room for the multipart framing around the largest document.
+const documentUploadBodyLimit = documents.MaxSize + 1<<20
+
+// RegisterDocumentRoutes sets up the upload of verification documents for the current user
+// on the /api/v1/me group. The review endpoints are registered with the admin routes.
+func RegisterDocumentRoutes(app *fiber.App, me fiber.Router, service *documents.Service, logger *zap.Logger) {
+	handler := documents.NewHandler(service, logger)
+
+	// The body limit is enforced by the server before routing, so the upload gets its
+	// larger limit when the request headers arrive. All other routes keep the default.
+	app.Server().HeaderReceived = func(header *fasthttp.RequestHeader) fasthttp.RequestConfig {
+		if isDocumentUpload(header) {
+			return fasthttp.RequestConfig{MaxRequestBodySize: documentUploadBodyLimit}
+		}
+		return fasthttp.RequestConfig{}
+	}
+
+	me.Get("/documents",
+		handler.List,
+	)
+
+	me.Post("/documents",
+		handler.Upload,
+	)
+
+	me.Get("/documents/:documentId",
+		handler.Download,
+	)
+
+	me.Delete("/documents/:documentId",
+		handler.Delete,
+	)
+}
+
+// isDocumentUpload reports whether the request headers belong to a document upload.
+func isDocumentUpload(header *fasthttp.RequestHeader) bool {
+	if !header.IsPost() {
+		return false
+	}
+	path, _, _ := strings.Cut(string(header.RequestURI()), "?")
+	return strings.TrimSuffix(path, "/") == documentUploadPath
+}
@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"carowebapp/core/internal/infrastructure/jwtauth"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RegisterJWKSRoutes publishes the public JWT verification keys for other services.
+func RegisterJWKSRoutes(app *fiber.App, keys *jwtauth.KeySet) {
+	app.Get("/.well-known/jwks.json", func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+		return c.JSON(keys.JWKS())
+	})
+}
@@ -0,0 +1,43 @@
+package routes
+
+import (
+	"carowebapp/core/internal/features/auth"
+
+	"carowebapp/core/internal/infrastructure/middleware"
+
+	"github.com/gofiber/fiber/v2"
+
+	"go.uber.org/zap"
+)
+
+// NewMeGroup returns the /api/v1/me group for endpoints acting on the current user.
+// Features register their routes on it so authentication runs once per request.
+func NewMeGroup(app *fiber.App, requireAuth fiber.Handler) fiber.Router {
+	me := app.Group("/api/v1/me")
+	me.Use(requireAuth)
+	return me
+}
+
+// RegisterProfileRoutes sets up the account overview, profile editing and the resubmission
+// of a rejected registration for the current user.
+func RegisterProfileRoutes(me fiber.Router, service *auth.Service, logger *zap.Logger) {
+	handler := auth.NewHandler(service, logger)
+
+	me.Get("/",
+		handler.Me,
+	)
+
+	me.Patch("/profile",
+		middleware.ValidateBody[auth.UpdateProfileRequest](),
+		handler.UpdateProfile,
+	)
+
+	me.Get("/rejection",
+		handler.GetRejection,
+	)
+
+	me.Post("/resubmission",
+		middleware.ValidateBody[auth.ResubmitProfileRequest](),
+		handler.Resubmit,
+	)
+}
@@ -0,0 +1,61 @@
+package routes
+
+import (
+	"carowebapp/core/internal/features/privacy"
+
+	"carowebapp/core/internal/infrastructure/middleware"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/gofiber/fiber/v2"
+
+	"go.uber.org/zap"
+
+	"time"
+)
+
+// RegisterPrivacyRoutes sets up the data export and account deletion endpoints of
+// the current user on the /api/v1/me group and the public confirmation link under /api/v1/privacy.
+// The admin variants are registered with the admin routes.
+func RegisterPrivacyRoutes(app *fiber.App, me fiber.Router, service *privacy.Service, logger *zap.Logger, redis *redis.Client) {
+	handler := privacy.NewHandler(service, logger)
+
+	// Deleting an account needs the password, so failed attempts are limited like logins.
+	deletionLimiter := middleware.RateLimitByRedis(middleware.RateLimiterConfig{
+		RedisClient:  redis,
+		Prefix:       "deletion",
+		MaxAttempts:  10,
+		Window:       15 * time.Minute,
+		Logger:       logger,
+		FailuresOnly: true,
+		KeyGenerator: func(c *fiber.Ctx) string {
+			return c.IP()
+		},
+	})
+
+	// --- Public routes: /api/v1/privacy
+	public := app.Group("/api/v1/privacy")
+
+	public.Get("/deletion/confirm",
+		handler.ConfirmDeletion,
+	)
+
+	// --- Protected routes: /api/v1/me
+	me.Get("/export",
+		handler.Export,
+	)
+
+	me.Get("/deletion",
+		handler.GetDeletion,
+	)
+
+	me.Post("/deletion",
+		deletionLimiter,
+		middleware.ValidateBody[privacy.DeleteAccountRequest](),
+		handler.RequestDeletion,
+	)
+
+	me.Delete("/deletion",
+		handler.CancelDeletion,
+	)
+}
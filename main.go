@@ -13,11 +13,20 @@ import (
 	"carowebapp/core/cmd"
 	"carowebapp/core/internal/features/admin"
 	"carowebapp/core/internal/features/auth"
+	"carowebapp/core/internal/features/documents"
+	"carowebapp/core/internal/features/onetimetoken"
+	"carowebapp/core/internal/features/privacy"
+	"carowebapp/core/internal/infrastructure/adapter"
 	database "carowebapp/core/internal/infrastructure/db"
 	"carowebapp/core/internal/infrastructure/db/migrations"
 	"carowebapp/core/internal/infrastructure/email"
+	"carowebapp/core/internal/infrastructure/jwtauth"
 	"carowebapp/core/internal/infrastructure/logger"
 	"carowebapp/core/internal/infrastructure/middleware"
+	"carowebapp/core/internal/infrastructure/storage"
+	"carowebapp/core/internal/pkg/passwordhash"
+
+	"carowebapp/core/internal/pkg/passwordpolicy"
 	"carowebapp/core/internal/routes"
 )
 
@@ -30,6 +39,8 @@ var rootCmd = &cobra.Command{
 // initCLI initializes the CLI commands using Cobra.
 func initCLI() {
 	rootCmd.AddCommand(cmd.CreateAdminCmd)
+	rootCmd.AddCommand(cmd.GDPRCmd)
+	rootCmd.AddCommand(cmd.AuditCmd)
 }
 
 // initRedis initializes a Redis client using the specified configuration.
@@ -67,21 +78,67 @@ func runServer() {
 	app.Use(middleware.TraceID())
 	app.Use(middleware.HTTPLogger(logger.Log))
 
+	jwtKeys, err := jwtauth.LoadFromEnv()
+	if err != nil {
+		logger.Log.Fatal("failed to load JWT keys", zap.Error(err))
+	}
+
+	passwordPolicy, err := passwordpolicy.FromEnv()
+	if err != nil {
+		logger.Log.Fatal("invalid password policy", zap.Error(err))
+	}
+
+	passwordHasher, err := passwordhash.FromEnv()
+	if err != nil {
+		logger.Log.Fatal("invalid password hashing settings", zap.Error(err))
+	}
+
+	gracePeriod, err := privacy.GracePeriodFromEnv()
+	if err != nil {
+		logger.Log.Fatal("invalid account deletion settings", zap.Error(err))
+	}
+
+	documentStore, err := storage.LocalFromEnv()
+	if err != nil {
+		logger.Log.Fatal("failed to open document storage", zap.Error(err))
+	}
+
+	documentRetention, err := documents.RetentionFromEnv()
+	if err != nil {
+		logger.Log.Fatal("invalid document retention", zap.Error(err))
+	}
+
 	db := database.InitDB()
 	migrations.RunMigrations()
 
 	sender := email.NewMailer(logger.Log)
 
+	tokenService := onetimetoken.NewService(onetimetoken.NewSQLXRepository(db))
+
 	authRepo := auth.NewSQLXRepository(db)
-	authService := auth.NewService(authRepo, sender)
+	authService := auth.NewService(authRepo, sender, jwtKeys, passwordPolicy, passwordHasher, tokenService)
+
+	adminService := admin.NewService(admin.NewSQLXRepository(db), logger.Log, sender)
+	go adminService.RunReactivator(context.Background(), time.Minute)
+	go adminService.Mails.Run(context.Background(), 2)
 
-	adminRepo := admin.NewSQLXRepository(db)
-	adminService := admin.NewService(adminRepo, logger.Log, sender)
+	documentService := documents.NewService(documents.NewSQLXRepository(db), documentStore, documentRetention, logger.Log)
+	go documentService.RunPurger(context.Background(), time.Hour)
+
+	privacyService := privacy.NewService(privacy.NewSQLXRepository(db), sender, tokenService, authService, documentService, gracePeriod, logger.Log)
+	go privacyService.RunPurger(context.Background(), time.Hour)
 
 	redisClient := initRedis()
 
-	routes.RegisterAuthRoutes(app, authService, logger.Log, redisClient)
-	routes.RegisterAdminRoutes(app, adminService, adminRepo, logger.Log)
+	requireAuth := middleware.JWTMiddleware(jwtKeys, &adapter.AuthSessionChecker{Repo: authRepo})
+
+	routes.RegisterJWKSRoutes(app, jwtKeys)
+	routes.RegisterAuthRoutes(app, authService, logger.Log, redisClient, requireAuth)
+	me := routes.NewMeGroup(app, requireAuth)
+	routes.RegisterProfileRoutes(me, authService, logger.Log)
+	routes.RegisterPrivacyRoutes(app, me, privacyService, logger.Log, redisClient)
+	routes.RegisterDocumentRoutes(app, me, documentService, logger.Log)
+	routes.RegisterAdminRoutes(app, adminService, privacyService, documentService, logger.Log, requireAuth)
 
 	if err := app.Listen(":8080"); err != nil {
 		logger.Log.Fatal("Failed to start server")
@@ -0,0 +1,1128 @@
+package unit
+
+import (
+	"bytes"
+
+	domainuser "carowebapp/core/internal/domain/user"
+
+	"carowebapp/core/internal/features/admin"
+
+	"encoding/json"
+
+	"fmt"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/stretchr/testify/require"
+
+	"go.uber.org/zap"
+
+	"strings"
+
+	"testing"
+
+	"time"
+)
+
+type MockAdminRepo struct {
+	mock.Mock
+}
+
+func (m *MockAdminRepo) GetUserByID(userID string) (*admin.User, error) {
+	args := m.Called(userID)
+	return args.Get(0).(*admin.User), args.Error(1)
+}
+
+func (m *MockAdminRepo) GetUsersByIDs(userIDs []string) ([]admin.User, error) {
+	args := m.Called(userIDs)
+	return args.Get(0).([]admin.User), args.Error(1)
+}
+
+func (m *MockAdminRepo) ApplyDecisions(decisions []admin.Decision) ([]error, error) {
+	args := m.Called(decisions)
+	return args.Get(0).([]error), args.Error(1)
+}
+
+func (m *MockAdminRepo) ChangeStatus(change *domainuser.StatusChange, entry domainuser.AuditEntry) error {
+	return m.Called(change, entry).Error(0)
+}
+
+func (m *MockAdminRepo) RejectUser(change *domainuser.StatusChange, reasons, codes map[string]string, entry domainuser.AuditEntry) error {
+	return m.Called(change, reasons, codes, entry).Error(0)
+}
+
+func (m *MockAdminRepo) ListRejectionReasons(includeInactive bool) ([]domainuser.RejectionReason, error) {
+	args := m.Called(includeInactive)
+	return args.Get(0).([]domainuser.RejectionReason), args.Error(1)
+}
+
+func (m *MockAdminRepo) GetRejectionReasons(codes []string) ([]domainuser.RejectionReason, error) {
+	args := m.Called(codes)
+	return args.Get(0).([]domainuser.RejectionReason), args.Error(1)
+}
+
+func (m *MockAdminRepo) SaveRejectionReason(reason *domainuser.RejectionReason, entry domainuser.AuditEntry) error {
+	return m.Called(reason, entry).Error(0)
+}
+
+func (m *MockAdminRepo) RejectionReasonStats(from, to *time.Time) ([]admin.RejectionReasonCount, error) {
+	args := m.Called(from, to)
+	return args.Get(0).([]admin.RejectionReasonCount), args.Error(1)
+}
+
+func (m *MockAdminRepo) ListUsers(filter admin.UserFilter) (*admin.UserPage, error) {
+	args := m.Called(filter)
+	return args.Get(0).(*admin.UserPage), args.Error(1)
+}
+
+func (m *MockAdminRepo) GetUserDetail(userID string) (*admin.UserDetail, error) {
+	args := m.Called(userID)
+	return args.Get(0).(*admin.UserDetail), args.Error(1)
+}
+
+func (m *MockAdminRepo) ListStatusChanges(userID string) ([]domainuser.StatusChange, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]domainuser.StatusChange), args.Error(1)
+}
+
+func (m *MockAdminRepo) SetUserRole(userID, role string, event domainuser.SecurityEvent, entry domainuser.AuditEntry) error {
+	return m.Called(userID, role, event, entry).Error(0)
+}
+
+func (m *MockAdminRepo) GetUserProfile(userID string) (*domainuser.Profile, error) {
+	args := m.Called(userID)
+	return args.Get(0).(*domainuser.Profile), args.Error(1)
+}
+
+func (m *MockAdminRepo) ListProfileChanges(userID string) ([]domainuser.ProfileChange, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]domainuser.ProfileChange), args.Error(1)
+}
+
+func (m *MockAdminRepo) ListRejections(userID string) ([]domainuser.Rejection, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]domainuser.Rejection), args.Error(1)
+}
+
+func (m *MockAdminRepo) ListRolePermissions() (map[string][]string, error) {
+	args := m.Called()
+	return args.Get(0).(map[string][]string), args.Error(1)
+}
+
+func (m *MockAdminRepo) SetRolePermissions(role string, permissions []string, entry domainuser.AuditEntry) error {
+	return m.Called(role, permissions, entry).Error(0)
+}
+
+func (m *MockAdminRepo) UnlockUser(userID string, event domainuser.SecurityEvent, entry domainuser.AuditEntry) (bool, error) {
+	args := m.Called(userID, event, entry)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAdminRepo) ListAuditEntries(filter admin.AuditFilter) (*admin.AuditPage, error) {
+	args := m.Called(filter)
+	return args.Get(0).(*admin.AuditPage), args.Error(1)
+}
+
+func (m *MockAdminRepo) ClaimNext(adminID string, lease time.Duration) (*admin.Claim, error) {
+	args := m.Called(adminID, lease)
+	return args.Get(0).(*admin.Claim), args.Error(1)
+}
+
+func (m *MockAdminRepo) ClaimUser(userID, adminID string, lease time.Duration) (*admin.Claim, error) {
+	args := m.Called(userID, adminID, lease)
+	return args.Get(0).(*admin.Claim), args.Error(1)
+}
+
+func (m *MockAdminRepo) ReleaseClaim(userID, adminID string) (bool, error) {
+	args := m.Called(userID, adminID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAdminRepo) GetStats(from, to time.Time) (*admin.Stats, error) {
+	args := m.Called(from, to)
+	return args.Get(0).(*admin.Stats), args.Error(1)
+}
+
+func (m *MockAdminRepo) ListNotes(userID string) ([]admin.Note, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]admin.Note), args.Error(1)
+}
+
+func (m *MockAdminRepo) AddNote(note *admin.Note, entry domainuser.AuditEntry) error {
+	return m.Called(note, entry).Error(0)
+}
+
+func (m *MockAdminRepo) DeleteNote(userID, noteID string, entry domainuser.AuditEntry) (bool, error) {
+	args := m.Called(userID, noteID, entry)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAdminRepo) ListTags(userID string) ([]string, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockAdminRepo) SetTags(userID string, tags []string, createdBy string, entry domainuser.AuditEntry) error {
+	return m.Called(userID, tags, createdBy, entry).Error(0)
+}
+
+func (m *MockAdminRepo) ListAllTags() ([]admin.TagCount, error) {
+	args := m.Called()
+	return args.Get(0).([]admin.TagCount), args.Error(1)
+}
+
+func (m *MockAdminRepo) GetOpenBlock(userID string) (*domainuser.AccountBlock, error) {
+	args := m.Called(userID)
+	return args.Get(0).(*domainuser.AccountBlock), args.Error(1)
+}
+
+func (m *MockAdminRepo) BlockUser(block *domainuser.AccountBlock, change *domainuser.StatusChange, event domainuser.SecurityEvent, entry domainuser.AuditEntry) error {
+	return m.Called(block, change, event, entry).Error(0)
+}
+
+func (m *MockAdminRepo) LiftBlock(block *domainuser.AccountBlock, change *domainuser.StatusChange, event domainuser.SecurityEvent, entry domainuser.AuditEntry) error {
+	return m.Called(block, change, event, entry).Error(0)
+}
+
+func (m *MockAdminRepo) ListExpiredSuspensions(now time.Time) ([]domainuser.AccountBlock, error) {
+	args := m.Called(now)
+	return args.Get(0).([]domainuser.AccountBlock), args.Error(1)
+}
+
+type MockSender struct {
+	mock.Mock
+}
+
+func (m *MockSender) SendMail(to string, subject string, body string) error {
+	return m.Called(to, subject, body).Error(0)
+}
+
+func (m *MockSender) SendConfirmation(to string, token string) error {
+	return m.Called(to, token).Error(0)
+}
+
+func (m *MockSender) SendResetPasswordLink(to string, token string) error {
+	return m.Called(to, token).Error(0)
+}
+
+func (m *MockSender) SendApprovalNotification(email string) error {
+	return m.Called(email).Error(0)
+}
+
+func (m *MockSender) SendRejectionNotification(email, language string, errors map[string]string) error {
+	return m.Called(email, language, errors).Error(0)
+}
+
+func (m *MockSender) SendAccountLocked(to string, token string, until time.Time) error {
+	return m.Called(to, token, until).Error(0)
+}
+
+func (m *MockSender) SendMagicLink(to string, token string) error {
+	return m.Called(to, token).Error(0)
+}
+
+func (m *MockSender) SendEmailChangeConfirmation(to string, token string) error {
+	return m.Called(to, token).Error(0)
+}
+
+func (m *MockSender) SendEmailChangedAlert(to string, newEmail string, undoToken string) error {
+	return m.Called(to, newEmail, undoToken).Error(0)
+}
+
+func (m *MockSender) SendAccountDeletionConfirmation(to string, token string) error {
+	return m.Called(to, token).Error(0)
+}
+
+func (m *MockSender) SendAccountDeletionScheduled(to string, scheduledFor time.Time) error {
+	return m.Called(to, scheduledFor).Error(0)
+}
+
+func (m *MockSender) SendAccountDeleted(to string) error {
+	return m.Called(to).Error(0)
+}
+
+func (m *MockSender) SendAccountSuspended(to string, reason string, until time.Time) error {
+	return m.Called(to, reason, until).Error(0)
+}
+
+func (m *MockSender) SendAccountBanned(to string, reason string) error {
+	return m.Called(to, reason).Error(0)
+}
+
+func (m *MockSender) SendAccountReactivated(to string) error {
+	return m.Called(to).Error(0)
+}
+
+func newService(repo *MockAdminRepo, sender *MockSender) *admin.Service {
+	return admin.NewService(repo, zap.NewNop(), sender)
+}
+
+var testAdmin = domainuser.Actor{ID: "admin-id", IP: "127.0.0.1", TraceID: "trace-id"}
+
+func pendingUser() *admin.User {
+	return &admin.User{
+		ID:             "user-id",
+		Email:          "user@example.com",
+		Status:         domainuser.StatusPending,
+		Role:           domainuser.RoleHomeowner,
+		EmailConfirmed: true,
+		HasProfile:     true,
+		Language:       domainuser.LanguageDE,
+	}
+}
+
+func catalogReason(code, field string) domainuser.RejectionReason {
+	return domainuser.RejectionReason{
+		Code:   code,
+		Field:  field,
+		TextDE: "Bitte korrigieren: " + field,
+		TextEN: "Please correct: " + field,
+		Active: true,
+	}
+}
+
+// TestApproveUser_RequiresConfirmedEmail verifies that the approval guard is enforced.
+func TestApproveUser_RequiresConfirmedEmail(t *testing.T) {
+	repo := new(MockAdminRepo)
+	sender := new(MockSender)
+	svc := newService(repo, sender)
+
+	user := pendingUser()
+	user.EmailConfirmed = false
+	repo.On("GetUserByID", "user-id").Return(user, nil)
+
+	err := svc.ApproveUser(testAdmin, "user-id")
+
+	assert.ErrorIs(t, err, domainuser.ErrEmailNotConfirmed)
+	repo.AssertNotCalled(t, "ChangeStatus", mock.Anything, mock.Anything)
+	sender.AssertNotCalled(t, "SendApprovalNotification", mock.Anything)
+}
+
+// TestRejectUser_CanonicalReasons verifies that reasons are keyed by profile field, stored and sent
+// in the user's language, and the admin is recorded.
+func TestRejectUser_CanonicalReasons(t *testing.T) {
+	repo := new(MockAdminRepo)
+	sender := new(MockSender)
+	svc := newService(repo, sender)
+
+	texts := map[string]string{"last_name": "Bitte korrigieren: last_name"}
+	codes := map[string]string{"last_name": "last_name_illegible"}
+	repo.On("GetRejectionReasons", []string{"last_name_illegible"}).Return([]domainuser.RejectionReason{catalogReason("last_name_illegible", "last_name")}, nil)
+	repo.On("GetUserByID", "user-id").Return(pendingUser(), nil)
+	repo.On("RejectUser", mock.AnythingOfType("*user.StatusChange"), texts, codes, mock.AnythingOfType("user.AuditEntry")).Return(nil)
+	sender.On("SendRejectionNotification", "user@example.com", domainuser.LanguageDE, texts).Return(nil)
+
+	err := svc.RejectUser(testAdmin, "user-id", map[string]string{"lastName": "last_name_illegible"})
+
+	require.NoError(t, err)
+	change := repo.Calls[2].Arguments.Get(0).(*domainuser.StatusChange)
+	assert.Equal(t, domainuser.StatusRejected, change.To)
+	assert.Equal(t, "admin-id", *change.ActorID)
+}
+
+// TestRejectUser_UnknownField verifies that reasons must name profile fields.
+func TestRejectUser_UnknownField(t *testing.T) {
+	repo := new(MockAdminRepo)
+	svc := newService(repo, new(MockSender))
+
+	err := svc.RejectUser(testAdmin, "user-id", map[string]string{"shoe_size": "too_big"})
+
+	assert.ErrorIs(t, err, admin.ErrUnknownProfileField)
+	repo.AssertNotCalled(t, "GetRejectionReasons", mock.Anything)
+	repo.AssertNotCalled(t, "GetUserByID", mock.Anything)
+}
+
+// TestRejectUser_NeedsReasons verifies that a rejection without any reason is refused, since
+// the user could not resubmit it.
+func TestRejectUser_NeedsReasons(t *testing.T) {
+	repo := new(MockAdminRepo)
+	svc := newService(repo, new(MockSender))
+
+	err := svc.RejectUser(testAdmin, "user-id", map[string]string{})
+	assert.ErrorIs(t, err, admin.ErrNoRejectionReasons)
+
+	_, err = svc.BulkReject(testAdmin, []string{"user-id"}, nil, false)
+	assert.ErrorIs(t, err, admin.ErrNoRejectionReasons)
+
+	repo.AssertNotCalled(t, "GetUserByID", mock.Anything)
+	repo.AssertNotCalled(t, "GetUsersByIDs", mock.Anything)
+}
+
+// TestRejectUser_ValidatesCatalog verifies that codes must exist, be active and belong to their field.
+func TestRejectUser_ValidatesCatalog(t *testing.T) {
+	repo := new(MockAdminRepo)
+	svc := newService(repo, new(MockSender))
+
+	retired := catalogReason("city_retired", "city")
+	retired.Active = false
+	repo.On("GetRejectionReasons", []string{"no_such_reason"}).Return([]domainuser.RejectionReason{}, nil)
+	repo.On("GetRejectionReasons", []string{"city_retired"}).Return([]domainuser.RejectionReason{retired}, nil)
+	repo.On("GetRejectionReasons", []string{"street_unknown"}).Return([]domainuser.RejectionReason{catalogReason("street_unknown", "street")}, nil)
+
+	err := svc.RejectUser(testAdmin, "user-id", map[string]string{"city": "no_such_reason"})
+	assert.ErrorIs(t, err, admin.ErrUnknownRejectionReason)
+
+	err = svc.RejectUser(testAdmin, "user-id", map[string]string{"city": "city_retired"})
+	assert.ErrorIs(t, err, admin.ErrUnknownRejectionReason)
+
+	err = svc.RejectUser(testAdmin, "user-id", map[string]string{"city": "street_unknown"})
+	assert.ErrorIs(t, err, admin.ErrReasonFieldMismatch)
+
+	repo.AssertNotCalled(t, "GetUserByID", mock.Anything)
+}
+
+// TestListUsers_RejectsUnknownFilters verifies that status and role filters are validated.
+func TestListUsers_RejectsUnknownFilters(t *testing.T) {
+	repo := new(MockAdminRepo)
+	svc := newService(repo, new(MockSender))
+
+	_, err := svc.ListUsers(admin.UserFilter{Status: "ACTIVE"})
+	assert.ErrorIs(t, err, domainuser.ErrUnknownStatus)
+
+	_, err = svc.ListUsers(admin.UserFilter{Role: "ROLE_ROOT"})
+	assert.ErrorIs(t, err, admin.ErrUnknownRole)
+
+	repo.AssertNotCalled(t, "ListUsers", mock.Anything)
+}
+
+// TestSetUserRole_LogsChange verifies that a role change records who changed what.
+func TestSetUserRole_LogsChange(t *testing.T) {
+	repo := new(MockAdminRepo)
+	svc := newService(repo, new(MockSender))
+
+	repo.On("GetUserByID", "user-id").Return(pendingUser(), nil)
+	repo.On("SetUserRole", "user-id", domainuser.RoleManager, mock.AnythingOfType("user.SecurityEvent"), mock.AnythingOfType("user.AuditEntry")).Return(nil)
+
+	err := svc.SetUserRole(testAdmin, "user-id", domainuser.RoleManager)
+
+	require.NoError(t, err)
+	event := repo.Calls[1].Arguments.Get(2).(domainuser.SecurityEvent)
+	assert.Equal(t, domainuser.SecurityEventRoleChanged, event.Type)
+	assert.Equal(t, domainuser.RoleHomeowner, event.Details["from"])
+	assert.Equal(t, domainuser.RoleManager, event.Details["to"])
+}
+
+// TestSetUserRole_Self verifies that admins cannot change their own role.
+func TestSetUserRole_Self(t *testing.T) {
+	repo := new(MockAdminRepo)
+	svc := newService(repo, new(MockSender))
+
+	err := svc.SetUserRole(testAdmin, "admin-id", domainuser.RoleHomeowner)
+
+	assert.ErrorIs(t, err, admin.ErrSelfManagement)
+	repo.AssertNotCalled(t, "SetUserRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestOverrideStatus_KeepsReason verifies that an override is stored with its reason.
+func TestOverrideStatus_KeepsReason(t *testing.T) {
+	repo := new(MockAdminRepo)
+	svc := newService(repo, new(MockSender))
+
+	user := pendingUser()
+	user.Status = domainuser.StatusRejected
+	repo.On("GetUserByID", "user-id").Return(user, nil)
+	repo.On("ChangeStatus", mock.AnythingOfType("*user.StatusChange"), mock.AnythingOfType("user.AuditEntry")).Return(nil)
+
+	change, err := svc.OverrideStatus(testAdmin, "user-id", domainuser.StatusApproved, "rejected by mistake")
+
+	require.NoError(t, err)
+	assert.Equal(t, domainuser.TransitionOverride, change.Transition)
+	assert.Equal(t, "rejected by mistake", *change.Reason)
+	assert.WithinDuration(t, time.Now(), change.CreatedAt, time.Second)
+}
+
+// TestOverrideStatus_TargetGuards verifies that an override cannot skip the approval preconditions.
+func TestOverrideStatus_TargetGuards(t *testing.T) {
+	repo := new(MockAdminRepo)
+	svc := newService(repo, new(MockSender))
+
+	user := pendingUser()
+	user.HasProfile = false
+	user.Status = domainuser.StatusCreated
+	repo.On("GetUserByID", "user-id").Return(user, nil)
+
+	_, err := svc.OverrideStatus(testAdmin, "user-id", domainuser.StatusApproved, "vip")
+
+	assert.ErrorIs(t, err, domainuser.ErrProfileRequired)
+	repo.AssertNotCalled(t, "ChangeStatus", mock.Anything, mock.Anything)
+}
+
+// TestSuspendUser_RevokesAndNotifies verifies that a suspension stores the block with the
+// previous status and emails the user.
+func TestSuspendUser_RevokesAndNotifies(t *testing.T) {
+	repo := new(MockAdminRepo)
+	sender := new(MockSender)
+	svc := newService(repo, sender)
+
+	until := time.Now().Add(24 * time.Hour)
+	user := pendingUser()
+	user.Status = domainuser.StatusApproved
+	repo.On("GetUserByID", "user-id").Return(user, nil)
+	repo.On("BlockUser", mock.AnythingOfType("*user.AccountBlock"), mock.AnythingOfType("*user.StatusChange"), mock.AnythingOfType("user.SecurityEvent"), mock.AnythingOfType("user.AuditEntry")).Return(nil)
+	sender.On("SendAccountSuspended", "user@example.com", "spam", until).Return(nil)
+
+	block, err := svc.SuspendUser(testAdmin, "user-id", "spam", until)
+
+	require.NoError(t, err)
+	assert.Equal(t, domainuser.BlockSuspension, block.Kind)
+	assert.Equal(t, domainuser.StatusApproved, block.PreviousStatus)
+	change := repo.Calls[1].Arguments.Get(1).(*domainuser.StatusChange)
+	assert.Equal(t, domainuser.StatusSuspended, change.To)
+	assert.Equal(t, "spam", *change.Reason)
+	event := repo.Calls[1].Arguments.Get(2).(domainuser.SecurityEvent)
+	assert.Equal(t, domainuser.SecurityEventAccountSuspended, event.Type)
+	sender.AssertExpectations(t)
+}
+
+// TestAuditEntries_HoldNoFreeText verifies that block and override reasons stay out of
+// the append-only audit log, which cannot be erased together with the user.
+func TestAuditEntries_HoldNoFreeText(t *testing.T) {
+	repo := new(MockAdminRepo)
+	sender := new(MockSender)
+	svc := newService(repo, sender)
+
+	user := pendingUser()
+	user.Status = domainuser.StatusApproved
+	repo.On("GetUserByID", "user-id").Return(user, nil)
+	repo.On("GetOpenBlock", "user-id").Return((*domainuser.AccountBlock)(nil), nil)
+	repo.On("BlockUser", mock.AnythingOfType("*user.AccountBlock"), mock.AnythingOfType("*user.StatusChange"), mock.AnythingOfType("user.SecurityEvent"), mock.AnythingOfType("user.AuditEntry")).Return(nil)
+	repo.On("ChangeStatus", mock.AnythingOfType("*user.StatusChange"), mock.AnythingOfType("user.AuditEntry")).Return(nil)
+	sender.On("SendAccountBanned", "user@example.com", "Jane Doe sells stolen accounts").Return(nil)
+
+	_, err := svc.BanUser(testAdmin, "user-id", "Jane Doe sells stolen accounts")
+	require.NoError(t, err)
+	_, err = svc.OverrideStatus(testAdmin, "user-id", domainuser.StatusPending, "asked by Jane Doe on the phone")
+	require.NoError(t, err)
+
+	var entries []domainuser.AuditEntry
+	for _, call := range repo.Calls {
+		for _, arg := range call.Arguments {
+			if entry, ok := arg.(domainuser.AuditEntry); ok {
+				entries = append(entries, entry)
+			}
+		}
+	}
+	require.Len(t, entries, 2)
+	for _, entry := range entries {
+		body, err := json.Marshal(entry)
+		require.NoError(t, err)
+		assert.NotContains(t, string(body), "Jane Doe", entry.Action)
+	}
+}
+
+// TestSuspendUser_EndInPast verifies that a suspension must end in the future.
+func TestSuspendUser_EndInPast(t *testing.T) {
+	repo := new(MockAdminRepo)
+	svc := newService(repo, new(MockSender))
+
+	_, err := svc.SuspendUser(testAdmin, "user-id", "spam", time.Now().Add(-time.Minute))
+
+	assert.ErrorIs(t, err, admin.ErrInvalidSuspensionEnd)
+	repo.AssertNotCalled(t, "GetUserByID", mock.Anything)
+}
+
+// TestBanUser_KeepsStatusBeforeSuspension verifies that banning a suspended user restores
+// the status from before the suspension on reactivation.
+func TestBanUser_KeepsStatusBeforeSuspension(t *testing.T) {
+	repo := new(MockAdminRepo)
+	sender := new(MockSender)
+	svc := newService(repo, sender)
+
+	user := pendingUser()
+	user.Status = domainuser.StatusSuspended
+	repo.On("GetUserByID", "user-id").Return(user, nil)
+	repo.On("GetOpenBlock", "user-id").Return(&domainuser.AccountBlock{
+		ID:             "block-id",
+		Kind:           domainuser.BlockSuspension,
+		PreviousStatus: domainuser.StatusApproved,
+	}, nil)
+	repo.On("BlockUser", mock.AnythingOfType("*user.AccountBlock"), mock.AnythingOfType("*user.StatusChange"), mock.AnythingOfType("user.SecurityEvent"), mock.AnythingOfType("user.AuditEntry")).Return(nil)
+	sender.On("SendAccountBanned", "user@example.com", "fraud").Return(nil)
+
+	block, err := svc.BanUser(testAdmin, "user-id", "fraud")
+
+	require.NoError(t, err)
+	assert.Equal(t, domainuser.BlockBan, block.Kind)
+	assert.Nil(t, block.EndsAt)
+	assert.Equal(t, domainuser.StatusApproved, block.PreviousStatus)
+}
+
+// TestBanUser_Self verifies that admins cannot ban themselves.
+func TestBanUser_Self(t *testing.T) {
+	repo := new(MockAdminRepo)
+	svc := newService(repo, new(MockSender))
+
+	_, err := svc.BanUser(testAdmin, "admin-id", "oops")
+
+	assert.ErrorIs(t, err, admin.ErrSelfManagement)
+	repo.AssertNotCalled(t, "BlockUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestReactivateUser_RestoresPreviousStatus verifies that lifting a block restores the
+// status from before it.
+func TestReactivateUser_RestoresPreviousStatus(t *testing.T) {
+	repo := new(MockAdminRepo)
+	sender := new(MockSender)
+	svc := newService(repo, sender)
+
+	user := pendingUser()
+	user.Status = domainuser.StatusBanned
+	repo.On("GetUserByID", "user-id").Return(user, nil)
+	repo.On("GetOpenBlock", "user-id").Return(&domainuser.AccountBlock{
+		ID:             "block-id",
+		Kind:           domainuser.BlockBan,
+		PreviousStatus: domainuser.StatusPending,
+	}, nil)
+	repo.On("LiftBlock", mock.AnythingOfType("*user.AccountBlock"), mock.AnythingOfType("*user.StatusChange"), mock.AnythingOfType("user.SecurityEvent"), mock.AnythingOfType("user.AuditEntry")).Return(nil)
+	sender.On("SendAccountReactivated", "user@example.com").Return(nil)
+
+	change, err := svc.ReactivateUser(testAdmin, "user-id")
+
+	require.NoError(t, err)
+	assert.Equal(t, domainuser.TransitionReactivate, change.Transition)
+	assert.Equal(t, domainuser.StatusPending, change.To)
+	block := repo.Calls[2].Arguments.Get(0).(*domainuser.AccountBlock)
+	assert.Equal(t, "admin-id", *block.LiftedBy)
+	sender.AssertExpectations(t)
+}
+
+// TestReactivateUser_NotBlocked verifies that only blocked users can be reactivated.
+func TestReactivateUser_NotBlocked(t *testing.T) {
+	repo := new(MockAdminRepo)
+	svc := newService(repo, new(MockSender))
+
+	repo.On("GetUserByID", "user-id").Return(pendingUser(), nil)
+	repo.On("GetOpenBlock", "user-id").Return((*domainuser.AccountBlock)(nil), nil)
+
+	_, err := svc.ReactivateUser(testAdmin, "user-id")
+
+	assert.ErrorIs(t, err, admin.ErrNotBlocked)
+}
+
+// TestReactivateExpired_LiftsEndedSuspensions verifies that ended suspensions are lifted
+// without an admin.
+func TestReactivateExpired_LiftsEndedSuspensions(t *testing.T) {
+	repo := new(MockAdminRepo)
+	sender := new(MockSender)
+	svc := newService(repo, sender)
+
+	ended := time.Now().Add(-time.Minute)
+	user := pendingUser()
+	user.Status = domainuser.StatusSuspended
+	repo.On("ListExpiredSuspensions", mock.AnythingOfType("time.Time")).Return([]domainuser.AccountBlock{{
+		ID:             "block-id",
+		UserID:         "user-id",
+		Kind:           domainuser.BlockSuspension,
+		PreviousStatus: domainuser.StatusApproved,
+		EndsAt:         &ended,
+	}}, nil)
+	repo.On("GetUserByID", "user-id").Return(user, nil)
+	repo.On("LiftBlock", mock.AnythingOfType("*user.AccountBlock"), mock.AnythingOfType("*user.StatusChange"), mock.AnythingOfType("user.SecurityEvent"), mock.AnythingOfType("user.AuditEntry")).Return(nil)
+	sender.On("SendAccountReactivated", "user@example.com").Return(nil)
+
+	count, err := svc.ReactivateExpired()
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	change := repo.Calls[2].Arguments.Get(1).(*domainuser.StatusChange)
+	assert.Nil(t, change.ActorID)
+	assert.Equal(t, domainuser.StatusApproved, change.To)
+}
+
+// TestRejectUser_AuditEntry verifies that a rejection is audited with the admin, the request
+// and the status before and after.
+func TestRejectUser_AuditEntry(t *testing.T) {
+	repo := new(MockAdminRepo)
+	sender := new(MockSender)
+	svc := newService(repo, sender)
+
+	reasons := map[string]string{"city": "city_mismatch"}
+	repo.On("GetRejectionReasons", []string{"city_mismatch"}).Return([]domainuser.RejectionReason{catalogReason("city_mismatch", "city")}, nil)
+	repo.On("GetUserByID", "user-id").Return(pendingUser(), nil)
+	repo.On("RejectUser", mock.AnythingOfType("*user.StatusChange"), mock.Anything, reasons, mock.AnythingOfType("user.AuditEntry")).Return(nil)
+	sender.On("SendRejectionNotification", "user@example.com", domainuser.LanguageDE, mock.Anything).Return(nil)
+
+	require.NoError(t, svc.RejectUser(testAdmin, "user-id", reasons))
+
+	entry := repo.Calls[2].Arguments.Get(3).(domainuser.AuditEntry)
+	assert.Equal(t, domainuser.AuditUserRejected, entry.Action)
+	assert.Equal(t, domainuser.AuditTargetUser, entry.TargetType)
+	assert.Equal(t, "user-id", entry.TargetID)
+	assert.Equal(t, "admin-id", *entry.ActorID)
+	assert.Equal(t, "127.0.0.1", *entry.IP)
+	assert.Equal(t, "trace-id", *entry.TraceID)
+	assert.Equal(t, domainuser.StatusPending, entry.Before["status"])
+	assert.Equal(t, domainuser.StatusRejected, entry.After["status"])
+	assert.Equal(t, reasons, entry.After["reasons"])
+}
+
+// TestSetRolePermissions_AuditsPreviousPermissions verifies that the audit entry of a role
+// change holds the permissions before and after.
+func TestSetRolePermissions_AuditsPreviousPermissions(t *testing.T) {
+	repo := new(MockAdminRepo)
+	svc := newService(repo, new(MockSender))
+
+	repo.On("ListRolePermissions").Return(map[string][]string{
+		domainuser.RoleManager: {domainuser.PermissionUsersRead},
+	}, nil)
+	repo.On("SetRolePermissions", domainuser.RoleManager, []string{domainuser.PermissionUsersModerate}, mock.AnythingOfType("user.AuditEntry")).Return(nil)
+
+	err := svc.SetRolePermissions(testAdmin, domainuser.RoleManager, []string{domainuser.PermissionUsersModerate})
+
+	require.NoError(t, err)
+	entry := repo.Calls[1].Arguments.Get(2).(domainuser.AuditEntry)
+	assert.Equal(t, domainuser.AuditRolePermissionsChanged, entry.Action)
+	assert.Equal(t, domainuser.AuditTargetRole, entry.TargetType)
+	assert.Equal(t, domainuser.RoleManager, entry.TargetID)
+	assert.Equal(t, []string{domainuser.PermissionUsersRead}, entry.Before["permissions"])
+	assert.Equal(t, []string{domainuser.PermissionUsersModerate}, entry.After["permissions"])
+}
+
+// TestReactivateExpired_AuditsWithoutActor verifies that the end of a suspension is audited
+// as a change the application made.
+func TestReactivateExpired_AuditsWithoutActor(t *testing.T) {
+	repo := new(MockAdminRepo)
+	sender := new(MockSender)
+	svc := newService(repo, sender)
+
+	ended := time.Now().Add(-time.Minute)
+	user := pendingUser()
+	user.Status = domainuser.StatusSuspended
+	repo.On("ListExpiredSuspensions", mock.AnythingOfType("time.Time")).Return([]domainuser.AccountBlock{{
+		ID:             "block-id",
+		UserID:         "user-id",
+		Kind:           domainuser.BlockSuspension,
+		PreviousStatus: domainuser.StatusPending,
+		EndsAt:         &ended,
+	}}, nil)
+	repo.On("GetUserByID", "user-id").Return(user, nil)
+	repo.On("LiftBlock", mock.AnythingOfType("*user.AccountBlock"), mock.AnythingOfType("*user.StatusChange"), mock.AnythingOfType("user.SecurityEvent"), mock.AnythingOfType("user.AuditEntry")).Return(nil)
+	sender.On("SendAccountReactivated", "user@example.com").Return(nil)
+
+	_, err := svc.ReactivateExpired()
+
+	require.NoError(t, err)
+	entry := repo.Calls[2].Arguments.Get(3).(domainuser.AuditEntry)
+	assert.Equal(t, domainuser.AuditUserReactivated, entry.Action)
+	assert.Nil(t, entry.ActorID)
+	assert.Nil(t, entry.IP)
+	assert.Equal(t, domainuser.StatusPending, entry.After["status"])
+}
+
+// TestListAuditEntries_RejectsUnknownFilters verifies that action and target type filters are validated.
+func TestListAuditEntries_RejectsUnknownFilters(t *testing.T) {
+	repo := new(MockAdminRepo)
+	svc := newService(repo, new(MockSender))
+
+	_, err := svc.ListAuditEntries(admin.AuditFilter{Action: "user.deleted_everything"})
+	assert.ErrorIs(t, err, admin.ErrUnknownAuditAction)
+
+	_, err = svc.ListAuditEntries(admin.AuditFilter{TargetType: "ticket"})
+	assert.ErrorIs(t, err, admin.ErrUnknownAuditTarget)
+
+	repo.AssertNotCalled(t, "ListAuditEntries", mock.Anything)
+}
+
+// TestListPendingUsers_HidesOtherClaims verifies that the pending list is scoped to the calling admin.
+func TestListPendingUsers_HidesOtherClaims(t *testing.T) {
+	repo := new(MockAdminRepo)
+	svc := newService(repo, new(MockSender))
+
+	repo.On("ListUsers", mock.AnythingOfType("admin.UserFilter")).Return(&admin.UserPage{}, nil)
+
+	_, err := svc.ListPendingUsers("admin-id", "", 20, 0)
+
+	require.NoError(t, err)
+	filter := repo.Calls[0].Arguments.Get(0).(admin.UserFilter)
+	assert.Equal(t, domainuser.StatusPending, filter.Status)
+	assert.Equal(t, "admin-id", filter.VisibleTo)
+}
+
+// TestClaimNext_ReturnsCase verifies that the claimed user is returned with their details.
+func TestClaimNext_ReturnsCase(t *testing.T) {
+	repo := new(MockAdminRepo)
+	svc := newService(repo, new(MockSender))
+
+	claim := &admin.Claim{UserID: "user-id", AdminID: "admin-id", ExpiresAt: time.Now().Add(admin.ClaimLease)}
+	repo.On("ClaimNext", "admin-id", admin.ClaimLease).Return(claim, nil)
+	repo.On("GetUserDetail", "user-id").Return(&admin.UserDetail{}, nil)
+	repo.On("ListStatusChanges", "user-id").Return([]domainuser.StatusChange{}, nil)
+	repo.On("GetOpenBlock", "user-id").Return((*domainuser.AccountBlock)(nil), nil)
+
+	moderationCase, err := svc.ClaimNext(testAdmin)
+
+	require.NoError(t, err)
+	assert.Same(t, claim, moderationCase.Claim)
+	assert.NotNil(t, moderationCase.User)
+}
+
+// TestClaimNext_EmptyQueue verifies that an empty queue is reported as such.
+func TestClaimNext_EmptyQueue(t *testing.T) {
+	repo := new(MockAdminRepo)
+	svc := newService(repo, new(MockSender))
+
+	repo.On("ClaimNext", "admin-id", admin.ClaimLease).Return((*admin.Claim)(nil), nil)
+
+	_, err := svc.ClaimNext(testAdmin)
+
+	assert.ErrorIs(t, err, admin.ErrQueueEmpty)
+	repo.AssertNotCalled(t, "GetUserDetail", mock.Anything)
+}
+
+// TestClaimUser_Guards verifies that only pending users that nobody else holds can be claimed.
+func TestClaimUser_Guards(t *testing.T) {
+	repo := new(MockAdminRepo)
+	svc := newService(repo, new(MockSender))
+
+	approved := pendingUser()
+	approved.ID = "approved-id"
+	approved.Status = domainuser.StatusApproved
+	repo.On("GetUserByID", "approved-id").Return(approved, nil)
+	repo.On("GetUserByID", "user-id").Return(pendingUser(), nil)
+	repo.On("ClaimUser", "user-id", "admin-id", admin.ClaimLease).Return((*admin.Claim)(nil), nil)
+
+	_, err := svc.ClaimUser(testAdmin, "approved-id")
+	assert.ErrorIs(t, err, admin.ErrNotPending)
+
+	_, err = svc.ClaimUser(testAdmin, "user-id")
+	assert.ErrorIs(t, err, admin.ErrClaimedByOther)
+}
+
+// TestApproveUser_ClaimedByOther verifies that a decision on a user another admin holds is refused
+// and no email is sent.
+func TestApproveUser_ClaimedByOther(t *testing.T) {
+	repo := new(MockAdminRepo)
+	sender := new(MockSender)
+	svc := newService(repo, sender)
+
+	repo.On("GetUserByID", "user-id").Return(pendingUser(), nil)
+	repo.On("ChangeStatus", mock.AnythingOfType("*user.StatusChange"), mock.AnythingOfType("user.AuditEntry")).Return(admin.ErrClaimedByOther)
+
+	err := svc.ApproveUser(testAdmin, "user-id")
+
+	assert.ErrorIs(t, err, admin.ErrClaimedByOther)
+	sender.AssertNotCalled(t, "SendApprovalNotification", mock.Anything)
+}
+
+// TestReleaseClaim_NotHeld verifies that an admin can only release their own claim.
+func TestReleaseClaim_NotHeld(t *testing.T) {
+	repo := new(MockAdminRepo)
+	svc := newService(repo, new(MockSender))
+
+	repo.On("ReleaseClaim", "user-id", "admin-id").Return(false, nil)
+
+	err := svc.ReleaseClaim(testAdmin, "user-id")
+
+	assert.ErrorIs(t, err, admin.ErrNotClaimed)
+}
+
+// TestBulkApprove_ReportsEachUser verifies that only pending users are approved, failures are
+// reported per user and approval emails are queued instead of sent.
+func TestBulkApprove_ReportsEachUser(t *testing.T) {
+	repo := new(MockAdminRepo)
+	sender := new(MockSender)
+	svc := newService(repo, sender)
+
+	approved := *pendingUser()
+	approved.ID, approved.Email, approved.Status = "approved-id", "approved@example.com", domainuser.StatusApproved
+	claimed := *pendingUser()
+	claimed.ID, claimed.Email = "claimed-id", "claimed@example.com"
+	repo.On("GetUsersByIDs", []string{"user-id", "approved-id", "claimed-id", "missing-id"}).
+		Return([]admin.User{*pendingUser(), approved, claimed}, nil)
+	repo.On("ApplyDecisions", mock.AnythingOfType("[]admin.Decision")).Return([]error{nil, admin.ErrClaimedByOther}, nil)
+
+	results, err := svc.BulkApprove(testAdmin, []string{"user-id", "approved-id", "claimed-id", "missing-id", "user-id"}, false)
+
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+	assert.Equal(t, admin.BulkResult{UserID: "user-id", OK: true, Status: domainuser.StatusApproved, EmailQueued: true}, results[0])
+	assert.Equal(t, admin.ErrNotPending.Error(), results[1].Error)
+	assert.Equal(t, admin.ErrClaimedByOther.Error(), results[2].Error)
+	assert.False(t, results[2].EmailQueued)
+	assert.Equal(t, admin.ErrUserNotFound.Error(), results[3].Error)
+
+	decisions := repo.Calls[1].Arguments.Get(0).([]admin.Decision)
+	require.Len(t, decisions, 2)
+	assert.Equal(t, domainuser.AuditUserApproved, decisions[0].Entry.Action)
+	assert.Equal(t, true, decisions[0].Entry.After["bulk"])
+	assert.Equal(t, 1, svc.Mails.Len())
+	sender.AssertNotCalled(t, "SendApprovalNotification", mock.Anything)
+}
+
+// TestBulkReject_DryRun verifies that a dry run reports the outcome without storing anything.
+func TestBulkReject_DryRun(t *testing.T) {
+	repo := new(MockAdminRepo)
+	svc := newService(repo, new(MockSender))
+
+	rejected := *pendingUser()
+	rejected.ID, rejected.Status = "rejected-id", domainuser.StatusRejected
+	repo.On("GetRejectionReasons", []string{"last_name_illegible"}).Return([]domainuser.RejectionReason{catalogReason("last_name_illegible", "last_name")}, nil)
+	repo.On("GetUsersByIDs", []string{"user-id", "rejected-id"}).Return([]admin.User{*pendingUser(), rejected}, nil)
+
+	results, err := svc.BulkReject(testAdmin, []string{"user-id", "rejected-id"}, map[string]string{"lastName": "last_name_illegible"}, true)
+
+	require.NoError(t, err)
+	assert.True(t, results[0].OK)
+	assert.Equal(t, domainuser.StatusRejected, results[0].Status)
+	assert.False(t, results[0].EmailQueued)
+	assert.Equal(t, admin.BulkResult{UserID: "rejected-id", Error: admin.ErrNotPending.Error()}, results[1])
+	repo.AssertNotCalled(t, "ApplyDecisions", mock.Anything)
+	assert.Equal(t, 0, svc.Mails.Len())
+}
+
+// TestBulkApprove_Limit verifies that oversized requests are refused before touching the database.
+func TestBulkApprove_Limit(t *testing.T) {
+	repo := new(MockAdminRepo)
+	svc := newService(repo, new(MockSender))
+
+	userIDs := make([]string, admin.BulkMaxUsers+1)
+	for i := range userIDs {
+		userIDs[i] = fmt.Sprintf("user-%d", i)
+	}
+
+	_, err := svc.BulkApprove(testAdmin, userIDs, false)
+
+	assert.ErrorIs(t, err, admin.ErrTooManyUsers)
+	repo.AssertNotCalled(t, "GetUsersByIDs", mock.Anything)
+}
+
+// TestSaveRejectionReason_Validates verifies that codes and fields are checked before saving.
+func TestSaveRejectionReason_Validates(t *testing.T) {
+	repo := new(MockAdminRepo)
+	svc := newService(repo, new(MockSender))
+
+	_, err := svc.SaveRejectionReason(testAdmin, catalogReason("City Mismatch", "city"))
+	assert.ErrorIs(t, err, admin.ErrInvalidReasonCode)
+
+	_, err = svc.SaveRejectionReason(testAdmin, catalogReason("shoe_size_wrong", "shoe_size"))
+	assert.ErrorIs(t, err, admin.ErrUnknownProfileField)
+
+	repo.AssertNotCalled(t, "SaveRejectionReason", mock.Anything, mock.Anything)
+}
+
+// TestSaveRejectionReason_AuditsChange verifies that the field is canonicalized and the previous
+// version is recorded in the audit log.
+func TestSaveRejectionReason_AuditsChange(t *testing.T) {
+	repo := new(MockAdminRepo)
+	svc := newService(repo, new(MockSender))
+
+	previous := catalogReason("postal_code_invalid", "postal_code")
+	repo.On("GetRejectionReasons", []string{"postal_code_invalid"}).Return([]domainuser.RejectionReason{previous}, nil)
+	repo.On("SaveRejectionReason", mock.AnythingOfType("*user.RejectionReason"), mock.AnythingOfType("user.AuditEntry")).Return(nil)
+
+	reason := catalogReason("postal_code_invalid", "postalCode")
+	reason.Active = false
+	saved, err := svc.SaveRejectionReason(testAdmin, reason)
+
+	require.NoError(t, err)
+	assert.Equal(t, "postal_code", saved.Field)
+	entry := repo.Calls[1].Arguments.Get(1).(domainuser.AuditEntry)
+	assert.Equal(t, domainuser.AuditRejectionReasonSaved, entry.Action)
+	assert.Equal(t, domainuser.AuditTargetRejectionReason, entry.TargetType)
+	assert.Equal(t, "postal_code_invalid", entry.TargetID)
+	assert.Equal(t, true, entry.Before["active"])
+	assert.Equal(t, false, entry.After["active"])
+}
+
+// TestGetStats_DefaultsToLastThirtyDays verifies the period used when none is given.
+func TestGetStats_DefaultsToLastThirtyDays(t *testing.T) {
+	repo := new(MockAdminRepo)
+	svc := newService(repo, new(MockSender))
+
+	repo.On("GetStats", mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return(&admin.Stats{}, nil)
+
+	_, err := svc.GetStats(nil, nil)
+
+	require.NoError(t, err)
+	from := repo.Calls[0].Arguments.Get(0).(time.Time)
+	to := repo.Calls[0].Arguments.Get(1).(time.Time)
+	assert.True(t, to.After(time.Now()))
+	assert.False(t, to.After(time.Now().Add(24*time.Hour)))
+	assert.Equal(t, to.AddDate(0, 0, -admin.DefaultStatsDays), from)
+}
+
+// TestGetStats_RejectsInvalidPeriods verifies that reversed and oversized periods are refused.
+func TestGetStats_RejectsInvalidPeriods(t *testing.T) {
+	repo := new(MockAdminRepo)
+	svc := newService(repo, new(MockSender))
+
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	before := from.AddDate(0, 0, -1)
+	tooLate := from.AddDate(2, 0, 0)
+
+	_, err := svc.GetStats(&from, &before)
+	assert.ErrorIs(t, err, admin.ErrInvalidStatsRange)
+
+	_, err = svc.GetStats(&from, &tooLate)
+	assert.ErrorIs(t, err, admin.ErrInvalidStatsRange)
+
+	repo.AssertNotCalled(t, "GetStats", mock.Anything, mock.Anything)
+}
+
+// TestWriteStatsCSV verifies that every section ends up in the report.
+func TestWriteStatsCSV(t *testing.T) {
+	median := 5400.0
+	email := "admin@example.com"
+	adminID := "admin-id"
+	stats := &admin.Stats{
+		From:                  time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		To:                    time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC),
+		Registrations:         []admin.DailyCount{{Day: "2026-03-01", Count: 4}},
+		Funnel:                admin.Funnel{Created: 4, EmailConfirmed: 3, ProfileSubmitted: 2, Approved: 1, Rejected: 1},
+		Backlog:               []admin.BacklogBucket{{Age: admin.BacklogUnderOneDay, Count: 2}},
+		MedianDecisionSeconds: &median,
+		Decisions:             []admin.AdminDecisions{{AdminID: &adminID, Email: &email, Approved: 1, Rejected: 1}, {Approved: 2}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, admin.WriteStatsCSV(&buf, stats))
+
+	out := buf.String()
+	assert.True(t, strings.HasPrefix(out, "section,key,value\n"))
+	assert.Contains(t, out, "registrations,2026-03-01,4\n")
+	assert.Contains(t, out, "funnel,profile_submitted,2\n")
+	assert.Contains(t, out, "backlog,under_1d,2\n")
+	assert.Contains(t, out, "decision_time,median_seconds,5400\n")
+	assert.Contains(t, out, "decisions_rejected,admin@example.com,1\n")
+	assert.Contains(t, out, "decisions_approved,deleted,2\n")
+}
+
+// TestAddNote_AuditOmitsBody verifies that the note is attributed to the admin and that its
+// text stays out of the audit log.
+func TestAddNote_AuditOmitsBody(t *testing.T) {
+	repo := new(MockAdminRepo)
+	svc := newService(repo, new(MockSender))
+
+	repo.On("GetUserByID", "user-id").Return(pendingUser(), nil)
+	repo.On("AddNote", mock.AnythingOfType("*admin.Note"), mock.AnythingOfType("user.AuditEntry")).Return(nil)
+
+	note, err := svc.AddNote(testAdmin, "user-id", "called about missing documents")
+
+	require.NoError(t, err)
+	require.NotNil(t, note.AuthorID)
+	assert.Equal(t, testAdmin.ID, *note.AuthorID)
+	assert.Equal(t, "user-id", note.UserID)
+
+	entry := repo.Calls[1].Arguments.Get(1).(domainuser.AuditEntry)
+	assert.Equal(t, domainuser.AuditUserNoteAdded, entry.Action)
+	assert.Equal(t, domainuser.AuditState{"note_id": note.ID}, entry.After)
+	assert.NotContains(t, fmt.Sprint(entry), "missing documents")
+}
+
+// TestDeleteNote_NotFound verifies that deleting a note the user does not have is reported.
+func TestDeleteNote_NotFound(t *testing.T) {
+	repo := new(MockAdminRepo)
+	svc := newService(repo, new(MockSender))
+
+	repo.On("DeleteNote", "user-id", "note-id", mock.AnythingOfType("user.AuditEntry")).Return(false, nil)
+
+	err := svc.DeleteNote(testAdmin, "user-id", "note-id")
+
+	assert.ErrorIs(t, err, admin.ErrNoteNotFound)
+}
+
+// TestSetTags_Normalizes verifies that tags are trimmed, lowercased, deduplicated and sorted
+// and that the audit entry counts the tags without naming them.
+func TestSetTags_Normalizes(t *testing.T) {
+	repo := new(MockAdminRepo)
+	svc := newService(repo, new(MockSender))
+
+	repo.On("GetUserByID", "user-id").Return(pendingUser(), nil)
+	repo.On("ListTags", "user-id").Return([]string{"vip"}, nil)
+	repo.On("SetTags", "user-id", []string{"fraud-check", "vip"}, testAdmin.ID, mock.AnythingOfType("user.AuditEntry")).Return(nil)
+
+	tags, err := svc.SetTags(testAdmin, "user-id", []string{" VIP ", "fraud-check", "vip"})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"fraud-check", "vip"}, tags)
+	entry := repo.Calls[2].Arguments.Get(3).(domainuser.AuditEntry)
+	assert.Equal(t, domainuser.AuditUserTagsChanged, entry.Action)
+	assert.Equal(t, domainuser.AuditState{"tag_count": 1}, entry.Before)
+	assert.Equal(t, domainuser.AuditState{"tag_count": 2}, entry.After)
+}
+
+// TestSetTags_Limits verifies that empty, overlong and too many tags are refused.
+func TestSetTags_Limits(t *testing.T) {
+	repo := new(MockAdminRepo)
+	svc := newService(repo, new(MockSender))
+
+	_, err := svc.SetTags(testAdmin, "user-id", []string{"  "})
+	assert.ErrorIs(t, err, admin.ErrInvalidTag)
+
+	_, err = svc.SetTags(testAdmin, "user-id", []string{strings.Repeat("x", admin.MaxTagLength+1)})
+	assert.ErrorIs(t, err, admin.ErrInvalidTag)
+
+	many := make([]string, admin.MaxTagsPerUser+1)
+	for i := range many {
+		many[i] = fmt.Sprintf("tag-%d", i)
+	}
+	_, err = svc.SetTags(testAdmin, "user-id", many)
+	assert.ErrorIs(t, err, admin.ErrTooManyTags)
+
+	repo.AssertNotCalled(t, "SetTags", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestListUsers_NormalizesTagFilter verifies that tag filters match the stored form of tags.
+func TestListUsers_NormalizesTagFilter(t *testing.T) {
+	repo := new(MockAdminRepo)
+	svc := newService(repo, new(MockSender))
+
+	repo.On("ListUsers", mock.AnythingOfType("admin.UserFilter")).Return(&admin.UserPage{}, nil)
+
+	_, err := svc.ListUsers(admin.UserFilter{Tags: []string{"VIP", "vip "}})
+
+	require.NoError(t, err)
+	filter := repo.Calls[0].Arguments.Get(0).(admin.UserFilter)
+	assert.Equal(t, []string{"vip"}, filter.Tags)
+}
+
+// TestGetUserProfile_IncludesNotesAndTags verifies that moderators see the internal notes and
+// tags with the profile.
+func TestGetUserProfile_IncludesNotesAndTags(t *testing.T) {
+	repo := new(MockAdminRepo)
+	svc := newService(repo, new(MockSender))
+
+	notes := []admin.Note{{ID: "note-id", UserID: "user-id", Body: "verified by phone"}}
+	repo.On("GetUserByID", "user-id").Return(&admin.User{ID: "user-id"}, nil)
+	repo.On("GetUserProfile", "user-id").Return(&domainuser.Profile{}, nil)
+	repo.On("ListRejections", "user-id").Return([]domainuser.Rejection{}, nil)
+	repo.On("ListProfileChanges", "user-id").Return([]domainuser.ProfileChange{}, nil)
+	repo.On("ListNotes", "user-id").Return(notes, nil)
+	repo.On("ListTags", "user-id").Return([]string{"vip"}, nil)
+
+	detail, err := svc.GetUserProfile("user-id")
+
+	require.NoError(t, err)
+	assert.Equal(t, notes, detail.Notes)
+	assert.Equal(t, []string{"vip"}, detail.Tags)
+	assert.True(t, detail.HasProfile)
+}
+
+// TestGetUserProfile_WithoutProfile verifies that notes and tags are shown for users who
+// have not created a profile yet, and that only unknown users are not found.
+func TestGetUserProfile_WithoutProfile(t *testing.T) {
+	repo := new(MockAdminRepo)
+	svc := newService(repo, new(MockSender))
+
+	notes := []admin.Note{{ID: "note-id", UserID: "user-id", Body: "called about missing confirmation"}}
+	repo.On("GetUserByID", "user-id").Return(&admin.User{ID: "user-id"}, nil)
+	repo.On("GetUserByID", "missing-id").Return((*admin.User)(nil), nil)
+	repo.On("GetUserProfile", "user-id").Return((*domainuser.Profile)(nil), nil)
+	repo.On("ListRejections", "user-id").Return([]domainuser.Rejection{}, nil)
+	repo.On("ListProfileChanges", "user-id").Return([]domainuser.ProfileChange{}, nil)
+	repo.On("ListNotes", "user-id").Return(notes, nil)
+	repo.On("ListTags", "user-id").Return([]string{"support"}, nil)
+
+	detail, err := svc.GetUserProfile("user-id")
+
+	require.NoError(t, err)
+	assert.False(t, detail.HasProfile)
+	assert.Nil(t, detail.Profile)
+	assert.Equal(t, notes, detail.Notes)
+	assert.Equal(t, []string{"support"}, detail.Tags)
+
+	_, err = svc.GetUserProfile("missing-id")
+	assert.ErrorIs(t, err, admin.ErrUserNotFound)
+}
@@ -1,12 +1,30 @@
 package unit
 
 import (
+	"crypto/sha256"
+
+	"encoding/hex"
+
+	domainuser "carowebapp/core/internal/domain/user"
+
 	"carowebapp/core/internal/features/auth"
 
+	"carowebapp/core/internal/features/onetimetoken"
+
+	"carowebapp/core/internal/pkg/passwordhash"
+
+	"carowebapp/core/internal/pkg/passwordpolicy"
+
+	"github.com/golang-jwt/jwt/v5"
+
 	"github.com/stretchr/testify/assert"
 
 	"github.com/stretchr/testify/mock"
 
+	"golang.org/x/crypto/bcrypt"
+
+	"sync"
+
 	"testing"
 
 	"time"
@@ -26,16 +44,36 @@ func (m *MockUserRepo) Update(user *auth.User) error {
 	return args.Error(0)
 }
 
-func (m *MockUserRepo) UpdateEmailConfirmation(userID string, token string, sentAt time.Time) error {
-	args := m.Called(userID, token, sentAt)
+func (m *MockUserRepo) SetConfirmationSentAt(userID string, sentAt time.Time) error {
+	args := m.Called(userID, sentAt)
+	return args.Error(0)
+}
+
+func (m *MockUserRepo) CreateProfile(profile *auth.UserProfile, statusChange *domainuser.StatusChange) error {
+	args := m.Called(profile, statusChange)
 	return args.Error(0)
 }
 
-func (m *MockUserRepo) CreateProfile(profile *auth.UserProfile) error {
-	args := m.Called(profile)
+func (m *MockUserRepo) GetProfile(userID string) (*auth.UserProfile, error) {
+	args := m.Called(userID)
+	return args.Get(0).(*auth.UserProfile), args.Error(1)
+}
+
+func (m *MockUserRepo) UpdateProfile(profile *auth.UserProfile, change *domainuser.ProfileChange, statusChange *domainuser.StatusChange) error {
+	args := m.Called(profile, change, statusChange)
 	return args.Error(0)
 }
 
+func (m *MockUserRepo) GetLatestRejection(userID string) (*domainuser.Rejection, error) {
+	args := m.Called(userID)
+	return args.Get(0).(*domainuser.Rejection), args.Error(1)
+}
+
+func (m *MockUserRepo) GetActiveBlock(userID string) (*domainuser.AccountBlock, error) {
+	args := m.Called(userID)
+	return args.Get(0).(*domainuser.AccountBlock), args.Error(1)
+}
+
 func (m *MockUserRepo) StoreRefreshToken(token *auth.RefreshToken) error {
 	args := m.Called(token)
 	return args.Error(0)
@@ -46,11 +84,101 @@ func (m *MockUserRepo) GetRefreshToken(tokenStr string) (*auth.RefreshToken, err
 	return args.Get(0).(*auth.RefreshToken), args.Error(1)
 }
 
-func (m *MockUserRepo) DeleteRefreshTokensByUserID(userID string) error {
+func (m *MockUserRepo) MarkRefreshTokenUsed(id string) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepo) GetRolePermissions(role string) ([]string, error) {
+	args := m.Called(role)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockUserRepo) CreateSession(session *auth.Session) error {
+	args := m.Called(session)
+	return args.Error(0)
+}
+
+func (m *MockUserRepo) GetSession(id string) (*auth.Session, error) {
+	args := m.Called(id)
+	return args.Get(0).(*auth.Session), args.Error(1)
+}
+
+func (m *MockUserRepo) ListActiveSessions(userID string) ([]auth.Session, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]auth.Session), args.Error(1)
+}
+
+func (m *MockUserRepo) TouchSession(id string, ip string) error {
+	args := m.Called(id, ip)
+	return args.Error(0)
+}
+
+func (m *MockUserRepo) IsSessionActive(id string) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepo) RevokeSession(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepo) RevokeUserSessions(userID string, exceptSessionID string) error {
+	args := m.Called(userID, exceptSessionID)
+	return args.Error(0)
+}
+
+func (m *MockUserRepo) SetTOTPSecret(userID string, secret string) error {
+	args := m.Called(userID, secret)
+	return args.Error(0)
+}
+
+func (m *MockUserRepo) EnableTOTP(userID string) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockUserRepo) DisableTOTP(userID string) error {
 	args := m.Called(userID)
 	return args.Error(0)
 }
 
+func (m *MockUserRepo) UseTOTPStep(userID string, step int64) (bool, error) {
+	args := m.Called(userID, step)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepo) ReplaceRecoveryCodes(userID string, codeHashes []string) error {
+	args := m.Called(userID, codeHashes)
+	return args.Error(0)
+}
+
+func (m *MockUserRepo) UseRecoveryCode(userID string, codeHash string) (bool, error) {
+	args := m.Called(userID, codeHash)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepo) CreateMFAChallenge(challenge *auth.MFAChallenge) error {
+	args := m.Called(challenge)
+	return args.Error(0)
+}
+
+func (m *MockUserRepo) GetMFAChallenge(token string) (*auth.MFAChallenge, error) {
+	args := m.Called(token)
+	return args.Get(0).(*auth.MFAChallenge), args.Error(1)
+}
+
+func (m *MockUserRepo) IncrementMFAChallengeAttempts(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepo) MarkMFAChallengeUsed(id string) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockUserRepo) Create(user *auth.User) (*auth.User, error) {
 	args := m.Called(user)
 	return args.Get(0).(*auth.User), args.Error(1)
@@ -66,36 +194,163 @@ func (m *MockUserRepo) EmailExists(email string) (bool, error) {
 	return args.Bool(0), args.Error(1)
 }
 
-func (m *MockUserRepo) GetByConfirmationToken(token string) (*auth.User, error) {
-	args := m.Called(token)
-	return args.Get(0).(*auth.User), args.Error(1)
-}
-
 func (m *MockUserRepo) SetEmailConfirmed(userID string) error {
 	args := m.Called(userID)
 	return args.Error(0)
 }
 
-func (m *MockUserRepo) CreatePasswordResetToken(token *auth.UserPasswordResetToken) error {
-	args := m.Called(token)
+func (m *MockUserRepo) UpdateUserPassword(userID string, hashed string) error {
+	args := m.Called(userID, hashed)
 	return args.Error(0)
 }
 
-func (m *MockUserRepo) GetPasswordResetToken(token string) (*auth.UserPasswordResetToken, error) {
-	args := m.Called(token)
-	return args.Get(0).(*auth.UserPasswordResetToken), args.Error(1)
+// sha256Hex mirrors how the service stores one-time tokens.
+func sha256Hex(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
 }
 
-func (m *MockUserRepo) UpdateUserPassword(userID string, hashed string) error {
-	args := m.Called(userID, hashed)
+// memoryTokens is an in-memory onetimetoken.Repository.
+type memoryTokens struct {
+	mu     sync.Mutex
+	tokens map[string]*onetimetoken.Token
+}
+
+func newMemoryTokens() *memoryTokens {
+	return &memoryTokens{tokens: map[string]*onetimetoken.Token{}}
+}
+
+func (m *memoryTokens) Create(token *onetimetoken.Token, revokeActive bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if revokeActive {
+		m.revoke(token.UserID, token.Purpose)
+	}
+	stored := *token
+	m.tokens[token.ID] = &stored
+	return nil
+}
+
+func (m *memoryTokens) GetByHash(purpose onetimetoken.Purpose, tokenHash string) (*onetimetoken.Token, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, token := range m.tokens {
+		if token.Purpose == purpose && token.TokenHash == tokenHash {
+			found := *token
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memoryTokens) RecordAttempt(id string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tokens[id].Attempts++
+	return m.tokens[id].Attempts, nil
+}
+
+func (m *memoryTokens) MarkUsed(id string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	token := m.tokens[id]
+	if token.UsedAt != nil || token.RevokedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	token.UsedAt = &now
+	return true, nil
+}
+
+func (m *memoryTokens) RevokeActive(userID string, purpose onetimetoken.Purpose) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.revoke(userID, purpose)
+	return nil
+}
+
+func (m *memoryTokens) revoke(userID string, purpose onetimetoken.Purpose) {
+	now := time.Now()
+	for _, token := range m.tokens {
+		if token.UserID == userID && token.Purpose == purpose && token.UsedAt == nil && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+}
+
+// only returns the single stored token.
+func (m *memoryTokens) only(t *testing.T) *onetimetoken.Token {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.tokens) != 1 {
+		t.Fatalf("expected one stored token, got %d", len(m.tokens))
+	}
+	for _, token := range m.tokens {
+		return token
+	}
+	return nil
+}
+
+// testHasher uses cheap Argon2id parameters to keep the tests fast.
+var testHasher = passwordhash.NewHasher(&passwordhash.Argon2id{Params: passwordhash.Argon2idParams{
+	Memory:      1024,
+	Iterations:  1,
+	Parallelism: 1,
+	SaltLength:  16,
+	KeyLength:   32,
+}}, 2, time.Second, &passwordhash.Bcrypt{Cost: bcrypt.MinCost})
+
+func (m *MockUserRepo) RecordFailedLogin(userID string) (int, error) {
+	args := m.Called(userID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockUserRepo) LockAccount(userID string, until time.Time, event domainuser.SecurityEvent) error {
+	args := m.Called(userID, until, event)
 	return args.Error(0)
 }
 
-func (m *MockUserRepo) MarkResetTokenUsed(token string) error {
-	args := m.Called(token)
+func (m *MockUserRepo) ResetFailedLogins(userID string) error {
+	args := m.Called(userID)
 	return args.Error(0)
 }
 
+func (m *MockUserRepo) UnlockAccount(userID string, event domainuser.SecurityEvent) error {
+	args := m.Called(userID, event)
+	return args.Error(0)
+}
+
+func (m *MockUserRepo) CreateEmailChange(change *auth.EmailChange) error {
+	args := m.Called(change)
+	return args.Error(0)
+}
+
+func (m *MockUserRepo) GetEmailChange(tokenHash string) (*auth.EmailChange, error) {
+	args := m.Called(tokenHash)
+	return args.Get(0).(*auth.EmailChange), args.Error(1)
+}
+
+func (m *MockUserRepo) GetEmailChangeByUndoToken(undoTokenHash string) (*auth.EmailChange, error) {
+	args := m.Called(undoTokenHash)
+	return args.Get(0).(*auth.EmailChange), args.Error(1)
+}
+
+func (m *MockUserRepo) ApplyEmailChange(id, undoTokenHash string, undoExpiresAt time.Time) (bool, error) {
+	args := m.Called(id, undoTokenHash, undoExpiresAt)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepo) RevertEmailChange(id string) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
+type stubSigner struct{}
+
+func (stubSigner) Sign(_ jwt.MapClaims, _ time.Duration) (string, error) {
+	return "access-token", nil
+}
+
 type MockSender struct {
 	mock.Mock
 }
@@ -115,8 +370,8 @@ func (m *MockSender) SendApprovalNotification(email string) error {
 	return args.Error(0)
 }
 
-func (m *MockSender) SendRejectionNotification(email string, errors map[string]string) error {
-	args := m.Called(email, errors)
+func (m *MockSender) SendRejectionNotification(email, language string, errors map[string]string) error {
+	args := m.Called(email, language, errors)
 	return args.Error(0)
 }
 
@@ -125,13 +380,63 @@ func (m *MockSender) SendResetPasswordLink(to string, token string) error {
 	return args.Error(0)
 }
 
+func (m *MockSender) SendMagicLink(to string, token string) error {
+	args := m.Called(to, token)
+	return args.Error(0)
+}
+
+func (m *MockSender) SendEmailChangeConfirmation(to string, token string) error {
+	args := m.Called(to, token)
+	return args.Error(0)
+}
+
+func (m *MockSender) SendEmailChangedAlert(to string, newEmail string, undoToken string) error {
+	args := m.Called(to, newEmail, undoToken)
+	return args.Error(0)
+}
+
+func (m *MockSender) SendAccountDeletionConfirmation(to string, token string) error {
+	args := m.Called(to, token)
+	return args.Error(0)
+}
+
+func (m *MockSender) SendAccountDeletionScheduled(to string, scheduledFor time.Time) error {
+	args := m.Called(to, scheduledFor)
+	return args.Error(0)
+}
+
+func (m *MockSender) SendAccountDeleted(to string) error {
+	args := m.Called(to)
+	return args.Error(0)
+}
+
+func (m *MockSender) SendAccountSuspended(to string, reason string, until time.Time) error {
+	args := m.Called(to, reason, until)
+	return args.Error(0)
+}
+
+func (m *MockSender) SendAccountBanned(to string, reason string) error {
+	args := m.Called(to, reason)
+	return args.Error(0)
+}
+
+func (m *MockSender) SendAccountReactivated(to string) error {
+	args := m.Called(to)
+	return args.Error(0)
+}
+
+func (m *MockSender) SendAccountLocked(to string, token string, until time.Time) error {
+	args := m.Called(to, token, until)
+	return args.Error(0)
+}
+
 // TestRegisterUser_Success verifies that a new user can be registered successfully
 // and that a confirmation email is sent.
 func TestRegisterUser_Success(t *testing.T) {
 	mockRepo := new(MockUserRepo)
 	mockMailer := new(MockSender)
 
-	svc := auth.NewService(mockRepo, mockMailer)
+	svc := auth.NewService(mockRepo, mockMailer, stubSigner{}, passwordpolicy.Default(), testHasher, onetimetoken.NewService(newMemoryTokens()))
 
 	email := "test@example.com"
 	password := "securepass"
@@ -142,15 +447,25 @@ func TestRegisterUser_Success(t *testing.T) {
 		Role:  role,
 	}
 
+	mockRepo.On("EmailExists", email).Return(false, nil)
 	mockRepo.On("Create", mock.AnythingOfType("*auth.User")).Return(expectedUser, nil)
-	mockMailer.On("SendConfirmation", email, mock.AnythingOfType("string")).Return(nil)
+	sent := make(chan struct{})
+	mockMailer.On("SendConfirmation", email, mock.AnythingOfType("string")).Return(nil).
+		Run(func(mock.Arguments) { close(sent) })
 
-	user, err := svc.RegisterUser(email, password, role)
+	user, err := svc.RegisterUser(email, password, role, "")
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedUser.Email, user.Email)
 	assert.Equal(t, expectedUser.Role, user.Role)
 
+	// The confirmation email is sent asynchronously.
+	select {
+	case <-sent:
+	case <-time.After(time.Second):
+		t.Fatal("confirmation email was not sent")
+	}
+
 	mockRepo.AssertExpectations(t)
 	mockMailer.AssertExpectations(t)
 }
@@ -160,7 +475,7 @@ func TestRegisterUser_Success(t *testing.T) {
 func TestRegisterUser_EmailAlreadyExists(t *testing.T) {
 	mockRepo := new(MockUserRepo)
 	mockMailer := new(MockSender)
-	svc := auth.NewService(mockRepo, mockMailer)
+	svc := auth.NewService(mockRepo, mockMailer, stubSigner{}, passwordpolicy.Default(), testHasher, onetimetoken.NewService(newMemoryTokens()))
 
 	email := "existing@example.com"
 	password := "securepass"
@@ -168,7 +483,7 @@ func TestRegisterUser_EmailAlreadyExists(t *testing.T) {
 
 	mockRepo.On("EmailExists", email).Return(true, nil)
 
-	user, err := svc.RegisterUser(email, password, role)
+	user, err := svc.RegisterUser(email, password, role, "")
 
 	assert.Nil(t, user)
 	assert.ErrorIs(t, err, auth.ErrEmailExists)
@@ -181,13 +496,13 @@ func TestRegisterUser_EmailAlreadyExists(t *testing.T) {
 func TestRegisterUser_WeakPassword(t *testing.T) {
 	mockRepo := new(MockUserRepo)
 	mockMailer := new(MockSender)
-	svc := auth.NewService(mockRepo, mockMailer)
+	svc := auth.NewService(mockRepo, mockMailer, stubSigner{}, passwordpolicy.Default(), testHasher, onetimetoken.NewService(newMemoryTokens()))
 
 	email := "test@example.com"
 	password := "123"
 	role := "ROLE_HOMEOWNER"
 
-	user, err := svc.RegisterUser(email, password, role)
+	user, err := svc.RegisterUser(email, password, role, "")
 
 	assert.Nil(t, user)
 	assert.ErrorIs(t, err, auth.ErrWeakPassword)
@@ -198,14 +513,994 @@ func TestRegisterUser_WeakPassword(t *testing.T) {
 func TestRegisterUser_InvalidEmail(t *testing.T) {
 	mockRepo := new(MockUserRepo)
 	mockMailer := new(MockSender)
-	svc := auth.NewService(mockRepo, mockMailer)
+	svc := auth.NewService(mockRepo, mockMailer, stubSigner{}, passwordpolicy.Default(), testHasher, onetimetoken.NewService(newMemoryTokens()))
 
 	email := "invalid-email"
 	password := "securepass"
 	role := "ROLE_HOMEOWNER"
 
-	user, err := svc.RegisterUser(email, password, role)
+	user, err := svc.RegisterUser(email, password, role, "")
 
 	assert.Nil(t, user)
 	assert.ErrorIs(t, err, auth.ErrInvalidEmail)
 }
+
+// TestRegisterUser_UnsupportedLanguage verifies that registration fails
+// when emails cannot be rendered in the requested language.
+func TestRegisterUser_UnsupportedLanguage(t *testing.T) {
+	mockRepo := new(MockUserRepo)
+	mockMailer := new(MockSender)
+	svc := auth.NewService(mockRepo, mockMailer, stubSigner{}, passwordpolicy.Default(), testHasher, onetimetoken.NewService(newMemoryTokens()))
+
+	user, err := svc.RegisterUser("test@example.com", "securepass", "ROLE_HOMEOWNER", "fr")
+
+	assert.Nil(t, user)
+	assert.ErrorIs(t, err, auth.ErrInvalidLanguage)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+// TestLogin_RehashesLegacyHash verifies that a successful login with a bcrypt hash
+// stores an Argon2id hash of the same password.
+func TestLogin_RehashesLegacyHash(t *testing.T) {
+	mockRepo := new(MockUserRepo)
+	mockMailer := new(MockSender)
+	svc := auth.NewService(mockRepo, mockMailer, stubSigner{}, passwordpolicy.Default(), testHasher, onetimetoken.NewService(newMemoryTokens()))
+
+	legacy, err := bcrypt.GenerateFromPassword([]byte("securepass"), bcrypt.MinCost)
+	assert.NoError(t, err)
+
+	user := &auth.User{ID: "user-id", Email: "test@example.com", Password: string(legacy), Role: "ROLE_HOMEOWNER"}
+
+	var rehashed string
+	mockRepo.On("GetByEmail", "test@example.com").Return(user, nil)
+	mockRepo.On("UpdateUserPassword", "user-id", mock.AnythingOfType("string")).Return(nil).
+		Run(func(args mock.Arguments) { rehashed = args.String(1) })
+	mockRepo.On("GetActiveBlock", "user-id").Return((*domainuser.AccountBlock)(nil), nil)
+	mockRepo.On("CreateSession", mock.AnythingOfType("*auth.Session")).Return(nil)
+	mockRepo.On("GetRolePermissions", "ROLE_HOMEOWNER").Return([]string{}, nil)
+	mockRepo.On("StoreRefreshToken", mock.AnythingOfType("*auth.RefreshToken")).Return(nil)
+
+	result, err := svc.Login("test@example.com", "securepass", auth.SessionInfo{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "user-id", result.User.ID)
+	assert.Contains(t, rehashed, "$argon2id$")
+
+	match, needsRehash, err := testHasher.Verify(rehashed, "securepass")
+	assert.NoError(t, err)
+	assert.True(t, match)
+	assert.False(t, needsRehash)
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestLogin_WrongPasswordKeepsHash verifies that a failed login never rewrites the hash.
+func TestLogin_WrongPasswordKeepsHash(t *testing.T) {
+	mockRepo := new(MockUserRepo)
+	mockMailer := new(MockSender)
+	svc := auth.NewService(mockRepo, mockMailer, stubSigner{}, passwordpolicy.Default(), testHasher, onetimetoken.NewService(newMemoryTokens()))
+
+	legacy, err := bcrypt.GenerateFromPassword([]byte("securepass"), bcrypt.MinCost)
+	assert.NoError(t, err)
+
+	mockRepo.On("GetByEmail", "test@example.com").
+		Return(&auth.User{ID: "user-id", Email: "test@example.com", Password: string(legacy)}, nil)
+	mockRepo.On("RecordFailedLogin", "user-id").Return(1, nil)
+
+	result, err := svc.Login("test@example.com", "wrongpass", auth.SessionInfo{})
+
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+	mockRepo.AssertNotCalled(t, "UpdateUserPassword", mock.Anything, mock.Anything)
+}
+
+// TestLogin_LocksAccountAtThreshold verifies that the failure reaching the threshold
+// locks the account, records it in the security log and emails an unlock link.
+func TestLogin_LocksAccountAtThreshold(t *testing.T) {
+	mockRepo := new(MockUserRepo)
+	mockMailer := new(MockSender)
+	svc := auth.NewService(mockRepo, mockMailer, stubSigner{}, passwordpolicy.Default(), testHasher, onetimetoken.NewService(newMemoryTokens()))
+
+	hash, err := testHasher.Hash("securepass")
+	assert.NoError(t, err)
+
+	mockRepo.On("GetByEmail", "test@example.com").
+		Return(&auth.User{ID: "user-id", Email: "test@example.com", Password: hash, FailedLoginAttempts: 4}, nil)
+	mockRepo.On("RecordFailedLogin", "user-id").Return(5, nil)
+	mockRepo.On("LockAccount", "user-id", mock.AnythingOfType("time.Time"), mock.MatchedBy(func(event domainuser.SecurityEvent) bool {
+		return event.Type == domainuser.SecurityEventAccountLocked && event.UserID == "user-id" && event.IP == "10.0.0.1"
+	})).Return(nil)
+	sent := make(chan struct{})
+	mockMailer.On("SendAccountLocked", "test@example.com", mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).
+		Return(nil).Run(func(mock.Arguments) { close(sent) })
+
+	result, err := svc.Login("test@example.com", "wrongpass", auth.SessionInfo{IP: "10.0.0.1"})
+
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, auth.ErrAccountLocked)
+
+	select {
+	case <-sent:
+	case <-time.After(time.Second):
+		t.Fatal("account locked email was not sent")
+	}
+
+	mockRepo.AssertExpectations(t)
+	mockMailer.AssertExpectations(t)
+}
+
+// TestUnlockAccount_TokenExpires verifies that the emailed unlock link works once
+// and stops working after its TTL.
+func TestUnlockAccount_TokenExpires(t *testing.T) {
+	mockRepo := new(MockUserRepo)
+	mockMailer := new(MockSender)
+	store := newMemoryTokens()
+	svc := auth.NewService(mockRepo, mockMailer, stubSigner{}, passwordpolicy.Default(), testHasher, onetimetoken.NewService(store))
+
+	hash, err := testHasher.Hash("securepass")
+	assert.NoError(t, err)
+
+	mockRepo.On("GetByEmail", "test@example.com").
+		Return(&auth.User{ID: "user-id", Email: "test@example.com", Password: hash, FailedLoginAttempts: 4}, nil)
+	mockRepo.On("RecordFailedLogin", "user-id").Return(5, nil)
+	mockRepo.On("LockAccount", "user-id", mock.AnythingOfType("time.Time"), mock.Anything).Return(nil)
+	mockRepo.On("UnlockAccount", "user-id", mock.MatchedBy(func(event domainuser.SecurityEvent) bool {
+		return event.Type == domainuser.SecurityEventAccountUnlocked && event.UserID == "user-id"
+	})).Return(nil)
+	sent := make(chan string, 1)
+	mockMailer.On("SendAccountLocked", "test@example.com", mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).
+		Return(nil).Run(func(args mock.Arguments) { sent <- args.String(1) })
+
+	_, err = svc.Login("test@example.com", "wrongpass", auth.SessionInfo{})
+	assert.ErrorIs(t, err, auth.ErrAccountLocked)
+
+	var token string
+	select {
+	case token = <-sent:
+	case <-time.After(time.Second):
+		t.Fatal("account locked email was not sent")
+	}
+
+	stored := store.only(t)
+	assert.Equal(t, onetimetoken.PurposeAccountUnlock, stored.Purpose)
+	assert.WithinDuration(t, time.Now().Add(24*time.Hour), stored.ExpiresAt, time.Minute)
+
+	stored.ExpiresAt = time.Now().Add(-time.Second)
+	assert.ErrorIs(t, svc.UnlockAccount(token, "10.0.0.1"), auth.ErrInvalidUnlockToken)
+	mockRepo.AssertNotCalled(t, "UnlockAccount", mock.Anything, mock.Anything)
+
+	stored.ExpiresAt = time.Now().Add(time.Hour)
+	assert.NoError(t, svc.UnlockAccount(token, "10.0.0.1"))
+	assert.ErrorIs(t, svc.UnlockAccount(token, "10.0.0.1"), auth.ErrInvalidUnlockToken)
+	mockRepo.AssertNumberOfCalls(t, "UnlockAccount", 1)
+}
+
+// TestLogin_LockedAccountRejectsCorrectPassword verifies that a locked account
+// cannot log in, even with the right password, and that nothing is counted.
+func TestLogin_LockedAccountRejectsCorrectPassword(t *testing.T) {
+	mockRepo := new(MockUserRepo)
+	mockMailer := new(MockSender)
+	svc := auth.NewService(mockRepo, mockMailer, stubSigner{}, passwordpolicy.Default(), testHasher, onetimetoken.NewService(newMemoryTokens()))
+
+	hash, err := testHasher.Hash("securepass")
+	assert.NoError(t, err)
+	lockedUntil := time.Now().Add(time.Minute)
+
+	mockRepo.On("GetByEmail", "test@example.com").
+		Return(&auth.User{ID: "user-id", Email: "test@example.com", Password: hash, FailedLoginAttempts: 5, LockedUntil: &lockedUntil}, nil)
+
+	result, err := svc.Login("test@example.com", "securepass", auth.SessionInfo{})
+
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+	var lockedErr *auth.AccountLockedError
+	assert.ErrorAs(t, err, &lockedErr)
+	assert.WithinDuration(t, lockedUntil, lockedErr.Until, 0)
+	mockRepo.AssertNotCalled(t, "RecordFailedLogin", mock.Anything)
+	mockRepo.AssertNotCalled(t, "CreateSession", mock.Anything)
+}
+
+// countingHasher counts password checks.
+type countingHasher struct {
+	auth.PasswordHasher
+	verified int
+}
+
+func (h *countingHasher) Verify(hash, password string) (bool, bool, error) {
+	h.verified++
+	return h.PasswordHasher.Verify(hash, password)
+}
+
+// TestLogin_UnknownEmailChecksPassword verifies that unknown emails get the same error as
+// a wrong password and still spend the time of a password check.
+func TestLogin_UnknownEmailChecksPassword(t *testing.T) {
+	mockRepo := new(MockUserRepo)
+	mockMailer := new(MockSender)
+	hasher := &countingHasher{PasswordHasher: testHasher}
+	svc := auth.NewService(mockRepo, mockMailer, stubSigner{}, passwordpolicy.Default(), hasher, onetimetoken.NewService(newMemoryTokens()))
+
+	mockRepo.On("GetByEmail", "nobody@example.com").Return((*auth.User)(nil), nil)
+
+	result, err := svc.Login("nobody@example.com", "securepass", auth.SessionInfo{})
+
+	assert.Nil(t, result)
+	assert.Equal(t, auth.ErrInvalidCredentials, err)
+	assert.Equal(t, 1, hasher.verified)
+}
+
+// TestLogin_SuccessResetsFailures verifies that a successful login clears earlier failures.
+func TestLogin_SuccessResetsFailures(t *testing.T) {
+	mockRepo := new(MockUserRepo)
+	mockMailer := new(MockSender)
+	svc := auth.NewService(mockRepo, mockMailer, stubSigner{}, passwordpolicy.Default(), testHasher, onetimetoken.NewService(newMemoryTokens()))
+
+	hash, err := testHasher.Hash("securepass")
+	assert.NoError(t, err)
+
+	mockRepo.On("GetByEmail", "test@example.com").
+		Return(&auth.User{ID: "user-id", Email: "test@example.com", Password: hash, Role: "ROLE_HOMEOWNER", FailedLoginAttempts: 3}, nil)
+	mockRepo.On("ResetFailedLogins", "user-id").Return(nil)
+	mockRepo.On("GetActiveBlock", "user-id").Return((*domainuser.AccountBlock)(nil), nil)
+	mockRepo.On("CreateSession", mock.AnythingOfType("*auth.Session")).Return(nil)
+	mockRepo.On("GetRolePermissions", "ROLE_HOMEOWNER").Return([]string{}, nil)
+	mockRepo.On("StoreRefreshToken", mock.AnythingOfType("*auth.RefreshToken")).Return(nil)
+
+	_, err = svc.Login("test@example.com", "securepass", auth.SessionInfo{})
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestLogin_TwoFactorKeepsFailures verifies that the password step of a two-factor
+// login does not clear earlier failures, because the second factor is still missing.
+func TestLogin_TwoFactorKeepsFailures(t *testing.T) {
+	mockRepo := new(MockUserRepo)
+	mockMailer := new(MockSender)
+	svc := auth.NewService(mockRepo, mockMailer, stubSigner{}, passwordpolicy.Default(), testHasher, onetimetoken.NewService(newMemoryTokens()))
+
+	hash, err := testHasher.Hash("securepass")
+	assert.NoError(t, err)
+
+	mockRepo.On("GetByEmail", "test@example.com").
+		Return(&auth.User{ID: "user-id", Email: "test@example.com", Password: hash, TOTPEnabled: true, FailedLoginAttempts: 3}, nil)
+	mockRepo.On("GetActiveBlock", "user-id").Return((*domainuser.AccountBlock)(nil), nil)
+	mockRepo.On("CreateMFAChallenge", mock.AnythingOfType("*auth.MFAChallenge")).Return(nil)
+
+	result, err := svc.Login("test@example.com", "securepass", auth.SessionInfo{})
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, result.ChallengeToken)
+	mockRepo.AssertNotCalled(t, "ResetFailedLogins", mock.Anything)
+}
+
+// TestMagicLink_RoundTrip verifies that an emailed magic link logs in the browser
+// holding the nonce and is stored only as hashes.
+func TestMagicLink_RoundTrip(t *testing.T) {
+	mockRepo := new(MockUserRepo)
+	mockMailer := new(MockSender)
+	store := newMemoryTokens()
+	svc := auth.NewService(mockRepo, mockMailer, stubSigner{}, passwordpolicy.Default(), testHasher, onetimetoken.NewService(store))
+
+	user := &auth.User{ID: "user-id", Email: "test@example.com", Role: "ROLE_HOMEOWNER"}
+
+	mockRepo.On("GetByEmail", "test@example.com").Return(user, nil)
+	tokens := make(chan string, 1)
+	mockMailer.On("SendMagicLink", "test@example.com", mock.AnythingOfType("string")).Return(nil).
+		Run(func(args mock.Arguments) { tokens <- args.String(1) })
+
+	nonce, err := svc.RequestMagicLink("test@example.com")
+	assert.NoError(t, err)
+
+	var token string
+	select {
+	case token = <-tokens:
+	case <-time.After(time.Second):
+		t.Fatal("magic link email was not sent")
+	}
+	stored := store.only(t)
+	assert.Equal(t, onetimetoken.PurposeLoginLink, stored.Purpose)
+	assert.Equal(t, sha256Hex(token), stored.TokenHash)
+	assert.Equal(t, sha256Hex(nonce), *stored.BindingHash)
+
+	result, err := svc.LoginWithMagicLink(token, "other-browser", auth.SessionInfo{})
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, auth.ErrInvalidMagicLink)
+
+	mockRepo.On("GetByID", "user-id").Return(user, nil)
+	mockRepo.On("GetActiveBlock", "user-id").Return((*domainuser.AccountBlock)(nil), nil)
+	mockRepo.On("CreateSession", mock.AnythingOfType("*auth.Session")).Return(nil)
+	mockRepo.On("GetRolePermissions", "ROLE_HOMEOWNER").Return([]string{}, nil)
+	mockRepo.On("StoreRefreshToken", mock.AnythingOfType("*auth.RefreshToken")).Return(nil)
+
+	result, err = svc.LoginWithMagicLink(token, nonce, auth.SessionInfo{})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, result.AccessToken)
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestMagicLink_UsedLinkRejected verifies that a magic link works only once.
+func TestMagicLink_UsedLinkRejected(t *testing.T) {
+	mockRepo := new(MockUserRepo)
+	mockMailer := new(MockSender)
+	store := newMemoryTokens()
+	svc := auth.NewService(mockRepo, mockMailer, stubSigner{}, passwordpolicy.Default(), testHasher, onetimetoken.NewService(store))
+
+	nonceHash := sha256Hex("nonce")
+	usedAt := time.Now().Add(-time.Minute)
+	assert.NoError(t, store.Create(&onetimetoken.Token{
+		ID:          "link-id",
+		Purpose:     onetimetoken.PurposeLoginLink,
+		UserID:      "user-id",
+		TokenHash:   sha256Hex("token"),
+		BindingHash: &nonceHash,
+		MaxAttempts: 3,
+		ExpiresAt:   time.Now().Add(time.Minute),
+		UsedAt:      &usedAt,
+	}, false))
+
+	result, err := svc.LoginWithMagicLink("token", "nonce", auth.SessionInfo{})
+
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, auth.ErrInvalidMagicLink)
+	mockRepo.AssertNotCalled(t, "GetByID", mock.Anything)
+}
+
+// TestChangePassword_RevokesOtherSessions verifies that a password change keeps
+// only the current session.
+func TestChangePassword_RevokesOtherSessions(t *testing.T) {
+	mockRepo := new(MockUserRepo)
+	mockMailer := new(MockSender)
+	svc := auth.NewService(mockRepo, mockMailer, stubSigner{}, passwordpolicy.Default(), testHasher, onetimetoken.NewService(newMemoryTokens()))
+
+	hash, err := testHasher.Hash("oldsecret1")
+	assert.NoError(t, err)
+
+	mockRepo.On("GetByID", "user-id").Return(&auth.User{ID: "user-id", Email: "test@example.com", Password: hash}, nil)
+	mockRepo.On("GetProfile", "user-id").Return((*auth.UserProfile)(nil), nil)
+	mockRepo.On("UpdateUserPassword", "user-id", mock.AnythingOfType("string")).Return(nil)
+	mockRepo.On("RevokeUserSessions", "user-id", "session-id").Return(nil)
+
+	err = svc.ChangePassword("user-id", "session-id", "oldsecret1", "newsecret2")
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestChangePassword_WrongCurrentPassword verifies that nothing changes without the current password.
+func TestChangePassword_WrongCurrentPassword(t *testing.T) {
+	mockRepo := new(MockUserRepo)
+	mockMailer := new(MockSender)
+	svc := auth.NewService(mockRepo, mockMailer, stubSigner{}, passwordpolicy.Default(), testHasher, onetimetoken.NewService(newMemoryTokens()))
+
+	hash, err := testHasher.Hash("oldsecret1")
+	assert.NoError(t, err)
+
+	mockRepo.On("GetByID", "user-id").Return(&auth.User{ID: "user-id", Password: hash}, nil)
+
+	err = svc.ChangePassword("user-id", "session-id", "guessed", "newsecret2")
+
+	assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+	mockRepo.AssertNotCalled(t, "UpdateUserPassword", mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "RevokeUserSessions", mock.Anything, mock.Anything)
+}
+
+// TestRequestEmailChange_TakenAddress verifies that an email change cannot target a registered address.
+func TestRequestEmailChange_TakenAddress(t *testing.T) {
+	mockRepo := new(MockUserRepo)
+	mockMailer := new(MockSender)
+	svc := auth.NewService(mockRepo, mockMailer, stubSigner{}, passwordpolicy.Default(), testHasher, onetimetoken.NewService(newMemoryTokens()))
+
+	hash, err := testHasher.Hash("secret123")
+	assert.NoError(t, err)
+
+	mockRepo.On("GetByID", "user-id").Return(&auth.User{ID: "user-id", Email: "old@example.com", Password: hash}, nil)
+	mockRepo.On("EmailExists", "taken@example.com").Return(true, nil)
+
+	err = svc.RequestEmailChange("user-id", "taken@example.com", "secret123")
+
+	assert.ErrorIs(t, err, auth.ErrEmailExists)
+	mockRepo.AssertNotCalled(t, "CreateEmailChange", mock.Anything)
+}
+
+// TestConfirmEmailChange_AlertsOldAddress verifies that a confirmed change is applied
+// and the old address receives an undo link.
+func TestConfirmEmailChange_AlertsOldAddress(t *testing.T) {
+	mockRepo := new(MockUserRepo)
+	mockMailer := new(MockSender)
+	svc := auth.NewService(mockRepo, mockMailer, stubSigner{}, passwordpolicy.Default(), testHasher, onetimetoken.NewService(newMemoryTokens()))
+
+	change := &auth.EmailChange{
+		ID:        "change-id",
+		UserID:    "user-id",
+		OldEmail:  "old@example.com",
+		NewEmail:  "new@example.com",
+		TokenHash: sha256Hex("token"),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	mockRepo.On("GetEmailChange", sha256Hex("token")).Return(change, nil)
+	mockRepo.On("EmailExists", "new@example.com").Return(false, nil)
+	mockRepo.On("ApplyEmailChange", "change-id", mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(true, nil)
+	sent := make(chan struct{})
+	mockMailer.On("SendEmailChangedAlert", "old@example.com", "new@example.com", mock.AnythingOfType("string")).Return(nil).
+		Run(func(mock.Arguments) { close(sent) })
+
+	err := svc.ConfirmEmailChange("token")
+
+	assert.NoError(t, err)
+	select {
+	case <-sent:
+	case <-time.After(time.Second):
+		t.Fatal("email changed alert was not sent")
+	}
+	mockRepo.AssertExpectations(t)
+}
+
+// TestRefresh_RotatesToken verifies that a valid refresh token is marked as used
+// and replaced by a new token of the same session.
+func TestRefresh_RotatesToken(t *testing.T) {
+	mockRepo := new(MockUserRepo)
+	mockMailer := new(MockSender)
+	svc := auth.NewService(mockRepo, mockMailer, stubSigner{}, passwordpolicy.Default(), testHasher, onetimetoken.NewService(newMemoryTokens()))
+
+	stored := &auth.RefreshToken{
+		ID:        "token-id",
+		UserID:    "user-id",
+		SessionID: "session-id",
+		TokenHash: sha256Hex("old-token"),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	mockRepo.On("GetRefreshToken", sha256Hex("old-token")).Return(stored, nil)
+	mockRepo.On("MarkRefreshTokenUsed", "token-id").Return(true, nil)
+	mockRepo.On("GetSession", "session-id").Return(&auth.Session{ID: "session-id", UserID: "user-id"}, nil)
+	mockRepo.On("GetByID", "user-id").Return(&auth.User{ID: "user-id", Role: "ROLE_HOMEOWNER"}, nil)
+	mockRepo.On("GetActiveBlock", "user-id").Return((*domainuser.AccountBlock)(nil), nil)
+	mockRepo.On("TouchSession", "session-id", "127.0.0.1").Return(nil)
+	mockRepo.On("GetRolePermissions", "ROLE_HOMEOWNER").Return([]string{}, nil)
+	mockRepo.On("StoreRefreshToken", mock.MatchedBy(func(token *auth.RefreshToken) bool {
+		return token.SessionID == "session-id" && token.UserID == "user-id" && token.TokenHash != sha256Hex("old-token")
+	})).Return(nil)
+
+	result, err := svc.Refresh("old-token", auth.SessionInfo{IP: "127.0.0.1"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "user-id", result.User.ID)
+	assert.NotEmpty(t, result.AccessToken)
+	assert.NotEqual(t, "old-token", result.RefreshToken)
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestLogin_BlockedAccount verifies that a suspended user gets the end of the suspension
+// instead of a session.
+func TestLogin_BlockedAccount(t *testing.T) {
+	mockRepo := new(MockUserRepo)
+	mockMailer := new(MockSender)
+	svc := auth.NewService(mockRepo, mockMailer, stubSigner{}, passwordpolicy.Default(), testHasher, onetimetoken.NewService(newMemoryTokens()))
+
+	hash, err := testHasher.Hash("securepass")
+	assert.NoError(t, err)
+	until := time.Now().Add(time.Hour)
+
+	mockRepo.On("GetByEmail", "test@example.com").
+		Return(&auth.User{ID: "user-id", Email: "test@example.com", Password: hash, Role: "ROLE_HOMEOWNER"}, nil)
+	mockRepo.On("GetActiveBlock", "user-id").
+		Return(&domainuser.AccountBlock{Kind: domainuser.BlockSuspension, Reason: "spam", EndsAt: &until}, nil)
+
+	result, err := svc.Login("test@example.com", "securepass", auth.SessionInfo{})
+
+	assert.Nil(t, result)
+	var blockedErr *auth.AccountBlockedError
+	assert.ErrorAs(t, err, &blockedErr)
+	assert.ErrorIs(t, err, auth.ErrAccountBlocked)
+	assert.Equal(t, domainuser.BlockSuspension, blockedErr.Kind)
+	assert.WithinDuration(t, until, *blockedErr.Until, 0)
+	mockRepo.AssertNotCalled(t, "CreateSession", mock.Anything)
+}
+
+// TestRefresh_BlockedAccount verifies that a banned user cannot renew their tokens.
+func TestRefresh_BlockedAccount(t *testing.T) {
+	mockRepo := new(MockUserRepo)
+	mockMailer := new(MockSender)
+	svc := auth.NewService(mockRepo, mockMailer, stubSigner{}, passwordpolicy.Default(), testHasher, onetimetoken.NewService(newMemoryTokens()))
+
+	stored := &auth.RefreshToken{
+		ID:        "token-id",
+		UserID:    "user-id",
+		SessionID: "session-id",
+		TokenHash: sha256Hex("old-token"),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	mockRepo.On("GetRefreshToken", sha256Hex("old-token")).Return(stored, nil)
+	mockRepo.On("MarkRefreshTokenUsed", "token-id").Return(true, nil)
+	mockRepo.On("GetSession", "session-id").Return(&auth.Session{ID: "session-id", UserID: "user-id"}, nil)
+	mockRepo.On("GetByID", "user-id").Return(&auth.User{ID: "user-id", Role: "ROLE_HOMEOWNER"}, nil)
+	mockRepo.On("GetActiveBlock", "user-id").Return(&domainuser.AccountBlock{Kind: domainuser.BlockBan, Reason: "fraud"}, nil)
+
+	result, err := svc.Refresh("old-token", auth.SessionInfo{})
+
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, auth.ErrAccountBlocked)
+	mockRepo.AssertNotCalled(t, "StoreRefreshToken", mock.Anything)
+}
+
+// TestRefresh_ReuseRevokesSession verifies that presenting an already rotated
+// refresh token revokes the whole session.
+func TestRefresh_ReuseRevokesSession(t *testing.T) {
+	mockRepo := new(MockUserRepo)
+	mockMailer := new(MockSender)
+	svc := auth.NewService(mockRepo, mockMailer, stubSigner{}, passwordpolicy.Default(), testHasher, onetimetoken.NewService(newMemoryTokens()))
+
+	usedAt := time.Now().Add(-time.Minute)
+	stored := &auth.RefreshToken{
+		ID:        "token-id",
+		UserID:    "user-id",
+		SessionID: "session-id",
+		TokenHash: sha256Hex("old-token"),
+		ExpiresAt: time.Now().Add(time.Hour),
+		UsedAt:    &usedAt,
+	}
+
+	mockRepo.On("GetRefreshToken", sha256Hex("old-token")).Return(stored, nil)
+	mockRepo.On("RevokeSession", "session-id").Return(nil)
+
+	result, err := svc.Refresh("old-token", auth.SessionInfo{})
+
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, auth.ErrRefreshTokenReuse)
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestRevokeSession_ForeignSession verifies that a user cannot revoke
+// a session that belongs to somebody else.
+func TestRevokeSession_ForeignSession(t *testing.T) {
+	mockRepo := new(MockUserRepo)
+	mockMailer := new(MockSender)
+	svc := auth.NewService(mockRepo, mockMailer, stubSigner{}, passwordpolicy.Default(), testHasher, onetimetoken.NewService(newMemoryTokens()))
+
+	mockRepo.On("GetSession", "session-id").Return(&auth.Session{ID: "session-id", UserID: "other-user"}, nil)
+
+	err := svc.RevokeSession("user-id", "session-id")
+
+	assert.ErrorIs(t, err, auth.ErrSessionNotFound)
+	mockRepo.AssertNotCalled(t, "RevokeSession", "session-id")
+}
+
+// TestVerifyLoginChallenge_InvalidCodeCountsAttempt verifies that a wrong second-factor
+// code is rejected and counted against the challenge and toward the account lockout.
+func TestVerifyLoginChallenge_InvalidCodeCountsAttempt(t *testing.T) {
+	mockRepo := new(MockUserRepo)
+	mockMailer := new(MockSender)
+	svc := auth.NewService(mockRepo, mockMailer, stubSigner{}, passwordpolicy.Default(), testHasher, onetimetoken.NewService(newMemoryTokens()))
+
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	challenge := &auth.MFAChallenge{
+		ID:        "challenge-id",
+		UserID:    "user-id",
+		TokenHash: sha256Hex("challenge-token"),
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+
+	mockRepo.On("GetMFAChallenge", sha256Hex("challenge-token")).Return(challenge, nil)
+	mockRepo.On("GetByID", "user-id").Return(&auth.User{ID: "user-id", TOTPEnabled: true, TOTPSecret: &secret}, nil)
+	mockRepo.On("UseRecoveryCode", "user-id", mock.AnythingOfType("string")).Return(false, nil)
+	mockRepo.On("IncrementMFAChallengeAttempts", "challenge-id").Return(nil)
+	mockRepo.On("RecordFailedLogin", "user-id").Return(1, nil)
+
+	result, err := svc.VerifyLoginChallenge("challenge-token", "not-a-code", auth.SessionInfo{})
+
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, auth.ErrInvalidTwoFactorCode)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestVerifyLoginChallenge_LockedAccount verifies that a challenge cannot be completed
+// once wrong codes have locked the account.
+func TestVerifyLoginChallenge_LockedAccount(t *testing.T) {
+	mockRepo := new(MockUserRepo)
+	mockMailer := new(MockSender)
+	svc := auth.NewService(mockRepo, mockMailer, stubSigner{}, passwordpolicy.Default(), testHasher, onetimetoken.NewService(newMemoryTokens()))
+
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	lockedUntil := time.Now().Add(time.Minute)
+	challenge := &auth.MFAChallenge{
+		ID:        "challenge-id",
+		UserID:    "user-id",
+		TokenHash: sha256Hex("challenge-token"),
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+
+	mockRepo.On("GetMFAChallenge", sha256Hex("challenge-token")).Return(challenge, nil)
+	mockRepo.On("GetByID", "user-id").
+		Return(&auth.User{ID: "user-id", TOTPEnabled: true, TOTPSecret: &secret, FailedLoginAttempts: 5, LockedUntil: &lockedUntil}, nil)
+
+	result, err := svc.VerifyLoginChallenge("challenge-token", "123456", auth.SessionInfo{})
+
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, auth.ErrInvalidChallenge)
+	mockRepo.AssertNotCalled(t, "UseRecoveryCode", mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "MarkMFAChallengeUsed", mock.Anything)
+}
+
+// TestIsResetTokenValid_UnknownToken verifies that tokens that were never issued are reported as invalid.
+func TestIsResetTokenValid_UnknownToken(t *testing.T) {
+	mockRepo := new(MockUserRepo)
+	mockMailer := new(MockSender)
+	svc := auth.NewService(mockRepo, mockMailer, stubSigner{}, passwordpolicy.Default(), testHasher, onetimetoken.NewService(newMemoryTokens()))
+
+	valid, err := svc.IsResetTokenValid("unknown")
+
+	assert.NoError(t, err)
+	assert.False(t, valid)
+}
+
+// TestRequestPasswordReset_StoresDigest verifies that only the digest of the emailed token is stored.
+func TestRequestPasswordReset_StoresDigest(t *testing.T) {
+	mockRepo := new(MockUserRepo)
+	mockMailer := new(MockSender)
+	store := newMemoryTokens()
+	svc := auth.NewService(mockRepo, mockMailer, stubSigner{}, passwordpolicy.Default(), testHasher, onetimetoken.NewService(store))
+
+	var sent string
+	mockRepo.On("GetByEmail", "test@example.com").Return(&auth.User{ID: "user-id", Email: "test@example.com"}, nil)
+	mockMailer.On("SendResetPasswordLink", "test@example.com", mock.AnythingOfType("string")).Return(nil).
+		Run(func(args mock.Arguments) { sent = args.String(1) })
+
+	assert.NoError(t, svc.RequestPasswordReset("test@example.com"))
+
+	stored := store.only(t)
+	assert.Equal(t, onetimetoken.PurposePasswordReset, stored.Purpose)
+	assert.NotEqual(t, sent, stored.TokenHash)
+	assert.Equal(t, sha256Hex(sent), stored.TokenHash)
+}
+
+// TestResetPassword_TokenUsedOnce verifies that a reset token changes the password
+// only once and that a rejected password does not use it up.
+func TestResetPassword_TokenUsedOnce(t *testing.T) {
+	mockRepo := new(MockUserRepo)
+	mockMailer := new(MockSender)
+	svc := auth.NewService(mockRepo, mockMailer, stubSigner{}, passwordpolicy.Default(), testHasher, onetimetoken.NewService(newMemoryTokens()))
+
+	var sent string
+	mockRepo.On("GetByEmail", "test@example.com").Return(&auth.User{ID: "user-id", Email: "test@example.com"}, nil)
+	mockMailer.On("SendResetPasswordLink", "test@example.com", mock.AnythingOfType("string")).Return(nil).
+		Run(func(args mock.Arguments) { sent = args.String(1) })
+	assert.NoError(t, svc.RequestPasswordReset("test@example.com"))
+
+	mockRepo.On("GetByID", "user-id").Return(&auth.User{ID: "user-id", Email: "test@example.com"}, nil)
+	mockRepo.On("GetProfile", "user-id").Return((*auth.UserProfile)(nil), nil)
+	mockRepo.On("UpdateUserPassword", "user-id", mock.AnythingOfType("string")).Return(nil)
+
+	assert.ErrorIs(t, svc.ResetPassword(sent, "short"), auth.ErrWeakPassword)
+	assert.NoError(t, svc.ResetPassword(sent, "newsecret2"))
+	assert.ErrorIs(t, svc.ResetPassword(sent, "newsecret3"), auth.ErrInvalidResetToken)
+
+	mockRepo.AssertNumberOfCalls(t, "UpdateUserPassword", 1)
+}
+
+// TestConfirmEmail_ExpiredToken verifies that confirmation tokens stop working after their expiry.
+func TestConfirmEmail_ExpiredToken(t *testing.T) {
+	mockRepo := new(MockUserRepo)
+	mockMailer := new(MockSender)
+	store := newMemoryTokens()
+	svc := auth.NewService(mockRepo, mockMailer, stubSigner{}, passwordpolicy.Default(), testHasher, onetimetoken.NewService(store))
+
+	assert.NoError(t, store.Create(&onetimetoken.Token{
+		ID:          "confirmation-id",
+		Purpose:     onetimetoken.PurposeEmailConfirmation,
+		UserID:      "user-id",
+		TokenHash:   sha256Hex("token"),
+		MaxAttempts: 10,
+		ExpiresAt:   time.Now().Add(-time.Minute),
+	}, false))
+
+	user, err := svc.ConfirmEmail("token")
+
+	assert.Nil(t, user)
+	assert.ErrorIs(t, err, auth.ErrInvalidConfirmationToken)
+	mockRepo.AssertNotCalled(t, "SetEmailConfirmed", mock.Anything)
+}
+
+// TestConfirmEmail_Success verifies that a confirmation link confirms the email once.
+func TestConfirmEmail_Success(t *testing.T) {
+	mockRepo := new(MockUserRepo)
+	mockMailer := new(MockSender)
+	svc := auth.NewService(mockRepo, mockMailer, stubSigner{}, passwordpolicy.Default(), testHasher, onetimetoken.NewService(newMemoryTokens()))
+
+	user := &auth.User{ID: "user-id", Email: "test@example.com"}
+	mockRepo.On("EmailExists", "test@example.com").Return(false, nil)
+	mockRepo.On("Create", mock.AnythingOfType("*auth.User")).Return(user, nil)
+	tokens := make(chan string, 1)
+	mockMailer.On("SendConfirmation", "test@example.com", mock.AnythingOfType("string")).Return(nil).
+		Run(func(args mock.Arguments) { tokens <- args.String(1) })
+
+	_, err := svc.RegisterUser("test@example.com", "securepass", auth.RoleHomeowner, "")
+	assert.NoError(t, err)
+
+	var token string
+	select {
+	case token = <-tokens:
+	case <-time.After(time.Second):
+		t.Fatal("confirmation email was not sent")
+	}
+
+	mockRepo.On("GetByID", "user-id").Return(user, nil)
+	mockRepo.On("SetEmailConfirmed", "user-id").Return(nil)
+
+	confirmed, err := svc.ConfirmEmail(token)
+	assert.NoError(t, err)
+	assert.Equal(t, "user-id", confirmed.ID)
+
+	_, err = svc.ConfirmEmail(token)
+	assert.ErrorIs(t, err, auth.ErrInvalidConfirmationToken)
+	mockRepo.AssertNumberOfCalls(t, "SetEmailConfirmed", 1)
+}
+
+func testProfile() *auth.UserProfile {
+	return &auth.UserProfile{
+		UserID:      "user-id",
+		Salutation:  "Herr",
+		FirstName:   "Max",
+		LastName:    "Muster",
+		Street:      "Hauptstr.",
+		HouseNumber: "1",
+		PostalCode:  "10115",
+		City:        "Berlin",
+	}
+}
+
+// TestAddUserProfile_AlreadyExists verifies that a second profile is refused.
+func TestAddUserProfile_AlreadyExists(t *testing.T) {
+	mockRepo := new(MockUserRepo)
+	mockMailer := new(MockSender)
+	svc := auth.NewService(mockRepo, mockMailer, stubSigner{}, passwordpolicy.Default(), testHasher, onetimetoken.NewService(newMemoryTokens()))
+
+	mockRepo.On("GetByID", "user-id").Return(&auth.User{ID: "user-id", EmailConfirmed: true}, nil)
+	mockRepo.On("GetProfile", "user-id").Return(testProfile(), nil)
+
+	err := svc.AddUserProfile(testProfile())
+
+	assert.ErrorIs(t, err, auth.ErrProfileExists)
+	mockRepo.AssertNotCalled(t, "CreateProfile", mock.Anything, mock.Anything)
+}
+
+// TestUpdateProfile_AddressChangeRequiresModeration verifies that an approved account
+// goes back to pending when its address changes and that the diff is recorded.
+func TestUpdateProfile_AddressChangeRequiresModeration(t *testing.T) {
+	mockRepo := new(MockUserRepo)
+	mockMailer := new(MockSender)
+	svc := auth.NewService(mockRepo, mockMailer, stubSigner{}, passwordpolicy.Default(), testHasher, onetimetoken.NewService(newMemoryTokens()))
+
+	mockRepo.On("GetByID", "user-id").Return(&auth.User{ID: "user-id", Status: auth.StatusApproved}, nil)
+	mockRepo.On("GetProfile", "user-id").Return(testProfile(), nil)
+	mockRepo.On("UpdateProfile", mock.AnythingOfType("*auth.UserProfile"), mock.AnythingOfType("*user.ProfileChange"), mock.Anything).Return(nil)
+
+	city, unchanged := "Hamburg", "Max"
+	user, moderationRequired, err := svc.UpdateProfile("user-id", auth.ProfileUpdate{City: &city, FirstName: &unchanged})
+
+	assert.NoError(t, err)
+	assert.True(t, moderationRequired)
+	assert.Equal(t, auth.StatusPending, user.Status)
+	assert.Equal(t, "Hamburg", user.Profile.City)
+
+	change := mockRepo.Calls[len(mockRepo.Calls)-1].Arguments.Get(1).(*domainuser.ProfileChange)
+	assert.Equal(t, domainuser.ProfileDiff{"city": {Old: "Berlin", New: "Hamburg"}}, change.Changes)
+	assert.Equal(t, auth.StatusApproved, change.PreviousStatus)
+	assert.Equal(t, auth.StatusPending, change.NewStatus)
+
+	statusChange := mockRepo.Calls[len(mockRepo.Calls)-1].Arguments.Get(2).(*domainuser.StatusChange)
+	assert.Equal(t, domainuser.TransitionProfileEdited, statusChange.Transition)
+	assert.Nil(t, statusChange.ActorID)
+}
+
+// TestUpdateProfile_SalutationKeepsStatus verifies that edits moderators do not review
+// are recorded without changing the account status.
+func TestUpdateProfile_SalutationKeepsStatus(t *testing.T) {
+	mockRepo := new(MockUserRepo)
+	mockMailer := new(MockSender)
+	svc := auth.NewService(mockRepo, mockMailer, stubSigner{}, passwordpolicy.Default(), testHasher, onetimetoken.NewService(newMemoryTokens()))
+
+	mockRepo.On("GetByID", "user-id").Return(&auth.User{ID: "user-id", Status: auth.StatusApproved}, nil)
+	mockRepo.On("GetProfile", "user-id").Return(testProfile(), nil)
+	mockRepo.On("UpdateProfile", mock.AnythingOfType("*auth.UserProfile"), mock.AnythingOfType("*user.ProfileChange"), mock.Anything).Return(nil)
+
+	salutation := "Frau"
+	user, moderationRequired, err := svc.UpdateProfile("user-id", auth.ProfileUpdate{Salutation: &salutation})
+
+	assert.NoError(t, err)
+	assert.False(t, moderationRequired)
+	assert.Equal(t, auth.StatusApproved, user.Status)
+	assert.Nil(t, mockRepo.Calls[len(mockRepo.Calls)-1].Arguments.Get(2))
+}
+
+// TestUpdateProfile_PendingApprovedMeanwhile verifies that an edit computed for a pending
+// user is refused once the repository finds the account approved under its lock.
+func TestUpdateProfile_PendingApprovedMeanwhile(t *testing.T) {
+	mockRepo := new(MockUserRepo)
+	mockMailer := new(MockSender)
+	svc := auth.NewService(mockRepo, mockMailer, stubSigner{}, passwordpolicy.Default(), testHasher, onetimetoken.NewService(newMemoryTokens()))
+
+	mockRepo.On("GetByID", "user-id").Return(&auth.User{ID: "user-id", Status: auth.StatusPending}, nil)
+	mockRepo.On("GetProfile", "user-id").Return(testProfile(), nil)
+	mockRepo.On("UpdateProfile", mock.AnythingOfType("*auth.UserProfile"),
+		mock.MatchedBy(func(c *domainuser.ProfileChange) bool { return c.PreviousStatus == auth.StatusPending }),
+		mock.Anything).Return(domainuser.ErrStatusConflict)
+
+	city := "Hamburg"
+	_, _, err := svc.UpdateProfile("user-id", auth.ProfileUpdate{City: &city})
+
+	assert.ErrorIs(t, err, domainuser.ErrStatusConflict)
+}
+
+// TestUpdateProfile_TrimsAndRejectsBlankValues verifies that values are stored trimmed
+// and that whitespace-only values are refused before anything is loaded.
+func TestUpdateProfile_TrimsAndRejectsBlankValues(t *testing.T) {
+	mockRepo := new(MockUserRepo)
+	mockMailer := new(MockSender)
+	svc := auth.NewService(mockRepo, mockMailer, stubSigner{}, passwordpolicy.Default(), testHasher, onetimetoken.NewService(newMemoryTokens()))
+
+	blank := "   "
+	_, _, err := svc.UpdateProfile("user-id", auth.ProfileUpdate{City: &blank})
+	assert.ErrorIs(t, err, auth.ErrBlankProfileField)
+	mockRepo.AssertNotCalled(t, "GetByID", mock.Anything)
+
+	mockRepo.On("GetByID", "user-id").Return(&auth.User{ID: "user-id", Status: auth.StatusPending}, nil)
+	mockRepo.On("GetProfile", "user-id").Return(testProfile(), nil)
+	mockRepo.On("UpdateProfile", mock.AnythingOfType("*auth.UserProfile"), mock.AnythingOfType("*user.ProfileChange"), mock.Anything).Return(nil)
+
+	city := "  Hamburg "
+	user, _, err := svc.UpdateProfile("user-id", auth.ProfileUpdate{City: &city})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Hamburg", user.Profile.City)
+}
+
+// TestUpdateProfile_NoChanges verifies that an update without differences stores nothing.
+func TestUpdateProfile_NoChanges(t *testing.T) {
+	mockRepo := new(MockUserRepo)
+	mockMailer := new(MockSender)
+	svc := auth.NewService(mockRepo, mockMailer, stubSigner{}, passwordpolicy.Default(), testHasher, onetimetoken.NewService(newMemoryTokens()))
+
+	mockRepo.On("GetByID", "user-id").Return(&auth.User{ID: "user-id", Status: auth.StatusApproved}, nil)
+	mockRepo.On("GetProfile", "user-id").Return(testProfile(), nil)
+
+	city, title := "Berlin", ""
+	_, moderationRequired, err := svc.UpdateProfile("user-id", auth.ProfileUpdate{City: &city, Title: &title})
+
+	assert.NoError(t, err)
+	assert.False(t, moderationRequired)
+	mockRepo.AssertNotCalled(t, "UpdateProfile", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func rejectedUser(mockRepo *MockUserRepo) {
+	mockRepo.On("GetByID", "user-id").Return(&auth.User{ID: "user-id", Status: auth.StatusRejected}, nil)
+	mockRepo.On("GetProfile", "user-id").Return(testProfile(), nil)
+	mockRepo.On("GetLatestRejection", "user-id").Return(&domainuser.Rejection{
+		ID:      "rejection-id",
+		Reasons: domainuser.RejectionReasons{"lastName": "illegible", "city": "unknown city", "document": "missing"},
+	}, nil)
+}
+
+// TestGetRejection_MapsReasonsToFields verifies that reasons are keyed by canonical
+// profile field in form order and carry the current value.
+func TestGetRejection_MapsReasonsToFields(t *testing.T) {
+	mockRepo := new(MockUserRepo)
+	mockMailer := new(MockSender)
+	svc := auth.NewService(mockRepo, mockMailer, stubSigner{}, passwordpolicy.Default(), testHasher, onetimetoken.NewService(newMemoryTokens()))
+	rejectedUser(mockRepo)
+
+	rejection, err := svc.GetRejection("user-id")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []auth.RejectedField{
+		{Field: "last_name", Reason: "illegible", Value: "Muster"},
+		{Field: "city", Reason: "unknown city", Value: "Berlin"},
+	}, rejection.Fields)
+	assert.Equal(t, map[string]string{"document": "missing"}, rejection.Other)
+}
+
+// TestGetRejection_NotRejected verifies that only rejected users see rejection reasons.
+func TestGetRejection_NotRejected(t *testing.T) {
+	mockRepo := new(MockUserRepo)
+	mockMailer := new(MockSender)
+	svc := auth.NewService(mockRepo, mockMailer, stubSigner{}, passwordpolicy.Default(), testHasher, onetimetoken.NewService(newMemoryTokens()))
+
+	mockRepo.On("GetByID", "user-id").Return(&auth.User{ID: "user-id", Status: auth.StatusApproved}, nil)
+	mockRepo.On("GetProfile", "user-id").Return(testProfile(), nil)
+
+	_, err := svc.GetRejection("user-id")
+
+	assert.ErrorIs(t, err, auth.ErrNotRejected)
+}
+
+// TestResubmit_MovesToPending verifies that correcting the rejected fields resubmits the registration.
+func TestResubmit_MovesToPending(t *testing.T) {
+	mockRepo := new(MockUserRepo)
+	mockMailer := new(MockSender)
+	svc := auth.NewService(mockRepo, mockMailer, stubSigner{}, passwordpolicy.Default(), testHasher, onetimetoken.NewService(newMemoryTokens()))
+	rejectedUser(mockRepo)
+	mockRepo.On("UpdateProfile", mock.AnythingOfType("*auth.UserProfile"), mock.AnythingOfType("*user.ProfileChange"), mock.Anything).Return(nil)
+
+	lastName, city := "Mustermann", "Hamburg"
+	user, err := svc.Resubmit("user-id", auth.ProfileUpdate{LastName: &lastName, City: &city})
+
+	assert.NoError(t, err)
+	assert.Equal(t, auth.StatusPending, user.Status)
+
+	change := mockRepo.Calls[len(mockRepo.Calls)-1].Arguments.Get(1).(*domainuser.ProfileChange)
+	assert.Equal(t, "rejection-id", *change.RejectionID)
+	assert.Equal(t, auth.StatusRejected, change.PreviousStatus)
+	assert.Equal(t, auth.StatusPending, change.NewStatus)
+	assert.Len(t, change.Changes, 2)
+}
+
+// TestResubmit_FieldsMustMatchRejection verifies that missing and additional fields are refused.
+func TestResubmit_FieldsMustMatchRejection(t *testing.T) {
+	mockRepo := new(MockUserRepo)
+	mockMailer := new(MockSender)
+	svc := auth.NewService(mockRepo, mockMailer, stubSigner{}, passwordpolicy.Default(), testHasher, onetimetoken.NewService(newMemoryTokens()))
+	rejectedUser(mockRepo)
+
+	lastName, street := "Mustermann", "Nebenstr."
+	_, err := svc.Resubmit("user-id", auth.ProfileUpdate{LastName: &lastName, Street: &street})
+
+	var mismatch *auth.ResubmissionError
+	assert.ErrorAs(t, err, &mismatch)
+	assert.Equal(t, []string{"city"}, mismatch.Missing)
+	assert.Equal(t, []string{"street"}, mismatch.Unexpected)
+	mockRepo.AssertNotCalled(t, "UpdateProfile", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestResubmit_RejectedFieldsMustChange verifies that rejected fields sent back with
+// their old values are refused.
+func TestResubmit_RejectedFieldsMustChange(t *testing.T) {
+	mockRepo := new(MockUserRepo)
+	mockMailer := new(MockSender)
+	svc := auth.NewService(mockRepo, mockMailer, stubSigner{}, passwordpolicy.Default(), testHasher, onetimetoken.NewService(newMemoryTokens()))
+	rejectedUser(mockRepo)
+
+	lastName, city := "Mustermann", " Berlin "
+	_, err := svc.Resubmit("user-id", auth.ProfileUpdate{LastName: &lastName, City: &city})
+
+	var mismatch *auth.ResubmissionError
+	assert.ErrorAs(t, err, &mismatch)
+	assert.Equal(t, []string{"city"}, mismatch.Unchanged)
+	mockRepo.AssertNotCalled(t, "UpdateProfile", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestUpdateProfile_RejectedNeedsResubmission verifies that rejected users cannot bypass
+// the resubmission through a regular profile edit.
+func TestUpdateProfile_RejectedNeedsResubmission(t *testing.T) {
+	mockRepo := new(MockUserRepo)
+	mockMailer := new(MockSender)
+	svc := auth.NewService(mockRepo, mockMailer, stubSigner{}, passwordpolicy.Default(), testHasher, onetimetoken.NewService(newMemoryTokens()))
+	rejectedUser(mockRepo)
+
+	city := "Hamburg"
+	_, _, err := svc.UpdateProfile("user-id", auth.ProfileUpdate{City: &city})
+
+	assert.ErrorIs(t, err, auth.ErrResubmissionRequired)
+}
+
+// TestAddUserProfile_SubmitsForModeration verifies that creating the profile moves a new user to pending.
+func TestAddUserProfile_SubmitsForModeration(t *testing.T) {
+	mockRepo := new(MockUserRepo)
+	mockMailer := new(MockSender)
+	svc := auth.NewService(mockRepo, mockMailer, stubSigner{}, passwordpolicy.Default(), testHasher, onetimetoken.NewService(newMemoryTokens()))
+
+	mockRepo.On("GetByID", "user-id").Return(&auth.User{ID: "user-id", Status: auth.StatusCreated}, nil)
+	mockRepo.On("GetProfile", "user-id").Return((*auth.UserProfile)(nil), nil)
+	mockRepo.On("CreateProfile", mock.AnythingOfType("*auth.UserProfile"), mock.AnythingOfType("*user.StatusChange")).Return(nil)
+
+	err := svc.AddUserProfile(testProfile())
+
+	assert.NoError(t, err)
+	change := mockRepo.Calls[len(mockRepo.Calls)-1].Arguments.Get(1).(*domainuser.StatusChange)
+	assert.Equal(t, domainuser.TransitionSubmit, change.Transition)
+	assert.Equal(t, auth.StatusCreated, change.From)
+	assert.Equal(t, auth.StatusPending, change.To)
+}
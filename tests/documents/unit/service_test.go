@@ -0,0 +1,266 @@
+package unit
+
+import (
+	"bytes"
+
+	domainuser "carowebapp/core/internal/domain/user"
+
+	"carowebapp/core/internal/features/documents"
+
+	"carowebapp/core/internal/infrastructure/storage"
+
+	"encoding/json"
+
+	"errors"
+
+	"io"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/stretchr/testify/require"
+
+	"go.uber.org/zap"
+
+	"strings"
+
+	"testing"
+
+	"time"
+)
+
+type MockDocumentRepo struct {
+	mock.Mock
+}
+
+func (m *MockDocumentRepo) GetUserStatus(userID string) (string, error) {
+	args := m.Called(userID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockDocumentRepo) ListDocuments(userID string) ([]domainuser.Document, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]domainuser.Document), args.Error(1)
+}
+
+func (m *MockDocumentRepo) GetDocument(userID, documentID string) (*domainuser.Document, error) {
+	args := m.Called(userID, documentID)
+	return args.Get(0).(*domainuser.Document), args.Error(1)
+}
+
+func (m *MockDocumentRepo) CreateDocument(doc *domainuser.Document) error {
+	return m.Called(doc).Error(0)
+}
+
+func (m *MockDocumentRepo) DeleteDocument(documentID string) error {
+	return m.Called(documentID).Error(0)
+}
+
+func (m *MockDocumentRepo) ReviewDocument(doc *domainuser.Document, entry domainuser.AuditEntry) error {
+	return m.Called(doc, entry).Error(0)
+}
+
+func (m *MockDocumentRepo) ListExpiredDocuments(approvedBefore time.Time, limit int) ([]domainuser.Document, error) {
+	args := m.Called(approvedBefore, limit)
+	return args.Get(0).([]domainuser.Document), args.Error(1)
+}
+
+const userID = "4b0c5bde-55a3-4c8a-9d36-0f6f3d3c7a11"
+
+// pngHeader is the signature that makes http.DetectContentType report image/png.
+var pngHeader = []byte("\x89PNG\x0D\x0A\x1A\x0A\x00\x00\x00\x0DIHDR")
+
+func newService(t *testing.T, repo *MockDocumentRepo) (*documents.Service, *storage.Local) {
+	store, err := storage.NewLocal(t.TempDir())
+	require.NoError(t, err)
+	return documents.NewService(repo, store, 30*24*time.Hour, zap.NewNop()), store
+}
+
+func storedDocument(t *testing.T, store *storage.Local, id, status string) domainuser.Document {
+	doc := domainuser.Document{ID: id, UserID: userID, StorageKey: userID + "/" + id, Status: status}
+	require.NoError(t, store.Put(doc.StorageKey, bytes.NewReader(pngHeader)))
+	return doc
+}
+
+// TestUpload_SniffsContentType verifies that the stored type comes from the content,
+// not from the file name, and that the file lands in the store.
+func TestUpload_SniffsContentType(t *testing.T) {
+	repo := new(MockDocumentRepo)
+	svc, store := newService(t, repo)
+
+	repo.On("GetUserStatus", userID).Return(domainuser.StatusPending, nil)
+	repo.On("ListDocuments", userID).Return([]domainuser.Document{}, nil)
+	repo.On("CreateDocument", mock.Anything).Return(nil)
+
+	doc, err := svc.Upload(userID, domainuser.DocumentIdentity, `C:\scans\passport.pdf`, bytes.NewReader(pngHeader))
+
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", doc.ContentType)
+	assert.Equal(t, "passport.pdf", doc.FileName)
+	assert.Equal(t, domainuser.DocumentPending, doc.Status)
+	assert.Equal(t, int64(len(pngHeader)), doc.Size)
+
+	content, err := store.Open(doc.StorageKey)
+	require.NoError(t, err)
+	defer func() { _ = content.Close() }()
+	stored, _ := io.ReadAll(content)
+	assert.Equal(t, pngHeader, stored)
+}
+
+// TestUpload_RejectsInvalidFiles verifies the type, format and size checks.
+func TestUpload_RejectsInvalidFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		docType string
+		content []byte
+		want    error
+	}{
+		{"unknown type", "selfie", pngHeader, documents.ErrUnknownType},
+		{"html", domainuser.DocumentIdentity, []byte("<html><script>alert(1)</script></html>"), documents.ErrUnsupportedContent},
+		{"empty", domainuser.DocumentIdentity, nil, documents.ErrEmptyDocument},
+		{"too large", domainuser.DocumentIdentity, append(append([]byte{}, pngHeader...), make([]byte, documents.MaxSize)...), documents.ErrTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockDocumentRepo)
+			svc, _ := newService(t, repo)
+			repo.On("GetUserStatus", userID).Return(domainuser.StatusPending, nil)
+			repo.On("ListDocuments", userID).Return([]domainuser.Document{}, nil)
+
+			_, err := svc.Upload(userID, tt.docType, "file", bytes.NewReader(tt.content))
+
+			assert.ErrorIs(t, err, tt.want)
+			repo.AssertNotCalled(t, "CreateDocument", mock.Anything)
+		})
+	}
+}
+
+// TestUpload_ClosedAfterApproval verifies that approved users cannot change their documents.
+func TestUpload_ClosedAfterApproval(t *testing.T) {
+	repo := new(MockDocumentRepo)
+	svc, _ := newService(t, repo)
+	repo.On("GetUserStatus", userID).Return(domainuser.StatusApproved, nil)
+
+	_, err := svc.Upload(userID, domainuser.DocumentIdentity, "id.png", bytes.NewReader(pngHeader))
+
+	assert.ErrorIs(t, err, documents.ErrUploadClosed)
+}
+
+// TestUpload_RemovesFileWhenMetadataFails verifies that no orphaned file is left behind.
+func TestUpload_RemovesFileWhenMetadataFails(t *testing.T) {
+	repo := new(MockDocumentRepo)
+	svc, store := newService(t, repo)
+
+	var key string
+	repo.On("GetUserStatus", userID).Return(domainuser.StatusCreated, nil)
+	repo.On("ListDocuments", userID).Return([]domainuser.Document{}, nil)
+	repo.On("CreateDocument", mock.Anything).Return(errors.New("db down")).
+		Run(func(args mock.Arguments) { key = args.Get(0).(*domainuser.Document).StorageKey })
+
+	_, err := svc.Upload(userID, domainuser.DocumentIdentity, "id.png", bytes.NewReader(pngHeader))
+
+	assert.Error(t, err)
+	_, err = store.Open(key)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+// TestDelete_KeepsReviewedDocuments verifies that applicants cannot remove documents a moderator reviewed.
+func TestDelete_KeepsReviewedDocuments(t *testing.T) {
+	repo := new(MockDocumentRepo)
+	svc, store := newService(t, repo)
+	doc := storedDocument(t, store, "doc-id", domainuser.DocumentAccepted)
+
+	repo.On("GetUserStatus", userID).Return(domainuser.StatusRejected, nil)
+	repo.On("GetDocument", userID, "doc-id").Return(&doc, nil)
+
+	err := svc.Delete(userID, "doc-id")
+
+	assert.ErrorIs(t, err, documents.ErrAlreadyReviewed)
+	repo.AssertNotCalled(t, "DeleteDocument", mock.Anything)
+}
+
+// TestReview_RecordsAuditEntry verifies that a review is stored with its audit log entry,
+// which leaves out the note.
+func TestReview_RecordsAuditEntry(t *testing.T) {
+	repo := new(MockDocumentRepo)
+	svc, store := newService(t, repo)
+	doc := storedDocument(t, store, "doc-id", domainuser.DocumentPending)
+	actor := domainuser.Actor{ID: "admin-id", IP: "127.0.0.1"}
+
+	var entry domainuser.AuditEntry
+	repo.On("GetDocument", userID, "doc-id").Return(&doc, nil)
+	repo.On("ReviewDocument", mock.Anything, mock.Anything).Return(nil).
+		Run(func(args mock.Arguments) { entry = args.Get(1).(domainuser.AuditEntry) })
+
+	reviewed, err := svc.Review(actor, userID, "doc-id", domainuser.DocumentRejected, "address does not match")
+
+	require.NoError(t, err)
+	assert.Equal(t, domainuser.DocumentRejected, reviewed.Status)
+	assert.Equal(t, "admin-id", *reviewed.ReviewedBy)
+	assert.Equal(t, "address does not match", *reviewed.ReviewNote)
+	assert.Equal(t, domainuser.AuditUserDocumentReviewed, entry.Action)
+	assert.Equal(t, userID, entry.TargetID)
+	assert.Equal(t, domainuser.AuditState{"document_id": "doc-id", "status": domainuser.DocumentRejected}, entry.After)
+}
+
+// TestNewApplicantDocument_HidesReviewDetails verifies that applicants see neither the
+// moderator who reviewed a document nor the internal note.
+func TestNewApplicantDocument_HidesReviewDetails(t *testing.T) {
+	note, reviewer, now := "address does not match", "admin-id", time.Now()
+	doc := domainuser.Document{ID: "doc-id", UserID: userID, Status: domainuser.DocumentRejected,
+		ReviewNote: &note, ReviewedBy: &reviewer, ReviewedAt: &now}
+
+	body, err := json.Marshal(documents.NewApplicantDocument(doc))
+
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"status":"rejected"`)
+	assert.NotContains(t, string(body), note)
+	assert.NotContains(t, string(body), reviewer)
+}
+
+// TestReview_RejectsUnknownStatus verifies that a document cannot be put back to pending.
+func TestReview_RejectsUnknownStatus(t *testing.T) {
+	repo := new(MockDocumentRepo)
+	svc, _ := newService(t, repo)
+
+	_, err := svc.Review(domainuser.Actor{ID: "admin-id"}, userID, "doc-id", domainuser.DocumentPending, "")
+
+	assert.ErrorIs(t, err, documents.ErrInvalidReview)
+	repo.AssertNotCalled(t, "ReviewDocument", mock.Anything, mock.Anything)
+}
+
+// TestPurgeExpired_DeletesFilesAndSkipsFailures verifies that expired documents lose
+// their file and metadata and that one failure does not stop the run.
+func TestPurgeExpired_DeletesFilesAndSkipsFailures(t *testing.T) {
+	repo := new(MockDocumentRepo)
+	svc, store := newService(t, repo)
+	failing := storedDocument(t, store, "failing", domainuser.DocumentAccepted)
+	expired := storedDocument(t, store, "expired", domainuser.DocumentAccepted)
+
+	var cutoff time.Time
+	repo.On("ListExpiredDocuments", mock.AnythingOfType("time.Time"), mock.AnythingOfType("int")).
+		Return([]domainuser.Document{failing, expired}, nil).
+		Run(func(args mock.Arguments) { cutoff = args.Get(0).(time.Time) })
+	repo.On("DeleteDocument", "failing").Return(errors.New("db down"))
+	repo.On("DeleteDocument", "expired").Return(nil)
+
+	deleted, err := svc.PurgeExpired()
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+	assert.WithinDuration(t, time.Now().Add(-30*24*time.Hour), cutoff, time.Minute)
+	_, err = store.Open(expired.StorageKey)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+// TestLocal_RejectsKeysOutsideTheStore verifies that keys cannot point outside the directory.
+func TestLocal_RejectsKeysOutsideTheStore(t *testing.T) {
+	store, err := storage.NewLocal(t.TempDir())
+	require.NoError(t, err)
+
+	for _, key := range []string{"../secret", "a/../../b", "/etc/passwd", "", "a//b", strings.Repeat("a", 256)} {
+		assert.ErrorIs(t, store.Put(key, bytes.NewReader(pngHeader)), storage.ErrInvalidKey, key)
+	}
+}
@@ -0,0 +1,44 @@
+package unit
+
+import (
+	"carowebapp/core/internal/infrastructure/email"
+
+	"context"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.uber.org/zap"
+
+	"sync/atomic"
+
+	"testing"
+
+	"time"
+)
+
+// TestQueue_RefusesWhenFull verifies that Enqueue never blocks the caller.
+func TestQueue_RefusesWhenFull(t *testing.T) {
+	queue := email.NewQueue(zap.NewNop(), 1)
+
+	assert.True(t, queue.Enqueue("a@example.com", "approval", func() error { return nil }))
+	assert.False(t, queue.Enqueue("b@example.com", "approval", func() error { return nil }))
+	assert.Equal(t, 1, queue.Len())
+}
+
+// TestQueue_RunSendsQueuedEmails verifies that the workers deliver everything in the queue.
+func TestQueue_RunSendsQueuedEmails(t *testing.T) {
+	queue := email.NewQueue(zap.NewNop(), 10)
+	var sent atomic.Int32
+	for i := 0; i < 5; i++ {
+		queue.Enqueue("user@example.com", "approval", func() error {
+			sent.Add(1)
+			return nil
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go queue.Run(ctx, 2)
+
+	assert.Eventually(t, func() bool { return sent.Load() == 5 }, time.Second, 10*time.Millisecond)
+}
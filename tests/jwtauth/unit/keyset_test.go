@@ -0,0 +1,108 @@
+package unit
+
+import (
+	"carowebapp/core/internal/infrastructure/jwtauth"
+
+	"crypto/ed25519"
+
+	"crypto/rand"
+
+	"testing"
+
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newKey(t *testing.T, id string) jwtauth.Key {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return jwtauth.Key{ID: id, PrivateKey: private}
+}
+
+// TestSignAndParse verifies that signed tokens carry the registered claims and verify.
+func TestSignAndParse(t *testing.T) {
+	keys, err := jwtauth.NewKeySet("core", "web", "k1", newKey(t, "k1"))
+	require.NoError(t, err)
+
+	token, err := keys.Sign(jwt.MapClaims{"sub": "user-id"}, time.Minute)
+	require.NoError(t, err)
+
+	claims, err := keys.Parse(token)
+	require.NoError(t, err)
+	assert.Equal(t, "user-id", claims["sub"])
+	assert.Equal(t, "core", claims["iss"])
+	assert.NotEmpty(t, claims["jti"])
+	assert.NotEmpty(t, claims["iat"])
+}
+
+// TestParse_AcceptsRotatedKey verifies that tokens signed with a previous key
+// still verify after the active key was rotated.
+func TestParse_AcceptsRotatedKey(t *testing.T) {
+	oldKey, newKeyPair := newKey(t, "old"), newKey(t, "new")
+
+	before, err := jwtauth.NewKeySet("core", "web", "old", oldKey)
+	require.NoError(t, err)
+	token, err := before.Sign(jwt.MapClaims{"sub": "user-id"}, time.Minute)
+	require.NoError(t, err)
+
+	after, err := jwtauth.NewKeySet("core", "web", "new", oldKey, newKeyPair)
+	require.NoError(t, err)
+
+	_, err = after.Parse(token)
+	assert.NoError(t, err)
+	assert.Len(t, after.JWKS().Keys, 2)
+}
+
+// TestParse_RejectsSymmetricToken verifies that HS256 tokens are rejected, even
+// when they are signed with an empty secret.
+func TestParse_RejectsSymmetricToken(t *testing.T) {
+	keys, err := jwtauth.NewKeySet("core", "web", "k1", newKey(t, "k1"))
+	require.NoError(t, err)
+
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": "user-id",
+		"iss": "core",
+		"aud": "web",
+		"iat": time.Now().Unix(),
+		"exp": time.Now().Add(time.Minute).Unix(),
+		"jti": "id",
+	})
+	forged.Header["kid"] = "k1"
+	token, err := forged.SignedString([]byte(""))
+	require.NoError(t, err)
+
+	_, err = keys.Parse(token)
+	assert.Error(t, err)
+}
+
+// TestParse_RejectsWrongAudience verifies that tokens for another audience are rejected.
+func TestParse_RejectsWrongAudience(t *testing.T) {
+	key := newKey(t, "k1")
+	issuer, err := jwtauth.NewKeySet("core", "admin-panel", "k1", key)
+	require.NoError(t, err)
+	verifier, err := jwtauth.NewKeySet("core", "web", "k1", key)
+	require.NoError(t, err)
+
+	token, err := issuer.Sign(jwt.MapClaims{"sub": "user-id"}, time.Minute)
+	require.NoError(t, err)
+
+	_, err = verifier.Parse(token)
+	assert.Error(t, err)
+}
+
+// TestNewKeySet_RequiresSigningKey verifies that a key set without a private
+// active key cannot be created.
+func TestNewKeySet_RequiresSigningKey(t *testing.T) {
+	key := newKey(t, "k1")
+
+	_, err := jwtauth.NewKeySet("core", "web", "k1", jwtauth.Key{ID: "k1", PublicKey: key.PrivateKey.Public()})
+	assert.ErrorIs(t, err, jwtauth.ErrNoSigningKey)
+
+	_, err = jwtauth.NewKeySet("core", "web", "missing", key)
+	assert.ErrorIs(t, err, jwtauth.ErrNoSigningKey)
+}
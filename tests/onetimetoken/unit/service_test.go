@@ -0,0 +1,190 @@
+package unit
+
+import (
+	"crypto/sha256"
+
+	"encoding/hex"
+
+	"carowebapp/core/internal/features/onetimetoken"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stretchr/testify/mock"
+
+	"testing"
+
+	"time"
+)
+
+type MockTokenRepo struct {
+	mock.Mock
+}
+
+func (m *MockTokenRepo) Create(token *onetimetoken.Token, revokeActive bool) error {
+	args := m.Called(token, revokeActive)
+	return args.Error(0)
+}
+
+func (m *MockTokenRepo) GetByHash(purpose onetimetoken.Purpose, tokenHash string) (*onetimetoken.Token, error) {
+	args := m.Called(purpose, tokenHash)
+	return args.Get(0).(*onetimetoken.Token), args.Error(1)
+}
+
+func (m *MockTokenRepo) RecordAttempt(id string) (int, error) {
+	args := m.Called(id)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockTokenRepo) MarkUsed(id string) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTokenRepo) RevokeActive(userID string, purpose onetimetoken.Purpose) error {
+	args := m.Called(userID, purpose)
+	return args.Error(0)
+}
+
+func sha256Hex(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+// activeToken returns a stored token for "token" that can still be used.
+func activeToken(purpose onetimetoken.Purpose) *onetimetoken.Token {
+	return &onetimetoken.Token{
+		ID:          "token-id",
+		Purpose:     purpose,
+		UserID:      "user-id",
+		TokenHash:   sha256Hex("token"),
+		MaxAttempts: 3,
+		ExpiresAt:   time.Now().Add(time.Minute),
+	}
+}
+
+// TestIssue_StoresDigestWithPolicy verifies that only digests are stored and that
+// the purpose's policy decides lifetime, attempt limit and revocation of older tokens.
+func TestIssue_StoresDigestWithPolicy(t *testing.T) {
+	repo := new(MockTokenRepo)
+	svc := onetimetoken.NewService(repo)
+
+	var stored *onetimetoken.Token
+	repo.On("Create", mock.AnythingOfType("*onetimetoken.Token"), true).Return(nil).
+		Run(func(args mock.Arguments) { stored = args.Get(0).(*onetimetoken.Token) })
+
+	token, err := svc.Issue(onetimetoken.PurposePasswordReset, "user-id", "")
+
+	assert.NoError(t, err)
+	assert.Len(t, token, 64)
+	assert.Equal(t, sha256Hex(token), stored.TokenHash)
+	assert.Nil(t, stored.BindingHash)
+	assert.Equal(t, onetimetoken.Policies[onetimetoken.PurposePasswordReset].MaxAttempts, stored.MaxAttempts)
+	assert.WithinDuration(t, time.Now().Add(30*time.Minute), stored.ExpiresAt, time.Second)
+	repo.AssertExpectations(t)
+}
+
+// TestIssue_UnknownPurpose verifies that purposes without a policy are rejected.
+func TestIssue_UnknownPurpose(t *testing.T) {
+	repo := new(MockTokenRepo)
+	svc := onetimetoken.NewService(repo)
+
+	_, err := svc.Issue("newsletter", "user-id", "")
+
+	assert.ErrorIs(t, err, onetimetoken.ErrUnknownPurpose)
+	repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+// TestCheck_CountsAttemptsAndStopsAtLimit verifies that every check is counted
+// and that a token stops working once its attempts are exhausted.
+func TestCheck_CountsAttemptsAndStopsAtLimit(t *testing.T) {
+	repo := new(MockTokenRepo)
+	svc := onetimetoken.NewService(repo)
+
+	repo.On("GetByHash", onetimetoken.PurposePasswordReset, sha256Hex("token")).
+		Return(activeToken(onetimetoken.PurposePasswordReset), nil)
+	repo.On("RecordAttempt", "token-id").Return(3, nil).Once()
+	repo.On("RecordAttempt", "token-id").Return(4, nil).Once()
+
+	token, err := svc.Check(onetimetoken.PurposePasswordReset, "token", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "user-id", token.UserID)
+
+	_, err = svc.Check(onetimetoken.PurposePasswordReset, "token", "")
+	assert.ErrorIs(t, err, onetimetoken.ErrInvalidToken)
+	repo.AssertExpectations(t)
+}
+
+// TestCheck_RejectsExpiredRevokedAndUnknown verifies the remaining reasons a token is refused.
+func TestCheck_RejectsExpiredRevokedAndUnknown(t *testing.T) {
+	now := time.Now()
+
+	expired := activeToken(onetimetoken.PurposeEmailConfirmation)
+	expired.ExpiresAt = now.Add(-time.Second)
+
+	revoked := activeToken(onetimetoken.PurposeEmailConfirmation)
+	revoked.RevokedAt = &now
+
+	used := activeToken(onetimetoken.PurposeEmailConfirmation)
+	used.UsedAt = &now
+
+	for name, stored := range map[string]*onetimetoken.Token{"expired": expired, "revoked": revoked, "used": used} {
+		t.Run(name, func(t *testing.T) {
+			repo := new(MockTokenRepo)
+			svc := onetimetoken.NewService(repo)
+			repo.On("GetByHash", onetimetoken.PurposeEmailConfirmation, sha256Hex("token")).Return(stored, nil)
+			repo.On("RecordAttempt", "token-id").Return(1, nil)
+
+			_, err := svc.Check(onetimetoken.PurposeEmailConfirmation, "token", "")
+
+			assert.ErrorIs(t, err, onetimetoken.ErrInvalidToken)
+		})
+	}
+
+	t.Run("unknown", func(t *testing.T) {
+		repo := new(MockTokenRepo)
+		svc := onetimetoken.NewService(repo)
+		repo.On("GetByHash", onetimetoken.PurposeEmailConfirmation, sha256Hex("token")).Return((*onetimetoken.Token)(nil), nil)
+
+		_, err := svc.Check(onetimetoken.PurposeEmailConfirmation, "token", "")
+
+		assert.ErrorIs(t, err, onetimetoken.ErrInvalidToken)
+		repo.AssertNotCalled(t, "RecordAttempt", mock.Anything)
+	})
+}
+
+// TestConsume_RequiresBinding verifies that a bound token is only accepted together with its binding.
+func TestConsume_RequiresBinding(t *testing.T) {
+	repo := new(MockTokenRepo)
+	svc := onetimetoken.NewService(repo)
+
+	stored := activeToken(onetimetoken.PurposeLoginLink)
+	binding := sha256Hex("nonce")
+	stored.BindingHash = &binding
+	repo.On("GetByHash", onetimetoken.PurposeLoginLink, sha256Hex("token")).Return(stored, nil)
+	repo.On("RecordAttempt", "token-id").Return(1, nil)
+	repo.On("MarkUsed", "token-id").Return(true, nil)
+
+	_, err := svc.Consume(onetimetoken.PurposeLoginLink, "token", "other")
+	assert.ErrorIs(t, err, onetimetoken.ErrInvalidToken)
+	repo.AssertNotCalled(t, "MarkUsed", mock.Anything)
+
+	_, err = svc.Consume(onetimetoken.PurposeLoginLink, "token", "nonce")
+	assert.NoError(t, err)
+	repo.AssertCalled(t, "MarkUsed", "token-id")
+}
+
+// TestConsume_UsedConcurrently verifies that only one of two parallel requests can use a token.
+func TestConsume_UsedConcurrently(t *testing.T) {
+	repo := new(MockTokenRepo)
+	svc := onetimetoken.NewService(repo)
+
+	repo.On("GetByHash", onetimetoken.PurposePasswordReset, sha256Hex("token")).
+		Return(activeToken(onetimetoken.PurposePasswordReset), nil)
+	repo.On("RecordAttempt", "token-id").Return(1, nil)
+	repo.On("MarkUsed", "token-id").Return(false, nil)
+
+	token, err := svc.Consume(onetimetoken.PurposePasswordReset, "token", "")
+
+	assert.Nil(t, token)
+	assert.ErrorIs(t, err, onetimetoken.ErrInvalidToken)
+}
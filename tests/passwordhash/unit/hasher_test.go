@@ -0,0 +1,113 @@
+package unit
+
+import (
+	"carowebapp/core/internal/pkg/passwordhash"
+
+	"github.com/stretchr/testify/assert"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"testing"
+
+	"time"
+)
+
+var cheapParams = passwordhash.Argon2idParams{
+	Memory:      1024,
+	Iterations:  1,
+	Parallelism: 1,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+// TestHasher_Argon2idRoundTrip verifies that a fresh hash verifies and needs no rehash.
+func TestHasher_Argon2idRoundTrip(t *testing.T) {
+	hasher := passwordhash.NewHasher(&passwordhash.Argon2id{Params: cheapParams}, 1, time.Second)
+
+	hash, err := hasher.Hash("correct horse")
+	assert.NoError(t, err)
+	assert.Contains(t, hash, "$argon2id$v=19$m=1024,t=1,p=1$")
+
+	match, needsRehash, err := hasher.Verify(hash, "correct horse")
+	assert.NoError(t, err)
+	assert.True(t, match)
+	assert.False(t, needsRehash)
+
+	match, _, err = hasher.Verify(hash, "wrong horse")
+	assert.NoError(t, err)
+	assert.False(t, match)
+}
+
+// TestHasher_OutdatedParametersNeedRehash verifies that a hash made with other
+// Argon2id parameters still matches but is reported for rehashing.
+func TestHasher_OutdatedParametersNeedRehash(t *testing.T) {
+	old := passwordhash.NewHasher(&passwordhash.Argon2id{Params: cheapParams}, 1, time.Second)
+	hash, err := old.Hash("correct horse")
+	assert.NoError(t, err)
+
+	stronger := cheapParams
+	stronger.Iterations = 2
+	current := passwordhash.NewHasher(&passwordhash.Argon2id{Params: stronger}, 1, time.Second)
+
+	match, needsRehash, err := current.Verify(hash, "correct horse")
+	assert.NoError(t, err)
+	assert.True(t, match)
+	assert.True(t, needsRehash)
+}
+
+// TestHasher_LegacyBcrypt verifies that bcrypt hashes keep working and are
+// reported for rehashing when Argon2id is the current algorithm.
+func TestHasher_LegacyBcrypt(t *testing.T) {
+	hasher := passwordhash.NewHasher(&passwordhash.Argon2id{Params: cheapParams}, 1, time.Second,
+		&passwordhash.Bcrypt{Cost: bcrypt.MinCost},
+	)
+
+	legacy, err := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+	assert.NoError(t, err)
+
+	match, needsRehash, err := hasher.Verify(string(legacy), "correct horse")
+	assert.NoError(t, err)
+	assert.True(t, match)
+	assert.True(t, needsRehash)
+
+	match, needsRehash, err = hasher.Verify(string(legacy), "wrong horse")
+	assert.NoError(t, err)
+	assert.False(t, match)
+	assert.False(t, needsRehash)
+}
+
+// TestHasher_UnknownAlgorithm verifies that unrecognised hashes are an error, not a mismatch.
+func TestHasher_UnknownAlgorithm(t *testing.T) {
+	hasher := passwordhash.NewHasher(&passwordhash.Argon2id{Params: cheapParams}, 1, time.Second)
+
+	_, _, err := hasher.Verify("$1$md5crypt", "correct horse")
+	assert.ErrorIs(t, err, passwordhash.ErrUnknownAlgorithm)
+}
+
+// blockingAlgorithm holds the worker slot until released.
+type blockingAlgorithm struct {
+	passwordhash.Argon2id
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingAlgorithm) Hash(string) (string, error) {
+	close(b.started)
+	<-b.release
+	return "", nil
+}
+
+// TestHasher_BusyWhenWorkersExhausted verifies that callers give up with ErrBusy
+// instead of queueing forever when every worker is occupied.
+func TestHasher_BusyWhenWorkersExhausted(t *testing.T) {
+	alg := &blockingAlgorithm{started: make(chan struct{}), release: make(chan struct{})}
+	hasher := passwordhash.NewHasher(alg, 1, 20*time.Millisecond)
+
+	go func() { _, _ = hasher.Hash("first") }()
+	<-alg.started
+
+	_, err := hasher.Hash("second")
+	assert.ErrorIs(t, err, passwordhash.ErrBusy)
+
+	close(alg.release)
+}
@@ -0,0 +1,99 @@
+package unit
+
+import (
+	"carowebapp/core/internal/pkg/passwordpolicy"
+
+	"crypto/sha1"
+
+	"encoding/hex"
+
+	"errors"
+
+	"os"
+
+	"path/filepath"
+
+	"strings"
+
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stretchr/testify/require"
+)
+
+func violationCodes(t *testing.T, err error) []string {
+	var policyErr *passwordpolicy.ViolationError
+	require.True(t, errors.As(err, &policyErr))
+
+	codes := make([]string, 0, len(policyErr.Violations))
+	for _, v := range policyErr.Violations {
+		codes = append(codes, v.Code)
+	}
+	return codes
+}
+
+// TestValidate_ReportsAllViolations verifies that every broken rule is reported at once.
+func TestValidate_ReportsAllViolations(t *testing.T) {
+	policy := &passwordpolicy.Policy{
+		MinLength:    10,
+		MaxBytes:     72,
+		RequireUpper: true,
+		RequireDigit: true,
+	}
+
+	err := policy.Validate("short")
+
+	assert.ErrorIs(t, err, passwordpolicy.ErrWeakPassword)
+	assert.ElementsMatch(t, []string{
+		passwordpolicy.CodeTooShort,
+		passwordpolicy.CodeMissingUpper,
+		passwordpolicy.CodeMissingDigit,
+	}, violationCodes(t, err))
+}
+
+// TestValidate_RejectsBytesBeyondBcryptLimit verifies that passwords longer than
+// bcrypt can hash are rejected instead of silently truncated.
+func TestValidate_RejectsBytesBeyondBcryptLimit(t *testing.T) {
+	err := passwordpolicy.Default().Validate(strings.Repeat("ä", 40))
+
+	assert.Equal(t, []string{passwordpolicy.CodeTooLong}, violationCodes(t, err))
+}
+
+// TestValidate_RejectsPersonalInfo verifies that the email local part and names are blocked.
+func TestValidate_RejectsPersonalInfo(t *testing.T) {
+	policy := passwordpolicy.Default()
+
+	err := policy.Validate("Mueller2024!", "anna.mueller@example.com", "Anna", "Mueller")
+	assert.Equal(t, []string{passwordpolicy.CodeContainsPersonal}, violationCodes(t, err))
+
+	err = policy.Validate("xxanna.muellerxx", "anna.mueller@example.com")
+	assert.Equal(t, []string{passwordpolicy.CodeContainsPersonal}, violationCodes(t, err))
+
+	assert.NoError(t, policy.Validate("correct horse battery", "anna.mueller@example.com", "Anna", "Mueller"))
+}
+
+// TestRangeCorpus_DetectsBreachedPassword verifies the lookup in a SHA-1 prefix range file.
+func TestRangeCorpus_DetectsBreachedPassword(t *testing.T) {
+	dir := t.TempDir()
+
+	sum := sha1.Sum([]byte("password123"))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	content := "0000000000000000000000000000000000A:1\n" + hash[5:] + ":42\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, hash[:5]+".txt"), []byte(content), 0o600))
+
+	corpus, err := passwordpolicy.NewRangeCorpus(dir)
+	require.NoError(t, err)
+
+	breached, err := corpus.IsBreached("password123")
+	require.NoError(t, err)
+	assert.True(t, breached)
+
+	breached, err = corpus.IsBreached("a much better passphrase")
+	require.NoError(t, err)
+	assert.False(t, breached)
+
+	policy := passwordpolicy.Default()
+	policy.Breached = corpus
+	assert.Equal(t, []string{passwordpolicy.CodeBreached}, violationCodes(t, policy.Validate("password123")))
+}
@@ -0,0 +1,450 @@
+package unit
+
+import (
+	"archive/zip"
+
+	"bytes"
+
+	"encoding/csv"
+
+	"encoding/json"
+
+	"errors"
+
+	domainuser "carowebapp/core/internal/domain/user"
+
+	"carowebapp/core/internal/features/onetimetoken"
+
+	"carowebapp/core/internal/features/privacy"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/stretchr/testify/require"
+
+	"go.uber.org/zap"
+
+	"sync"
+
+	"testing"
+
+	"time"
+)
+
+type MockPrivacyRepo struct {
+	mock.Mock
+}
+
+func (m *MockPrivacyRepo) GetAccount(userID string) (*privacy.Account, error) {
+	args := m.Called(userID)
+	return args.Get(0).(*privacy.Account), args.Error(1)
+}
+
+func (m *MockPrivacyRepo) FindUserIDByEmail(email string) (string, error) {
+	args := m.Called(email)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockPrivacyRepo) Export(userID string) (*privacy.Export, error) {
+	args := m.Called(userID)
+	return args.Get(0).(*privacy.Export), args.Error(1)
+}
+
+func (m *MockPrivacyRepo) RecordExport(event domainuser.SecurityEvent, entry *domainuser.AuditEntry) error {
+	args := m.Called(event, entry)
+	return args.Error(0)
+}
+
+func (m *MockPrivacyRepo) GetActiveDeletion(userID string) (*privacy.Deletion, error) {
+	args := m.Called(userID)
+	return args.Get(0).(*privacy.Deletion), args.Error(1)
+}
+
+func (m *MockPrivacyRepo) CreateDeletion(deletion *privacy.Deletion, event domainuser.SecurityEvent, entry *domainuser.AuditEntry) error {
+	args := m.Called(deletion, event, entry)
+	return args.Error(0)
+}
+
+func (m *MockPrivacyRepo) CancelDeletion(userID string, event domainuser.SecurityEvent, entry *domainuser.AuditEntry) (bool, error) {
+	args := m.Called(userID, event, entry)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockPrivacyRepo) ListDueDeletions(now time.Time) ([]privacy.Deletion, error) {
+	args := m.Called(now)
+	return args.Get(0).([]privacy.Deletion), args.Error(1)
+}
+
+func (m *MockPrivacyRepo) DeleteAccount(deletion *privacy.Deletion, event domainuser.SecurityEvent, entry domainuser.AuditEntry) error {
+	args := m.Called(deletion, event, entry)
+	return args.Error(0)
+}
+
+type MockSender struct {
+	mock.Mock
+}
+
+func (m *MockSender) SendMail(to string, subject string, body string) error {
+	return m.Called(to, subject, body).Error(0)
+}
+
+func (m *MockSender) SendConfirmation(to string, token string) error {
+	return m.Called(to, token).Error(0)
+}
+
+func (m *MockSender) SendResetPasswordLink(to string, token string) error {
+	return m.Called(to, token).Error(0)
+}
+
+func (m *MockSender) SendApprovalNotification(email string) error {
+	return m.Called(email).Error(0)
+}
+
+func (m *MockSender) SendRejectionNotification(email, language string, errors map[string]string) error {
+	return m.Called(email, language, errors).Error(0)
+}
+
+func (m *MockSender) SendAccountLocked(to string, token string, until time.Time) error {
+	return m.Called(to, token, until).Error(0)
+}
+
+func (m *MockSender) SendMagicLink(to string, token string) error {
+	return m.Called(to, token).Error(0)
+}
+
+func (m *MockSender) SendEmailChangeConfirmation(to string, token string) error {
+	return m.Called(to, token).Error(0)
+}
+
+func (m *MockSender) SendEmailChangedAlert(to string, newEmail string, undoToken string) error {
+	return m.Called(to, newEmail, undoToken).Error(0)
+}
+
+func (m *MockSender) SendAccountDeletionConfirmation(to string, token string) error {
+	return m.Called(to, token).Error(0)
+}
+
+func (m *MockSender) SendAccountDeletionScheduled(to string, scheduledFor time.Time) error {
+	return m.Called(to, scheduledFor).Error(0)
+}
+
+func (m *MockSender) SendAccountDeleted(to string) error {
+	return m.Called(to).Error(0)
+}
+
+func (m *MockSender) SendAccountSuspended(to string, reason string, until time.Time) error {
+	return m.Called(to, reason, until).Error(0)
+}
+
+func (m *MockSender) SendAccountBanned(to string, reason string) error {
+	return m.Called(to, reason).Error(0)
+}
+
+func (m *MockSender) SendAccountReactivated(to string) error {
+	return m.Called(to).Error(0)
+}
+
+// stubPasswords accepts only the password "secret".
+type stubPasswords struct{}
+
+func (stubPasswords) CheckPassword(_ string, password string) (bool, error) {
+	return password == "secret", nil
+}
+
+// stubDocuments records the users whose documents were erased and fails for "fail-id".
+type stubDocuments struct {
+	mu     sync.Mutex
+	erased []string
+}
+
+func (d *stubDocuments) DeleteAll(userID string) error {
+	if userID == "fail-id" {
+		return errors.New("storage down")
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.erased = append(d.erased, userID)
+	return nil
+}
+
+// memoryTokens is an in-memory onetimetoken.Repository.
+type memoryTokens struct {
+	mu     sync.Mutex
+	tokens map[string]*onetimetoken.Token
+}
+
+func (m *memoryTokens) Create(token *onetimetoken.Token, _ bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored := *token
+	m.tokens[token.ID] = &stored
+	return nil
+}
+
+func (m *memoryTokens) GetByHash(purpose onetimetoken.Purpose, tokenHash string) (*onetimetoken.Token, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, token := range m.tokens {
+		if token.Purpose == purpose && token.TokenHash == tokenHash {
+			found := *token
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memoryTokens) RecordAttempt(id string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tokens[id].Attempts++
+	return m.tokens[id].Attempts, nil
+}
+
+func (m *memoryTokens) MarkUsed(id string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.tokens[id].UsedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	m.tokens[id].UsedAt = &now
+	return true, nil
+}
+
+func (m *memoryTokens) RevokeActive(string, onetimetoken.Purpose) error {
+	return nil
+}
+
+const gracePeriod = 14 * 24 * time.Hour
+
+func newService(repo *MockPrivacyRepo, sender *MockSender) *privacy.Service {
+	tokens := onetimetoken.NewService(&memoryTokens{tokens: map[string]*onetimetoken.Token{}})
+	return privacy.NewService(repo, sender, tokens, stubPasswords{}, &stubDocuments{}, gracePeriod, zap.NewNop())
+}
+
+var account = &privacy.Account{ID: "user-id", Email: "test@example.com"}
+
+// TestRequestDeletion_WrongPassword verifies that no confirmation is sent without the password.
+func TestRequestDeletion_WrongPassword(t *testing.T) {
+	repo := new(MockPrivacyRepo)
+	sender := new(MockSender)
+	svc := newService(repo, sender)
+
+	repo.On("GetAccount", "user-id").Return(account, nil)
+	repo.On("GetActiveDeletion", "user-id").Return((*privacy.Deletion)(nil), nil)
+
+	err := svc.RequestDeletion("user-id", "wrong")
+
+	assert.ErrorIs(t, err, privacy.ErrInvalidPassword)
+	sender.AssertNotCalled(t, "SendAccountDeletionConfirmation", mock.Anything, mock.Anything)
+}
+
+// TestDeletion_ConfirmSchedulesAfterGracePeriod verifies that the emailed link schedules
+// the deletion after the grace period, informs the user and works only once.
+func TestDeletion_ConfirmSchedulesAfterGracePeriod(t *testing.T) {
+	repo := new(MockPrivacyRepo)
+	sender := new(MockSender)
+	svc := newService(repo, sender)
+
+	repo.On("GetAccount", "user-id").Return(account, nil)
+	repo.On("GetActiveDeletion", "user-id").Return((*privacy.Deletion)(nil), nil)
+	tokens := make(chan string, 1)
+	sender.On("SendAccountDeletionConfirmation", "test@example.com", mock.AnythingOfType("string")).Return(nil).
+		Run(func(args mock.Arguments) { tokens <- args.String(1) })
+
+	assert.NoError(t, svc.RequestDeletion("user-id", "secret"))
+
+	var token string
+	select {
+	case token = <-tokens:
+	case <-time.After(time.Second):
+		t.Fatal("deletion confirmation email was not sent")
+	}
+
+	var event domainuser.SecurityEvent
+	repo.On("CreateDeletion", mock.AnythingOfType("*privacy.Deletion"), mock.Anything, (*domainuser.AuditEntry)(nil)).Return(nil).
+		Run(func(args mock.Arguments) { event = args.Get(1).(domainuser.SecurityEvent) })
+	sender.On("SendAccountDeletionScheduled", "test@example.com", mock.AnythingOfType("time.Time")).Return(nil)
+
+	deletion, err := svc.ConfirmDeletion(token, "127.0.0.1")
+	assert.NoError(t, err)
+	assert.Equal(t, privacy.RequestedByUser, deletion.RequestedBy)
+	assert.WithinDuration(t, time.Now().Add(gracePeriod), deletion.ScheduledFor, time.Second)
+	assert.Equal(t, domainuser.SecurityEventDeletionScheduled, event.Type)
+	assert.Equal(t, "user-id", event.UserID)
+
+	_, err = svc.ConfirmDeletion(token, "127.0.0.1")
+	assert.ErrorIs(t, err, privacy.ErrInvalidDeletionLink)
+	sender.AssertNumberOfCalls(t, "SendAccountDeletionScheduled", 1)
+}
+
+// TestRequestDeletion_AlreadyScheduled verifies that a second request is rejected.
+func TestRequestDeletion_AlreadyScheduled(t *testing.T) {
+	repo := new(MockPrivacyRepo)
+	sender := new(MockSender)
+	svc := newService(repo, sender)
+
+	repo.On("GetAccount", "user-id").Return(account, nil)
+	repo.On("GetActiveDeletion", "user-id").Return(&privacy.Deletion{ID: "deletion-id"}, nil)
+
+	assert.ErrorIs(t, svc.RequestDeletion("user-id", "secret"), privacy.ErrDeletionScheduled)
+}
+
+// TestExport_RecordsRequester verifies that exports are logged with who asked for them,
+// in the audit log as well when an admin asked.
+func TestExport_RecordsRequester(t *testing.T) {
+	repo := new(MockPrivacyRepo)
+	sender := new(MockSender)
+	svc := newService(repo, sender)
+
+	repo.On("Export", "user-id").Return(&privacy.Export{Account: *account}, nil)
+	repo.On("RecordExport", domainuser.SecurityEvent{
+		UserID:  "user-id",
+		Type:    domainuser.SecurityEventDataExported,
+		IP:      "127.0.0.1",
+		Details: map[string]any{"requested_by": privacy.RequestedByAdmin, "admin_id": "admin-id"},
+	}, mock.MatchedBy(func(entry *domainuser.AuditEntry) bool {
+		return entry != nil && entry.Action == domainuser.AuditUserDataExported &&
+			entry.TargetID == "user-id" && *entry.ActorID == "admin-id"
+	})).Return(nil)
+
+	export, err := svc.Export("user-id", privacy.RequestedByAdmin, "admin-id", "127.0.0.1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "test@example.com", export.Account.Email)
+	repo.AssertExpectations(t)
+}
+
+// TestExport_UnknownUser verifies that exports of unknown users fail.
+func TestExport_UnknownUser(t *testing.T) {
+	repo := new(MockPrivacyRepo)
+	sender := new(MockSender)
+	svc := newService(repo, sender)
+
+	repo.On("Export", "user-id").Return((*privacy.Export)(nil), nil)
+
+	_, err := svc.Export("user-id", privacy.RequestedByUser, "", "")
+
+	assert.ErrorIs(t, err, privacy.ErrUserNotFound)
+	repo.AssertNotCalled(t, "RecordExport", mock.Anything, mock.Anything)
+}
+
+// TestPurgeDue_SkipsFailures verifies that one failing account does not block the
+// others and that the deletion log entry does not point to the erased user.
+func TestPurgeDue_SkipsFailures(t *testing.T) {
+	repo := new(MockPrivacyRepo)
+	sender := new(MockSender)
+	svc := newService(repo, sender)
+
+	failing := privacy.Deletion{ID: "failing", UserID: "other-id", RequestedBy: privacy.RequestedByUser}
+	due := privacy.Deletion{ID: "due", UserID: "user-id", RequestedBy: privacy.RequestedByUser}
+	repo.On("ListDueDeletions", mock.AnythingOfType("time.Time")).Return([]privacy.Deletion{failing, due}, nil)
+	repo.On("GetAccount", "other-id").Return(&privacy.Account{ID: "other-id", Email: "other@example.com"}, nil)
+	repo.On("GetAccount", "user-id").Return(account, nil)
+	repo.On("DeleteAccount", mock.MatchedBy(func(d *privacy.Deletion) bool { return d.ID == "failing" }), mock.Anything, mock.Anything).
+		Return(errors.New("db down"))
+
+	var event domainuser.SecurityEvent
+	repo.On("DeleteAccount", mock.MatchedBy(func(d *privacy.Deletion) bool { return d.ID == "due" }), mock.Anything, mock.Anything).Return(nil).
+		Run(func(args mock.Arguments) { event = args.Get(1).(domainuser.SecurityEvent) })
+	sender.On("SendAccountDeleted", "test@example.com").Return(nil)
+
+	deleted, err := svc.PurgeDue()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+	assert.Equal(t, domainuser.SecurityEventAccountDeleted, event.Type)
+	assert.Empty(t, event.UserID)
+	sender.AssertNotCalled(t, "SendAccountDeleted", "other@example.com")
+	sender.AssertExpectations(t)
+}
+
+// TestDeleteNow_AuditsUnderDeletion verifies that an immediate deletion by an admin is
+// recorded in the audit log under the deletion, without naming the erased user.
+func TestDeleteNow_AuditsUnderDeletion(t *testing.T) {
+	repo := new(MockPrivacyRepo)
+	sender := new(MockSender)
+	svc := newService(repo, sender)
+
+	var scheduled *domainuser.AuditEntry
+	var completed domainuser.AuditEntry
+	repo.On("GetActiveDeletion", "user-id").Return((*privacy.Deletion)(nil), nil)
+	repo.On("GetAccount", "user-id").Return(account, nil)
+	repo.On("CreateDeletion", mock.AnythingOfType("*privacy.Deletion"), mock.Anything, mock.Anything).Return(nil).
+		Run(func(args mock.Arguments) { scheduled = args.Get(2).(*domainuser.AuditEntry) })
+	repo.On("DeleteAccount", mock.AnythingOfType("*privacy.Deletion"), mock.Anything, mock.Anything).Return(nil).
+		Run(func(args mock.Arguments) { completed = args.Get(2).(domainuser.AuditEntry) })
+	sender.On("SendAccountDeleted", "test@example.com").Return(nil)
+
+	require.NoError(t, svc.DeleteNow("user-id", privacy.RequestedByAdmin, "admin-id", "127.0.0.1"))
+
+	deletion := repo.Calls[2].Arguments.Get(0).(*privacy.Deletion)
+	require.NotNil(t, scheduled)
+	for _, entry := range []domainuser.AuditEntry{*scheduled, completed} {
+		assert.Equal(t, domainuser.AuditTargetDeletion, entry.TargetType)
+		assert.Equal(t, deletion.ID, entry.TargetID)
+		assert.Equal(t, "admin-id", *entry.ActorID)
+		body, err := json.Marshal(entry)
+		require.NoError(t, err)
+		assert.NotContains(t, string(body), "user-id")
+	}
+	assert.Equal(t, domainuser.AuditDeletionScheduled, scheduled.Action)
+	assert.Equal(t, domainuser.AuditDeletionCompleted, completed.Action)
+}
+
+// TestPurgeDue_ErasesDocumentsFirst verifies that an account is kept while its
+// document files cannot be deleted, so no file outlives the account.
+func TestPurgeDue_ErasesDocumentsFirst(t *testing.T) {
+	repo := new(MockPrivacyRepo)
+	sender := new(MockSender)
+	tokens := onetimetoken.NewService(&memoryTokens{tokens: map[string]*onetimetoken.Token{}})
+	docs := &stubDocuments{}
+	svc := privacy.NewService(repo, sender, tokens, stubPasswords{}, docs, gracePeriod, zap.NewNop())
+
+	failing := privacy.Deletion{ID: "failing", UserID: "fail-id", RequestedBy: privacy.RequestedByUser}
+	due := privacy.Deletion{ID: "due", UserID: "user-id", RequestedBy: privacy.RequestedByUser}
+	repo.On("ListDueDeletions", mock.AnythingOfType("time.Time")).Return([]privacy.Deletion{failing, due}, nil)
+	repo.On("GetAccount", "fail-id").Return(&privacy.Account{ID: "fail-id", Email: "fail@example.com"}, nil)
+	repo.On("GetAccount", "user-id").Return(account, nil)
+	repo.On("DeleteAccount", mock.MatchedBy(func(d *privacy.Deletion) bool { return d.ID == "due" }), mock.Anything, mock.Anything).Return(nil)
+	sender.On("SendAccountDeleted", "test@example.com").Return(nil)
+
+	deleted, err := svc.PurgeDue()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+	assert.Equal(t, []string{"user-id"}, docs.erased)
+	repo.AssertNotCalled(t, "DeleteAccount", mock.MatchedBy(func(d *privacy.Deletion) bool { return d.ID == "failing" }), mock.Anything, mock.Anything)
+}
+
+// TestWriteZip_ContainsJSONAndCSV verifies the layout of the zip export.
+func TestWriteZip_ContainsJSONAndCSV(t *testing.T) {
+	export := &privacy.Export{
+		Account:  *account,
+		Sessions: []privacy.Session{{ID: "session-id", Device: "Laptop", CreatedAt: time.Now(), LastUsedAt: time.Now()}},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, privacy.WriteZip(&buf, export))
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+
+	files := map[string]*zip.File{}
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+	for _, name := range []string{"export.json", "account.csv", "profile.csv", "rejections.csv",
+		"sessions.csv", "status_history.csv", "documents.csv", "profile_changes.csv", "email_changes.csv", "security_events.csv", "tickets.csv"} {
+		assert.Contains(t, files, name)
+	}
+
+	reader, err := files["sessions.csv"].Open()
+	assert.NoError(t, err)
+	rows, err := csv.NewReader(reader).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, rows, 2)
+	assert.Equal(t, "session-id", rows[1][0])
+	assert.Equal(t, "Laptop", rows[1][1])
+}
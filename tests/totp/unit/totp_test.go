@@ -0,0 +1,59 @@
+package unit
+
+import (
+	"carowebapp/core/internal/pkg/totp"
+
+	"encoding/base32"
+
+	"strings"
+
+	"testing"
+
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rfcSecret is the SHA-1 shared secret from RFC 6238 Appendix B.
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+// TestCodeAt_RFC6238Vectors verifies the implementation against the RFC 6238 test vectors.
+func TestCodeAt_RFC6238Vectors(t *testing.T) {
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1111111111: "050471",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+
+	for unix, expected := range vectors {
+		code, err := totp.CodeAt(rfcSecret, totp.Step(time.Unix(unix, 0)))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, code, "time %d", unix)
+	}
+}
+
+// TestValidate_AllowsClockSkew verifies that codes from the neighbouring period are accepted
+// and codes from further away are rejected.
+func TestValidate_AllowsClockSkew(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	previous, _ := totp.CodeAt(rfcSecret, totp.Step(now)-1)
+	stale, _ := totp.CodeAt(rfcSecret, totp.Step(now)-3)
+
+	step, ok := totp.Validate(rfcSecret, previous, now)
+	assert.True(t, ok)
+	assert.Equal(t, totp.Step(now)-1, step)
+
+	_, ok = totp.Validate(rfcSecret, stale, now)
+	assert.False(t, ok)
+}
+
+// TestProvisioningURI verifies the otpauth URI contains the secret and issuer.
+func TestProvisioningURI(t *testing.T) {
+	uri := totp.ProvisioningURI("CaroWebApp", "admin@example.com", "ABCDEF")
+
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/CaroWebApp:admin@example.com?"))
+	assert.Contains(t, uri, "secret=ABCDEF")
+	assert.Contains(t, uri, "issuer=CaroWebApp")
+}
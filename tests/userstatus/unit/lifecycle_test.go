@@ -0,0 +1,114 @@
+package unit
+
+import (
+	domainuser "carowebapp/core/internal/domain/user"
+
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stretchr/testify/require"
+)
+
+var complete = domainuser.StatusFacts{EmailConfirmed: true, HasProfile: true}
+
+// TestChangeStatus_Lifecycle walks a registration through rejection, resubmission,
+// approval and a later profile edit.
+func TestChangeStatus_Lifecycle(t *testing.T) {
+	steps := []struct {
+		transition domainuser.Transition
+		to         string
+	}{
+		{domainuser.TransitionSubmit, domainuser.StatusPending},
+		{domainuser.TransitionReject, domainuser.StatusRejected},
+		{domainuser.TransitionResubmit, domainuser.StatusPending},
+		{domainuser.TransitionApprove, domainuser.StatusApproved},
+		{domainuser.TransitionProfileEdited, domainuser.StatusPending},
+	}
+
+	status := domainuser.StatusCreated
+	for _, step := range steps {
+		change, err := domainuser.ChangeStatus("user-id", status, step.transition, complete, "")
+		require.NoError(t, err, step.transition)
+		assert.Equal(t, status, change.From)
+		assert.Equal(t, step.to, change.To)
+		status = change.To
+	}
+}
+
+// TestChangeStatus_ForbiddenTransition verifies that transitions only start from their source statuses.
+func TestChangeStatus_ForbiddenTransition(t *testing.T) {
+	_, err := domainuser.ChangeStatus("user-id", domainuser.StatusCreated, domainuser.TransitionApprove, complete, "admin-id")
+	assert.ErrorIs(t, err, domainuser.ErrTransitionForbidden)
+
+	_, err = domainuser.ChangeStatus("user-id", domainuser.StatusApproved, domainuser.TransitionReject, complete, "admin-id")
+	assert.ErrorIs(t, err, domainuser.ErrTransitionForbidden)
+
+	_, err = domainuser.ChangeStatus("user-id", "email_confirmed", domainuser.TransitionSubmit, complete, "")
+	assert.ErrorIs(t, err, domainuser.ErrTransitionForbidden)
+}
+
+// TestChangeStatus_ApprovalGuards verifies that approval needs a confirmed email and a profile.
+func TestChangeStatus_ApprovalGuards(t *testing.T) {
+	_, err := domainuser.ChangeStatus("user-id", domainuser.StatusPending, domainuser.TransitionApprove,
+		domainuser.StatusFacts{HasProfile: true}, "admin-id")
+	assert.ErrorIs(t, err, domainuser.ErrEmailNotConfirmed)
+
+	_, err = domainuser.ChangeStatus("user-id", domainuser.StatusPending, domainuser.TransitionApprove,
+		domainuser.StatusFacts{EmailConfirmed: true}, "admin-id")
+	assert.ErrorIs(t, err, domainuser.ErrProfileRequired)
+
+	change, err := domainuser.ChangeStatus("user-id", domainuser.StatusPending, domainuser.TransitionApprove, complete, "admin-id")
+	require.NoError(t, err)
+	require.NotNil(t, change.ActorID)
+	assert.Equal(t, "admin-id", *change.ActorID)
+}
+
+// TestChangeStatus_UnknownTransition verifies that only modelled transitions are accepted.
+func TestChangeStatus_UnknownTransition(t *testing.T) {
+	_, err := domainuser.ChangeStatus("user-id", domainuser.StatusPending, domainuser.Transition("activate"), complete, "")
+	assert.ErrorIs(t, err, domainuser.ErrUnknownTransition)
+}
+
+// TestChangeStatus_SuspendAndBan verifies that any account can be suspended or banned and
+// that a ban can follow a suspension but not the other way round.
+func TestChangeStatus_SuspendAndBan(t *testing.T) {
+	unconfirmed := domainuser.StatusFacts{}
+	for _, status := range []string{domainuser.StatusCreated, domainuser.StatusPending, domainuser.StatusApproved, domainuser.StatusRejected} {
+		change, err := domainuser.ChangeStatus("user-id", status, domainuser.TransitionSuspend, unconfirmed, "admin-id")
+		require.NoError(t, err, status)
+		assert.Equal(t, domainuser.StatusSuspended, change.To)
+	}
+
+	change, err := domainuser.ChangeStatus("user-id", domainuser.StatusSuspended, domainuser.TransitionBan, complete, "admin-id")
+	require.NoError(t, err)
+	assert.Equal(t, domainuser.StatusBanned, change.To)
+
+	_, err = domainuser.ChangeStatus("user-id", domainuser.StatusBanned, domainuser.TransitionSuspend, complete, "admin-id")
+	assert.ErrorIs(t, err, domainuser.ErrTransitionForbidden)
+}
+
+// TestReactivate_RestoresPreviousStatus verifies that reactivation only lifts blocks and
+// returns to the status from before.
+func TestReactivate_RestoresPreviousStatus(t *testing.T) {
+	change, err := domainuser.Reactivate("user-id", domainuser.StatusSuspended, domainuser.StatusApproved, "")
+	require.NoError(t, err)
+	assert.Equal(t, domainuser.StatusApproved, change.To)
+	assert.Equal(t, domainuser.TransitionReactivate, change.Transition)
+	assert.Nil(t, change.ActorID)
+
+	_, err = domainuser.Reactivate("user-id", domainuser.StatusApproved, domainuser.StatusPending, "admin-id")
+	assert.ErrorIs(t, err, domainuser.ErrTransitionForbidden)
+
+	_, err = domainuser.Reactivate("user-id", domainuser.StatusBanned, domainuser.StatusSuspended, "admin-id")
+	assert.ErrorIs(t, err, domainuser.ErrUnknownStatus)
+}
+
+// TestOverrideStatus_Blocks verifies that overrides neither set nor leave the blocked statuses.
+func TestOverrideStatus_Blocks(t *testing.T) {
+	_, err := domainuser.OverrideStatus("user-id", domainuser.StatusApproved, domainuser.StatusBanned, complete, "admin-id", "spam")
+	assert.ErrorIs(t, err, domainuser.ErrTransitionForbidden)
+
+	_, err = domainuser.OverrideStatus("user-id", domainuser.StatusSuspended, domainuser.StatusApproved, complete, "admin-id", "early")
+	assert.ErrorIs(t, err, domainuser.ErrTransitionForbidden)
+}